@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"slices"
 	"time"
 
 	"github.com/alecthomas/kong"
@@ -12,11 +13,11 @@ import (
 	"github.com/buildkite/buildkite-logs/logparser"
 	"github.com/buildkite/buildkite-mcp-server/internal/commands"
 	"github.com/buildkite/buildkite-mcp-server/internal/headerpassthrough"
+	"github.com/buildkite/buildkite-mcp-server/pkg/buildkite"
 	"github.com/buildkite/buildkite-mcp-server/pkg/recording"
 	"github.com/buildkite/buildkite-mcp-server/pkg/trace"
 	gobuildkite "github.com/buildkite/go-buildkite/v5"
 	"github.com/mattn/go-isatty"
-	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 )
 
@@ -24,20 +25,44 @@ var (
 	version = "dev"
 
 	cli struct {
-		Stdio                 commands.StdioCmd `cmd:"" help:"stdio mcp server."`
-		HTTP                  commands.HTTPCmd  `cmd:"" help:"http mcp server using streamable HTTP transport."`
-		Tools                 commands.ToolsCmd `cmd:"" help:"list available tools." hidden:""`
-		APIToken              string            `help:"The Buildkite API token to use." env:"BUILDKITE_API_TOKEN"`
-		APITokenFrom1Password string            `help:"The 1Password item to read the Buildkite API token from. Format: 'op://vault/item/field'" env:"BUILDKITE_API_TOKEN_FROM_1PASSWORD"`
-		BaseURL               string            `help:"The base URL of the Buildkite API to use." env:"BUILDKITE_BASE_URL" default:"https://api.buildkite.com/"`
-		CacheURL              string            `help:"The blob storage URL for job logs cache." env:"BKLOG_CACHE_URL"`
-		MaxLogBytes           int64             `help:"Maximum log size in bytes. Set to 0 to disable the limit." env:"BKLOG_MAX_LOG_BYTES" default:"104857600"`
-		MaxLogLineBytes       int               `help:"Maximum log line length in bytes to parse." env:"BKLOG_MAX_LOG_LINE_BYTES" default:"1048576"`
-		Debug                 bool              `help:"Enable debug mode." env:"DEBUG"`
-		OTELExporter          string            `help:"OpenTelemetry exporter to enable. Options are 'http/protobuf', 'grpc', or 'noop'." enum:"http/protobuf, grpc, noop" env:"OTEL_EXPORTER_OTLP_PROTOCOL" default:"noop"`
-		HTTPHeaders           []string          `help:"Additional HTTP headers to send with every request. Format: 'Key: Value'" name:"http-header" env:"BUILDKITE_HTTP_HEADERS"`
-		Record                string            `help:"Record API calls to this HAR file path." env:"BUILDKITE_RECORD"`
-		Replay                string            `help:"Replay recorded API calls from this HAR file path." env:"BUILDKITE_REPLAY"`
+		Stdio                 commands.StdioCmd    `cmd:"" help:"stdio mcp server."`
+		HTTP                  commands.HTTPCmd     `cmd:"" help:"http mcp server using streamable HTTP transport."`
+		Tools                 commands.ToolsCmd    `cmd:"" help:"list available tools." hidden:""`
+		Toolsets              commands.ToolsetsCmd `cmd:"" help:"list toolsets with their tool counts and the API token scopes they need."`
+		Doctor                commands.DoctorCmd   `cmd:"" help:"check the API token, organization access, log cache and tracing setup."`
+		APIToken              string               `help:"The Buildkite API token to use." env:"BUILDKITE_API_TOKEN"`
+		APITokenFile          string               `help:"Path to a file containing the Buildkite API token, such as a mounted secret." type:"path" env:"BUILDKITE_API_TOKEN_FILE"`
+		APITokenFrom1Password string               `help:"The 1Password item to read the Buildkite API token from. Format: 'op://vault/item/field'" env:"BUILDKITE_API_TOKEN_FROM_1PASSWORD"`
+		APITokenFromAWSSecret string               `help:"The AWS Secrets Manager secret name or ARN to read the Buildkite API token from. Uses the default AWS credential chain." env:"BUILDKITE_API_TOKEN_FROM_AWS_SECRET"`
+		APITokenFromVault     string               `help:"The Vault KV secret to read the Buildkite API token from, using VAULT_ADDR and VAULT_TOKEN. Format: 'vault://mount/path#field'" env:"BUILDKITE_API_TOKEN_FROM_VAULT"`
+		BaseURL               string               `help:"The base URL of the Buildkite API to use." env:"BUILDKITE_BASE_URL" default:"https://api.buildkite.com/"`
+		GraphQLURL            string               `help:"The URL of the Buildkite GraphQL API for the graphql toolset. Defaults to https://graphql.buildkite.com/v1 when --base-url is the default, and must be set to use the toolset otherwise." name:"graphql-url" env:"BUILDKITE_GRAPHQL_URL"`
+		CacheURL              string               `help:"The blob storage URL for job logs cache." env:"BKLOG_CACHE_URL"`
+		Org                   string               `help:"Organization slug used by tools called without org_slug, for single-organization deployments. An org_slug in the call takes precedence." env:"BUILDKITE_ORG"`
+		APIMaxAttempts        int                  `help:"Maximum times to send a Buildkite API request that is rate limited or fails with a server error. Set to 1 to disable retries." name:"api-max-attempts" env:"BUILDKITE_API_MAX_ATTEMPTS" default:"4"`
+		CacheTTL              time.Duration        `help:"Cache results of read-only tools in memory for this long, e.g. '30s'. The cache is per process and entries are only removed when they expire. Disabled by default." name:"cache-ttl" env:"BUILDKITE_CACHE_TTL" default:"0s"`
+		MaxLogBytes           int64                `help:"Maximum job log size in bytes the log tools will download. Larger logs are refused before they are fetched. Set to 0 to disable the limit." env:"BKLOG_MAX_LOG_BYTES" default:"104857600"`
+		MaxLogLineBytes       int                  `help:"Maximum log line length in bytes to parse." env:"BKLOG_MAX_LOG_LINE_BYTES" default:"1048576"`
+		LogFetchConcurrency   int                  `help:"Maximum job logs to fetch at once for tools that read the logs of several jobs, such as get_build_failed_logs." env:"BUILDKITE_LOG_FETCH_CONCURRENCY" default:"4"`
+		SlowToolThreshold     time.Duration        `help:"Log a warning for tool calls that take longer than this, e.g. '10s'. Set to 0 to disable." name:"slow-tool-threshold" env:"BUILDKITE_MCP_SLOW_TOOL_THRESHOLD" default:"0s"`
+		LargeToolResultBytes  int                  `help:"Log a warning for tool calls whose result is larger than this many bytes of JSON. Set to 0 to disable." name:"large-tool-result-bytes" env:"BUILDKITE_MCP_LARGE_TOOL_RESULT_BYTES" default:"0"`
+		Debug                 bool                 `help:"Enable debug mode. The same as --log-level=debug." env:"DEBUG"`
+		LogLevel              string               `help:"Minimum level of log messages to write: trace, debug, info, warn or error." enum:"trace,debug,info,warn,error" default:"info" env:"BUILDKITE_LOG_LEVEL"`
+		LogFormat             string               `help:"Log format: 'json', 'console' for people to read, or 'auto', which uses console in an interactive terminal and json otherwise." enum:"auto,json,console" default:"auto" env:"BUILDKITE_LOG_FORMAT"`
+		PrintConfig           bool                 `help:"Print the configuration resolved from flags, environment variables, the config file and defaults, with secrets left out, and exit without running the command." name:"print-config"`
+		CheckVersion          bool                 `help:"Check GitHub for a newer release at startup and log a notice if there is one. The binary is never updated." name:"check-version" env:"BUILDKITE_MCP_CHECK_VERSION"`
+		Redact                bool                 `help:"Mask secrets such as AWS keys, bearer tokens and BUILDKITE_* tokens in the text of tool results and resources read. Matching is heuristic, so not every secret is caught." env:"BUILDKITE_MCP_REDACT"`
+		RedactPatterns        []string             `help:"Regular expression of another secret to mask with --redact. A pattern with a capture group only masks the group. May be repeated." name:"redact-pattern" sep:"none" env:"BUILDKITE_MCP_REDACT_PATTERN"`
+		DebugRateLimit        bool                 `help:"Add the Buildkite API rate limit from the last response of each tool call to the tool result metadata, as _rate_limit." env:"BUILDKITE_DEBUG_RATE_LIMIT"`
+		PrettyJSON            bool                 `help:"Indent the JSON of tool results, for transcripts read by people. Results are compact by default to save tokens." name:"pretty-json" env:"BUILDKITE_PRETTY_JSON"`
+		OTELExporter          string               `help:"OpenTelemetry exporter to enable. Options are 'http/protobuf', 'grpc', or 'noop'." enum:"http/protobuf, grpc, noop" env:"OTEL_EXPORTER_OTLP_PROTOCOL" default:"noop"`
+		OTELEndpoint          string               `help:"OTLP collector URL to export traces to, e.g. 'https://collector:4318'. Overrides OTEL_EXPORTER_OTLP_ENDPOINT. Tracing is disabled when no endpoint is configured." name:"otel-endpoint" env:"BUILDKITE_OTEL_ENDPOINT"`
+		OTELHeaders           map[string]string    `help:"Headers to send with trace exports, such as an API key. Format: 'key=value'. Overrides OTEL_EXPORTER_OTLP_HEADERS." name:"otel-headers" env:"BUILDKITE_OTEL_HEADERS"`
+		OTELSampleRatio       *float64             `help:"Fraction of traces to sample, from 0 to 1. Overrides OTEL_TRACES_SAMPLER. Defaults to sampling every trace." name:"otel-sample-ratio" env:"BUILDKITE_OTEL_SAMPLE_RATIO"`
+		HTTPHeaders           []string             `help:"Additional HTTP headers to send with every request. Format: 'Key: Value'. Repeat a header to send it with each of its values." name:"http-header" env:"BUILDKITE_HTTP_HEADERS"`
+		Record                string               `help:"Record API calls to this HAR file path." env:"BUILDKITE_RECORD"`
+		Replay                string               `help:"Replay recorded API calls from this HAR file path." env:"BUILDKITE_REPLAY"`
+		Config                kong.ConfigFlag      `help:"Path to a YAML config file of flag values, e.g. 'read-only: true'. Flags and environment variables take precedence over the file." type:"existingfile"`
 		Version               kong.VersionFlag
 	}
 )
@@ -49,20 +74,54 @@ func main() {
 		kong.Name("buildkite-mcp-server"),
 		kong.Description("A server that proxies requests to the Buildkite API."),
 		kong.UsageOnError(),
+		kong.Configuration(commands.YAMLConfig),
 		kong.Vars{
 			"version": version,
 		},
 		kong.BindTo(ctx, (*context.Context)(nil)),
 	)
 
-	log.Logger = setupLogger(cli.Debug)
+	logger, err := commands.NewLogger(os.Stderr, commands.LoggerConfig{
+		Level:       cli.LogLevel,
+		Debug:       cli.Debug,
+		Format:      cli.LogFormat,
+		Interactive: isatty.IsTerminal(os.Stdout.Fd()),
+	})
+	cmd.FatalIfErrorf(err)
+	log.Logger = logger
 
-	err := run(ctx, cmd)
+	err = run(ctx, cmd)
 	cmd.FatalIfErrorf(err)
 }
 
 func run(ctx context.Context, cmd *kong.Context) error {
-	tp, err := trace.NewProvider(ctx, cli.OTELExporter, "buildkite-mcp-server", version)
+	// Listing tools and toolsets doesn't call the API, so it works before a
+	// token is set up, such as to choose the token's scopes or to generate
+	// a client offline.
+	if (cmd.Command() == "tools" || cmd.Command() == "toolsets") && !cli.PrintConfig {
+		return cmd.Run(&commands.Globals{Version: version, Logger: log.Logger})
+	}
+
+	if cli.CheckVersion {
+		commands.StartVersionCheck(ctx, version)
+	}
+
+	traceOpts := []trace.ProviderOption{
+		trace.WithEndpoint(cli.OTELEndpoint),
+		trace.WithHeaders(cli.OTELHeaders),
+	}
+	if cli.OTELSampleRatio != nil {
+		if ratio := *cli.OTELSampleRatio; ratio < 0 || ratio > 1 {
+			return fmt.Errorf("--otel-sample-ratio must be between 0 and 1, got %v", ratio)
+		}
+		traceOpts = append(traceOpts, trace.WithSampleRatio(*cli.OTELSampleRatio))
+	}
+	// An exporter chosen without an endpoint has nowhere to send traces, so
+	// tracing is off; say so rather than leave the user waiting for them.
+	if cli.OTELExporter != "noop" && trace.ResolveExporter(cli.OTELExporter, cli.OTELEndpoint) == "noop" {
+		log.Warn().Str("exporter", cli.OTELExporter).Msg("Tracing is disabled as no OTLP endpoint is configured; set --otel-endpoint or OTEL_EXPORTER_OTLP_ENDPOINT to export traces")
+	}
+	tp, err := trace.NewProvider(ctx, cli.OTELExporter, "buildkite-mcp-server", version, traceOpts...)
 	if err != nil {
 		return fmt.Errorf("failed to create trace provider: %w", err)
 	}
@@ -70,8 +129,15 @@ func run(ctx context.Context, cmd *kong.Context) error {
 		_ = tp.Shutdown(ctx)
 	}()
 
-	// Parse additional headers into a map
-	headers := commands.ParseHeaders(cli.HTTPHeaders)
+	// Parse additional headers
+	headers, err := commands.ParseHeaders(cli.HTTPHeaders)
+	if err != nil {
+		return fmt.Errorf("invalid --http-header: %w", err)
+	}
+
+	if cli.PrintConfig {
+		return commands.PrintEffectiveConfig(os.Stdout, effectiveConfig(cmd.Command(), headers))
+	}
 
 	var passthrough *headerpassthrough.Config
 	if cmd.Command() == "http" && len(cli.HTTP.PassthroughHTTPHeaders) > 0 {
@@ -85,8 +151,41 @@ func run(ctx context.Context, cmd *kong.Context) error {
 		return fmt.Errorf("cannot specify both --record and --replay")
 	}
 
+	if err := commands.ValidateBaseURL(cli.BaseURL); err != nil {
+		return err
+	}
+
+	if cli.LogFetchConcurrency < 1 {
+		return fmt.Errorf("--log-fetch-concurrency must be at least 1, got %d", cli.LogFetchConcurrency)
+	}
+
+	if cli.SlowToolThreshold < 0 || cli.LargeToolResultBytes < 0 {
+		return fmt.Errorf("--slow-tool-threshold and --large-tool-result-bytes must not be negative")
+	}
+
+	var redactor *buildkite.Redactor
+	if cli.Redact {
+		redactor, err = buildkite.NewRedactor(append(slices.Clone(buildkite.DefaultRedactPatterns), cli.RedactPatterns...))
+		if err != nil {
+			return err
+		}
+	} else if len(cli.RedactPatterns) > 0 {
+		return fmt.Errorf("--redact-pattern requires --redact")
+	}
+
+	if cli.APIMaxAttempts < 1 {
+		return fmt.Errorf("--api-max-attempts must be at least 1, got %d", cli.APIMaxAttempts)
+	}
+
 	usesRequestAuthorization := passthrough != nil && passthrough.UsesAuthorization()
-	apiToken, err := resolveAPITokenForMode(passthrough, cli.Replay, cli.APIToken, cli.APITokenFrom1Password)
+	tokenSources := commands.APITokenSources{
+		Token:         cli.APIToken,
+		File:          cli.APITokenFile,
+		From1Password: cli.APITokenFrom1Password,
+		FromAWSSecret: cli.APITokenFromAWSSecret,
+		FromVault:     cli.APITokenFromVault,
+	}
+	apiToken, err := resolveAPITokenForMode(ctx, passthrough, cli.Replay, tokenSources)
 	if err != nil {
 		return err
 	}
@@ -96,11 +195,31 @@ func run(ctx context.Context, cmd *kong.Context) error {
 		return err
 	}
 
-	httpClient := trace.NewHTTPClientWithHeadersAndTransport(headers, innerTransport)
+	if cli.Replay == "" {
+		innerTransport = commands.NewBaseURLErrorTransport(innerTransport, cli.BaseURL)
+	}
+	if cli.DebugRateLimit {
+		innerTransport = buildkite.NewRateLimitTransport(innerTransport)
+	}
+
+	// Tokens from a secret store may expire or be rotated while the server
+	// runs, so they are re-resolved when the API rejects them.
+	if apiToken != "" && tokenSources.Refreshable() {
+		provider := commands.NewCredentialProvider(tokenSources, apiToken)
+		innerTransport, err = commands.NewRefreshingTransport(innerTransport, provider, cli.BaseURL)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Rate limited and failed requests are retried by the HTTP client, so the
+	// Buildkite client's own retries are disabled.
+	httpClient := trace.NewHTTPClientWithHeaderValuesAndTransport(headers, innerTransport, trace.WithMaxAttempts(cli.APIMaxAttempts))
 	clientOptions := []gobuildkite.ClientOpt{
 		gobuildkite.WithUserAgent(commands.UserAgent(version)),
 		gobuildkite.WithHTTPClient(httpClient),
 		gobuildkite.WithBaseURL(cli.BaseURL),
+		gobuildkite.WithMaxRetries(0),
 	}
 	if !usesRequestAuthorization {
 		clientOptions = append(clientOptions, gobuildkite.WithTokenAuth(apiToken))
@@ -135,14 +254,75 @@ func run(ctx context.Context, cmd *kong.Context) error {
 	})
 
 	return cmd.Run(&commands.Globals{
-		Version:             version,
-		Client:              client,
-		HTTPClient:          httpClient,
-		BuildkiteLogsClient: buildkiteLogsClient,
-		HeaderPassthrough:   passthrough,
+		Version:              version,
+		Logger:               log.Logger,
+		Client:               client,
+		HTTPClient:           httpClient,
+		BuildkiteLogsClient:  buildkiteLogsClient,
+		HeaderPassthrough:    passthrough,
+		GraphQLURL:           commands.ResolveGraphQLURL(cli.BaseURL, cli.GraphQLURL),
+		MaxLogBytes:          cli.MaxLogBytes,
+		LogFetchConcurrency:  cli.LogFetchConcurrency,
+		DebugRateLimit:       cli.DebugRateLimit,
+		PrettyJSON:           cli.PrettyJSON,
+		DefaultOrg:           cli.Org,
+		CacheTTL:             cli.CacheTTL,
+		CacheURL:             cli.CacheURL,
+		SlowToolThreshold:    cli.SlowToolThreshold,
+		LargeToolResultBytes: cli.LargeToolResultBytes,
+		Redactor:             redactor,
+		OTELExporter:         trace.ResolveExporter(cli.OTELExporter, cli.OTELEndpoint),
+		OTELEndpoint:         cli.OTELEndpoint,
 	})
 }
 
+// effectiveConfig describes the configuration of command from the parsed
+// flags, leaving out secrets such as the API token and header values.
+func effectiveConfig(command string, headers http.Header) commands.EffectiveConfig {
+	cfg := commands.EffectiveConfig{
+		Command:              command,
+		BaseURL:              commands.RedactURL(cli.BaseURL),
+		DefaultOrg:           cli.Org,
+		CacheURLSet:          cli.CacheURL != "",
+		CacheTTL:             cli.CacheTTL.String(),
+		LogLevel:             cli.LogLevel,
+		LogFormat:            cli.LogFormat,
+		Redact:               cli.Redact,
+		SlowToolThreshold:    cli.SlowToolThreshold.String(),
+		LargeToolResultBytes: cli.LargeToolResultBytes,
+		APITokenSet: commands.APITokenSources{
+			Token:         cli.APIToken,
+			File:          cli.APITokenFile,
+			From1Password: cli.APITokenFrom1Password,
+			FromAWSSecret: cli.APITokenFromAWSSecret,
+			FromVault:     cli.APITokenFromVault,
+		}.IsSet(),
+	}
+	for name := range headers {
+		cfg.HTTPHeaders = append(cfg.HTTPHeaders, name)
+	}
+	slices.Sort(cfg.HTTPHeaders)
+
+	switch command {
+	case "stdio":
+		cfg.EnabledToolsets = cli.Stdio.EnabledToolsets
+		cfg.ReadOnly = cli.Stdio.ReadOnly
+		cfg.DynamicToolsets = cli.Stdio.DynamicToolsets
+	case "http":
+		cfg.EnabledToolsets = cli.HTTP.EnabledToolsets
+		cfg.ReadOnly = cli.HTTP.ReadOnly
+		cfg.Listen = cli.HTTP.Listen
+		cfg.PassthroughHTTPHeaders = cli.HTTP.PassthroughHTTPHeaders
+		cfg.AuthMode = cli.HTTP.AuthMode
+		cfg.Metrics = cli.HTTP.Metrics
+		cfg.DebugTools = cli.HTTP.DebugTools
+	case "doctor":
+		cfg.EnabledToolsets = cli.Doctor.EnabledToolsets
+		cfg.ReadOnly = cli.Doctor.ReadOnly
+	}
+	return cfg
+}
+
 func newAPITransport(passthrough *headerpassthrough.Config, recordPath, replayPath, version string) (http.RoundTripper, error) {
 	if replayPath != "" {
 		transport, err := recording.NewReplayTransport(replayPath)
@@ -169,9 +349,9 @@ func newAPITransport(passthrough *headerpassthrough.Config, recordPath, replayPa
 	return transport, nil
 }
 
-func resolveAPITokenForMode(passthrough *headerpassthrough.Config, replay, token, tokenFrom1Password string) (string, error) {
+func resolveAPITokenForMode(ctx context.Context, passthrough *headerpassthrough.Config, replay string, sources commands.APITokenSources) (string, error) {
 	if passthrough != nil && passthrough.UsesAuthorization() {
-		if token != "" || tokenFrom1Password != "" {
+		if sources.IsSet() {
 			return "", fmt.Errorf("cannot configure a fixed Buildkite API token when passing through Authorization")
 		}
 		return "", nil
@@ -183,28 +363,9 @@ func resolveAPITokenForMode(passthrough *headerpassthrough.Config, replay, token
 		return "", nil
 	}
 
-	apiToken, err := commands.ResolveAPIToken(token, tokenFrom1Password)
+	apiToken, err := commands.ResolveAPIToken(ctx, sources)
 	if err != nil {
 		return "", fmt.Errorf("failed to resolve Buildkite API token: %w", err)
 	}
 	return apiToken, nil
 }
-
-func setupLogger(debug bool) zerolog.Logger {
-	var logger zerolog.Logger
-	level := zerolog.InfoLevel
-	if debug {
-		level = zerolog.DebugLevel
-	}
-
-	logger = zerolog.New(os.Stderr).Level(level).With().Timestamp().Stack().Logger()
-
-	// are we in an interactive terminal use a console writer
-	if isatty.IsTerminal(os.Stdout.Fd()) {
-		logger = logger.Output(zerolog.ConsoleWriter{Out: os.Stderr, FormatTimestamp: func(i any) string {
-			return time.Now().Format(time.Stamp)
-		}}).Level(level).With().Stack().Logger()
-	}
-
-	return logger
-}
@@ -0,0 +1,87 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/buildkite/buildkite-mcp-server/pkg/buildkite"
+	"github.com/buildkite/buildkite-mcp-server/pkg/toolsets"
+)
+
+// DefaultBaseURL is the default --base-url, the public Buildkite REST API.
+const DefaultBaseURL = "https://api.buildkite.com/"
+
+// ValidateBaseURL checks the Buildkite API base URL is an absolute HTTPS URL.
+// Plain HTTP is allowed for loopback hosts, such as a local proxy.
+func ValidateBaseURL(baseURL string) error {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return fmt.Errorf("invalid --base-url %q: %w", baseURL, err)
+	}
+	if !u.IsAbs() || u.Host == "" {
+		return fmt.Errorf("invalid --base-url %q: must be an absolute URL such as https://api.buildkite.com/", baseURL)
+	}
+
+	switch u.Scheme {
+	case "https":
+		return nil
+	case "http":
+		if ip := net.ParseIP(u.Hostname()); u.Hostname() == "localhost" || (ip != nil && ip.IsLoopback()) {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid --base-url %q: must use https", baseURL)
+}
+
+// ResolveGraphQLURL returns the GraphQL endpoint for the graphql toolset:
+// graphQLURL when it is set, or the public endpoint when baseURL is the public
+// REST API. It is empty otherwise, as the GraphQL endpoint of another API
+// can't be derived from its base URL.
+func ResolveGraphQLURL(baseURL, graphQLURL string) string {
+	if graphQLURL != "" {
+		return graphQLURL
+	}
+	if strings.TrimSuffix(baseURL, "/") == strings.TrimSuffix(DefaultBaseURL, "/") {
+		return buildkite.DefaultGraphQLURL
+	}
+	return ""
+}
+
+// checkGraphQLURL refuses to enable the graphql toolset without a GraphQL
+// endpoint, which would otherwise send queries, and the API token, to the
+// public API instead of the one --base-url points to.
+func checkGraphQLURL(enabledToolsets []string, graphQLURL string) error {
+	if graphQLURL == "" && slices.Contains(enabledToolsets, toolsets.ToolsetGraphQL) {
+		return fmt.Errorf("the graphql toolset needs --graphql-url (BUILDKITE_GRAPHQL_URL) when --base-url isn't %s", DefaultBaseURL)
+	}
+	return nil
+}
+
+// NewBaseURLErrorTransport adds the configured base URL to connection and DNS
+// errors, which otherwise don't hint that a wrong --base-url is the cause.
+func NewBaseURLErrorTransport(next http.RoundTripper, baseURL string) http.RoundTripper {
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		res, err := next.RoundTrip(req)
+		if err != nil && isConnectionError(err) {
+			return nil, fmt.Errorf("could not connect to the Buildkite API at %s, check --base-url (BUILDKITE_BASE_URL) is correct: %w", baseURL, err)
+		}
+		return res, err
+	})
+}
+
+func isConnectionError(err error) bool {
+	var dnsErr *net.DNSError
+	var opErr *net.OpError
+	return errors.As(err, &dnsErr) || (errors.As(err, &opErr) && opErr.Op == "dial")
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
@@ -0,0 +1,63 @@
+package commands
+
+import (
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateBaseURL(t *testing.T) {
+	tests := []struct {
+		baseURL string
+		wantErr string
+	}{
+		{baseURL: "https://api.buildkite.com/"},
+		{baseURL: "https://buildkite-proxy.internal:8443/"},
+		{baseURL: "http://localhost:8080/"},
+		{baseURL: "http://127.0.0.1:8080/"},
+		{baseURL: "http://github.example.com/", wantErr: "must use https"},
+		{baseURL: "ftp://api.buildkite.com/", wantErr: "must use https"},
+		{baseURL: "api.buildkite.com", wantErr: "must be an absolute URL"},
+		{baseURL: "https://%zz", wantErr: "invalid --base-url"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.baseURL, func(t *testing.T) {
+			err := ValidateBaseURL(tt.baseURL)
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestResolveGraphQLURL(t *testing.T) {
+	assert := require.New(t)
+
+	assert.Equal("https://graphql.buildkite.com/v1", ResolveGraphQLURL("https://api.buildkite.com/", ""))
+	assert.Equal("https://graphql.buildkite.com/v1", ResolveGraphQLURL("https://api.buildkite.com", ""))
+	assert.Equal("", ResolveGraphQLURL("https://buildkite-proxy.internal/", ""))
+	assert.Equal("https://graphql-proxy.internal/v1", ResolveGraphQLURL("https://buildkite-proxy.internal/", "https://graphql-proxy.internal/v1"))
+
+	assert.NoError(checkGraphQLURL([]string{"all"}, ""))
+	assert.NoError(checkGraphQLURL([]string{"all", "graphql"}, "https://graphql-proxy.internal/v1"))
+	assert.ErrorContains(checkGraphQLURL([]string{"all", "graphql"}, ""), "needs --graphql-url")
+}
+
+func TestBaseURLErrorTransport(t *testing.T) {
+	assert := require.New(t)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(err)
+	addr := listener.Addr().String()
+	assert.NoError(listener.Close())
+
+	client := &http.Client{Transport: NewBaseURLErrorTransport(http.DefaultTransport, "http://"+addr+"/")}
+	_, err = client.Get("http://" + addr + "/v2/user")
+	assert.ErrorContains(err, "could not connect to the Buildkite API at http://"+addr+"/, check --base-url (BUILDKITE_BASE_URL) is correct")
+	assert.ErrorContains(err, "connection refused")
+}
@@ -1,24 +1,50 @@
 package commands
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io/fs"
 	"net/http"
+	"os"
 	"os/exec"
 	"runtime"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
 	"github.com/buildkite/buildkite-mcp-server/internal/headerpassthrough"
 	"github.com/buildkite/buildkite-mcp-server/pkg/buildkite"
 	gobuildkite "github.com/buildkite/go-buildkite/v5"
+	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 )
 
 type Globals struct {
-	Client              *gobuildkite.Client
-	HTTPClient          *http.Client
-	BuildkiteLogsClient buildkite.BuildkiteLogsClient
-	HeaderPassthrough   *headerpassthrough.Config
-	Version             string
+	Logger               zerolog.Logger
+	Client               *gobuildkite.Client
+	HTTPClient           *http.Client
+	BuildkiteLogsClient  buildkite.BuildkiteLogsClient
+	HeaderPassthrough    *headerpassthrough.Config
+	GraphQLURL           string
+	MaxLogBytes          int64
+	LogFetchConcurrency  int
+	DebugRateLimit       bool
+	PrettyJSON           bool
+	DefaultOrg           string
+	CacheTTL             time.Duration
+	CacheURL             string
+	SlowToolThreshold    time.Duration
+	LargeToolResultBytes int
+	Redactor             *buildkite.Redactor
+	OTELExporter         string
+	OTELEndpoint         string
+	Version              string
 }
 
 func UserAgent(version string) string {
@@ -28,23 +54,153 @@ func UserAgent(version string) string {
 	return fmt.Sprintf("buildkite-mcp-server/%s (%s; %s)", version, os, arch)
 }
 
-func ResolveAPIToken(token, tokenFrom1Password string) (string, error) {
-	if token != "" && tokenFrom1Password != "" {
-		return "", fmt.Errorf("cannot specify both --api-token and --api-token-from-1password")
+// APITokenSources holds the ways the Buildkite API token can be supplied.
+// Exactly one of them must be set.
+type APITokenSources struct {
+	Token         string
+	File          string
+	From1Password string
+	FromAWSSecret string
+	FromVault     string
+}
+
+// flags returns the command line flags for the sources that are set.
+func (s APITokenSources) flags() []string {
+	var flags []string
+	if s.Token != "" {
+		flags = append(flags, "--api-token")
+	}
+	if s.File != "" {
+		flags = append(flags, "--api-token-file")
+	}
+	if s.From1Password != "" {
+		flags = append(flags, "--api-token-from-1password")
+	}
+	if s.FromAWSSecret != "" {
+		flags = append(flags, "--api-token-from-aws-secret")
+	}
+	if s.FromVault != "" {
+		flags = append(flags, "--api-token-from-vault")
+	}
+	return flags
+}
+
+// Refreshable reports whether the token comes from a source that can issue a
+// new token, such as a rotated secret, so it is worth re-resolving when the
+// current token is rejected.
+func (s APITokenSources) Refreshable() bool {
+	return s.File != "" || s.FromAWSSecret != "" || s.FromVault != ""
+}
+
+// cacheKey identifies the secret manager entry the token is fetched from.
+func (s APITokenSources) cacheKey() string {
+	switch {
+	case s.From1Password != "":
+		return "1password:" + s.From1Password
+	case s.FromAWSSecret != "":
+		return "aws:" + s.FromAWSSecret
+	case s.FromVault != "":
+		return "vault:" + s.FromVault
 	}
-	if token == "" && tokenFrom1Password == "" {
-		return "", fmt.Errorf("must specify either --api-token or --api-token-from-1password")
+	return ""
+}
+
+// IsSet reports whether any token source is configured.
+func (s APITokenSources) IsSet() bool {
+	return len(s.flags()) > 0
+}
+
+func ResolveAPIToken(ctx context.Context, sources APITokenSources) (string, error) {
+	switch flags := sources.flags(); {
+	case len(flags) > 1:
+		return "", fmt.Errorf("cannot specify both %s and %s", flags[0], flags[1])
+	case len(flags) == 0:
+		return "", fmt.Errorf("must specify one of --api-token, --api-token-file, --api-token-from-1password, --api-token-from-aws-secret or --api-token-from-vault")
+	}
+
+	switch {
+	case sources.Token != "":
+		return sources.Token, nil
+	case sources.File != "":
+		return readTokenFile(sources.File)
+	case sources.From1Password != "":
+		opToken, err := fetchedTokens.get(sources.cacheKey(), func() (string, error) {
+			return fetchTokenFrom1Password(sources.From1Password)
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to fetch API token from 1Password: %w", err)
+		}
+		return opToken, nil
+	case sources.FromAWSSecret != "":
+		awsToken, err := fetchedTokens.get(sources.cacheKey(), func() (string, error) {
+			return fetchTokenFromAWSSecret(ctx, sources.FromAWSSecret)
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to fetch API token from AWS Secrets Manager: %w", err)
+		}
+		return awsToken, nil
+	default:
+		vaultToken, err := fetchedTokens.get(sources.cacheKey(), func() (string, error) {
+			return fetchTokenFromVault(ctx, sources.FromVault)
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to fetch API token from Vault: %w", err)
+		}
+		return vaultToken, nil
 	}
-	if token != "" {
+}
+
+// fetchedTokens holds tokens fetched from secret managers, so each is fetched
+// at most once per process rather than prompting the user again, as `op` can.
+var fetchedTokens = &tokenCache{tokens: make(map[string]string)}
+
+// tokenCache memoizes token lookups by source. Failed lookups aren't cached, so
+// a transient failure can be retried.
+type tokenCache struct {
+	mu     sync.Mutex
+	tokens map[string]string
+}
+
+// get returns the cached token for key, calling fetch if there isn't one.
+// The lock is held during fetch so concurrent callers share a single lookup.
+func (c *tokenCache) get(key string, fetch func() (string, error)) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if token, ok := c.tokens[key]; ok {
 		return token, nil
 	}
+	token, err := fetch()
+	if err != nil {
+		return "", err
+	}
+	c.tokens[key] = token
+	return token, nil
+}
 
-	// Fetch the token from 1Password
-	opToken, err := fetchTokenFrom1Password(tokenFrom1Password)
+// forget drops the cached token for key so the next lookup fetches it again.
+func (c *tokenCache) forget(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.tokens, key)
+}
+
+// readTokenFile reads the token from a file, such as a mounted Kubernetes
+// secret, which usually ends in a newline.
+func readTokenFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", fmt.Errorf("API token file %s does not exist", path)
+	}
 	if err != nil {
-		return "", fmt.Errorf("failed to fetch API token from 1Password: %w", err)
+		return "", fmt.Errorf("failed to read API token file: %w", err)
+	}
+
+	token := strings.TrimRight(string(data), "\r\n")
+	if strings.TrimSpace(token) == "" {
+		return "", fmt.Errorf("API token file %s is empty", path)
 	}
-	return opToken, nil
+	return token, nil
 }
 
 func fetchTokenFrom1Password(opID string) (string, error) {
@@ -59,6 +215,132 @@ func fetchTokenFrom1Password(opID string) (string, error) {
 	return string(out), nil
 }
 
+// awsSecretRequestTimeout bounds reading a secret from AWS Secrets Manager,
+// so an unreachable endpoint fails startup or a token refresh instead of
+// hanging it.
+const awsSecretRequestTimeout = 10 * time.Second
+
+// fetchTokenFromAWSSecret reads a secret string from AWS Secrets Manager by
+// name or ARN, using the default AWS credential chain.
+func fetchTokenFromAWSSecret(ctx context.Context, secretID string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, awsSecretRequestTimeout)
+	defer cancel()
+
+	cfg, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to load AWS config: %w", err)
+	}
+
+	// An ARN names its own region, which takes precedence over the default.
+	if secretARN, err := arn.Parse(secretID); err == nil {
+		cfg.Region = secretARN.Region
+	}
+	if cfg.Region == "" {
+		return "", fmt.Errorf("no AWS region configured, set AWS_REGION or use the secret ARN")
+	}
+
+	out, err := secretsmanager.NewFromConfig(cfg).GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{
+		SecretId: aws.String(secretID),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get secret value: %w", err)
+	}
+
+	token := strings.TrimSpace(aws.ToString(out.SecretString))
+	if token == "" {
+		return "", fmt.Errorf("secret %q has no string value", secretID)
+	}
+
+	log.Info().Msg("Fetched API token from AWS Secrets Manager")
+
+	return token, nil
+}
+
+// vaultRequestTimeout bounds reading a secret from Vault, so an unreachable
+// server fails startup or a token refresh instead of hanging it.
+const vaultRequestTimeout = 10 * time.Second
+
+// fetchTokenFromVault reads a field of a Vault KV secret referenced as
+// vault://mount/path#field, authenticating with VAULT_ADDR and VAULT_TOKEN.
+// KV version 2 is tried first, falling back to version 1 if the secret isn't
+// found there.
+func fetchTokenFromVault(ctx context.Context, ref string) (string, error) {
+	rest, ok := strings.CutPrefix(ref, "vault://")
+	if !ok {
+		return "", fmt.Errorf("invalid Vault reference %q, expected format: vault://mount/path#field", ref)
+	}
+	secretPath, field, _ := strings.Cut(rest, "#")
+	mount, secretPath, _ := strings.Cut(secretPath, "/")
+	if mount == "" || secretPath == "" || field == "" {
+		return "", fmt.Errorf("invalid Vault reference %q, expected format: vault://mount/path#field", ref)
+	}
+
+	addr := os.Getenv("VAULT_ADDR")
+	if addr == "" {
+		return "", fmt.Errorf("VAULT_ADDR is not set")
+	}
+	vaultToken := os.Getenv("VAULT_TOKEN")
+	if vaultToken == "" {
+		return "", fmt.Errorf("VAULT_TOKEN is not set")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, vaultRequestTimeout)
+	defer cancel()
+
+	read := func(apiPath string) (map[string]any, int, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(addr, "/")+"/v1/"+apiPath, nil)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to create request: %w", err)
+		}
+		req.Header.Set("X-Vault-Token", vaultToken)
+		if namespace := os.Getenv("VAULT_NAMESPACE"); namespace != "" {
+			req.Header.Set("X-Vault-Namespace", namespace)
+		}
+
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return nil, 0, fmt.Errorf("request failed: %w", err)
+		}
+		defer res.Body.Close()
+
+		var out struct {
+			Data   map[string]any `json:"data"`
+			Errors []string       `json:"errors"`
+		}
+		if err := json.NewDecoder(res.Body).Decode(&out); err != nil && res.StatusCode == http.StatusOK {
+			return nil, res.StatusCode, fmt.Errorf("failed to decode response: %w", err)
+		}
+		if res.StatusCode != http.StatusOK {
+			return nil, res.StatusCode, fmt.Errorf("%s: %s", res.Status, strings.Join(out.Errors, "; "))
+		}
+		return out.Data, res.StatusCode, nil
+	}
+
+	data, status, err := read(mount + "/data/" + secretPath)
+	if status == http.StatusNotFound {
+		data, _, err = read(mount + "/" + secretPath)
+	} else if err == nil {
+		// KV version 2 nests the secret's fields alongside its metadata.
+		data, _ = data["data"].(map[string]any)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	value, ok := data[field].(string)
+	if !ok {
+		return "", fmt.Errorf("secret %s/%s has no string field %q", mount, secretPath, field)
+	}
+	token := strings.TrimSpace(value)
+	if token == "" {
+		return "", fmt.Errorf("field %q of secret %s/%s is empty", field, mount, secretPath)
+	}
+
+	log.Info().Msg("Fetched API token from Vault")
+
+	return token, nil
+}
+
 func expandExecErr(err error) error {
 	var exitErr *exec.ExitError
 	if errors.As(err, &exitErr) {
@@ -0,0 +1,175 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveAPIToken(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("static token", func(t *testing.T) {
+		token, err := ResolveAPIToken(ctx, APITokenSources{Token: "bkua_123"})
+		require.NoError(t, err)
+		require.Equal(t, "bkua_123", token)
+	})
+
+	t.Run("token file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "token")
+		require.NoError(t, os.WriteFile(path, []byte("bkua_123\n"), 0o600))
+
+		token, err := ResolveAPIToken(ctx, APITokenSources{File: path})
+		require.NoError(t, err)
+		require.Equal(t, "bkua_123", token)
+	})
+
+	t.Run("missing token file", func(t *testing.T) {
+		_, err := ResolveAPIToken(ctx, APITokenSources{File: filepath.Join(t.TempDir(), "token")})
+		require.ErrorContains(t, err, "does not exist")
+	})
+
+	t.Run("empty token file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "token")
+		require.NoError(t, os.WriteFile(path, []byte("\n"), 0o600))
+
+		_, err := ResolveAPIToken(ctx, APITokenSources{File: path})
+		require.ErrorContains(t, err, "is empty")
+	})
+
+	t.Run("no source", func(t *testing.T) {
+		_, err := ResolveAPIToken(ctx, APITokenSources{})
+		require.ErrorContains(t, err, "must specify one of --api-token, --api-token-file, --api-token-from-1password, --api-token-from-aws-secret or --api-token-from-vault")
+	})
+
+	t.Run("multiple sources", func(t *testing.T) {
+		_, err := ResolveAPIToken(ctx, APITokenSources{File: "/run/secrets/buildkite", FromAWSSecret: "buildkite/api-token"})
+		require.ErrorContains(t, err, "cannot specify both --api-token-file and --api-token-from-aws-secret")
+	})
+}
+
+func TestTokenCache(t *testing.T) {
+	assert := require.New(t)
+
+	cache := &tokenCache{tokens: make(map[string]string)}
+	calls := 0
+	fetch := func(token string, err error) func() (string, error) {
+		return func() (string, error) {
+			calls++
+			return token, err
+		}
+	}
+
+	// Failures are not cached, so the next lookup tries again.
+	_, err := cache.get("1password:op://vault/item/token", fetch("", errors.New("op: not signed in")))
+	assert.Error(err)
+
+	token, err := cache.get("1password:op://vault/item/token", fetch("bkua_123", nil))
+	assert.NoError(err)
+	assert.Equal("bkua_123", token)
+
+	token, err = cache.get("1password:op://vault/item/token", fetch("bkua_456", nil))
+	assert.NoError(err)
+	assert.Equal("bkua_123", token)
+	assert.Equal(2, calls)
+
+	token, err = cache.get("1password:op://vault/item/other", fetch("bkua_456", nil))
+	assert.NoError(err)
+	assert.Equal("bkua_456", token)
+	assert.Equal(3, calls)
+}
+
+func TestFetchTokenFromAWSSecret(t *testing.T) {
+	assert := require.New(t)
+
+	secretsManager := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal("secretsmanager.GetSecretValue", r.Header.Get("X-Amz-Target"))
+		assert.True(strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/"))
+		assert.Contains(r.Header.Get("Authorization"), "/ap-southeast-2/secretsmanager/aws4_request")
+
+		var in struct{ SecretId string }
+		assert.NoError(json.NewDecoder(r.Body).Decode(&in))
+		if in.SecretId != "buildkite/api-token" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"__type":"com.amazonaws.secretsmanager#ResourceNotFoundException","message":"Secrets Manager can't find the specified secret."}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"Name":"buildkite/api-token","SecretString":"bkua_123\n"}`))
+	}))
+	defer secretsManager.Close()
+
+	dir := t.TempDir()
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(dir, "config"))
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(dir, "credentials"))
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIDEXAMPLE")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	t.Setenv("AWS_REGION", "ap-southeast-2")
+	t.Setenv("AWS_ENDPOINT_URL", secretsManager.URL)
+
+	token, err := fetchTokenFromAWSSecret(context.Background(), "buildkite/api-token")
+	assert.NoError(err)
+	assert.Equal("bkua_123", token)
+
+	_, err = fetchTokenFromAWSSecret(context.Background(), "missing")
+	assert.ErrorContains(err, "ResourceNotFoundException: Secrets Manager can't find the specified secret.")
+}
+
+func TestFetchTokenFromVault(t *testing.T) {
+	assert := require.New(t)
+
+	vault := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal("s.vaulttoken", r.Header.Get("X-Vault-Token"))
+
+		switch r.URL.Path {
+		case "/v1/secret/data/buildkite":
+			_, _ = w.Write([]byte(`{"data":{"data":{"api_token":"bkua_kv2"},"metadata":{"version":3}}}`))
+		case "/v1/kv/buildkite":
+			_, _ = w.Write([]byte(`{"data":{"api_token":"bkua_kv1\n"}}`))
+		case "/v1/secret/data/forbidden":
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"errors":["permission denied"]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"errors":[]}`))
+		}
+	}))
+	defer vault.Close()
+
+	t.Setenv("VAULT_ADDR", vault.URL)
+	t.Setenv("VAULT_TOKEN", "s.vaulttoken")
+
+	tests := []struct {
+		name    string
+		ref     string
+		want    string
+		wantErr string
+	}{
+		{name: "KV version 2", ref: "vault://secret/buildkite#api_token", want: "bkua_kv2"},
+		{name: "KV version 1", ref: "vault://kv/buildkite#api_token", want: "bkua_kv1"},
+		{name: "missing field", ref: "vault://secret/buildkite#token", wantErr: `has no string field "token"`},
+		{name: "permission denied", ref: "vault://secret/forbidden#api_token", wantErr: "403 Forbidden: permission denied"},
+		{name: "missing secret", ref: "vault://secret/missing#api_token", wantErr: "404 Not Found"},
+		{name: "missing field in reference", ref: "vault://secret/buildkite", wantErr: "expected format: vault://mount/path#field"},
+		{name: "wrong scheme", ref: "op://vault/item/field", wantErr: "expected format: vault://mount/path#field"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := fetchTokenFromVault(context.Background(), tt.ref)
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, token)
+		})
+	}
+}
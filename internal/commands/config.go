@@ -0,0 +1,80 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/alecthomas/kong"
+	"gopkg.in/yaml.v3"
+)
+
+// YAMLConfig is a kong.ConfigurationLoader for YAML config files. Keys are
+// flag names without the leading dashes, in kebab or snake case, e.g.
+//
+//	enabled-toolsets: [builds, pipelines]
+//	read_only: true
+//	listen: 0.0.0.0:3000
+//
+// Values from the file are only used for flags not set on the command line or
+// by an environment variable. Keys that don't match a flag are an error, so
+// typos aren't silently ignored.
+func YAMLConfig(r io.Reader) (kong.Resolver, error) {
+	values := map[string]any{}
+	if err := yaml.NewDecoder(r).Decode(&values); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	}
+
+	normalized := make(map[string]any, len(values))
+	for key, value := range values {
+		normalized[strings.ReplaceAll(key, "_", "-")] = value
+	}
+	return &yamlResolver{values: normalized}, nil
+}
+
+type yamlResolver struct {
+	values map[string]any // keyed by flag name
+}
+
+func (r *yamlResolver) Validate(app *kong.Application) error {
+	known := map[string]bool{}
+	var walk func(node *kong.Node)
+	walk = func(node *kong.Node) {
+		for _, flag := range node.Flags {
+			known[flag.Name] = true
+		}
+		for _, child := range node.Children {
+			walk(child)
+		}
+	}
+	walk(app.Node)
+
+	var unknown []string
+	for key := range r.values {
+		if !known[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) > 0 {
+		slices.Sort(unknown)
+		return fmt.Errorf("unknown config file keys: %s", strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+func (r *yamlResolver) Resolve(context *kong.Context, parent *kong.Path, flag *kong.Flag) (any, error) {
+	value, ok := r.values[flag.Name]
+	if !ok {
+		return nil, nil
+	}
+	// Kong applies resolvers over environment variables, so defer to them
+	// here to keep the file at the lowest precedence.
+	for _, env := range flag.Envs {
+		if _, set := os.LookupEnv(env); set {
+			return nil, nil
+		}
+	}
+	return value, nil
+}
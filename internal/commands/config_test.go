@@ -0,0 +1,69 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/alecthomas/kong"
+	"github.com/stretchr/testify/require"
+)
+
+type configTestCLI struct {
+	Config   kong.ConfigFlag `type:"existingfile"`
+	BaseURL  string          `default:"https://api.buildkite.com/" env:"TEST_CONFIG_BASE_URL"`
+	ReadOnly bool
+	HTTP     struct {
+		EnabledToolsets []string      `default:"all"`
+		WriteTimeout    time.Duration `default:"30s"`
+	} `cmd:""`
+}
+
+func TestYAMLConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+base_url: https://buildkite.example.com/
+read-only: true
+enabled-toolsets: [builds, pipelines]
+write_timeout: 2m
+`), 0o600))
+
+	parse := func(args ...string) (*configTestCLI, error) {
+		var cli configTestCLI
+		parser, err := kong.New(&cli, kong.Configuration(YAMLConfig), kong.Exit(func(int) {}))
+		require.NoError(t, err)
+		_, err = parser.Parse(args)
+		return &cli, err
+	}
+
+	t.Run("file values are used", func(t *testing.T) {
+		cli, err := parse("--config", path, "http")
+		require.NoError(t, err)
+		require.Equal(t, "https://buildkite.example.com/", cli.BaseURL)
+		require.True(t, cli.ReadOnly)
+		require.Equal(t, []string{"builds", "pipelines"}, cli.HTTP.EnabledToolsets)
+		require.Equal(t, 2*time.Minute, cli.HTTP.WriteTimeout)
+	})
+
+	t.Run("flags take precedence", func(t *testing.T) {
+		cli, err := parse("--config", path, "--base-url", "https://flag.example.com/", "http", "--enabled-toolsets", "agents")
+		require.NoError(t, err)
+		require.Equal(t, "https://flag.example.com/", cli.BaseURL)
+		require.Equal(t, []string{"agents"}, cli.HTTP.EnabledToolsets)
+	})
+
+	t.Run("environment variables take precedence", func(t *testing.T) {
+		t.Setenv("TEST_CONFIG_BASE_URL", "https://env.example.com/")
+		cli, err := parse("--config", path, "http")
+		require.NoError(t, err)
+		require.Equal(t, "https://env.example.com/", cli.BaseURL)
+	})
+
+	t.Run("unknown keys are rejected", func(t *testing.T) {
+		bad := filepath.Join(t.TempDir(), "config.yaml")
+		require.NoError(t, os.WriteFile(bad, []byte("read-only: true\nreadonly: true\n"), 0o600))
+		_, err := parse("--config", bad, "http")
+		require.ErrorContains(t, err, "unknown config file keys: readonly")
+	})
+}
@@ -0,0 +1,134 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// minTokenRefreshInterval stops a token that is rejected for reasons other
+// than expiry from triggering a secret lookup on every request.
+const minTokenRefreshInterval = 30 * time.Second
+
+// CredentialProvider supplies the Buildkite API token, and a fresh one when
+// the current token is rejected, for tokens that expire during a session.
+type CredentialProvider interface {
+	// Token returns the current token.
+	Token() string
+	// Refresh re-resolves the token from its source and returns it.
+	Refresh(ctx context.Context) (string, error)
+}
+
+// NewCredentialProvider returns a provider that starts with token and
+// re-resolves it from sources on Refresh.
+func NewCredentialProvider(sources APITokenSources, token string) CredentialProvider {
+	return &sourceCredentialProvider{sources: sources, token: token, now: time.Now}
+}
+
+type sourceCredentialProvider struct {
+	sources APITokenSources
+	now     func() time.Time
+
+	mu          sync.Mutex
+	token       string
+	refreshedAt time.Time
+}
+
+func (p *sourceCredentialProvider) Token() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.token
+}
+
+// Refresh fetches the token again, bypassing the process-wide cache. Requests
+// rejected together share one refresh, as later callers get the token the
+// first one fetched.
+func (p *sourceCredentialProvider) Refresh(ctx context.Context) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.refreshedAt.IsZero() && p.now().Sub(p.refreshedAt) < minTokenRefreshInterval {
+		return p.token, nil
+	}
+	p.refreshedAt = p.now()
+
+	if key := p.sources.cacheKey(); key != "" {
+		fetchedTokens.forget(key)
+	}
+	token, err := ResolveAPIToken(ctx, p.sources)
+	if err != nil {
+		return p.token, err
+	}
+	if token != p.token {
+		log.Info().Msg("Refreshed Buildkite API token")
+	}
+	p.token = token
+	return token, nil
+}
+
+// NewRefreshingTransport sends requests to the Buildkite API with the
+// provider's current token. When a request is rejected with a 401 it
+// refreshes the token and, if that yields a new one, retries the request once.
+// Requests to other hosts, such as presigned artifact downloads, are left
+// alone.
+func NewRefreshingTransport(next http.RoundTripper, provider CredentialProvider, baseURL string) (http.RoundTripper, error) {
+	target, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base URL: %w", err)
+	}
+	return &refreshingTransport{next: next, provider: provider, target: target}, nil
+}
+
+type refreshingTransport struct {
+	next     http.RoundTripper
+	provider CredentialProvider
+	target   *url.URL
+}
+
+func (t *refreshingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !strings.EqualFold(req.URL.Scheme, t.target.Scheme) || !strings.EqualFold(req.URL.Host, t.target.Host) ||
+		!strings.HasPrefix(req.Header.Get("Authorization"), "Bearer ") {
+		return t.next.RoundTrip(req)
+	}
+
+	token := t.provider.Token()
+	res, err := t.next.RoundTrip(withBearerToken(req, token))
+	if err != nil || res.StatusCode != http.StatusUnauthorized {
+		return res, err
+	}
+
+	fresh, err := t.provider.Refresh(req.Context())
+	if err != nil {
+		log.Ctx(req.Context()).Warn().Err(err).Msg("Failed to refresh Buildkite API token")
+		return res, nil
+	}
+	if fresh == token {
+		return res, nil
+	}
+
+	retry := withBearerToken(req, fresh)
+	if req.Body != nil && req.Body != http.NoBody {
+		if req.GetBody == nil {
+			return res, nil
+		}
+		body, err := req.GetBody()
+		if err != nil {
+			return res, nil
+		}
+		retry.Body = body
+	}
+	_ = res.Body.Close()
+	return t.next.RoundTrip(retry)
+}
+
+func withBearerToken(req *http.Request, token string) *http.Request {
+	cloned := req.Clone(req.Context())
+	cloned.Header.Set("Authorization", "Bearer "+token)
+	return cloned
+}
@@ -0,0 +1,129 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakeCredentialProvider struct {
+	token     string
+	next      string
+	refreshes int
+}
+
+func (p *fakeCredentialProvider) Token() string {
+	return p.token
+}
+
+func (p *fakeCredentialProvider) Refresh(ctx context.Context) (string, error) {
+	p.refreshes++
+	p.token = p.next
+	return p.token, nil
+}
+
+func TestRefreshingTransport(t *testing.T) {
+	assert := require.New(t)
+
+	var bodies []string
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		if r.Header.Get("Authorization") != "Bearer fresh" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer api.Close()
+
+	provider := &fakeCredentialProvider{token: "expired", next: "fresh"}
+	transport, err := NewRefreshingTransport(http.DefaultTransport, provider, api.URL+"/")
+	assert.NoError(err)
+	client := &http.Client{Transport: transport}
+
+	req, err := http.NewRequest(http.MethodPost, api.URL+"/v2/builds", bytes.NewReader([]byte(`{"commit":"HEAD"}`)))
+	assert.NoError(err)
+	req.Header.Set("Authorization", "Bearer expired")
+	res, err := client.Do(req)
+	assert.NoError(err)
+	_ = res.Body.Close()
+
+	// The rejected request is retried with the refreshed token and its body.
+	assert.Equal(http.StatusOK, res.StatusCode)
+	assert.Equal(1, provider.refreshes)
+	assert.Equal([]string{`{"commit":"HEAD"}`, `{"commit":"HEAD"}`}, bodies)
+
+	// A token that is still rejected after refreshing isn't retried again.
+	provider.token = "revoked"
+	provider.next = "revoked"
+	req, err = http.NewRequest(http.MethodGet, api.URL+"/v2/user", nil)
+	assert.NoError(err)
+	req.Header.Set("Authorization", "Bearer revoked")
+	res, err = client.Do(req)
+	assert.NoError(err)
+	_ = res.Body.Close()
+	assert.Equal(http.StatusUnauthorized, res.StatusCode)
+	assert.Equal(2, provider.refreshes)
+}
+
+func TestRefreshingTransport_OtherHosts(t *testing.T) {
+	assert := require.New(t)
+
+	var authorization string
+	storage := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer storage.Close()
+
+	provider := &fakeCredentialProvider{token: "current", next: "fresh"}
+	transport, err := NewRefreshingTransport(http.DefaultTransport, provider, "https://api.buildkite.com/")
+	assert.NoError(err)
+
+	req, err := http.NewRequest(http.MethodGet, storage.URL+"/artifact", nil)
+	assert.NoError(err)
+	res, err := transport.RoundTrip(req)
+	assert.NoError(err)
+	_ = res.Body.Close()
+
+	assert.Empty(authorization)
+	assert.Equal(0, provider.refreshes)
+}
+
+func TestSourceCredentialProvider(t *testing.T) {
+	assert := require.New(t)
+
+	path := filepath.Join(t.TempDir(), "token")
+	assert.NoError(os.WriteFile(path, []byte("bkua_rotated\n"), 0o600))
+
+	now := time.Unix(0, 0)
+	provider := NewCredentialProvider(APITokenSources{File: path}, "bkua_original").(*sourceCredentialProvider)
+	provider.now = func() time.Time { return now }
+	assert.Equal("bkua_original", provider.Token())
+
+	token, err := provider.Refresh(context.Background())
+	assert.NoError(err)
+	assert.Equal("bkua_rotated", token)
+	assert.Equal("bkua_rotated", provider.Token())
+
+	// Refreshes are rate limited, so a token rejected for other reasons
+	// doesn't cause a lookup per request.
+	assert.NoError(os.WriteFile(path, []byte("bkua_rotated_again\n"), 0o600))
+	token, err = provider.Refresh(context.Background())
+	assert.NoError(err)
+	assert.Equal("bkua_rotated", token)
+
+	now = now.Add(minTokenRefreshInterval)
+	token, err = provider.Refresh(context.Background())
+	assert.NoError(err)
+	assert.Equal("bkua_rotated_again", token)
+}
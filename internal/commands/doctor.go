@@ -0,0 +1,228 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	buildkitelogs "github.com/buildkite/buildkite-logs"
+	"github.com/buildkite/buildkite-mcp-server/pkg/buildkite"
+	"github.com/buildkite/buildkite-mcp-server/pkg/toolsets"
+	gobuildkite "github.com/buildkite/go-buildkite/v5"
+)
+
+const doctorCheckTimeout = 10 * time.Second
+
+type DoctorCmd struct {
+	EnabledToolsets []string `help:"Comma-separated list of toolsets to check the API token's scopes against. Use 'all' for all toolsets." default:"all" env:"BUILDKITE_TOOLSETS"`
+	ReadOnly        bool     `help:"Check scopes for read-only mode, which only needs the scopes of read operations." default:"false" env:"BUILDKITE_READ_ONLY"`
+}
+
+func (c *DoctorCmd) Run(ctx context.Context, globals *Globals) error {
+	if err := toolsets.ValidateToolsets(c.EnabledToolsets); err != nil {
+		return err
+	}
+
+	d := &doctor{
+		out:           os.Stdout,
+		accessTokens:  globals.Client.AccessTokens,
+		organizations: globals.Client.Organizations,
+		checkCache:    probeLogCache,
+	}
+	failed := d.run(ctx, doctorConfig{
+		EnabledToolsets: c.EnabledToolsets,
+		ReadOnly:        c.ReadOnly,
+		CacheURL:        globals.CacheURL,
+		OTELExporter:    globals.OTELExporter,
+		OTELEndpoint:    globals.OTELEndpoint,
+	})
+	if failed > 0 {
+		return fmt.Errorf("%d check(s) failed", failed)
+	}
+	return nil
+}
+
+type doctorConfig struct {
+	EnabledToolsets []string
+	ReadOnly        bool
+	CacheURL        string
+	OTELExporter    string
+	OTELEndpoint    string
+}
+
+// doctor checks the server's setup and prints a report of each check.
+type doctor struct {
+	out           io.Writer
+	accessTokens  buildkite.AccessTokenClient
+	organizations buildkite.OrganizationsClient
+	// checkCache resolves the log cache URL and checks it can be read.
+	checkCache func(ctx context.Context, cacheURL string) (string, error)
+
+	failed int
+}
+
+func (d *doctor) run(ctx context.Context, cfg doctorConfig) int {
+	d.failed = 0
+	fmt.Fprintln(d.out, "Buildkite MCP server doctor")
+	fmt.Fprintln(d.out)
+
+	scopes, tokenOK := d.checkToken(ctx)
+	if tokenOK {
+		d.checkOrganizations(ctx)
+	}
+	d.checkToolsets(cfg.EnabledToolsets, cfg.ReadOnly, scopes, tokenOK)
+	d.checkLogCache(ctx, cfg.CacheURL)
+	d.reportTracing(cfg.OTELExporter, cfg.OTELEndpoint)
+
+	fmt.Fprintln(d.out)
+	if d.failed > 0 {
+		fmt.Fprintf(d.out, "%d check(s) failed\n", d.failed)
+	} else {
+		fmt.Fprintln(d.out, "All checks passed")
+	}
+	return d.failed
+}
+
+func (d *doctor) pass(format string, args ...any) {
+	fmt.Fprintf(d.out, "[ok]   "+format+"\n", args...)
+}
+
+func (d *doctor) warn(format string, args ...any) {
+	fmt.Fprintf(d.out, "[warn] "+format+"\n", args...)
+}
+
+func (d *doctor) fail(format string, args ...any) {
+	d.failed++
+	fmt.Fprintf(d.out, "[fail] "+format+"\n", args...)
+}
+
+func (d *doctor) detail(format string, args ...any) {
+	fmt.Fprintf(d.out, "         "+format+"\n", args...)
+}
+
+func (d *doctor) checkToken(ctx context.Context) ([]string, bool) {
+	ctx, cancel := context.WithTimeout(ctx, doctorCheckTimeout)
+	defer cancel()
+
+	token, _, err := d.accessTokens.Get(ctx)
+	if err != nil {
+		d.fail("API token: %v", err)
+		return nil, false
+	}
+
+	d.pass("API token belongs to %s <%s>", token.User.Name, token.User.Email)
+	if token.Description != "" {
+		d.detail("description: %s", token.Description)
+	}
+	d.detail("scopes: %s", strings.Join(token.Scopes, ", "))
+	return token.Scopes, true
+}
+
+func (d *doctor) checkOrganizations(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, doctorCheckTimeout)
+	defer cancel()
+
+	orgs, _, err := d.organizations.List(ctx, &gobuildkite.OrganizationListOptions{})
+	if err != nil {
+		d.fail("Organizations: %v", err)
+		return
+	}
+	if len(orgs) == 0 {
+		d.fail("Organizations: the API token has no access to any organization")
+		return
+	}
+
+	slugs := make([]string, 0, len(orgs))
+	for _, org := range orgs {
+		slugs = append(slugs, org.Slug)
+	}
+	d.pass("Organizations: %s", strings.Join(slugs, ", "))
+}
+
+// checkToolsets lists the enabled toolsets with their required scopes, and
+// which of those the token lacks if its scopes are known.
+func (d *doctor) checkToolsets(enabledToolsets []string, readOnly bool, granted []string, scopesKnown bool) {
+	registry := toolsets.NewDefaultRegistry()
+
+	var allMissing []string
+	var lines []string
+	for _, name := range registry.List() {
+		if !toolsets.IsToolsetEnabled(enabledToolsets, name) {
+			continue
+		}
+		required := registry.GetRequiredScopes([]string{name}, readOnly)
+		line := fmt.Sprintf("%s: %s", name, strings.Join(required, ", "))
+		if missing := missingScopes(required, granted); scopesKnown && len(missing) > 0 {
+			line += fmt.Sprintf(" (missing %s)", strings.Join(missing, ", "))
+			for _, scope := range missing {
+				if !slices.Contains(allMissing, scope) {
+					allMissing = append(allMissing, scope)
+				}
+			}
+		}
+		lines = append(lines, line)
+	}
+
+	switch {
+	case !scopesKnown:
+		d.warn("Toolsets (read-only: %t), scopes not checked as the API token could not be read", readOnly)
+	case len(allMissing) > 0:
+		d.fail("Toolsets (read-only: %t), API token is missing scopes: %s", readOnly, strings.Join(allMissing, ", "))
+	default:
+		d.pass("Toolsets (read-only: %t), API token has all required scopes", readOnly)
+	}
+	for _, line := range lines {
+		d.detail("%s", line)
+	}
+}
+
+func (d *doctor) checkLogCache(ctx context.Context, cacheURL string) {
+	ctx, cancel := context.WithTimeout(ctx, doctorCheckTimeout)
+	defer cancel()
+
+	resolved, err := d.checkCache(ctx, cacheURL)
+	if err != nil {
+		d.fail("Log cache %s: %v", resolved, err)
+		return
+	}
+	d.pass("Log cache %s is reachable", resolved)
+}
+
+func (d *doctor) reportTracing(exporter, endpoint string) {
+	if exporter == "" || exporter == "noop" {
+		d.pass("Tracing is disabled")
+		return
+	}
+
+	if endpoint == "" {
+		endpoint = os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT")
+	}
+	if endpoint == "" {
+		endpoint = os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
+	}
+	d.pass("Tracing exports over %s to %s", exporter, endpoint)
+}
+
+// probeLogCache opens the log cache's blob storage and reads from it, which
+// fails if the bucket doesn't exist or the credentials can't access it.
+func probeLogCache(ctx context.Context, cacheURL string) (string, error) {
+	resolved, err := buildkitelogs.GetDefaultStorageURL(cacheURL, false)
+	if err != nil {
+		return cacheURL, err
+	}
+
+	storage, err := buildkitelogs.NewBlobStorage(ctx, resolved, nil)
+	if err != nil {
+		return resolved, err
+	}
+	defer storage.Close()
+
+	if _, err := storage.Exists(ctx, "buildkite-mcp-server-doctor"); err != nil {
+		return resolved, err
+	}
+	return resolved, nil
+}
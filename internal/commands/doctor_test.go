@@ -0,0 +1,75 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/buildkite/buildkite-mcp-server/pkg/toolsets"
+	"github.com/buildkite/go-buildkite/v5"
+	"github.com/stretchr/testify/require"
+)
+
+type mockOrganizationsClient struct {
+	orgs []buildkite.Organization
+	err  error
+}
+
+func (m *mockOrganizationsClient) List(ctx context.Context, options *buildkite.OrganizationListOptions) ([]buildkite.Organization, *buildkite.Response, error) {
+	return m.orgs, nil, m.err
+}
+
+func TestDoctor(t *testing.T) {
+	required := toolsets.NewDefaultRegistry().GetRequiredScopes([]string{"builds"}, true)
+
+	newDoctor := func(out *bytes.Buffer, scopes []string, cacheErr error) *doctor {
+		return &doctor{
+			out:           out,
+			accessTokens:  &mockAccessTokenClient{scopes: scopes},
+			organizations: &mockOrganizationsClient{orgs: []buildkite.Organization{{Slug: "acme"}, {Slug: "acme-oss"}}},
+			checkCache: func(ctx context.Context, cacheURL string) (string, error) {
+				return "file:///tmp/bklog", cacheErr
+			},
+		}
+	}
+	cfg := doctorConfig{EnabledToolsets: []string{"builds"}, ReadOnly: true, OTELExporter: "noop"}
+
+	t.Run("healthy setup", func(t *testing.T) {
+		assert := require.New(t)
+		var out bytes.Buffer
+		assert.Equal(0, newDoctor(&out, required, nil).run(context.Background(), cfg))
+
+		report := out.String()
+		assert.Contains(report, "[ok]   Organizations: acme, acme-oss")
+		assert.Contains(report, "[ok]   Toolsets (read-only: true), API token has all required scopes")
+		assert.Contains(report, "builds: "+required[0])
+		assert.Contains(report, "[ok]   Log cache file:///tmp/bklog is reachable")
+		assert.Contains(report, "[ok]   Tracing is disabled")
+		assert.Contains(report, "All checks passed")
+	})
+
+	t.Run("missing scopes and unreachable cache", func(t *testing.T) {
+		assert := require.New(t)
+		var out bytes.Buffer
+		assert.Equal(2, newDoctor(&out, nil, errors.New("access denied")).run(context.Background(), cfg))
+
+		report := out.String()
+		assert.Contains(report, "[fail] Toolsets (read-only: true), API token is missing scopes: "+required[0])
+		assert.Contains(report, "[fail] Log cache file:///tmp/bklog: access denied")
+		assert.Contains(report, "2 check(s) failed")
+	})
+
+	t.Run("unreadable token", func(t *testing.T) {
+		assert := require.New(t)
+		var out bytes.Buffer
+		d := newDoctor(&out, nil, nil)
+		d.accessTokens = &mockAccessTokenClient{err: errors.New("401 Unauthorized")}
+		assert.Equal(1, d.run(context.Background(), cfg))
+
+		report := out.String()
+		assert.Contains(report, "[fail] API token: 401 Unauthorized")
+		assert.NotContains(report, "Organizations")
+		assert.Contains(report, "[warn] Toolsets (read-only: true), scopes not checked")
+	})
+}
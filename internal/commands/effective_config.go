@@ -0,0 +1,55 @@
+package commands
+
+import (
+	"encoding/json"
+	"io"
+	"net/url"
+)
+
+// EffectiveConfig is the configuration the server resolved from flags,
+// environment variables, the config file and defaults. It never holds a
+// secret: the API token and URLs that may carry credentials are only
+// recorded as set or not, and header values are left out.
+type EffectiveConfig struct {
+	Command         string   `json:"command"`
+	EnabledToolsets []string `json:"enabled_toolsets,omitempty"`
+	ReadOnly        bool     `json:"read_only"`
+	DynamicToolsets bool     `json:"dynamic_toolsets,omitempty"`
+	BaseURL         string   `json:"base_url"`
+	DefaultOrg      string   `json:"default_org,omitempty"`
+	APITokenSet     bool     `json:"api_token_set"`
+	CacheURLSet     bool     `json:"cache_url_set"`
+	CacheTTL        string   `json:"cache_ttl"`
+	HTTPHeaders     []string `json:"http_headers,omitempty"`
+	LogLevel        string   `json:"log_level"`
+	LogFormat       string   `json:"log_format"`
+	Redact          bool     `json:"redact"`
+
+	// Thresholds for warnings about tool calls; zero disables each.
+	SlowToolThreshold    string `json:"slow_tool_threshold"`
+	LargeToolResultBytes int    `json:"large_tool_result_bytes"`
+
+	// HTTP mode only.
+	Listen                 string   `json:"listen,omitempty"`
+	PassthroughHTTPHeaders []string `json:"passthrough_http_headers,omitempty"`
+	AuthMode               string   `json:"auth_mode,omitempty"`
+	Metrics                bool     `json:"metrics,omitempty"`
+	DebugTools             bool     `json:"debug_tools,omitempty"`
+}
+
+// PrintEffectiveConfig writes cfg to out as indented JSON.
+func PrintEffectiveConfig(out io.Writer, cfg EffectiveConfig) error {
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(cfg)
+}
+
+// RedactURL removes the password from a URL's user info, so a URL given
+// with credentials can be shown. A URL that can't be parsed is replaced.
+func RedactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "(invalid URL)"
+	}
+	return u.Redacted()
+}
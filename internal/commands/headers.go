@@ -1,26 +1,36 @@
 package commands
 
 import (
+	"fmt"
+	"net/http"
 	"strings"
 
 	"github.com/rs/zerolog/log"
 )
 
 // ParseHeaders takes a slice of header strings in the format "Key: Value"
-// and returns a map of headers. This is used to parse additional HTTP headers
-// that can be sent with every request to the Buildkite API.
-func ParseHeaders(headerStrings []string) map[string]string {
-	headers := make(map[string]string)
+// and returns them as an http.Header. This is used to parse additional HTTP
+// headers that can be sent with every request to the Buildkite API.
+//
+// Whitespace around keys and values is trimmed. An entry without a colon or
+// with an empty key is an error. A header given more than once keeps every
+// value, in order, so it is sent once for each; keys are compared ignoring
+// case, as in HTTP.
+func ParseHeaders(headerStrings []string) (http.Header, error) {
+	headers := make(http.Header)
 	for _, h := range headerStrings {
-		parts := strings.SplitN(h, ":", 2)
-		if len(parts) == 2 {
-			key := strings.TrimSpace(parts[0])
-			value := strings.TrimSpace(parts[1])
-			headers[key] = value
-			log.Debug().Str("key", key).Str("value", value).Msg("parsed header")
-		} else {
-			log.Warn().Str("header", h).Msg("invalid header format, expected 'Key: Value'")
+		key, value, ok := strings.Cut(h, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid header %q: expected 'Key: Value'", h)
 		}
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+		if key == "" {
+			return nil, fmt.Errorf("invalid header %q: the key is empty", h)
+		}
+
+		headers.Add(key, value)
+		log.Debug().Str("key", key).Str("value", value).Msg("parsed header")
 	}
-	return headers
+	return headers, nil
 }
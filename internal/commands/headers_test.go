@@ -1,35 +1,54 @@
 package commands
 
 import (
+	"net/http"
 	"testing"
+
+	"github.com/stretchr/testify/require"
 )
 
 func TestParseHeaders(t *testing.T) {
 	tests := []struct {
 		input []string
-		want  map[string]string
+		want  http.Header
 	}{
-		{[]string{"Authorization: Bearer token"}, map[string]string{"Authorization": "Bearer token"}},
-		{[]string{"Authorization: Bearer to.ke.n"}, map[string]string{"Authorization": "Bearer to.ke.n"}},
-		{[]string{"Key:Value"}, map[string]string{"Key": "Value"}},
-		{[]string{"Key:   Value with spaces"}, map[string]string{"Key": "Value with spaces"}},
-		{[]string{"NoColonHere"}, map[string]string{}},
-		{[]string{"JustKey:"}, map[string]string{"JustKey": ""}},
-		{[]string{":JustValue"}, map[string]string{"": "JustValue"}},
-		{[]string{"A:1", "B:2"}, map[string]string{"A": "1", "B": "2"}},
-		{[]string{"A:1", "NoColon", "B:2"}, map[string]string{"A": "1", "B": "2"}},
+		{[]string{"Authorization: Bearer token"}, http.Header{"Authorization": {"Bearer token"}}},
+		{[]string{"Authorization: Bearer to.ke.n"}, http.Header{"Authorization": {"Bearer to.ke.n"}}},
+		{[]string{"Key:Value"}, http.Header{"Key": {"Value"}}},
+		{[]string{"Key:   Value with spaces"}, http.Header{"Key": {"Value with spaces"}}},
+		{[]string{"  Key  :  Value  "}, http.Header{"Key": {"Value"}}},
+		{[]string{"JustKey:"}, http.Header{"Justkey": {""}}},
+		{[]string{"X-Url: https://example.com:8080"}, http.Header{"X-Url": {"https://example.com:8080"}}},
+		{[]string{"A:1", "B:2"}, http.Header{"A": {"1"}, "B": {"2"}}},
+		{nil, http.Header{}},
 	}
 
 	for _, tt := range tests {
-		got := ParseHeaders(tt.input)
-		if len(got) != len(tt.want) {
-			t.Errorf("parseHeaders(%v) = %v, want %v", tt.input, got, tt.want)
-			continue
-		}
-		for k, v := range tt.want {
-			if got[k] != v {
-				t.Errorf("parseHeaders(%v)[%q] = %q, want %q", tt.input, k, got[k], v)
-			}
-		}
+		got, err := ParseHeaders(tt.input)
+		require.NoError(t, err, "ParseHeaders(%v)", tt.input)
+		require.Equal(t, tt.want, got, "ParseHeaders(%v)", tt.input)
+	}
+}
+
+func TestParseHeaders_Malformed(t *testing.T) {
+	tests := []struct {
+		input   []string
+		wantErr string
+	}{
+		{[]string{"NoColonHere"}, `invalid header "NoColonHere": expected 'Key: Value'`},
+		{[]string{"A:1", "NoColon", "B:2"}, `invalid header "NoColon": expected 'Key: Value'`},
+		{[]string{":JustValue"}, `invalid header ":JustValue": the key is empty`},
+		{[]string{"   : value"}, `invalid header "   : value": the key is empty`},
 	}
+
+	for _, tt := range tests {
+		_, err := ParseHeaders(tt.input)
+		require.EqualError(t, err, tt.wantErr, "ParseHeaders(%v)", tt.input)
+	}
+}
+
+func TestParseHeaders_Duplicates(t *testing.T) {
+	got, err := ParseHeaders([]string{"X-Proxy: one", "Other: 1", "x-proxy: two"})
+	require.NoError(t, err)
+	require.Equal(t, http.Header{"X-Proxy": {"one", "two"}, "Other": {"1"}}, got)
 }
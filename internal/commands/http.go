@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/buildkite/buildkite-mcp-server/internal/middleware"
 	"github.com/buildkite/buildkite-mcp-server/pkg/buildkite"
 	"github.com/buildkite/buildkite-mcp-server/pkg/server"
 	"github.com/buildkite/buildkite-mcp-server/pkg/toolsets"
@@ -16,16 +17,34 @@ import (
 )
 
 type HTTPCmd struct {
-	Listen                 string   `help:"The address to listen on." default:"localhost:3000" env:"HTTP_LISTEN_ADDR"`
-	EnabledToolsets        []string `help:"Comma-separated list of toolsets to enable (e.g., 'pipelines,builds,clusters'). Use 'all' to enable all toolsets." default:"all" env:"BUILDKITE_TOOLSETS"`
-	ReadOnly               bool     `help:"Enable read-only mode, which filters out write operations from all toolsets." default:"false" env:"BUILDKITE_READ_ONLY"`
-	PassthroughHTTPHeaders []string `help:"Inbound HTTP header names to pass through to the Buildkite API. May be repeated." name:"passthrough-http-header" env:"BUILDKITE_PASSTHROUGH_HTTP_HEADERS"`
+	Listen                    string        `help:"The address to listen on." default:"localhost:3000" env:"HTTP_LISTEN_ADDR"`
+	EnabledToolsets           []string      `help:"Comma-separated list of toolsets to enable (e.g., 'pipelines,builds,clusters'). Use 'all' to enable all toolsets except opt-in ones such as 'graphql', which must be named, or an alias such as 'ci' (builds, logs, annotations) or 'monitoring' (agents, clusters, builds)." default:"all" env:"BUILDKITE_TOOLSETS"`
+	ReadOnly                  bool          `help:"Enable read-only mode, which filters out write operations from all toolsets." default:"false" env:"BUILDKITE_READ_ONLY"`
+	StrictScopes              bool          `help:"Fail at startup if the API token is missing scopes required by the enabled toolsets, instead of logging a warning." default:"false" env:"BUILDKITE_STRICT_SCOPES"`
+	PassthroughHTTPHeaders    []string      `help:"Inbound HTTP header names to pass through to the Buildkite API. May be repeated." name:"passthrough-http-header" env:"BUILDKITE_PASSTHROUGH_HTTP_HEADERS"`
+	Metrics                   bool          `help:"Expose Prometheus metrics on /metrics: request counts and latency, and the duration and result size of tool calls." default:"false" env:"BUILDKITE_MCP_METRICS"`
+	DebugTools                bool          `help:"Serve /debug/tools, which lists the tools an MCP request would get, with their toolsets and read-only status, as JSON. It checks clients' credentials as /mcp does." default:"false" env:"BUILDKITE_MCP_DEBUG_TOOLS"`
+	Compress                  bool          `help:"Gzip MCP responses for clients that accept it." default:"false" env:"BUILDKITE_MCP_COMPRESS"`
+	MaxRequestBodyBytes       int64         `help:"Maximum size in bytes of an MCP request body. Larger requests are rejected with HTTP 413. Set to 0 to disable the limit." default:"4194304" env:"BUILDKITE_MCP_MAX_REQUEST_BODY_BYTES"`
+	RateLimit                 int           `help:"Maximum MCP requests per second from each client IP. Set to 0 to disable rate limiting." default:"0" env:"BUILDKITE_MCP_RATE_LIMIT"`
+	RateLimitBurst            int           `help:"Requests a client IP may burst above the rate limit. Defaults to the rate limit." default:"0" env:"BUILDKITE_MCP_RATE_LIMIT_BURST"`
+	ReadTimeout               time.Duration `help:"Maximum time to read an HTTP request, including its body." default:"30s" env:"HTTP_READ_TIMEOUT"`
+	WriteTimeout              time.Duration `help:"Maximum time to write an HTTP response. Raise this if slow tools, such as reading large job logs, time out before responding." default:"30s" env:"HTTP_WRITE_TIMEOUT"`
+	IdleTimeout               time.Duration `help:"Maximum time to keep an idle keep-alive connection open." default:"60s" env:"HTTP_IDLE_TIMEOUT"`
+	AuthMode                  string        `help:"How to authenticate MCP clients: 'none', or 'introspection' to validate bearer tokens with an OAuth 2.0 token introspection endpoint." enum:"none,introspection" default:"none" env:"BUILDKITE_MCP_AUTH_MODE"`
+	IntrospectionURL          string        `help:"OAuth 2.0 token introspection (RFC 7662) endpoint, for --auth-mode=introspection." env:"BUILDKITE_MCP_INTROSPECTION_URL"`
+	IntrospectionClientID     string        `help:"Client ID this server uses to call the introspection endpoint." env:"BUILDKITE_MCP_INTROSPECTION_CLIENT_ID"`
+	IntrospectionClientSecret string        `help:"Client secret this server uses to call the introspection endpoint." env:"BUILDKITE_MCP_INTROSPECTION_CLIENT_SECRET"`
+	TokenPolicies             string        `help:"Path to a JSON file mapping client bearer tokens to the toolsets and read-only mode they are limited to, e.g. {\"<token>\": {\"toolsets\": [\"builds\"], \"read_only\": true}}. Tokens without a policy get the server defaults." type:"existingfile" env:"BUILDKITE_MCP_TOKEN_POLICIES"`
 }
 
 func (c *HTTPCmd) Run(ctx context.Context, globals *Globals) error {
 	if err := toolsets.ValidateToolsets(c.EnabledToolsets); err != nil {
 		return err
 	}
+	if err := checkGraphQLURL(c.EnabledToolsets, globals.GraphQLURL); err != nil {
+		return err
+	}
 
 	deps := buildkite.ToolDependencies{
 		BuildsClient:            globals.Client.Builds,
@@ -44,6 +63,66 @@ func (c *HTTPCmd) Run(ctx context.Context, globals *Globals) error {
 		TestExecutionsClient:    globals.Client.TestRuns,
 		TestsClient:             globals.Client.Tests,
 		BuildkiteLogsClient:     globals.BuildkiteLogsClient,
+		MaxLogBytes:             globals.MaxLogBytes,
+		LogFetchConcurrency:     globals.LogFetchConcurrency,
+		IncludeRateLimit:        globals.DebugRateLimit,
+		PrettyJSON:              globals.PrettyJSON,
+		DefaultOrg:              globals.DefaultOrg,
+		SlowToolThreshold:       globals.SlowToolThreshold,
+		LargeToolResultBytes:    globals.LargeToolResultBytes,
+		Redactor:                globals.Redactor,
+	}
+
+	if globals.GraphQLURL != "" {
+		deps.GraphQLClient = &buildkite.GraphQLAPIClient{Client: globals.Client, URL: globals.GraphQLURL}
+	}
+
+	if c.AuthMode == "introspection" {
+		if c.IntrospectionURL == "" {
+			return fmt.Errorf("--introspection-url is required with --auth-mode=introspection")
+		}
+		if globals.HeaderPassthrough != nil && globals.HeaderPassthrough.UsesAuthorization() {
+			return fmt.Errorf("cannot pass through Authorization with --auth-mode=introspection, as clients' bearer tokens are not Buildkite API tokens")
+		}
+	}
+
+	if globals.CacheTTL > 0 {
+		// Cached results are shared by every caller, so they mustn't depend
+		// on headers from the caller's request.
+		if globals.HeaderPassthrough != nil {
+			return fmt.Errorf("cannot use --cache-ttl with --passthrough-http-header, as cached tool results would be shared between callers")
+		}
+		deps.ResponseCache = buildkite.NewResponseCache(globals.CacheTTL)
+	}
+
+	// Idempotency keys aren't scoped to the caller, so a caller reusing
+	// another's key would get back their build.
+	if globals.HeaderPassthrough == nil {
+		deps.IdempotentBuilds = buildkite.NewIdempotentBuilds()
+	}
+
+	var tokenPolicies map[string]server.TokenPolicy
+	if c.TokenPolicies != "" {
+		policies, err := server.LoadTokenPolicies(c.TokenPolicies)
+		if err != nil {
+			return err
+		}
+		tokenPolicies = policies
+	}
+
+	// With Authorization passed through there is no server token to check;
+	// each client's token is only known per request.
+	if globals.HeaderPassthrough == nil || !globals.HeaderPassthrough.UsesAuthorization() {
+		deps.TokenScopes = &buildkite.TokenScopes{}
+		if err := checkTokenScopes(ctx, globals.Client.AccessTokens, c.EnabledToolsets, c.ReadOnly, c.StrictScopes); err != nil {
+			return err
+		}
+	}
+
+	var metrics *middleware.Metrics
+	if c.Metrics {
+		metrics = middleware.NewMetrics()
+		deps.ToolCallObserver = metrics
 	}
 
 	factory := server.NewPerRequestServerFactory(globals.Version, deps, c.EnabledToolsets, c.ReadOnly)
@@ -54,19 +133,66 @@ func (c *HTTPCmd) Run(ctx context.Context, globals *Globals) error {
 	}
 
 	mux := http.NewServeMux()
-	srv := newServerWithTimeouts(mux, 30*time.Second)
+	var rootHandler http.Handler = mux
+	if metrics != nil {
+		mux.Handle("/metrics", metrics)
+		rootHandler = metrics.WrapHandler(mux)
+	}
+	srv := newServerWithTimeouts(rootHandler, c.ReadTimeout, c.WriteTimeout, c.IdleTimeout)
 
 	mux.HandleFunc("/health", healthHandler)
 
-	handler := server.NewHTTPUnauthorizedHandler(
+	// When Authorization is passed through, each client brings its own token
+	// and the server has none of its own to check.
+	var readinessClient buildkite.AccessTokenClient = globals.Client.AccessTokens
+	if globals.HeaderPassthrough != nil && globals.HeaderPassthrough.UsesAuthorization() {
+		readinessClient = nil
+	}
+	mux.Handle("/ready", newReadinessHandler(readinessClient))
+
+	// withClientAuth applies the checks of each MCP client's credentials,
+	// which the debug endpoint shares with /mcp.
+	withClientAuth := func(handler http.Handler) http.Handler {
+		if globals.HeaderPassthrough != nil {
+			handler = globals.HeaderPassthrough.WrapHandler(handler)
+		}
+		if len(tokenPolicies) > 0 {
+			handler = server.NewHTTPTokenPolicyHandler(handler, tokenPolicies)
+		}
+		if c.AuthMode == "introspection" {
+			handler = middleware.Introspection(middleware.IntrospectionConfig{
+				URL:          c.IntrospectionURL,
+				ClientID:     c.IntrospectionClientID,
+				ClientSecret: c.IntrospectionClientSecret,
+			})(handler)
+		}
+		return handler
+	}
+
+	if c.DebugTools {
+		var debugHandler http.Handler = withClientAuth(server.NewDebugToolsHandler(c.EnabledToolsets, c.ReadOnly))
+		debugHandler = middleware.RequestLog(globals.Logger)(debugHandler)
+		debugHandler = middleware.RequestID()(debugHandler)
+		mux.Handle("/debug/tools", debugHandler)
+	}
+
+	handler := withClientAuth(server.NewHTTPUnauthorizedHandler(
 		mcp.NewStreamableHTTPHandler(factory, &mcp.StreamableHTTPOptions{
 			Stateless: true,
 		}),
 		`Bearer realm="buildkite"`,
-	)
-	if globals.HeaderPassthrough != nil {
-		handler = globals.HeaderPassthrough.WrapHandler(handler)
+	))
+	if c.MaxRequestBodyBytes > 0 {
+		handler = middleware.MaxBodyBytes(c.MaxRequestBodyBytes)(handler)
+	}
+	if c.RateLimit > 0 {
+		handler = middleware.RateLimit(c.RateLimit, c.RateLimitBurst)(handler)
+	}
+	if c.Compress {
+		handler = middleware.Compress()(handler)
 	}
+	handler = middleware.RequestLog(globals.Logger)(handler)
+	handler = middleware.RequestID()(handler)
 	mux.Handle("/mcp", handler)
 
 	log.Ctx(ctx).Info().
@@ -78,13 +204,13 @@ func (c *HTTPCmd) Run(ctx context.Context, globals *Globals) error {
 	return srv.Serve(listener)
 }
 
-func newServerWithTimeouts(mux *http.ServeMux, writeTimeout time.Duration) *http.Server {
+func newServerWithTimeouts(handler http.Handler, readTimeout, writeTimeout, idleTimeout time.Duration) *http.Server {
 	return &http.Server{
-		Handler:           otelhttp.NewHandler(mux, "mcp-server"),
-		ReadHeaderTimeout: 30 * time.Second,
-		ReadTimeout:       30 * time.Second,
+		Handler:           otelhttp.NewHandler(handler, "mcp-server"),
+		ReadHeaderTimeout: readTimeout,
+		ReadTimeout:       readTimeout,
 		WriteTimeout:      writeTimeout,
-		IdleTimeout:       60 * time.Second,
+		IdleTimeout:       idleTimeout,
 	}
 }
 
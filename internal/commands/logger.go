@@ -0,0 +1,59 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// Log formats accepted by --log-format.
+const (
+	LogFormatAuto    = "auto"
+	LogFormatJSON    = "json"
+	LogFormatConsole = "console"
+)
+
+// LoggerConfig is how the server's logger is set up from its flags.
+type LoggerConfig struct {
+	// Level is a zerolog level name: trace, debug, info, warn or error.
+	Level string
+	// Debug lowers Level to debug, for the older --debug flag.
+	Debug bool
+	// Format is LogFormatJSON, LogFormatConsole, or LogFormatAuto, which
+	// uses the console format when Interactive is set.
+	Format      string
+	Interactive bool
+}
+
+// NewLogger returns the server's logger, writing to out as configured.
+func NewLogger(out io.Writer, cfg LoggerConfig) (zerolog.Logger, error) {
+	level, err := zerolog.ParseLevel(cfg.Level)
+	if err != nil || level < zerolog.TraceLevel || level > zerolog.ErrorLevel {
+		return zerolog.Nop(), fmt.Errorf("invalid log level %q, expected trace, debug, info, warn or error", cfg.Level)
+	}
+	if cfg.Debug && level > zerolog.DebugLevel {
+		level = zerolog.DebugLevel
+	}
+
+	switch cfg.Format {
+	case LogFormatJSON:
+	case LogFormatConsole:
+		out = consoleWriter(out)
+	case LogFormatAuto, "":
+		if cfg.Interactive {
+			out = consoleWriter(out)
+		}
+	default:
+		return zerolog.Nop(), fmt.Errorf("invalid log format %q, expected json, console or auto", cfg.Format)
+	}
+
+	return zerolog.New(out).Level(level).With().Timestamp().Stack().Logger(), nil
+}
+
+func consoleWriter(out io.Writer) zerolog.ConsoleWriter {
+	return zerolog.ConsoleWriter{Out: out, FormatTimestamp: func(i any) string {
+		return time.Now().Format(time.Stamp)
+	}}
+}
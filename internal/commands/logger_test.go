@@ -0,0 +1,78 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewLogger(t *testing.T) {
+	t.Run("json", func(t *testing.T) {
+		assert := require.New(t)
+		var out bytes.Buffer
+
+		logger, err := NewLogger(&out, LoggerConfig{Level: "warn", Format: LogFormatJSON, Interactive: true})
+		assert.NoError(err)
+		logger.Info().Msg("hidden")
+		logger.Warn().Str("tool", "get_build").Msg("shown")
+
+		var line map[string]any
+		assert.NoError(json.Unmarshal(out.Bytes(), &line))
+		assert.Equal("warn", line["level"])
+		assert.Equal("shown", line["message"])
+		assert.Equal("get_build", line["tool"])
+	})
+
+	t.Run("console", func(t *testing.T) {
+		assert := require.New(t)
+		var out bytes.Buffer
+
+		logger, err := NewLogger(&out, LoggerConfig{Level: "info", Format: LogFormatConsole})
+		assert.NoError(err)
+		logger.Info().Msg("hello")
+
+		assert.Contains(out.String(), "INF")
+		assert.False(strings.HasPrefix(out.String(), "{"))
+	})
+
+	t.Run("auto uses console when interactive", func(t *testing.T) {
+		var out bytes.Buffer
+
+		logger, err := NewLogger(&out, LoggerConfig{Level: "info", Format: LogFormatAuto})
+		require.NoError(t, err)
+		logger.Info().Msg("hello")
+		require.True(t, strings.HasPrefix(out.String(), "{"))
+
+		out.Reset()
+		logger, err = NewLogger(&out, LoggerConfig{Level: "info", Format: LogFormatAuto, Interactive: true})
+		require.NoError(t, err)
+		logger.Info().Msg("hello")
+		require.False(t, strings.HasPrefix(out.String(), "{"))
+	})
+
+	t.Run("debug lowers the level", func(t *testing.T) {
+		var out bytes.Buffer
+
+		logger, err := NewLogger(&out, LoggerConfig{Level: "info", Debug: true, Format: LogFormatJSON})
+		require.NoError(t, err)
+		logger.Debug().Msg("shown")
+		require.Contains(t, out.String(), "shown")
+
+		out.Reset()
+		logger, err = NewLogger(&out, LoggerConfig{Level: "trace", Debug: true, Format: LogFormatJSON})
+		require.NoError(t, err)
+		logger.Trace().Msg("still shown")
+		require.Contains(t, out.String(), "still shown")
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := NewLogger(&bytes.Buffer{}, LoggerConfig{Level: "loud", Format: LogFormatJSON})
+		require.ErrorContains(t, err, `invalid log level "loud"`)
+
+		_, err = NewLogger(&bytes.Buffer{}, LoggerConfig{Level: "info", Format: "xml"})
+		require.ErrorContains(t, err, `invalid log format "xml"`)
+	})
+}
@@ -0,0 +1,78 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/buildkite/buildkite-mcp-server/pkg/buildkite"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// readinessCacheTTL is how long a readiness result is reused, so frequent
+	// probes don't turn into a stream of API calls.
+	readinessCacheTTL     = 10 * time.Second
+	readinessCheckTimeout = 5 * time.Second
+)
+
+// readinessHandler reports whether the server can reach the Buildkite API
+// with its configured token, unlike /health which only reports that the
+// process is up.
+type readinessHandler struct {
+	client buildkite.AccessTokenClient // nil skips the check
+	now    func() time.Time
+
+	mu        sync.Mutex
+	checkedAt time.Time
+	err       error
+}
+
+func newReadinessHandler(client buildkite.AccessTokenClient) *readinessHandler {
+	return &readinessHandler{client: client, now: time.Now}
+}
+
+func (h *readinessHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := h.check(r.Context()); err != nil {
+		http.Error(w, fmt.Sprintf("not ready: %v", err), http.StatusServiceUnavailable)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// check returns the cached result if it is fresh, otherwise calls the API.
+// Holding the lock during the call means concurrent probes share one request.
+//
+// The call doesn't use the probe's context, whose deadline is often shorter
+// than a token lookup, so a probe that gives up doesn't fail the check for
+// the probes after it. Timeouts and cancellations aren't cached.
+func (h *readinessHandler) check(ctx context.Context) error {
+	if h.client == nil {
+		return nil
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if !h.checkedAt.IsZero() && h.now().Sub(h.checkedAt) < readinessCacheTTL {
+		return h.err
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), readinessCheckTimeout)
+	defer cancel()
+
+	_, _, err := h.client.Get(ctx)
+	if err != nil {
+		err = fmt.Errorf("buildkite API check failed: %w", err)
+		log.Ctx(ctx).Warn().Err(err).Msg("Readiness check failed")
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return err
+		}
+	}
+	h.checkedAt = h.now()
+	h.err = err
+	return err
+}
@@ -0,0 +1,87 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/buildkite/go-buildkite/v5"
+	"github.com/stretchr/testify/require"
+)
+
+type mockAccessTokenClient struct {
+	calls  int
+	scopes []string
+	err    error
+	ctxErr error // the context's error when Get was last called
+}
+
+func (m *mockAccessTokenClient) Get(ctx context.Context) (buildkite.AccessToken, *buildkite.Response, error) {
+	m.calls++
+	m.ctxErr = ctx.Err()
+	return buildkite.AccessToken{Scopes: m.scopes}, nil, m.err
+}
+
+func TestReadinessHandler(t *testing.T) {
+	assert := require.New(t)
+
+	client := &mockAccessTokenClient{}
+	now := time.Unix(0, 0)
+	handler := newReadinessHandler(client)
+	handler.now = func() time.Time { return now }
+
+	probe := func() *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ready", nil))
+		return rec
+	}
+
+	assert.Equal(http.StatusOK, probe().Code)
+	assert.Equal(1, client.calls)
+
+	// The result is cached, even once the API starts failing.
+	client.err = errors.New("401 Unauthorized")
+	assert.Equal(http.StatusOK, probe().Code)
+	assert.Equal(1, client.calls)
+
+	now = now.Add(readinessCacheTTL)
+	rec := probe()
+	assert.Equal(http.StatusServiceUnavailable, rec.Code)
+	assert.Contains(rec.Body.String(), "not ready: buildkite API check failed: 401 Unauthorized")
+	assert.Equal(2, client.calls)
+
+	// Failures are cached too.
+	client.err = nil
+	assert.Equal(http.StatusServiceUnavailable, probe().Code)
+	assert.Equal(2, client.calls)
+}
+
+func TestReadinessHandler_ProbeContext(t *testing.T) {
+	assert := require.New(t)
+
+	client := &mockAccessTokenClient{}
+	handler := newReadinessHandler(client)
+
+	// A probe that has already given up doesn't cancel the check.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.NoError(handler.check(ctx))
+	assert.NoError(client.ctxErr)
+
+	// A check that times out isn't cached, so the next probe tries again.
+	handler = newReadinessHandler(client)
+	client.err = context.DeadlineExceeded
+	assert.ErrorIs(handler.check(context.Background()), context.DeadlineExceeded)
+	client.err = nil
+	assert.NoError(handler.check(context.Background()))
+	assert.Equal(3, client.calls)
+}
+
+func TestReadinessHandler_NoClient(t *testing.T) {
+	rec := httptest.NewRecorder()
+	newReadinessHandler(nil).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ready", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+}
@@ -0,0 +1,60 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/buildkite/buildkite-mcp-server/pkg/buildkite"
+	"github.com/buildkite/buildkite-mcp-server/pkg/toolsets"
+	"github.com/rs/zerolog/log"
+)
+
+const scopeCheckTimeout = 10 * time.Second
+
+// checkTokenScopes compares the API token's scopes with those required by the
+// enabled toolsets, so a misconfigured token is reported at startup rather
+// than by a tool failing mid-session. Missing scopes are logged as a warning,
+// or returned as an error when strict is set.
+func checkTokenScopes(ctx context.Context, client buildkite.AccessTokenClient, enabledToolsets []string, readOnly, strict bool) error {
+	required := toolsets.NewDefaultRegistry().GetRequiredScopes(enabledToolsets, readOnly)
+
+	ctx, cancel := context.WithTimeout(ctx, scopeCheckTimeout)
+	defer cancel()
+
+	token, _, err := client.Get(ctx)
+	if err != nil {
+		if strict {
+			return fmt.Errorf("failed to check API token scopes: %w", err)
+		}
+		log.Warn().Err(err).Msg("Failed to check API token scopes")
+		return nil
+	}
+
+	missing := missingScopes(required, token.Scopes)
+	if len(missing) == 0 {
+		log.Debug().Strs("scopes", token.Scopes).Msg("API token has all required scopes")
+		return nil
+	}
+
+	if strict {
+		return fmt.Errorf("API token is missing scopes required by the enabled toolsets: %s", strings.Join(missing, ", "))
+	}
+	log.Warn().
+		Strs("missing_scopes", missing).
+		Msg("API token is missing scopes required by the enabled toolsets, tools that need them will fail")
+	return nil
+}
+
+// missingScopes returns the required scopes that are not granted, in order.
+func missingScopes(required, granted []string) []string {
+	var missing []string
+	for _, scope := range required {
+		if !slices.Contains(granted, scope) {
+			missing = append(missing, scope)
+		}
+	}
+	return missing
+}
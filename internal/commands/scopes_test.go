@@ -0,0 +1,46 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/buildkite/buildkite-mcp-server/pkg/toolsets"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckTokenScopes(t *testing.T) {
+	ctx := context.Background()
+	required := toolsets.NewDefaultRegistry().GetRequiredScopes([]string{"builds"}, true)
+	require.NotEmpty(t, required)
+
+	t.Run("all scopes granted", func(t *testing.T) {
+		client := &mockAccessTokenClient{scopes: append([]string{"read_user"}, required...)}
+		require.NoError(t, checkTokenScopes(ctx, client, []string{"builds"}, true, true))
+	})
+
+	t.Run("missing scopes only warn", func(t *testing.T) {
+		client := &mockAccessTokenClient{scopes: []string{"read_user"}}
+		require.NoError(t, checkTokenScopes(ctx, client, []string{"builds"}, true, false))
+	})
+
+	t.Run("missing scopes fail when strict", func(t *testing.T) {
+		client := &mockAccessTokenClient{scopes: required[1:]}
+		err := checkTokenScopes(ctx, client, []string{"builds"}, true, true)
+		require.ErrorContains(t, err, "API token is missing scopes required by the enabled toolsets: "+required[0])
+	})
+
+	t.Run("API errors fail when strict", func(t *testing.T) {
+		client := &mockAccessTokenClient{err: errors.New("401 Unauthorized")}
+		require.NoError(t, checkTokenScopes(ctx, client, []string{"builds"}, true, false))
+		require.ErrorContains(t, checkTokenScopes(ctx, client, []string{"builds"}, true, true), "401 Unauthorized")
+	})
+}
+
+func TestMissingScopes(t *testing.T) {
+	require.Equal(t, []string{"read_builds", "write_builds"}, missingScopes(
+		[]string{"read_agents", "read_builds", "write_builds"},
+		[]string{"read_agents", "read_user"},
+	))
+	require.Empty(t, missingScopes([]string{"read_builds"}, []string{"read_builds"}))
+}
@@ -7,18 +7,22 @@ import (
 	"github.com/buildkite/buildkite-mcp-server/pkg/server"
 	"github.com/buildkite/buildkite-mcp-server/pkg/toolsets"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
-	"github.com/rs/zerolog/log"
 )
 
 type StdioCmd struct {
-	EnabledToolsets []string `help:"Comma-separated list of toolsets to enable (e.g., 'pipelines,builds,clusters'). Use 'all' to enable all toolsets." default:"all" env:"BUILDKITE_TOOLSETS"`
+	EnabledToolsets []string `help:"Comma-separated list of toolsets to enable (e.g., 'pipelines,builds,clusters'). Use 'all' to enable all toolsets except opt-in ones such as 'graphql', which must be named, or an alias such as 'ci' (builds, logs, annotations) or 'monitoring' (agents, clusters, builds)." default:"all" env:"BUILDKITE_TOOLSETS"`
 	ReadOnly        bool     `help:"Enable read-only mode, which filters out write operations from all toolsets." default:"false" env:"BUILDKITE_READ_ONLY"`
+	DynamicToolsets bool     `help:"Start with only the tool discovery tools and let the client load the enabled toolsets on demand with enable_toolset." default:"false" env:"BUILDKITE_DYNAMIC_TOOLSETS"`
+	StrictScopes    bool     `help:"Fail at startup if the API token is missing scopes required by the enabled toolsets, instead of logging a warning." default:"false" env:"BUILDKITE_STRICT_SCOPES"`
 }
 
 func (c *StdioCmd) Run(ctx context.Context, globals *Globals) error {
 	if err := toolsets.ValidateToolsets(c.EnabledToolsets); err != nil {
 		return err
 	}
+	if err := checkGraphQLURL(c.EnabledToolsets, globals.GraphQLURL); err != nil {
+		return err
+	}
 
 	deps := buildkite.ToolDependencies{
 		BuildsClient:            globals.Client.Builds,
@@ -37,14 +41,38 @@ func (c *StdioCmd) Run(ctx context.Context, globals *Globals) error {
 		TestExecutionsClient:    globals.Client.TestRuns,
 		TestsClient:             globals.Client.Tests,
 		BuildkiteLogsClient:     globals.BuildkiteLogsClient,
+		MaxLogBytes:             globals.MaxLogBytes,
+		LogFetchConcurrency:     globals.LogFetchConcurrency,
+		IncludeRateLimit:        globals.DebugRateLimit,
+		PrettyJSON:              globals.PrettyJSON,
+		DefaultOrg:              globals.DefaultOrg,
+		SlowToolThreshold:       globals.SlowToolThreshold,
+		LargeToolResultBytes:    globals.LargeToolResultBytes,
+		Redactor:                globals.Redactor,
+		TokenScopes:             &buildkite.TokenScopes{},
+		IdempotentBuilds:        buildkite.NewIdempotentBuilds(),
+	}
+
+	if globals.GraphQLURL != "" {
+		deps.GraphQLClient = &buildkite.GraphQLAPIClient{Client: globals.Client, URL: globals.GraphQLURL}
+	}
+
+	if globals.CacheTTL > 0 {
+		deps.ResponseCache = buildkite.NewResponseCache(globals.CacheTTL)
+	}
+
+	if err := checkTokenScopes(ctx, globals.Client.AccessTokens, c.EnabledToolsets, c.ReadOnly, c.StrictScopes); err != nil {
+		return err
 	}
 
-	log.Info().Msg("Starting MCP server over stdio")
-	ctx = log.Logger.WithContext(ctx)
+	globals.Logger.Info().Msg("Starting MCP server over stdio")
+	ctx = globals.Logger.WithContext(ctx)
 
 	s := server.NewMCPServer(globals.Version, deps,
 		server.WithReadOnly(c.ReadOnly),
-		server.WithToolsets(c.EnabledToolsets...))
+		server.WithToolsets(c.EnabledToolsets...),
+		server.WithDynamicToolsets(c.DynamicToolsets),
+		server.WithLogger(globals.Logger))
 
 	return s.Run(ctx, &mcp.StdioTransport{})
 }
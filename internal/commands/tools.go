@@ -5,17 +5,31 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
+	"os"
 
 	"github.com/buildkite/buildkite-mcp-server/pkg/toolsets"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
-type ToolsCmd struct{}
+type ToolsCmd struct {
+	Schema bool `help:"Print a single JSON document mapping each tool name to its description, input schema and annotations, for generating clients." name:"schema"`
+}
+
+// toolSchema describes a tool's input in the schema document, with the same
+// keys as the tool in an MCP tools/list result.
+type toolSchema struct {
+	Description string               `json:"description"`
+	InputSchema any                  `json:"inputSchema"`
+	Annotations *mcp.ToolAnnotations `json:"annotations,omitempty"`
+}
 
 func (c *ToolsCmd) Run(ctx context.Context, globals *Globals) error {
-	registry := toolsets.NewToolsetRegistry()
-	registry.RegisterToolsets(toolsets.CreateBuiltinToolsets())
+	tools := allTools()
 
-	tools := registry.GetEnabledTools([]string{"all"}, false)
+	if c.Schema {
+		return printToolSchemas(os.Stdout, tools)
+	}
 
 	for _, toolDef := range tools {
 		buf := new(bytes.Buffer)
@@ -30,3 +44,27 @@ func (c *ToolsCmd) Run(ctx context.Context, globals *Globals) error {
 
 	return nil
 }
+
+// allTools returns the tools of every registered toolset, including opt-in
+// ones such as graphql that "all" leaves out, in toolset order.
+func allTools() []toolsets.ToolDefinition {
+	registry := toolsets.NewDefaultRegistry()
+	return registry.GetEnabledTools(registry.List(), false)
+}
+
+// printToolSchemas writes one JSON object keyed by tool name, so the whole
+// set of tools can be read as a single document.
+func printToolSchemas(out io.Writer, tools []toolsets.ToolDefinition) error {
+	schemas := make(map[string]toolSchema, len(tools))
+	for _, toolDef := range tools {
+		schemas[toolDef.Tool.Name] = toolSchema{
+			Description: toolDef.Tool.Description,
+			InputSchema: toolDef.Tool.InputSchema,
+			Annotations: toolDef.Tool.Annotations,
+		}
+	}
+
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(schemas)
+}
@@ -0,0 +1,44 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrintToolSchemas(t *testing.T) {
+	assert := require.New(t)
+
+	tools := allTools()
+	var out bytes.Buffer
+	assert.NoError(printToolSchemas(&out, tools))
+
+	var schemas map[string]struct {
+		Description string `json:"description"`
+		InputSchema struct {
+			Type       string                     `json:"type"`
+			Properties map[string]json.RawMessage `json:"properties"`
+			Required   []string                   `json:"required"`
+		} `json:"inputSchema"`
+		Annotations struct {
+			ReadOnlyHint bool `json:"readOnlyHint"`
+		} `json:"annotations"`
+	}
+	assert.NoError(json.Unmarshal(out.Bytes(), &schemas))
+	assert.Len(schemas, len(tools))
+
+	getBuild, ok := schemas["get_build"]
+	assert.True(ok)
+	assert.NotEmpty(getBuild.Description)
+	assert.Equal("object", getBuild.InputSchema.Type)
+	assert.Contains(getBuild.InputSchema.Properties, "build_number")
+	assert.Contains(getBuild.InputSchema.Required, "build_number")
+	assert.True(getBuild.Annotations.ReadOnlyHint)
+
+	assert.False(schemas["create_build"].Annotations.ReadOnlyHint)
+
+	// Opt-in toolsets are listed too.
+	assert.Contains(schemas, "graphql_query")
+}
@@ -0,0 +1,69 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/buildkite/buildkite-mcp-server/pkg/toolsets"
+)
+
+type ToolsetsCmd struct {
+	JSON bool `help:"Print the toolsets as JSON, for scripts." name:"json"`
+}
+
+// toolsetSummary is a toolset's metadata with the API token scopes its tools
+// need, and those its read-only tools need.
+type toolsetSummary struct {
+	toolsets.ToolsetMetadata
+	Scopes         []string `json:"scopes"`
+	ReadOnlyScopes []string `json:"read_only_scopes"`
+}
+
+func (c *ToolsetsCmd) Run(ctx context.Context, globals *Globals) error {
+	return printToolsets(os.Stdout, toolsets.NewDefaultRegistry(), c.JSON)
+}
+
+// printToolsets writes each toolset in the registry with its description,
+// tool counts and required scopes, as a table or as JSON.
+func printToolsets(out io.Writer, registry *toolsets.ToolsetRegistry, asJSON bool) error {
+	metadata := registry.GetMetadata()
+	summaries := make([]toolsetSummary, len(metadata))
+	for i, toolset := range metadata {
+		summaries[i] = toolsetSummary{
+			ToolsetMetadata: toolset,
+			Scopes:          registry.GetRequiredScopes([]string{toolset.Name}, false),
+			ReadOnlyScopes:  registry.GetRequiredScopes([]string{toolset.Name}, true),
+		}
+	}
+
+	if asJSON {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(summaries)
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TOOLSET\tTOOLS\tREAD-ONLY\tSCOPES\tDESCRIPTION")
+	for _, summary := range summaries {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%s\t%s\n",
+			summary.Name,
+			summary.ToolCount,
+			summary.ReadOnlyCount,
+			formatScopes(summary.Scopes),
+			summary.Description,
+		)
+	}
+	return w.Flush()
+}
+
+func formatScopes(scopes []string) string {
+	if len(scopes) == 0 {
+		return "-"
+	}
+	return strings.Join(scopes, ",")
+}
@@ -0,0 +1,70 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/buildkite/buildkite-mcp-server/pkg/toolsets"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+func testToolDef(name string, readOnly bool, scopes ...string) toolsets.ToolDefinition {
+	return toolsets.ToolDefinition{
+		Tool:           mcp.Tool{Name: name, Annotations: &mcp.ToolAnnotations{ReadOnlyHint: readOnly}},
+		RequiredScopes: scopes,
+	}
+}
+
+func testToolsetRegistry() *toolsets.ToolsetRegistry {
+	registry := toolsets.NewToolsetRegistry()
+	registry.RegisterToolsets(map[string]toolsets.Toolset{
+		"builds": {
+			Description: "Tools for managing builds",
+			Tools: []toolsets.ToolDefinition{
+				testToolDef("get_build", true, "read_builds"),
+				testToolDef("cancel_build", false, "write_builds"),
+			},
+		},
+		"user": {
+			Description: "Tools for the current user",
+			Tools: []toolsets.ToolDefinition{
+				testToolDef("current_user", true, "read_user"),
+			},
+		},
+	})
+	return registry
+}
+
+func TestPrintToolsets(t *testing.T) {
+	t.Run("table", func(t *testing.T) {
+		assert := require.New(t)
+		var out bytes.Buffer
+
+		assert.NoError(printToolsets(&out, testToolsetRegistry(), false))
+
+		lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+		assert.Len(lines, 3)
+		assert.Equal([]string{"TOOLSET", "TOOLS", "READ-ONLY", "SCOPES", "DESCRIPTION"}, strings.Fields(lines[0]))
+		assert.Equal([]string{"builds", "2", "1", "read_builds,write_builds", "Tools", "for", "managing", "builds"}, strings.Fields(lines[1]))
+		assert.Equal([]string{"user", "1", "1", "read_user", "Tools", "for", "the", "current", "user"}, strings.Fields(lines[2]))
+	})
+
+	t.Run("json", func(t *testing.T) {
+		assert := require.New(t)
+		var out bytes.Buffer
+
+		assert.NoError(printToolsets(&out, testToolsetRegistry(), true))
+
+		var summaries []toolsetSummary
+		assert.NoError(json.Unmarshal(out.Bytes(), &summaries))
+		assert.Len(summaries, 2)
+		assert.Equal("builds", summaries[0].Name)
+		assert.Equal(2, summaries[0].ToolCount)
+		assert.Equal(1, summaries[0].ReadOnlyCount)
+		assert.Equal([]string{"read_builds", "write_builds"}, summaries[0].Scopes)
+		assert.Equal([]string{"read_builds"}, summaries[0].ReadOnlyScopes)
+	})
+}
@@ -0,0 +1,124 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	latestReleaseURL    = "https://api.github.com/repos/buildkite/buildkite-mcp-server/releases/latest"
+	versionCheckTimeout = 5 * time.Second
+)
+
+type githubRelease struct {
+	TagName string `json:"tag_name"`
+	HTMLURL string `json:"html_url"`
+}
+
+// StartVersionCheck looks up the latest release on GitHub in the background
+// and logs a notice when it is newer than current. It never blocks startup,
+// and failures are only logged at debug level. Builds without a release
+// version, such as "dev", aren't checked. The binary is never updated.
+func StartVersionCheck(ctx context.Context, current string) {
+	if _, ok := parseVersion(current); !ok {
+		log.Debug().Str("version", current).Msg("Skipping version check for a build without a release version")
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(ctx, versionCheckTimeout)
+		defer cancel()
+
+		release, newer, err := checkLatestVersion(ctx, http.DefaultClient, latestReleaseURL, current)
+		if err != nil {
+			log.Debug().Err(err).Msg("Failed to check for a newer version")
+			return
+		}
+		if newer {
+			log.Info().
+				Str("current_version", current).
+				Str("latest_version", release.TagName).
+				Str("url", release.HTMLURL).
+				Msg("A newer version of buildkite-mcp-server is available")
+		}
+	}()
+}
+
+// checkLatestVersion reads the latest release from url and reports whether
+// it is newer than current.
+func checkLatestVersion(ctx context.Context, client *http.Client, url, current string) (githubRelease, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return githubRelease{}, false, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("User-Agent", UserAgent(current))
+
+	res, err := client.Do(req)
+	if err != nil {
+		return githubRelease{}, false, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return githubRelease{}, false, fmt.Errorf("unexpected status reading the latest release: %s", res.Status)
+	}
+
+	var release githubRelease
+	if err := json.NewDecoder(res.Body).Decode(&release); err != nil {
+		return githubRelease{}, false, fmt.Errorf("failed to decode the latest release: %w", err)
+	}
+
+	latest, ok := parseVersion(release.TagName)
+	if !ok {
+		return githubRelease{}, false, fmt.Errorf("latest release has an invalid version %q", release.TagName)
+	}
+	installed, _ := parseVersion(current)
+	return release, latest.newerThan(installed), nil
+}
+
+// releaseVersion is a semantic version, without build metadata.
+type releaseVersion struct {
+	parts      [3]int
+	prerelease string
+}
+
+// parseVersion parses a version such as "1.2.3", "v1.2.3" or "1.2.3-rc.1".
+func parseVersion(version string) (releaseVersion, bool) {
+	version = strings.TrimPrefix(version, "v")
+	version, _, _ = strings.Cut(version, "+")
+	version, prerelease, _ := strings.Cut(version, "-")
+
+	fields := strings.Split(version, ".")
+	if len(fields) != 3 {
+		return releaseVersion{}, false
+	}
+	v := releaseVersion{prerelease: prerelease}
+	for i, field := range fields {
+		n, err := strconv.Atoi(field)
+		if err != nil || n < 0 {
+			return releaseVersion{}, false
+		}
+		v.parts[i] = n
+	}
+	return v, true
+}
+
+// newerThan reports whether v is a later version than other. A release is
+// newer than a pre-release of the same version; pre-releases of the same
+// version aren't ordered.
+func (v releaseVersion) newerThan(other releaseVersion) bool {
+	for i := range v.parts {
+		if v.parts[i] != other.parts[i] {
+			return v.parts[i] > other.parts[i]
+		}
+	}
+	return v.prerelease == "" && other.prerelease != ""
+}
@@ -0,0 +1,88 @@
+package commands
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		version string
+		ok      bool
+	}{
+		{"1.2.3", true},
+		{"v1.2.3", true},
+		{"1.2.3-rc.1", true},
+		{"1.2.3+abc123", true},
+		{"dev", false},
+		{"1.2", false},
+		{"1.x.3", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		_, ok := parseVersion(tt.version)
+		require.Equal(t, tt.ok, ok, "parseVersion(%q)", tt.version)
+	}
+}
+
+func TestReleaseVersionNewerThan(t *testing.T) {
+	tests := []struct {
+		latest, current string
+		newer           bool
+	}{
+		{"v1.2.4", "1.2.3", true},
+		{"v1.10.0", "1.9.9", true},
+		{"v2.0.0", "1.99.99", true},
+		{"v1.2.3", "1.2.3", false},
+		{"v1.2.3", "1.3.0", false},
+		{"v1.2.3", "1.2.3-rc.1", true},
+		{"v1.2.3-rc.2", "1.2.3-rc.1", false},
+	}
+
+	for _, tt := range tests {
+		latest, _ := parseVersion(tt.latest)
+		current, _ := parseVersion(tt.current)
+		require.Equal(t, tt.newer, latest.newerThan(current), "%s newer than %s", tt.latest, tt.current)
+	}
+}
+
+func TestCheckLatestVersion(t *testing.T) {
+	release := `{"tag_name": "v1.3.0", "html_url": "https://github.com/buildkite/buildkite-mcp-server/releases/tag/v1.3.0"}`
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "application/vnd.github+json", r.Header.Get("Accept"))
+		_, _ = w.Write([]byte(release))
+	}))
+	defer api.Close()
+
+	t.Run("newer", func(t *testing.T) {
+		assert := require.New(t)
+
+		got, newer, err := checkLatestVersion(context.Background(), api.Client(), api.URL, "1.2.0")
+		assert.NoError(err)
+		assert.True(newer)
+		assert.Equal("v1.3.0", got.TagName)
+		assert.Equal("https://github.com/buildkite/buildkite-mcp-server/releases/tag/v1.3.0", got.HTMLURL)
+	})
+
+	t.Run("current", func(t *testing.T) {
+		_, newer, err := checkLatestVersion(context.Background(), api.Client(), api.URL, "1.3.0")
+		require.NoError(t, err)
+		require.False(t, newer)
+	})
+
+	t.Run("error status", func(t *testing.T) {
+		failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+		}))
+		defer failing.Close()
+
+		_, newer, err := checkLatestVersion(context.Background(), failing.Client(), failing.URL, "1.2.0")
+		require.Error(t, err)
+		require.False(t, newer)
+	})
+}
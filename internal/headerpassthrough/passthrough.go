@@ -36,7 +36,7 @@ type Config struct {
 	usesAuthorization bool
 }
 
-func New(headerNames []string, fixedHeaders map[string]string, baseURL string) (*Config, error) {
+func New(headerNames []string, fixedHeaders http.Header, baseURL string) (*Config, error) {
 	target, err := url.Parse(baseURL)
 	if err != nil || target.Host == "" || (target.Scheme != "http" && target.Scheme != "https") {
 		return nil, fmt.Errorf("buildkite base URL for HTTP header passthrough must be an absolute HTTP or HTTPS URL")
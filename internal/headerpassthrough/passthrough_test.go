@@ -27,11 +27,11 @@ func TestNew(t *testing.T) {
 	tests := []struct {
 		name    string
 		headers []string
-		fixed   map[string]string
+		fixed   http.Header
 		baseURL string
 	}{
 		{name: "invalid header", headers: []string{"Bad Header"}, baseURL: "https://api.buildkite.com"},
-		{name: "fixed overlap", headers: []string{"X-Identity"}, fixed: map[string]string{"x-identity": "fixed"}, baseURL: "https://api.buildkite.com"},
+		{name: "fixed overlap", headers: []string{"X-Identity"}, fixed: http.Header{"X-Identity": {"fixed"}}, baseURL: "https://api.buildkite.com"},
 		{name: "relative base URL", headers: []string{"X-Identity"}, baseURL: "/api"},
 	}
 
@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompress(t *testing.T) {
+	large := strings.Repeat(`{"state":"passed"}`, 200)
+
+	tests := []struct {
+		name           string
+		acceptEncoding string
+		contentType    string
+		body           string
+		wantGzip       bool
+	}{
+		{name: "large JSON is compressed", acceptEncoding: "gzip, deflate", contentType: "application/json", body: large, wantGzip: true},
+		{name: "client without gzip", acceptEncoding: "deflate", contentType: "application/json", body: large},
+		{name: "gzip refused with q=0", acceptEncoding: "gzip;q=0", contentType: "application/json", body: large},
+		{name: "small body", acceptEncoding: "gzip", contentType: "application/json", body: `{"ok":true}`},
+		{name: "event stream", acceptEncoding: "gzip", contentType: "text/event-stream", body: large},
+		{name: "already compressed", acceptEncoding: "gzip", contentType: "application/zip", body: large},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := require.New(t)
+
+			handler := Compress()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", tt.contentType)
+				w.WriteHeader(http.StatusCreated)
+				// Write in pieces so the threshold is crossed mid-response.
+				for i := 0; i < len(tt.body); i += 100 {
+					_, _ = io.WriteString(w, tt.body[i:min(i+100, len(tt.body))])
+				}
+			}))
+
+			req := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+			req.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(http.StatusCreated, rec.Code)
+			assert.Equal("Accept-Encoding", rec.Header().Get("Vary"))
+
+			var got []byte
+			if tt.wantGzip {
+				assert.Equal("gzip", rec.Header().Get("Content-Encoding"))
+				assert.Less(rec.Body.Len(), len(tt.body))
+				gz, err := gzip.NewReader(rec.Body)
+				assert.NoError(err)
+				got, err = io.ReadAll(gz)
+				assert.NoError(err)
+			} else {
+				assert.Empty(rec.Header().Get("Content-Encoding"))
+				got = rec.Body.Bytes()
+			}
+			assert.Equal(tt.body, string(got))
+		})
+	}
+}
+
+func TestCompress_Flush(t *testing.T) {
+	assert := require.New(t)
+
+	handler := Compress()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "partial")
+		w.(http.Flusher).Flush()
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/mcp", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	// Flushing before the threshold sends the response uncompressed.
+	assert.True(rec.Flushed)
+	assert.Empty(rec.Header().Get("Content-Encoding"))
+	assert.Equal("partial", rec.Body.String())
+}
+
+func TestAcceptsGzip(t *testing.T) {
+	assert := require.New(t)
+	assert.True(acceptsGzip("gzip"))
+	assert.True(acceptsGzip("br, GZIP;q=0.5"))
+	assert.False(acceptsGzip("gzip;q=0"))
+	assert.False(acceptsGzip("deflate, br"))
+	assert.False(acceptsGzip(""))
+}
@@ -0,0 +1,171 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	defaultIntrospectionCacheTTL = time.Minute
+	introspectionTimeout         = 10 * time.Second
+	// introspectionCacheSweepSize is the cache size past which expired
+	// entries are dropped on insert.
+	introspectionCacheSweepSize = 1024
+)
+
+// IntrospectionConfig configures OAuth 2.0 token introspection (RFC 7662).
+type IntrospectionConfig struct {
+	// URL is the authorization server's introspection endpoint.
+	URL string
+	// ClientID and ClientSecret authenticate this server to the endpoint.
+	ClientID     string
+	ClientSecret string
+	// CacheTTL is how long an active token is trusted before it is
+	// introspected again. Defaults to one minute.
+	CacheTTL   time.Duration
+	HTTPClient *http.Client
+}
+
+type introspectionResponse struct {
+	Active bool  `json:"active"`
+	Exp    int64 `json:"exp"`
+}
+
+type introspector struct {
+	cfg IntrospectionConfig
+	now func() time.Time
+
+	mu    sync.Mutex
+	cache map[[sha256.Size]byte]time.Time // token hash to when it must be rechecked
+}
+
+// Introspection rejects requests unless their bearer token is reported active
+// by the configured introspection endpoint. Active tokens are cached for
+// CacheTTL, or until they expire if sooner; inactive tokens are not cached,
+// so a newly issued token is accepted straight away.
+func Introspection(cfg IntrospectionConfig) func(http.Handler) http.Handler {
+	if cfg.CacheTTL <= 0 {
+		cfg.CacheTTL = defaultIntrospectionCacheTTL
+	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = http.DefaultClient
+	}
+	return introspection(&introspector{
+		cfg:   cfg,
+		now:   time.Now,
+		cache: make(map[[sha256.Size]byte]time.Time),
+	})
+}
+
+func introspection(in *introspector) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			scheme, token, _ := strings.Cut(r.Header.Get("Authorization"), " ")
+			token = strings.TrimSpace(token)
+			if !strings.EqualFold(scheme, "Bearer") || token == "" {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="buildkite-mcp-server"`)
+				http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+				return
+			}
+
+			active, err := in.check(r.Context(), token)
+			if err != nil {
+				log.Ctx(r.Context()).Error().Err(err).Msg("Token introspection failed")
+				http.Error(w, "token introspection failed", http.StatusServiceUnavailable)
+				return
+			}
+			if !active {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="buildkite-mcp-server", error="invalid_token"`)
+				http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// check reports whether token is active, from the cache when possible.
+func (in *introspector) check(ctx context.Context, token string) (bool, error) {
+	// Only hashes are kept, so the cache never holds usable credentials.
+	key := sha256.Sum256([]byte(token))
+
+	in.mu.Lock()
+	until, cached := in.cache[key]
+	in.mu.Unlock()
+	if cached && in.now().Before(until) {
+		return true, nil
+	}
+
+	resp, err := in.introspect(ctx, token)
+	if err != nil {
+		return false, err
+	}
+	if !resp.Active {
+		in.mu.Lock()
+		delete(in.cache, key)
+		in.mu.Unlock()
+		return false, nil
+	}
+
+	now := in.now()
+	until = now.Add(in.cfg.CacheTTL)
+	if resp.Exp > 0 {
+		if exp := time.Unix(resp.Exp, 0); exp.Before(until) {
+			until = exp
+		}
+	}
+
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	if len(in.cache) >= introspectionCacheSweepSize {
+		for k, v := range in.cache {
+			if !now.Before(v) {
+				delete(in.cache, k)
+			}
+		}
+	}
+	in.cache[key] = until
+	return true, nil
+}
+
+func (in *introspector) introspect(ctx context.Context, token string) (introspectionResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, introspectionTimeout)
+	defer cancel()
+
+	form := url.Values{"token": {token}, "token_type_hint": {"access_token"}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, in.cfg.URL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return introspectionResponse{}, fmt.Errorf("failed to create introspection request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if in.cfg.ClientID != "" {
+		req.SetBasicAuth(url.QueryEscape(in.cfg.ClientID), url.QueryEscape(in.cfg.ClientSecret))
+	}
+
+	res, err := in.cfg.HTTPClient.Do(req)
+	if err != nil {
+		return introspectionResponse{}, fmt.Errorf("introspection request failed: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return introspectionResponse{}, fmt.Errorf("introspection endpoint returned %s", res.Status)
+	}
+
+	var resp introspectionResponse
+	if err := json.NewDecoder(res.Body).Decode(&resp); err != nil {
+		return introspectionResponse{}, fmt.Errorf("failed to decode introspection response: %w", err)
+	}
+	return resp, nil
+}
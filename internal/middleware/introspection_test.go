@@ -0,0 +1,95 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntrospection(t *testing.T) {
+	assert := require.New(t)
+
+	now := time.Unix(1_000_000, 0)
+	calls := 0
+	idp := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		clientID, secret, ok := r.BasicAuth()
+		assert.True(ok)
+		assert.Equal("mcp-server", clientID)
+		assert.Equal("s3cret", secret)
+		assert.NoError(r.ParseForm())
+		assert.Equal("access_token", r.PostForm.Get("token_type_hint"))
+
+		switch r.PostForm.Get("token") {
+		case "good":
+			_ = json.NewEncoder(w).Encode(map[string]any{"active": true})
+		case "expiring":
+			_ = json.NewEncoder(w).Encode(map[string]any{"active": true, "exp": now.Add(10 * time.Second).Unix()})
+		case "broken":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			_ = json.NewEncoder(w).Encode(map[string]any{"active": false})
+		}
+	}))
+	defer idp.Close()
+
+	in := &introspector{
+		cfg: IntrospectionConfig{
+			URL:          idp.URL,
+			ClientID:     "mcp-server",
+			ClientSecret: "s3cret",
+			CacheTTL:     time.Minute,
+			HTTPClient:   idp.Client(),
+		},
+		now:   func() time.Time { return now },
+		cache: make(map[[sha256.Size]byte]time.Time),
+	}
+	handler := introspection(in)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	request := func(authorization string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := request("")
+	assert.Equal(http.StatusUnauthorized, rec.Code)
+	assert.Equal(`Bearer realm="buildkite-mcp-server"`, rec.Header().Get("WWW-Authenticate"))
+	assert.Equal(0, calls)
+
+	rec = request("Bearer revoked")
+	assert.Equal(http.StatusUnauthorized, rec.Code)
+	assert.Contains(rec.Header().Get("WWW-Authenticate"), `error="invalid_token"`)
+
+	assert.Equal(http.StatusServiceUnavailable, request("Bearer broken").Code)
+
+	// Active tokens are cached for the TTL.
+	calls = 0
+	assert.Equal(http.StatusOK, request("Bearer good").Code)
+	assert.Equal(http.StatusOK, request("Bearer good").Code)
+	assert.Equal(1, calls)
+	now = now.Add(time.Minute)
+	assert.Equal(http.StatusOK, request("Bearer good").Code)
+	assert.Equal(2, calls)
+
+	// A token expiring before the TTL is only cached until it expires.
+	calls = 0
+	assert.Equal(http.StatusOK, request("Bearer expiring").Code)
+	now = now.Add(5 * time.Second)
+	assert.Equal(http.StatusOK, request("Bearer expiring").Code)
+	assert.Equal(1, calls)
+	now = now.Add(5 * time.Second)
+	request("Bearer expiring")
+	assert.Equal(2, calls)
+}
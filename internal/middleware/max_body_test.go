@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaxBodyBytes(t *testing.T) {
+	var received string
+	handler := MaxBodyBytes(16)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		received = string(body)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+
+	tests := []struct {
+		name          string
+		body          string
+		chunked       bool
+		wantStatus    int
+		wantForwarded bool
+	}{
+		{name: "within limit", body: `{"jsonrpc":"2"}`, wantStatus: http.StatusAccepted, wantForwarded: true},
+		{name: "exactly at limit", body: strings.Repeat("a", 16), wantStatus: http.StatusAccepted, wantForwarded: true},
+		{name: "over limit", body: strings.Repeat("a", 17), wantStatus: http.StatusRequestEntityTooLarge},
+		{name: "over limit without content length", body: strings.Repeat("a", 1024), chunked: true, wantStatus: http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			received = ""
+			req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(tt.body))
+			if tt.chunked {
+				req.ContentLength = -1
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			require.Equal(t, tt.wantStatus, rec.Code)
+			if tt.wantForwarded {
+				require.Equal(t, tt.body, received)
+			} else {
+				require.Empty(t, received)
+			}
+		})
+	}
+}
+
+func TestMaxBodyBytes_NoBody(t *testing.T) {
+	handler := MaxBodyBytes(16)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/mcp", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+}
@@ -0,0 +1,189 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// latencyBuckets are the upper bounds, in seconds, of the request duration
+// histogram. They reach past a minute because tools such as log reads can
+// hold an MCP request open for a long time.
+var latencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+// resultSizeBuckets are the upper bounds, in bytes, of the tool result size
+// histogram, from a short message up to a large job log.
+var resultSizeBuckets = []float64{1024, 4096, 16384, 65536, 262144, 1048576, 4194304}
+
+type requestKey struct {
+	path   string
+	status int
+}
+
+type histogram struct {
+	bounds  []float64
+	buckets []uint64 // per bucket, not cumulative; the last counts values above every bound
+	sum     float64
+	count   uint64
+}
+
+func newHistogram(bounds []float64) *histogram {
+	return &histogram{bounds: bounds, buckets: make([]uint64, len(bounds)+1)}
+}
+
+func (h *histogram) observe(value float64) {
+	i, _ := slices.BinarySearch(h.bounds, value)
+	h.buckets[i]++
+	h.sum += value
+	h.count++
+}
+
+// write writes the histogram's series for one label in the Prometheus text
+// format.
+func (h *histogram) write(b *strings.Builder, name, label, value string) {
+	var cumulative uint64
+	for i, bound := range h.bounds {
+		cumulative += h.buckets[i]
+		fmt.Fprintf(b, "%s_bucket{%s=%s,le=\"%s\"} %d\n", name, label, labelValue(value), strconv.FormatFloat(bound, 'f', -1, 64), cumulative)
+	}
+	fmt.Fprintf(b, "%s_bucket{%s=%s,le=\"+Inf\"} %d\n", name, label, labelValue(value), h.count)
+	fmt.Fprintf(b, "%s_sum{%s=%s} %s\n", name, label, labelValue(value), strconv.FormatFloat(h.sum, 'g', -1, 64))
+	fmt.Fprintf(b, "%s_count{%s=%s} %d\n", name, label, labelValue(value), h.count)
+}
+
+// Metrics counts HTTP requests by path and status and records their latency,
+// records the duration and result size of tool calls, and serves the results
+// in the Prometheus text format.
+type Metrics struct {
+	mu            sync.Mutex
+	requests      map[requestKey]uint64
+	latencies     map[string]*histogram
+	toolDurations map[string]*histogram
+	toolResults   map[string]*histogram
+}
+
+func NewMetrics() *Metrics {
+	return &Metrics{
+		requests:      make(map[requestKey]uint64),
+		latencies:     make(map[string]*histogram),
+		toolDurations: make(map[string]*histogram),
+		toolResults:   make(map[string]*histogram),
+	}
+}
+
+// WrapHandler records metrics for every request handled by handler. When
+// handler is an http.ServeMux, requests are labelled with the matched route
+// pattern rather than the raw path, so unknown paths can't grow the label set.
+func (m *Metrics) WrapHandler(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rw := newResponseWriter(w)
+
+		handler.ServeHTTP(rw, r)
+
+		path := r.Pattern
+		if path == "" {
+			path = "unmatched"
+		}
+		m.observe(path, rw.Status(), time.Since(start))
+	})
+}
+
+func (m *Metrics) observe(path string, status int, duration time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.requests[requestKey{path: path, status: status}]++
+
+	latency, ok := m.latencies[path]
+	if !ok {
+		latency = newHistogram(latencyBuckets)
+		m.latencies[path] = latency
+	}
+	latency.observe(duration.Seconds())
+}
+
+// ObserveToolCall records the duration and result size of a tool call. Only
+// calls that return a result are observed, so tool names come from the
+// server's own tools.
+func (m *Metrics) ObserveToolCall(toolName string, duration time.Duration, resultBytes int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	toolDuration, ok := m.toolDurations[toolName]
+	if !ok {
+		toolDuration = newHistogram(latencyBuckets)
+		m.toolDurations[toolName] = toolDuration
+	}
+	toolDuration.observe(duration.Seconds())
+
+	toolResult, ok := m.toolResults[toolName]
+	if !ok {
+		toolResult = newHistogram(resultSizeBuckets)
+		m.toolResults[toolName] = toolResult
+	}
+	toolResult.observe(float64(resultBytes))
+}
+
+// ServeHTTP writes the collected metrics in the Prometheus text exposition format.
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var b strings.Builder
+
+	b.WriteString("# HELP buildkite_mcp_http_requests_total Total HTTP requests by route and status code.\n")
+	b.WriteString("# TYPE buildkite_mcp_http_requests_total counter\n")
+	keys := make([]requestKey, 0, len(m.requests))
+	for key := range m.requests {
+		keys = append(keys, key)
+	}
+	slices.SortFunc(keys, func(a, b requestKey) int {
+		if c := strings.Compare(a.path, b.path); c != 0 {
+			return c
+		}
+		return a.status - b.status
+	})
+	for _, key := range keys {
+		fmt.Fprintf(&b, "buildkite_mcp_http_requests_total{path=%s,status=\"%d\"} %d\n", labelValue(key.path), key.status, m.requests[key])
+	}
+
+	b.WriteString("# HELP buildkite_mcp_http_request_duration_seconds HTTP request latency by route.\n")
+	b.WriteString("# TYPE buildkite_mcp_http_request_duration_seconds histogram\n")
+	writeHistograms(&b, "buildkite_mcp_http_request_duration_seconds", "path", m.latencies)
+
+	if len(m.toolDurations) > 0 {
+		b.WriteString("# HELP buildkite_mcp_tool_duration_seconds Tool call duration by tool.\n")
+		b.WriteString("# TYPE buildkite_mcp_tool_duration_seconds histogram\n")
+		writeHistograms(&b, "buildkite_mcp_tool_duration_seconds", "tool", m.toolDurations)
+
+		b.WriteString("# HELP buildkite_mcp_tool_result_bytes Size of tool call results as JSON by tool.\n")
+		b.WriteString("# TYPE buildkite_mcp_tool_result_bytes histogram\n")
+		writeHistograms(&b, "buildkite_mcp_tool_result_bytes", "tool", m.toolResults)
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, _ = w.Write([]byte(b.String()))
+}
+
+// writeHistograms writes each histogram, labelled with its key, in key order.
+func writeHistograms(b *strings.Builder, name, label string, histograms map[string]*histogram) {
+	keys := make([]string, 0, len(histograms))
+	for key := range histograms {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		histograms[key].write(b, name, label, key)
+	}
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func labelValue(value string) string {
+	return `"` + labelEscaper.Replace(value) + `"`
+}
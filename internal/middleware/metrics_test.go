@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetrics(t *testing.T) {
+	assert := require.New(t)
+
+	metrics := NewMetrics()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/mcp", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	})
+	mux.HandleFunc("/items/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	handler := metrics.WrapHandler(mux)
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodPost, "/mcp", nil),
+		httptest.NewRequest(http.MethodPost, "/mcp", nil),
+		httptest.NewRequest(http.MethodGet, "/mcp", nil),
+		httptest.NewRequest(http.MethodGet, "/items/1", nil),
+		httptest.NewRequest(http.MethodGet, "/items/2", nil),
+		httptest.NewRequest(http.MethodGet, "/nope", nil),
+	} {
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	rec := httptest.NewRecorder()
+	metrics.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(http.StatusOK, rec.Code)
+	assert.True(strings.HasPrefix(rec.Header().Get("Content-Type"), "text/plain"))
+
+	body, err := io.ReadAll(rec.Body)
+	assert.NoError(err)
+	output := string(body)
+
+	assert.Contains(output, `buildkite_mcp_http_requests_total{path="/mcp",status="200"} 2`)
+	assert.Contains(output, `buildkite_mcp_http_requests_total{path="/mcp",status="405"} 1`)
+	// Routes are labelled by pattern, and unknown paths share one label.
+	assert.Contains(output, `buildkite_mcp_http_requests_total{path="/items/{id}",status="204"} 2`)
+	assert.Contains(output, `buildkite_mcp_http_requests_total{path="unmatched",status="404"} 1`)
+
+	assert.Contains(output, `buildkite_mcp_http_request_duration_seconds_bucket{path="/mcp",le="+Inf"} 3`)
+	assert.Contains(output, `buildkite_mcp_http_request_duration_seconds_count{path="/mcp"} 3`)
+	assert.Contains(output, `buildkite_mcp_http_request_duration_seconds_bucket{path="/mcp",le="60"} 3`)
+}
+
+func TestMetricsToolCalls(t *testing.T) {
+	assert := require.New(t)
+
+	metrics := NewMetrics()
+
+	rec := httptest.NewRecorder()
+	metrics.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.NotContains(rec.Body.String(), "buildkite_mcp_tool_")
+
+	metrics.ObserveToolCall("get_build", 200*time.Millisecond, 2000)
+	metrics.ObserveToolCall("get_build", 3*time.Second, 500000)
+	metrics.ObserveToolCall("list_pipelines", 50*time.Millisecond, 100)
+
+	rec = httptest.NewRecorder()
+	metrics.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	output := rec.Body.String()
+
+	assert.Contains(output, `buildkite_mcp_tool_duration_seconds_bucket{tool="get_build",le="0.25"} 1`)
+	assert.Contains(output, `buildkite_mcp_tool_duration_seconds_bucket{tool="get_build",le="5"} 2`)
+	assert.Contains(output, `buildkite_mcp_tool_duration_seconds_count{tool="get_build"} 2`)
+	assert.Contains(output, `buildkite_mcp_tool_duration_seconds_count{tool="list_pipelines"} 1`)
+
+	assert.Contains(output, `buildkite_mcp_tool_result_bytes_bucket{tool="get_build",le="4096"} 1`)
+	assert.Contains(output, `buildkite_mcp_tool_result_bytes_bucket{tool="get_build",le="1048576"} 2`)
+	assert.Contains(output, `buildkite_mcp_tool_result_bytes_sum{tool="get_build"} 502000`)
+	assert.Contains(output, `buildkite_mcp_tool_result_bytes_bucket{tool="list_pipelines",le="+Inf"} 1`)
+}
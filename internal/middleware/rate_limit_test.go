@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimit(t *testing.T) {
+	assert := require.New(t)
+
+	now := time.Unix(0, 0)
+	limiter := newRateLimiter(2, 3, func() time.Time { return now })
+	handler := rateLimit(limiter)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	request := func(remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+		req.RemoteAddr = remoteAddr
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// The burst is allowed, then the client is limited.
+	for range 3 {
+		assert.Equal(http.StatusOK, request("10.0.0.1:1234").Code)
+	}
+	rec := request("10.0.0.1:1234")
+	assert.Equal(http.StatusTooManyRequests, rec.Code)
+	assert.Equal("1", rec.Header().Get("Retry-After"))
+
+	// Buckets are per IP, regardless of the client's port.
+	assert.Equal(http.StatusOK, request("10.0.0.2:1234").Code)
+	assert.Equal(http.StatusTooManyRequests, request("10.0.0.1:5678").Code)
+
+	// Tokens refill at the configured rate.
+	now = now.Add(500 * time.Millisecond)
+	assert.Equal(http.StatusOK, request("10.0.0.1:1234").Code)
+	assert.Equal(http.StatusTooManyRequests, request("10.0.0.1:1234").Code)
+}
+
+func TestRateLimit_SweepsIdleBuckets(t *testing.T) {
+	assert := require.New(t)
+
+	now := time.Unix(0, 0)
+	limiter := newRateLimiter(1, 0, func() time.Time { return now })
+
+	allowed, _ := limiter.allow("10.0.0.1")
+	assert.True(allowed)
+	allowed, retryAfter := limiter.allow("10.0.0.1")
+	assert.False(allowed)
+	assert.Equal(time.Second, retryAfter)
+
+	now = now.Add(rateLimitSweepInterval)
+	allowed, _ = limiter.allow("10.0.0.2")
+	assert.True(allowed)
+	assert.NotContains(limiter.buckets, "10.0.0.1")
+	assert.Contains(limiter.buckets, "10.0.0.2")
+}
@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// HeaderRequestID carries the request's correlation ID in both directions.
+const HeaderRequestID = "X-Request-ID"
+
+// maxRequestIDLength bounds client-supplied IDs, which end up in logs and traces.
+const maxRequestIDLength = 128
+
+type requestIDContextKey struct{}
+
+// GetRequestIDFromContext returns the request ID set by RequestID, or "" if none.
+func GetRequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDContextKey{}).(string)
+	return id
+}
+
+// RequestID gives each request a correlation ID, taken from the X-Request-ID
+// header when the client sends a usable one and generated otherwise. The ID
+// is stored in the request context, echoed in the response header, and added
+// to the current trace span.
+func RequestID() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := r.Header.Get(HeaderRequestID)
+			if !validRequestID(id) {
+				id = uuid.NewString()
+			}
+
+			w.Header().Set(HeaderRequestID, id)
+			trace.SpanFromContext(r.Context()).SetAttributes(attribute.String("request_id", id))
+
+			ctx := context.WithValue(r.Context(), requestIDContextKey{}, id)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// validRequestID accepts short IDs of printable ASCII, so a client can't
+// inject control characters or huge values into logs.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestID(t *testing.T) {
+	tests := []struct {
+		name     string
+		incoming string
+		wantSame bool
+	}{
+		{name: "incoming ID is kept", incoming: "req-123", wantSame: true},
+		{name: "missing ID is generated"},
+		{name: "ID with control characters is replaced", incoming: "req\n123"},
+		{name: "overlong ID is replaced", incoming: strings.Repeat("a", maxRequestIDLength+1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := require.New(t)
+
+			var seen string
+			handler := RequestID()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				seen = GetRequestIDFromContext(r.Context())
+			}))
+
+			req := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+			if tt.incoming != "" {
+				req.Header.Set(HeaderRequestID, tt.incoming)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(seen, rec.Header().Get(HeaderRequestID))
+			if tt.wantSame {
+				assert.Equal(tt.incoming, seen)
+			} else {
+				_, err := uuid.Parse(seen)
+				assert.NoError(err)
+			}
+		})
+	}
+}
+
+func TestRequestID_Logged(t *testing.T) {
+	var logs bytes.Buffer
+	handler := RequestID()(RequestLog(zerolog.New(&logs))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})))
+
+	req := httptest.NewRequest(http.MethodGet, "/mcp", nil)
+	req.Header.Set(HeaderRequestID, "req-123")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	require.Contains(t, logs.String(), `"request_id":"req-123"`)
+	require.Empty(t, GetRequestIDFromContext(req.Context()))
+}
@@ -0,0 +1,129 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// requestLogBodyLimit caps how much of a request body is kept for finding the
+// MCP method and tool name. Larger bodies are logged without them.
+const requestLogBodyLimit = 1 << 20
+
+// RequestLog logs each request once it completes, with its status, duration,
+// client IP and, when RequestID runs first, request ID. For MCP JSON-RPC
+// requests it also logs mcp_method and, for tool calls, tool_name; a batch
+// logs each as a comma-separated list.
+//
+// The request's context also gets a logger with the client IP and request ID,
+// so lines logged with log.Ctx while handling the request, such as by tools,
+// can be matched to it.
+func RequestLog(logger zerolog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			rw := newResponseWriter(w)
+
+			fields := logger.With().Str("remote_ip", clientIP(r))
+			if id := GetRequestIDFromContext(r.Context()); id != "" {
+				fields = fields.Str("request_id", id)
+			}
+			requestLogger := fields.Logger()
+			r = r.WithContext(requestLogger.WithContext(r.Context()))
+
+			// Capture the body as the next handler reads it, rather than
+			// reading it here, so body size limits further in still apply.
+			var body *capturingBody
+			if r.Body != nil && r.Body != http.NoBody {
+				body = &capturingBody{ReadCloser: r.Body}
+				r.Body = body
+			}
+
+			next.ServeHTTP(rw, r)
+
+			event := requestLogger.Info().
+				Str("method", r.Method).
+				Str("path", r.URL.Path).
+				Int("status", rw.Status()).
+				Dur("duration", time.Since(start))
+
+			if body != nil && !body.truncated {
+				methods, tools := parseJSONRPCMethods(body.buf.Bytes())
+				if len(methods) > 0 {
+					event = event.Str("mcp_method", strings.Join(methods, ","))
+				}
+				if len(tools) > 0 {
+					event = event.Str("tool_name", strings.Join(tools, ","))
+				}
+			}
+
+			event.Msg("HTTP request")
+		})
+	}
+}
+
+type capturingBody struct {
+	io.ReadCloser
+	buf       bytes.Buffer
+	truncated bool
+}
+
+func (b *capturingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if !b.truncated {
+		if b.buf.Len()+n > requestLogBodyLimit {
+			b.truncated = true
+			b.buf.Reset()
+		} else {
+			b.buf.Write(p[:n])
+		}
+	}
+	return n, err
+}
+
+type jsonRPCMessage struct {
+	Method string `json:"method"`
+	Params struct {
+		Name string `json:"name"`
+	} `json:"params"`
+}
+
+// parseJSONRPCMethods returns the methods in a JSON-RPC message or batch, and
+// the tool names of any tools/call requests. Bodies that aren't JSON-RPC
+// return nothing.
+func parseJSONRPCMethods(body []byte) (methods, tools []string) {
+	body = bytes.TrimSpace(body)
+	if len(body) == 0 {
+		return nil, nil
+	}
+
+	var messages []jsonRPCMessage
+	if body[0] == '[' {
+		if err := json.Unmarshal(body, &messages); err != nil {
+			return nil, nil
+		}
+	} else {
+		var message jsonRPCMessage
+		if err := json.Unmarshal(body, &message); err != nil {
+			return nil, nil
+		}
+		messages = []jsonRPCMessage{message}
+	}
+
+	for _, message := range messages {
+		// Responses sent back by the client have no method.
+		if message.Method == "" {
+			continue
+		}
+		methods = append(methods, message.Method)
+		if message.Method == "tools/call" && message.Params.Name != "" {
+			tools = append(tools, message.Params.Name)
+		}
+	}
+	return methods, tools
+}
@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestLog(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		body       string
+		wantMethod string
+		wantTool   string
+	}{
+		{
+			name:       "tool call",
+			method:     http.MethodPost,
+			body:       `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"get_build","arguments":{"build_number":"42"}}}`,
+			wantMethod: "tools/call",
+			wantTool:   "get_build",
+		},
+		{
+			name:       "non-tool method",
+			method:     http.MethodPost,
+			body:       `{"jsonrpc":"2.0","id":1,"method":"tools/list"}`,
+			wantMethod: "tools/list",
+		},
+		{
+			name:       "batch",
+			method:     http.MethodPost,
+			body:       `[{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"get_build"}},{"jsonrpc":"2.0","method":"notifications/initialized"},{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"tail_logs"}},{"jsonrpc":"2.0","id":9,"result":{}}]`,
+			wantMethod: "tools/call,notifications/initialized,tools/call",
+			wantTool:   "get_build,tail_logs",
+		},
+		{
+			name:   "not JSON",
+			method: http.MethodPost,
+			body:   `hello`,
+		},
+		{
+			name:   "no body",
+			method: http.MethodGet,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := require.New(t)
+
+			var logs bytes.Buffer
+			handler := RequestLog(zerolog.New(&logs))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, err := io.ReadAll(r.Body)
+				assert.NoError(err)
+				// The handler still sees the whole body.
+				assert.Equal(tt.body, string(body))
+				w.WriteHeader(http.StatusAccepted)
+			}))
+
+			var body io.Reader
+			if tt.body != "" {
+				body = strings.NewReader(tt.body)
+			}
+			req := httptest.NewRequest(tt.method, "/mcp", body)
+			req.RemoteAddr = "192.0.2.1:4321"
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			var entry map[string]any
+			assert.NoError(json.Unmarshal(logs.Bytes(), &entry))
+			assert.Equal("HTTP request", entry["message"])
+			assert.Equal(tt.method, entry["method"])
+			assert.Equal("/mcp", entry["path"])
+			assert.EqualValues(http.StatusAccepted, entry["status"])
+			assert.Equal("192.0.2.1", entry["remote_ip"])
+			assert.Contains(entry, "duration")
+
+			if tt.wantMethod == "" {
+				assert.NotContains(entry, "mcp_method")
+			} else {
+				assert.Equal(tt.wantMethod, entry["mcp_method"])
+			}
+			if tt.wantTool == "" {
+				assert.NotContains(entry, "tool_name")
+			} else {
+				assert.Equal(tt.wantTool, entry["tool_name"])
+			}
+		})
+	}
+}
+
+func TestRequestLog_UnreadBody(t *testing.T) {
+	var logs bytes.Buffer
+	handler := RequestLog(zerolog.New(&logs))(MaxBodyBytes(8)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("oversized request reached the handler")
+	})))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(`{"method":"tools/list"}`)))
+	require.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	require.Contains(t, logs.String(), `"status":413`)
+	require.NotContains(t, logs.String(), "mcp_method")
+}
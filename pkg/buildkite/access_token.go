@@ -2,6 +2,7 @@ package buildkite
 
 import (
 	"context"
+	"slices"
 
 	"github.com/buildkite/buildkite-mcp-server/pkg/trace"
 	"github.com/buildkite/go-buildkite/v5"
@@ -17,7 +18,7 @@ type AccessTokenArgs struct{}
 func AccessToken() (mcp.Tool, mcp.ToolHandlerFor[AccessTokenArgs, any], []string) {
 	return mcp.Tool{
 			Name:        "access_token",
-			Description: "Get information about the current API access token including its scopes and UUID",
+			Description: "Get information about the current API access token including its UUID and the scopes it was granted. Check the scopes when a tool is denied access with a 403",
 			Annotations: &mcp.ToolAnnotations{
 				Title:        "Get Access Token",
 				ReadOnlyHint: true,
@@ -29,9 +30,18 @@ func AccessToken() (mcp.Tool, mcp.ToolHandlerFor[AccessTokenArgs, any], []string
 			deps := DepsFromContext(ctx)
 			token, _, err := deps.AccessTokensClient.Get(ctx)
 			if err != nil {
-				return handleBuildkiteError(err)
+				return handleBuildkiteError(ctx, err)
 			}
 
-			return mcpTextResult(span, &token)
+			token.Scopes = sortedScopes(token.Scopes)
+			return mcpTextResult(ctx, span, &token)
 		}, []string{"read_user"}
 }
+
+// sortedScopes returns a sorted copy of scopes. It is empty rather than nil,
+// so results always include the scopes array.
+func sortedScopes(scopes []string) []string {
+	sorted := append([]string{}, scopes...)
+	slices.Sort(sorted)
+	return sorted
+}
@@ -31,7 +31,7 @@ func TestAccessToken(t *testing.T) {
 		GetFunc: func(ctx context.Context) (buildkite.AccessToken, *buildkite.Response, error) {
 			return buildkite.AccessToken{
 					UUID:        "123",
-					Scopes:      []string{"read_build", "read_pipeline"},
+					Scopes:      []string{"read_pipeline", "read_build"},
 					Description: "Test token",
 					User: struct {
 						Name  string `json:"name"`
@@ -66,3 +66,12 @@ func TestAccessToken(t *testing.T) {
 
 	assert.JSONEq(`{"uuid":"123","scopes":["read_build","read_pipeline"],"description":"Test token","created_at":"2023-01-01T00:00:00Z","expires_at":null,"user":{"name":"Test User","email":"test@example.com"}}`, textContent.Text)
 }
+
+func TestAccessTokenWithoutScopes(t *testing.T) {
+	ctx := ContextWithDeps(context.Background(), ToolDependencies{AccessTokensClient: &MockAccessTokenClient{}})
+	_, handler, _ := AccessToken()
+
+	result, _, err := handler(ctx, createMCPRequest(t, map[string]any{}), AccessTokenArgs{})
+	require.NoError(t, err)
+	require.Contains(t, getTextResult(t, result).Text, `"scopes":[]`)
+}
@@ -16,13 +16,14 @@ type AgentsClient interface {
 }
 
 type ListAgentsArgs struct {
-	OrgSlug     string `json:"org_slug"`
-	Name        string `json:"name,omitempty"`
-	Hostname    string `json:"hostname,omitempty"`
-	Version     string `json:"version,omitempty"`
-	Page        int    `json:"page,omitempty" jsonschema:"Page number for pagination (min 1)"`
-	PerPage     int    `json:"per_page,omitempty" jsonschema:"Results per page for pagination (min 1, max 100)"`
-	DetailLevel string `json:"detail_level,omitempty" jsonschema:"Response detail level: 'summary' (default), 'detailed', or 'full'"`
+	OrgSlug           string `json:"org_slug"`
+	Name              string `json:"name,omitempty"`
+	Hostname          string `json:"hostname,omitempty"`
+	Version           string `json:"version,omitempty"`
+	Page              int    `json:"page,omitempty" jsonschema:"Page number for pagination (min 1)"`
+	PerPage           int    `json:"per_page,omitempty" jsonschema:"Results per page for pagination (min 1, max 100)"`
+	DetailLevel       string `json:"detail_level,omitempty" jsonschema:"Response detail level: 'summary' (default), 'detailed', or 'full'"`
+	IncludePagination bool   `json:"include_pagination,omitempty" jsonschema:"Add page, has_more and next_page to the result, so you can tell whether more pages exist"`
 }
 
 type GetAgentArgs struct {
@@ -121,15 +122,16 @@ func detailAgent(agent buildkite.Agent) AgentDetail {
 	}
 }
 
-func createPaginatedAgentResult[T any](agents []buildkite.Agent, converter func(buildkite.Agent) T, headers map[string]string) PaginatedResult[T] {
+func createPaginatedAgentResult[T any](agents []buildkite.Agent, converter func(buildkite.Agent) T, headers map[string]string, pagination *Pagination) PaginatedResult[T] {
 	items := make([]T, len(agents))
 	for i, agent := range agents {
 		items[i] = converter(agent)
 	}
 
 	return PaginatedResult[T]{
-		Items:   items,
-		Headers: headers,
+		Items:      items,
+		Headers:    headers,
+		Pagination: pagination,
 	}
 }
 
@@ -175,26 +177,27 @@ func ListAgents() (mcp.Tool, mcp.ToolHandlerFor[ListAgentsArgs, any], []string)
 				Version:     args.Version,
 			})
 			if err != nil {
-				return handleBuildkiteError(err)
+				return handleBuildkiteError(ctx, err)
 			}
 
 			headers := map[string]string{
 				"Link": resp.Header.Get("Link"),
 			}
+			pagination := paginationFor(args.IncludePagination, paginationParams.Page, resp)
 
 			var result any
 			switch args.DetailLevel {
 			case "summary":
-				result = createPaginatedAgentResult(agents, summarizeAgent, headers)
+				result = createPaginatedAgentResult(agents, summarizeAgent, headers, pagination)
 			case "detailed":
-				result = createPaginatedAgentResult(agents, detailAgent, headers)
+				result = createPaginatedAgentResult(agents, detailAgent, headers, pagination)
 			default: // full
-				result = createPaginatedAgentResult(agents, func(a buildkite.Agent) buildkite.Agent { return a }, headers)
+				result = createPaginatedAgentResult(agents, func(a buildkite.Agent) buildkite.Agent { return a }, headers, pagination)
 			}
 
 			span.SetAttributes(attribute.Int("item_count", len(agents)))
 
-			return mcpTextResult(span, &result)
+			return mcpTextResult(ctx, span, &result)
 		}, []string{"read_agents"}
 }
 
@@ -229,7 +232,7 @@ func GetAgent() (mcp.Tool, mcp.ToolHandlerFor[GetAgentArgs, any], []string) {
 			deps := DepsFromContext(ctx)
 			agent, _, err := deps.AgentsClient.Get(ctx, args.OrgSlug, args.AgentID)
 			if err != nil {
-				return handleBuildkiteError(err)
+				return handleBuildkiteError(ctx, err)
 			}
 
 			var result any
@@ -242,6 +245,6 @@ func GetAgent() (mcp.Tool, mcp.ToolHandlerFor[GetAgentArgs, any], []string) {
 				result = agent
 			}
 
-			return mcpTextResult(span, &result)
+			return mcpTextResult(ctx, span, &result)
 		}, []string{"read_agents"}
 }
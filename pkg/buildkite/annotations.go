@@ -3,6 +3,7 @@ package buildkite
 import (
 	"context"
 	"errors"
+	"net/http"
 
 	"github.com/buildkite/buildkite-mcp-server/pkg/trace"
 	"github.com/buildkite/buildkite-mcp-server/pkg/utils"
@@ -25,13 +26,14 @@ type AnnotationsClient interface {
 }
 
 type ListAnnotationsArgs struct {
-	OrgSlug      string `json:"org_slug"`
-	PipelineSlug string `json:"pipeline_slug"`
-	BuildNumber  string `json:"build_number"`
-	Scope        string `json:"scope,omitempty" jsonschema:"Annotation scope: 'build' (default) or 'job'. When 'job', job_id is required."`
-	JobID        string `json:"job_id,omitempty" jsonschema:"Job ID required when scope is job"`
-	Page         int    `json:"page,omitempty" jsonschema:"Page number for pagination (min 1)"`
-	PerPage      int    `json:"per_page,omitempty" jsonschema:"Results per page for pagination (min 1, max 100)"`
+	OrgSlug           string `json:"org_slug"`
+	PipelineSlug      string `json:"pipeline_slug"`
+	BuildNumber       string `json:"build_number"`
+	Scope             string `json:"scope,omitempty" jsonschema:"Annotation scope: 'build' (default) or 'job'. When 'job', job_id is required."`
+	JobID             string `json:"job_id,omitempty" jsonschema:"Job ID required when scope is job"`
+	Page              int    `json:"page,omitempty" jsonschema:"Page number for pagination (min 1)"`
+	PerPage           int    `json:"per_page,omitempty" jsonschema:"Results per page for pagination (min 1, max 100)"`
+	IncludePagination bool   `json:"include_pagination,omitempty" jsonschema:"Add page, has_more and next_page to the result, so you can tell whether more pages exist"`
 }
 
 type CreateAnnotationArgs struct {
@@ -45,6 +47,7 @@ type CreateAnnotationArgs struct {
 	Priority     int    `json:"priority,omitempty" jsonschema:"Optional annotation priority from 1 to 10"`
 	Context      string `json:"context,omitempty" jsonschema:"Optional annotation context used to identify or append to an annotation"`
 	Append       bool   `json:"append,omitempty" jsonschema:"Append the body to an existing annotation with the same context"`
+	DryRun       bool   `json:"dry_run,omitempty" jsonschema:"Validate the arguments and return the request this would make, without making it"`
 }
 
 func normalizeAnnotationScope(scope, jobID string) (string, error) {
@@ -109,7 +112,7 @@ func ListAnnotations() (mcp.Tool, mcp.ToolHandlerFor[ListAnnotationsArgs, any],
 				})
 			}
 			if err != nil {
-				return handleBuildkiteError(err)
+				return handleBuildkiteError(ctx, err)
 			}
 
 			result := PaginatedResult[buildkite.Annotation]{
@@ -117,13 +120,14 @@ func ListAnnotations() (mcp.Tool, mcp.ToolHandlerFor[ListAnnotationsArgs, any],
 				Headers: map[string]string{
 					"Link": resp.Header.Get("Link"),
 				},
+				Pagination: paginationFor(args.IncludePagination, paginationParams.Page, resp),
 			}
 
 			span.SetAttributes(
 				attribute.Int("item_count", len(annotations)),
 			)
 
-			return mcpTextResult(span, &result)
+			return mcpTextResult(ctx, span, &result)
 		}, []string{"read_builds"}
 }
 
@@ -165,6 +169,13 @@ func CreateAnnotation() (mcp.Tool, mcp.ToolHandlerFor[CreateAnnotationArgs, any]
 				Append:   args.Append,
 			}
 
+			if args.DryRun {
+				if scope == annotationScopeJob {
+					return mcpDryRunResult(ctx, span, "Create an annotation on a job", http.MethodPost, apiPath("v2/organizations/%s/pipelines/%s/builds/%s/jobs/%s/annotations", args.OrgSlug, args.PipelineSlug, args.BuildNumber, args.JobID), create)
+				}
+				return mcpDryRunResult(ctx, span, "Create an annotation on a build", http.MethodPost, apiPath("v2/organizations/%s/pipelines/%s/builds/%s/annotations", args.OrgSlug, args.PipelineSlug, args.BuildNumber), create)
+			}
+
 			deps := DepsFromContext(ctx)
 
 			var (
@@ -178,9 +189,9 @@ func CreateAnnotation() (mcp.Tool, mcp.ToolHandlerFor[CreateAnnotationArgs, any]
 				annotation, _, err = deps.AnnotationsClient.Create(ctx, args.OrgSlug, args.PipelineSlug, args.BuildNumber, create)
 			}
 			if err != nil {
-				return handleBuildkiteError(err)
+				return handleBuildkiteError(ctx, err)
 			}
 
-			return mcpTextResult(span, &annotation)
+			return mcpTextResult(ctx, span, &annotation)
 		}, []string{"write_builds"}
 }
@@ -14,6 +14,7 @@ import (
 
 	"github.com/buildkite/buildkite-mcp-server/pkg/tokens"
 	"github.com/buildkite/buildkite-mcp-server/pkg/trace"
+	"github.com/buildkite/buildkite-mcp-server/pkg/utils"
 	"github.com/buildkite/go-buildkite/v5"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"go.opentelemetry.io/otel/attribute"
@@ -233,20 +234,26 @@ func toArtifactListItems(artifacts []buildkite.Artifact) []artifactListItem {
 }
 
 type ListArtifactsForBuildArgs struct {
-	OrgSlug      string `json:"org_slug"`
-	PipelineSlug string `json:"pipeline_slug"`
-	BuildNumber  string `json:"build_number"`
-	Page         int    `json:"page,omitempty" jsonschema:"Page number for pagination (min 1)"`
-	PerPage      int    `json:"per_page,omitempty" jsonschema:"Results per page for pagination (min 1, max 100)"`
+	OrgSlug           string   `json:"org_slug"`
+	PipelineSlug      string   `json:"pipeline_slug"`
+	BuildNumber       string   `json:"build_number"`
+	Page              int      `json:"page,omitempty" jsonschema:"Page number for pagination (min 1)"`
+	PerPage           int      `json:"per_page,omitempty" jsonschema:"Results per page for pagination (min 1, max 100)"`
+	AutoPaginate      bool     `json:"auto_paginate,omitempty" jsonschema:"Also fetch the following pages, up to 10 in total, instead of only the requested page. truncated is set when more pages remain"`
+	IncludePagination bool     `json:"include_pagination,omitempty" jsonschema:"Add page, has_more and next_page to the result, so you can tell whether more pages exist"`
+	Fields            []string `json:"fields,omitempty" jsonschema:"Only return these keys of each item to save tokens, with dots for nested keys, e.g. number, state, creator.name"`
+	OutputFormat      string   `json:"output_format,omitempty" jsonschema:"Result format: 'json' (default), 'yaml', 'markdown' (a table), or 'ndjson' (one JSON object per item, a line each, then a _meta object of the other keys such as pagination)"`
+	TimeoutSeconds    int      `json:"timeout_seconds,omitempty" jsonschema:"Give up after this many seconds (default 300, max 1800)"`
 }
 
 type ListArtifactsForJobArgs struct {
-	OrgSlug      string `json:"org_slug"`
-	PipelineSlug string `json:"pipeline_slug"`
-	BuildNumber  string `json:"build_number"`
-	JobID        string `json:"job_id"`
-	Page         int    `json:"page,omitempty" jsonschema:"Page number for pagination (min 1)"`
-	PerPage      int    `json:"per_page,omitempty" jsonschema:"Results per page for pagination (min 1, max 100)"`
+	OrgSlug           string `json:"org_slug"`
+	PipelineSlug      string `json:"pipeline_slug"`
+	BuildNumber       string `json:"build_number"`
+	JobID             string `json:"job_id"`
+	Page              int    `json:"page,omitempty" jsonschema:"Page number for pagination (min 1)"`
+	PerPage           int    `json:"per_page,omitempty" jsonschema:"Results per page for pagination (min 1, max 100)"`
+	IncludePagination bool   `json:"include_pagination,omitempty" jsonschema:"Add page, has_more and next_page to the result, so you can tell whether more pages exist"`
 }
 
 type GetArtifactArgs struct {
@@ -270,6 +277,9 @@ func ListArtifactsForBuild() (mcp.Tool, mcp.ToolHandlerFor[ListArtifactsForBuild
 			ctx, span := trace.Start(ctx, "buildkite.ListArtifactsForBuild")
 			defer span.End()
 
+			ctx, cancel := withCallTimeout(ctx, args.TimeoutSeconds)
+			defer cancel()
+
 			paginationParams := paginationFromArgs(args.Page, args.PerPage)
 
 			span.SetAttributes(
@@ -278,14 +288,27 @@ func ListArtifactsForBuild() (mcp.Tool, mcp.ToolHandlerFor[ListArtifactsForBuild
 				attribute.String("build_number", args.BuildNumber),
 				attribute.Int("page", paginationParams.Page),
 				attribute.Int("per_page", paginationParams.PerPage),
+				attribute.Bool("auto_paginate", args.AutoPaginate),
 			)
 
+			if err := requireParams(map[string]string{
+				"org_slug":      args.OrgSlug,
+				"pipeline_slug": args.PipelineSlug,
+				"build_number":  args.BuildNumber,
+			}); err != nil {
+				return utils.NewToolResultError(err.Error()), nil, nil
+			}
+
 			deps := DepsFromContext(ctx)
-			artifacts, resp, err := deps.ArtifactsClient.ListByBuild(ctx, args.OrgSlug, args.PipelineSlug, args.BuildNumber, &buildkite.ArtifactListOptions{
-				ListOptions: paginationParams,
+			artifacts, resp, truncated, err := listPages(paginationParams.Page, args.AutoPaginate, func(page int) ([]buildkite.Artifact, *buildkite.Response, error) {
+				listOptions := paginationParams
+				listOptions.Page = page
+				return deps.ArtifactsClient.ListByBuild(ctx, args.OrgSlug, args.PipelineSlug, args.BuildNumber, &buildkite.ArtifactListOptions{
+					ListOptions: listOptions,
+				})
 			})
 			if err != nil {
-				return handleBuildkiteError(err)
+				return handleBuildkiteError(ctx, err)
 			}
 
 			result := PaginatedResult[artifactListItem]{
@@ -293,13 +316,16 @@ func ListArtifactsForBuild() (mcp.Tool, mcp.ToolHandlerFor[ListArtifactsForBuild
 				Headers: map[string]string{
 					"Link": resp.Header.Get("Link"),
 				},
+				Truncated:  truncated,
+				Pagination: paginationFor(args.IncludePagination, paginationParams.Page, resp),
 			}
 
 			span.SetAttributes(
 				attribute.Int("item_count", len(artifacts)),
+				attribute.Bool("truncated", truncated),
 			)
 
-			return mcpTextResult(span, &result)
+			return mcpListResult(ctx, span, result, args.Fields, args.OutputFormat, artifactColumns)
 		}, []string{"read_artifacts"}
 }
 
@@ -327,12 +353,21 @@ func ListArtifactsForJob() (mcp.Tool, mcp.ToolHandlerFor[ListArtifactsForJobArgs
 				attribute.Int("per_page", paginationParams.PerPage),
 			)
 
+			if err := requireParams(map[string]string{
+				"org_slug":      args.OrgSlug,
+				"pipeline_slug": args.PipelineSlug,
+				"build_number":  args.BuildNumber,
+				"job_id":        args.JobID,
+			}); err != nil {
+				return utils.NewToolResultError(err.Error()), nil, nil
+			}
+
 			deps := DepsFromContext(ctx)
 			artifacts, resp, err := deps.ArtifactsClient.ListByJob(ctx, args.OrgSlug, args.PipelineSlug, args.BuildNumber, args.JobID, &buildkite.ArtifactListOptions{
 				ListOptions: paginationParams,
 			})
 			if err != nil {
-				return handleBuildkiteError(err)
+				return handleBuildkiteError(ctx, err)
 			}
 
 			result := PaginatedResult[artifactListItem]{
@@ -340,6 +375,7 @@ func ListArtifactsForJob() (mcp.Tool, mcp.ToolHandlerFor[ListArtifactsForJobArgs
 				Headers: map[string]string{
 					"Link": resp.Header.Get("Link"),
 				},
+				Pagination: paginationFor(args.IncludePagination, paginationParams.Page, resp),
 			}
 
 			span.SetAttributes(
@@ -347,7 +383,7 @@ func ListArtifactsForJob() (mcp.Tool, mcp.ToolHandlerFor[ListArtifactsForJobArgs
 				attribute.Int("estimated_tokens", tokens.EstimateTokens(fmt.Sprintf("%v", result))),
 			)
 
-			return mcpTextResult(span, &result)
+			return mcpTextResult(ctx, span, &result)
 		}, []string{"read_artifacts"}
 }
 
@@ -372,10 +408,20 @@ func GetArtifact() (mcp.Tool, mcp.ToolHandlerFor[GetArtifactArgs, any], []string
 				attribute.String("artifact_id", args.ArtifactID),
 			)
 
+			if err := requireParams(map[string]string{
+				"org_slug":      args.OrgSlug,
+				"pipeline_slug": args.PipelineSlug,
+				"build_number":  args.BuildNumber,
+				"job_id":        args.JobID,
+				"artifact_id":   args.ArtifactID,
+			}); err != nil {
+				return utils.NewToolResultError(err.Error()), nil, nil
+			}
+
 			deps := DepsFromContext(ctx)
 			artifact, _, err := deps.ArtifactsClient.GetByJob(ctx, args.OrgSlug, args.PipelineSlug, args.BuildNumber, args.JobID, args.ArtifactID)
 			if err != nil {
-				return handleBuildkiteError(err)
+				return handleBuildkiteError(ctx, err)
 			}
 
 			span.SetAttributes(
@@ -395,25 +441,25 @@ func GetArtifact() (mcp.Tool, mcp.ToolHandlerFor[GetArtifactArgs, any], []string
 				writer := &inlineLimitWriter{limit: textArtifactInlineLimit}
 				_, err := deps.ArtifactsClient.DownloadArtifact(ctx, args.OrgSlug, args.PipelineSlug, args.BuildNumber, args.JobID, args.ArtifactID, writer)
 				if err != nil {
-					return handleBuildkiteError(err)
+					return handleBuildkiteError(ctx, err)
 				}
 
 				switch {
 				case writer.overflow:
 					result := urlArtifactResult(" because it was larger than expected", artifact, downloadURL, downloadURLAuth, expiresInSeconds)
-					return mcpTextResult(span, &result)
+					return mcpTextResult(ctx, span, &result)
 				case !utf8.Valid(writer.buf.Bytes()):
 					result := urlArtifactResult(" because it was not valid UTF-8", artifact, downloadURL, downloadURLAuth, expiresInSeconds)
-					return mcpTextResult(span, &result)
+					return mcpTextResult(ctx, span, &result)
 				}
 
 				result := artifactResult("text", artifact, downloadURL, downloadURLAuth, expiresInSeconds)
 				result["content"] = writer.buf.String()
-				return mcpTextResult(span, &result)
+				return mcpTextResult(ctx, span, &result)
 			}
 
 			result := urlArtifactResult("", artifact, downloadURL, downloadURLAuth, expiresInSeconds)
-			return mcpTextResult(span, &result)
+			return mcpTextResult(ctx, span, &result)
 		}, []string{"read_artifacts"}
 }
 
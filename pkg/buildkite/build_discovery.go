@@ -0,0 +1,239 @@
+package buildkite
+
+import (
+	"context"
+
+	"github.com/buildkite/buildkite-mcp-server/pkg/trace"
+	"github.com/buildkite/buildkite-mcp-server/pkg/utils"
+	"github.com/buildkite/go-buildkite/v5"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type ListBlockedBuildsArgs struct {
+	OrgSlug      string `json:"org_slug"`
+	PipelineSlug string `json:"pipeline_slug,omitempty" jsonschema:"Only list blocked builds of this pipeline. When omitted, lists them across the organization"`
+	Page         int    `json:"page,omitempty" jsonschema:"Page number for pagination (min 1)"`
+	PerPage      int    `json:"per_page,omitempty" jsonschema:"Results per page for pagination (min 1, max 100)"`
+}
+
+// BlockedJob is a block step waiting to be unblocked.
+type BlockedJob struct {
+	JobID string `json:"job_id"`
+	Label string `json:"label"`
+}
+
+// BlockedBuild has the arguments unblock_job needs for each of its blocked
+// jobs.
+type BlockedBuild struct {
+	PipelineSlug string       `json:"pipeline_slug"`
+	BuildNumber  int          `json:"build_number"`
+	Branch       string       `json:"branch"`
+	Message      string       `json:"message"`
+	WebURL       string       `json:"web_url"`
+	BlockedJobs  []BlockedJob `json:"blocked_jobs"`
+}
+
+// listOrgOrPipelineBuilds lists the builds of a pipeline, or of every
+// pipeline in the organization when pipelineSlug is empty. Builds listed
+// across the organization include their pipeline, to tell which it is.
+func listOrgOrPipelineBuilds(ctx context.Context, client BuildsClient, org, pipelineSlug string, options *buildkite.BuildsListOptions) ([]buildkite.Build, *buildkite.Response, error) {
+	if pipelineSlug != "" {
+		options.ExcludePipeline = true
+		return client.ListByPipeline(ctx, org, pipelineSlug, options)
+	}
+	return client.ListByOrg(ctx, org, options)
+}
+
+// buildPipelineSlug returns the slug of the pipeline a build belongs to,
+// falling back to pipelineSlug when the build was listed without it.
+func buildPipelineSlug(build buildkite.Build, pipelineSlug string) string {
+	if build.Pipeline != nil {
+		return build.Pipeline.Slug
+	}
+	return pipelineSlug
+}
+
+// blockedJobs returns the block steps of a build that can be unblocked.
+func blockedJobs(build buildkite.Build) []BlockedJob {
+	var jobs []BlockedJob
+	for _, job := range build.Jobs {
+		if job.Type == "manual" && job.State == "blocked" {
+			label := job.Label
+			if label == "" {
+				label = job.Name
+			}
+			jobs = append(jobs, BlockedJob{JobID: job.ID, Label: label})
+		}
+	}
+	return jobs
+}
+
+func ListBlockedBuilds() (mcp.Tool, mcp.ToolHandlerFor[ListBlockedBuildsArgs, any], []string) {
+	return mcp.Tool{
+			Name:        "list_blocked_builds",
+			Description: "List builds waiting on a block step to be unblocked, for one pipeline or across an organization. Each build lists its blocked jobs with the job_id to pass to unblock_job; unblock_all_jobs unblocks every one of a build at once",
+			Annotations: &mcp.ToolAnnotations{
+				Title:        "List Blocked Builds",
+				ReadOnlyHint: true,
+			},
+		},
+		func(ctx context.Context, request *mcp.CallToolRequest, args ListBlockedBuildsArgs) (*mcp.CallToolResult, any, error) {
+			ctx, span := trace.Start(ctx, "buildkite.ListBlockedBuilds")
+			defer span.End()
+
+			paginationParams := paginationFromArgs(args.Page, args.PerPage)
+
+			span.SetAttributes(
+				attribute.String("org_slug", args.OrgSlug),
+				attribute.String("pipeline_slug", args.PipelineSlug),
+				attribute.Int("page", paginationParams.Page),
+				attribute.Int("per_page", paginationParams.PerPage),
+			)
+
+			if err := requireParams(map[string]string{"org_slug": args.OrgSlug}); err != nil {
+				return utils.NewToolResultError(err.Error()), nil, nil
+			}
+
+			// Jobs are needed to find the block steps.
+			options := &buildkite.BuildsListOptions{
+				State:       []string{"blocked"},
+				ListOptions: paginationParams,
+			}
+
+			deps := DepsFromContext(ctx)
+			builds, resp, err := listOrgOrPipelineBuilds(ctx, deps.BuildsClient, args.OrgSlug, args.PipelineSlug, options)
+			if err != nil {
+				return handleBuildkiteError(ctx, err)
+			}
+
+			blocked := []BlockedBuild{}
+			for _, build := range builds {
+				jobs := blockedJobs(build)
+				if len(jobs) == 0 {
+					continue
+				}
+				blocked = append(blocked, BlockedBuild{
+					PipelineSlug: buildPipelineSlug(build, args.PipelineSlug),
+					BuildNumber:  build.Number,
+					Branch:       build.Branch,
+					Message:      build.Message,
+					WebURL:       build.WebURL,
+					BlockedJobs:  jobs,
+				})
+			}
+
+			span.SetAttributes(attribute.Int("item_count", len(blocked)))
+
+			result := PaginatedResult[BlockedBuild]{
+				Items: blocked,
+				Headers: map[string]string{
+					"Link": resp.Header.Get("Link"),
+				},
+			}
+			return mcpTextResult(ctx, span, &result)
+		}, []string{"read_builds"}
+}
+
+type ListRunningBuildsArgs struct {
+	OrgSlug      string `json:"org_slug"`
+	PipelineSlug string `json:"pipeline_slug,omitempty" jsonschema:"Only list running builds of this pipeline. When omitted, lists them across the organization"`
+	Page         int    `json:"page,omitempty" jsonschema:"Page number for pagination (min 1)"`
+	PerPage      int    `json:"per_page,omitempty" jsonschema:"Results per page for pagination (min 1, max 100)"`
+}
+
+// RunningJob is a job of a running build that is running now.
+type RunningJob struct {
+	JobID     string               `json:"job_id"`
+	Label     string               `json:"label"`
+	StartedAt *buildkite.Timestamp `json:"started_at,omitempty"`
+}
+
+type RunningBuild struct {
+	PipelineSlug string               `json:"pipeline_slug"`
+	BuildNumber  int                  `json:"build_number"`
+	State        string               `json:"state"`
+	Branch       string               `json:"branch"`
+	Message      string               `json:"message"`
+	StartedAt    *buildkite.Timestamp `json:"started_at,omitempty"`
+	WebURL       string               `json:"web_url"`
+	RunningJobs  []RunningJob         `json:"running_jobs"`
+}
+
+func runningJobs(build buildkite.Build) []RunningJob {
+	jobs := []RunningJob{}
+	for _, job := range build.Jobs {
+		if job.State == "running" {
+			label := job.Label
+			if label == "" {
+				label = job.Name
+			}
+			jobs = append(jobs, RunningJob{JobID: job.ID, Label: label, StartedAt: job.StartedAt})
+		}
+	}
+	return jobs
+}
+
+func ListRunningBuilds() (mcp.Tool, mcp.ToolHandlerFor[ListRunningBuildsArgs, any], []string) {
+	return mcp.Tool{
+			Name:        "list_running_builds",
+			Description: "List the builds running or scheduled right now, for one pipeline or across an organization, with when each started and the jobs it is running",
+			Annotations: &mcp.ToolAnnotations{
+				Title:        "List Running Builds",
+				ReadOnlyHint: true,
+			},
+		},
+		func(ctx context.Context, request *mcp.CallToolRequest, args ListRunningBuildsArgs) (*mcp.CallToolResult, any, error) {
+			ctx, span := trace.Start(ctx, "buildkite.ListRunningBuilds")
+			defer span.End()
+
+			paginationParams := paginationFromArgs(args.Page, args.PerPage)
+
+			span.SetAttributes(
+				attribute.String("org_slug", args.OrgSlug),
+				attribute.String("pipeline_slug", args.PipelineSlug),
+				attribute.Int("page", paginationParams.Page),
+				attribute.Int("per_page", paginationParams.PerPage),
+			)
+
+			if err := requireParams(map[string]string{"org_slug": args.OrgSlug}); err != nil {
+				return utils.NewToolResultError(err.Error()), nil, nil
+			}
+
+			// Jobs are needed to find the running ones.
+			options := &buildkite.BuildsListOptions{
+				State:       []string{"running", "scheduled"},
+				ListOptions: paginationParams,
+			}
+
+			deps := DepsFromContext(ctx)
+			builds, resp, err := listOrgOrPipelineBuilds(ctx, deps.BuildsClient, args.OrgSlug, args.PipelineSlug, options)
+			if err != nil {
+				return handleBuildkiteError(ctx, err)
+			}
+
+			running := make([]RunningBuild, len(builds))
+			for i, build := range builds {
+				running[i] = RunningBuild{
+					PipelineSlug: buildPipelineSlug(build, args.PipelineSlug),
+					BuildNumber:  build.Number,
+					State:        build.State,
+					Branch:       build.Branch,
+					Message:      build.Message,
+					StartedAt:    build.StartedAt,
+					WebURL:       build.WebURL,
+					RunningJobs:  runningJobs(build),
+				}
+			}
+
+			span.SetAttributes(attribute.Int("item_count", len(running)))
+
+			result := PaginatedResult[RunningBuild]{
+				Items: running,
+				Headers: map[string]string{
+					"Link": resp.Header.Get("Link"),
+				},
+			}
+			return mcpTextResult(ctx, span, &result)
+		}, []string{"read_builds"}
+}
@@ -0,0 +1,108 @@
+package buildkite
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/buildkite/go-buildkite/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListBlockedBuilds(t *testing.T) {
+	assert := require.New(t)
+
+	client := &MockBuildsClient{
+		ListByOrgFunc: func(ctx context.Context, org string, opt *buildkite.BuildsListOptions) ([]buildkite.Build, *buildkite.Response, error) {
+			assert.Equal("org", org)
+			assert.Equal([]string{"blocked"}, opt.State)
+			assert.False(opt.ExcludeJobs)
+			assert.False(opt.ExcludePipeline)
+			return []buildkite.Build{
+					{
+						Number:   12,
+						Branch:   "main",
+						Pipeline: &buildkite.Pipeline{Slug: "deploy"},
+						Jobs: []buildkite.Job{
+							{ID: "job-1", Type: "script", State: "passed"},
+							{ID: "job-2", Type: "manual", Label: ":rocket: Release", State: "blocked"},
+							{ID: "job-3", Type: "manual", Label: "Approved", State: "unblocked"},
+						},
+					},
+					{
+						Number:   13,
+						Pipeline: &buildkite.Pipeline{Slug: "deploy"},
+						Jobs:     []buildkite.Job{{ID: "job-4", Type: "script", State: "running"}},
+					},
+				}, &buildkite.Response{
+					Response: &http.Response{StatusCode: 200},
+				}, nil
+		},
+	}
+
+	ctx := ContextWithDeps(context.Background(), ToolDependencies{BuildsClient: client})
+	_, handler, _ := ListBlockedBuilds()
+
+	result, _, err := handler(ctx, createMCPRequest(t, map[string]any{}), ListBlockedBuildsArgs{OrgSlug: "org"})
+	assert.NoError(err)
+	assert.JSONEq(`{
+		"headers": {"Link": ""},
+		"items": [{
+			"pipeline_slug": "deploy",
+			"build_number": 12,
+			"branch": "main",
+			"message": "",
+			"web_url": "",
+			"blocked_jobs": [{"job_id": "job-2", "label": ":rocket: Release"}]
+		}]
+	}`, getTextResult(t, result).Text)
+}
+
+func TestListRunningBuilds(t *testing.T) {
+	assert := require.New(t)
+
+	client := &MockBuildsClient{
+		ListByPipelineFunc: func(ctx context.Context, org string, pipeline string, opt *buildkite.BuildsListOptions) ([]buildkite.Build, *buildkite.Response, error) {
+			assert.Equal("app", pipeline)
+			assert.Equal([]string{"running", "scheduled"}, opt.State)
+			assert.True(opt.ExcludePipeline)
+			return []buildkite.Build{
+					{
+						Number:    40,
+						State:     "running",
+						StartedAt: testTimestamp(0),
+						Jobs: []buildkite.Job{
+							{ID: "job-1", Label: "Lint", State: "passed"},
+							{ID: "job-2", Label: "Test", State: "running", StartedAt: testTimestamp(0)},
+						},
+					},
+					{Number: 41, State: "scheduled"},
+				}, &buildkite.Response{
+					Response: &http.Response{StatusCode: 200},
+				}, nil
+		},
+	}
+
+	ctx := ContextWithDeps(context.Background(), ToolDependencies{BuildsClient: client})
+	_, handler, _ := ListRunningBuilds()
+
+	result, _, err := handler(ctx, createMCPRequest(t, map[string]any{}), ListRunningBuildsArgs{
+		OrgSlug:      "org",
+		PipelineSlug: "app",
+	})
+	assert.NoError(err)
+	assert.JSONEq(`{
+		"headers": {"Link": ""},
+		"items": [
+			{
+				"pipeline_slug": "app", "build_number": 40, "state": "running", "branch": "", "message": "",
+				"started_at": "2026-01-01T00:00:00Z", "web_url": "",
+				"running_jobs": [{"job_id": "job-2", "label": "Test", "started_at": "2026-01-01T00:00:00Z"}]
+			},
+			{
+				"pipeline_slug": "app", "build_number": 41, "state": "scheduled", "branch": "", "message": "",
+				"web_url": "", "running_jobs": []
+			}
+		]
+	}`, getTextResult(t, result).Text)
+}
@@ -0,0 +1,178 @@
+package buildkite
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/buildkite/buildkite-mcp-server/pkg/trace"
+	"github.com/buildkite/go-buildkite/v5"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.opentelemetry.io/otel/attribute"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	// buildFailedLogsMaxJobs bounds how many failed jobs get_build_failed_logs
+	// fetches logs for.
+	buildFailedLogsMaxJobs = 20
+	// buildFailedLogsByteLimit is shared evenly between the returned jobs, so
+	// a build with many failures gets shorter tails rather than a huge payload.
+	buildFailedLogsByteLimit = 128 * 1024
+)
+
+type GetBuildFailedLogsArgs struct {
+	OrgSlug        string `json:"org_slug"`
+	PipelineSlug   string `json:"pipeline_slug"`
+	BuildNumber    string `json:"build_number"`
+	TimeoutSeconds int    `json:"timeout_seconds,omitempty" jsonschema:"Give up after this many seconds (default 300, max 1800)"`
+}
+
+// FailedJobLog is the cleaned log tail of one failed job.
+type FailedJobLog struct {
+	JobID string `json:"job_id"`
+	Label string `json:"label,omitempty"`
+	State string `json:"state"`
+	JobLogResult
+	Error string `json:"error,omitempty"`
+}
+
+type BuildFailedLogsResult struct {
+	Logs          []FailedJobLog `json:"logs"`
+	JobsTruncated bool           `json:"jobs_truncated,omitempty"`
+}
+
+// failedJobLabel names a job, falling back to its label when it has no name.
+func failedJobLabel(job buildkite.Job) string {
+	if job.Name != "" {
+		return job.Name
+	}
+	return job.Label
+}
+
+// loadFailedJobLogs fetches and cleans the logs of jobs, at most concurrency
+// at a time, returning them in the same order as jobs. A failure to read one
+// job's log is reported on that job; an unauthorized response or a cancelled
+// context stops the outstanding fetches and fails the whole call.
+func loadFailedJobLogs(ctx context.Context, client JobsClient, args GetBuildFailedLogsArgs, jobs []buildkite.Job, maxLogBytes int64, concurrency int) ([]FailedJobLog, error) {
+	logs := make([]FailedJobLog, len(jobs))
+	perJobLimit := buildFailedLogsByteLimit / max(len(jobs), 1)
+	group, groupCtx := errgroup.WithContext(ctx)
+	group.SetLimit(max(concurrency, 1))
+
+	for i := range jobs {
+		logs[i] = FailedJobLog{JobID: jobs[i].ID, Label: failedJobLabel(jobs[i]), State: jobs[i].State}
+
+		group.Go(func() error {
+			if err := groupCtx.Err(); err != nil {
+				return err
+			}
+
+			if size := jobLogSize(groupCtx, client, args.OrgSlug, args.PipelineSlug, args.BuildNumber, jobs[i].ID, maxLogBytes); size > maxLogBytes {
+				logs[i].Error = jobLogTooLarge(size, maxLogBytes)
+				return nil
+			}
+
+			jobLog, _, err := client.GetJobLog(groupCtx, args.OrgSlug, args.PipelineSlug, args.BuildNumber, jobs[i].ID)
+			if err != nil {
+				if isBuildkiteUnauthorized(err) {
+					return ErrUnauthorized
+				}
+				if ctxErr := groupCtx.Err(); ctxErr != nil {
+					return ctxErr
+				}
+				logs[i].Error = err.Error()
+				return nil
+			}
+
+			size := jobLog.Size
+			if size == 0 {
+				size = len(jobLog.Content)
+			}
+			if maxLogBytes > 0 && int64(size) > maxLogBytes {
+				logs[i].Error = jobLogTooLarge(int64(size), maxLogBytes)
+				return nil
+			}
+
+			result, err := cleanJobLog(jobLog.Content, perJobLimit)
+			if err != nil {
+				logs[i].Error = fmt.Sprintf("failed to parse job log: %v", err)
+				return nil
+			}
+			if result.Truncated {
+				result.SizeBytes = size
+			}
+			logs[i].JobLogResult = result
+			return nil
+		})
+	}
+
+	if err := group.Wait(); err != nil {
+		return nil, err
+	}
+	return logs, nil
+}
+
+func GetBuildFailedLogs() (mcp.Tool, mcp.ToolHandlerFor[GetBuildFailedLogsArgs, any], []string) {
+	return mcp.Tool{
+			Name:        "get_build_failed_logs",
+			Description: "Get the cleaned log tail of every failed job in a build in one call, in job order with each job's ID and label. Up to 20 jobs share a 128KB budget, so each log is cut to its last lines; 'truncated', 'omitted_lines' and 'size_bytes' report what was dropped. For a broader diagnosis including annotations and tests, use get_build_failure_summary",
+			Annotations: &mcp.ToolAnnotations{
+				Title:        "Get Build Failed Logs",
+				ReadOnlyHint: true,
+			},
+		},
+		func(ctx context.Context, request *mcp.CallToolRequest, args GetBuildFailedLogsArgs) (*mcp.CallToolResult, any, error) {
+			ctx, span := trace.Start(ctx, "buildkite.GetBuildFailedLogs")
+			defer span.End()
+
+			ctx, cancel := withCallTimeout(ctx, args.TimeoutSeconds)
+			defer cancel()
+
+			span.SetAttributes(
+				attribute.String("org_slug", args.OrgSlug),
+				attribute.String("pipeline_slug", args.PipelineSlug),
+				attribute.String("build_number", args.BuildNumber),
+			)
+
+			deps := DepsFromContext(ctx)
+			includeRetriedJobs := false
+			jobsList, _, err := deps.JobsClient.ListByBuild(ctx, args.OrgSlug, args.PipelineSlug, args.BuildNumber, &buildkite.JobsListOptions{
+				State:              []string{"failed", "timed_out"},
+				IncludeRetriedJobs: &includeRetriedJobs,
+				PerPage:            buildFailedLogsMaxJobs + 1,
+			})
+			if err != nil {
+				return handleBuildkiteError(ctx, err)
+			}
+
+			jobs := make([]buildkite.Job, 0, buildFailedLogsMaxJobs)
+			jobsTruncated := jobsList.Links.Next != ""
+			for _, job := range jobsList.Items {
+				if job.Type != "script" {
+					continue
+				}
+				if len(jobs) < buildFailedLogsMaxJobs {
+					jobs = append(jobs, job)
+				} else {
+					jobsTruncated = true
+				}
+			}
+
+			logs, err := loadFailedJobLogs(ctx, deps.JobsClient, args, jobs, deps.MaxLogBytes, deps.logFetchConcurrency())
+			if err != nil {
+				return handleBuildkiteError(ctx, err)
+			}
+
+			result := BuildFailedLogsResult{
+				Logs:          logs,
+				JobsTruncated: jobsTruncated,
+			}
+
+			span.SetAttributes(
+				attribute.Int("item_count", len(result.Logs)),
+				attribute.Bool("jobs_truncated", jobsTruncated),
+			)
+
+			return mcpTextResult(ctx, span, &result)
+		}, []string{"read_builds", "read_build_logs"}
+}
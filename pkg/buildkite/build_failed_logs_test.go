@@ -0,0 +1,210 @@
+package buildkite
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/buildkite/go-buildkite/v5"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetBuildFailedLogs(t *testing.T) {
+	assert := require.New(t)
+
+	mockJobs := &MockJobsClient{
+		ListByBuildFunc: func(ctx context.Context, org string, pipeline string, buildNumber string, opt *buildkite.JobsListOptions) (buildkite.JobsList, *buildkite.Response, error) {
+			assert.Equal([]string{"failed", "timed_out"}, opt.State)
+			return buildkite.JobsList{Items: []buildkite.Job{
+				{ID: "job-1", Type: "script", Name: "rspec", State: "failed"},
+				{ID: "job-2", Type: "script", Name: "rspec", State: "failed"},
+				{ID: "job-3", Type: "script", Name: "lint", State: "timed_out"},
+				{ID: "job-4", Type: "manual", State: "failed"},
+			}}, &buildkite.Response{}, nil
+		},
+		GetJobLogFunc: func(ctx context.Context, org string, pipeline string, buildNumber string, jobID string) (buildkite.JobLog, *buildkite.Response, error) {
+			switch jobID {
+			case "job-1":
+				return buildkite.JobLog{Content: "\x1b[31mFAIL\x1b[0m spec/a_spec.rb\n"}, &buildkite.Response{}, nil
+			case "job-2":
+				return buildkite.JobLog{Content: "FAIL spec/b_spec.rb\n"}, &buildkite.Response{}, nil
+			default:
+				return buildkite.JobLog{}, nil, errors.New("log not found")
+			}
+		},
+	}
+
+	ctx := ContextWithDeps(context.Background(), ToolDependencies{JobsClient: mockJobs})
+	tool, handler, _ := GetBuildFailedLogs()
+	assert.Equal("get_build_failed_logs", tool.Name)
+
+	result, _, err := handler(ctx, createMCPRequest(t, map[string]any{}), GetBuildFailedLogsArgs{
+		OrgSlug:      "test-org",
+		PipelineSlug: "test-pipeline",
+		BuildNumber:  "123",
+	})
+	assert.NoError(err)
+	assert.False(result.IsError)
+
+	var got BuildFailedLogsResult
+	assert.NoError(json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &got))
+	assert.Len(got.Logs, 3)
+	assert.Equal([]string{"job-1", "job-2", "job-3"}, []string{got.Logs[0].JobID, got.Logs[1].JobID, got.Logs[2].JobID})
+	assert.Equal("rspec", got.Logs[0].Label)
+	assert.Equal("FAIL spec/a_spec.rb", got.Logs[0].Content)
+	assert.Equal("rspec", got.Logs[1].Label)
+	assert.Equal("FAIL spec/b_spec.rb", got.Logs[1].Content)
+	assert.Equal("lint", got.Logs[2].Label)
+	assert.Equal("timed_out", got.Logs[2].State)
+	assert.Contains(got.Logs[2].Error, "log not found")
+}
+
+func TestGetBuildFailedLogs_SharesByteBudget(t *testing.T) {
+	assert := require.New(t)
+
+	jobs := make([]buildkite.Job, 4)
+	for i := range jobs {
+		jobs[i] = buildkite.Job{ID: string(rune('a' + i)), Type: "script", State: "failed"}
+	}
+	line := make([]byte, 1023)
+	for i := range line {
+		line[i] = 'x'
+	}
+	var content []byte
+	for range 100 {
+		content = append(append(content, line...), '\n')
+	}
+
+	mockJobs := &MockJobsClient{
+		GetJobLogFunc: func(ctx context.Context, org string, pipeline string, buildNumber string, jobID string) (buildkite.JobLog, *buildkite.Response, error) {
+			return buildkite.JobLog{Content: string(content)}, &buildkite.Response{}, nil
+		},
+	}
+
+	logs, err := loadFailedJobLogs(context.Background(), mockJobs, GetBuildFailedLogsArgs{}, jobs, 0, failureSummaryConcurrency)
+	assert.NoError(err)
+
+	total := 0
+	for _, log := range logs {
+		assert.True(log.Truncated)
+		assert.Equal(len(content), log.SizeBytes)
+		total += len(log.Content)
+	}
+	assert.LessOrEqual(total, buildFailedLogsByteLimit)
+}
+
+func TestLoadFailedJobLogs_SizeLimitBeforeDownload(t *testing.T) {
+	assert := require.New(t)
+
+	jobs := []buildkite.Job{{ID: "small", State: "failed"}, {ID: "large", State: "failed"}}
+	mockJobs := &MockJobsClient{
+		JobLogExistsFunc: func(ctx context.Context, org string, pipeline string, buildNumber string, jobID string) (bool, *buildkite.Response, error) {
+			size := int64(10)
+			if jobID == "large" {
+				size = 4096
+			}
+			return true, &buildkite.Response{Response: &http.Response{StatusCode: http.StatusOK, ContentLength: size}}, nil
+		},
+		GetJobLogFunc: func(ctx context.Context, org string, pipeline string, buildNumber string, jobID string) (buildkite.JobLog, *buildkite.Response, error) {
+			if jobID == "large" {
+				t.Error("the large log shouldn't be downloaded")
+			}
+			return buildkite.JobLog{Content: "oops\n"}, &buildkite.Response{}, nil
+		},
+	}
+
+	logs, err := loadFailedJobLogs(context.Background(), mockJobs, GetBuildFailedLogsArgs{}, jobs, 1024, 1)
+	assert.NoError(err)
+	assert.Equal("oops", logs[0].Content)
+	assert.Equal("job log is 4096 bytes, over the server's 1024 byte BKLOG_MAX_LOG_BYTES limit; the limit must be raised to read this log", logs[1].Error)
+}
+
+func TestLoadFailedJobLogs_Concurrency(t *testing.T) {
+	assert := require.New(t)
+
+	jobs := make([]buildkite.Job, 8)
+	for i := range jobs {
+		jobs[i] = buildkite.Job{ID: fmt.Sprintf("job-%d", i), Type: "script", State: "failed"}
+	}
+
+	var running, peak atomic.Int32
+	mockJobs := &MockJobsClient{
+		GetJobLogFunc: func(ctx context.Context, org string, pipeline string, buildNumber string, jobID string) (buildkite.JobLog, *buildkite.Response, error) {
+			n := running.Add(1)
+			defer running.Add(-1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			return buildkite.JobLog{Content: "log of " + jobID + "\n"}, &buildkite.Response{}, nil
+		},
+	}
+
+	logs, err := loadFailedJobLogs(context.Background(), mockJobs, GetBuildFailedLogsArgs{}, jobs, 0, 3)
+	assert.NoError(err)
+	assert.LessOrEqual(peak.Load(), int32(3))
+	for i, log := range logs {
+		assert.Equal(jobs[i].ID, log.JobID)
+		assert.Equal("log of "+jobs[i].ID, log.Content)
+	}
+}
+
+func TestLoadFailedJobLogs_StopsOnCancel(t *testing.T) {
+	assert := require.New(t)
+
+	jobs := make([]buildkite.Job, 8)
+	for i := range jobs {
+		jobs[i] = buildkite.Job{ID: fmt.Sprintf("job-%d", i), Type: "script", State: "failed"}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var fetched atomic.Int32
+	mockJobs := &MockJobsClient{
+		GetJobLogFunc: func(ctx context.Context, org string, pipeline string, buildNumber string, jobID string) (buildkite.JobLog, *buildkite.Response, error) {
+			fetched.Add(1)
+			cancel()
+			return buildkite.JobLog{}, nil, ctx.Err()
+		},
+	}
+
+	_, err := loadFailedJobLogs(ctx, mockJobs, GetBuildFailedLogsArgs{}, jobs, 0, 1)
+	assert.ErrorIs(err, context.Canceled)
+	assert.Equal(int32(1), fetched.Load())
+}
+
+func BenchmarkLoadFailedJobLogs(b *testing.B) {
+	jobs := make([]buildkite.Job, 16)
+	for i := range jobs {
+		jobs[i] = buildkite.Job{ID: fmt.Sprintf("job-%d", i), Type: "script", State: "failed"}
+	}
+	// Simulates the latency of fetching a log from the API.
+	mockJobs := &MockJobsClient{
+		GetJobLogFunc: func(ctx context.Context, org string, pipeline string, buildNumber string, jobID string) (buildkite.JobLog, *buildkite.Response, error) {
+			time.Sleep(time.Millisecond)
+			return buildkite.JobLog{Content: "FAIL spec/a_spec.rb\n"}, &buildkite.Response{}, nil
+		},
+	}
+
+	for _, concurrency := range []int{1, failureSummaryConcurrency, 8} {
+		name := fmt.Sprintf("concurrency=%d", concurrency)
+		if concurrency == 1 {
+			name = "serial"
+		}
+		b.Run(name, func(b *testing.B) {
+			for b.Loop() {
+				if _, err := loadFailedJobLogs(context.Background(), mockJobs, GetBuildFailedLogsArgs{}, jobs, 0, concurrency); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
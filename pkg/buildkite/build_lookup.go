@@ -0,0 +1,154 @@
+package buildkite
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/buildkite/buildkite-mcp-server/pkg/trace"
+	"github.com/buildkite/buildkite-mcp-server/pkg/utils"
+	"github.com/buildkite/go-buildkite/v5"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type GetBuildByCommitArgs struct {
+	OrgSlug      string `json:"org_slug"`
+	PipelineSlug string `json:"pipeline_slug"`
+	Commit       string `json:"commit" jsonschema:"The full commit SHA. Abbreviated SHAs don't match"`
+}
+
+// latestBuild returns the most recent build of a pipeline matching options,
+// or nil if there is none. Builds are listed newest first, so only one is
+// fetched.
+func latestBuild(ctx context.Context, client BuildsClient, org, pipelineSlug string, options buildkite.BuildsListOptions) (*buildkite.Build, error) {
+	options.ExcludeJobs = true
+	options.ExcludePipeline = true
+	options.ListOptions = buildkite.ListOptions{Page: 1, PerPage: 1}
+
+	builds, _, err := client.ListByPipeline(ctx, org, pipelineSlug, &options)
+	if err != nil {
+		return nil, err
+	}
+	if len(builds) == 0 {
+		return nil, nil
+	}
+	return &builds[0], nil
+}
+
+func GetBuildByCommit() (mcp.Tool, mcp.ToolHandlerFor[GetBuildByCommitArgs, any], []string) {
+	return mcp.Tool{
+			Name:        "get_build_by_commit",
+			Description: "Get the most recent build of a pipeline for a commit SHA, as a lightweight summary. Use get_build with its number for more detail",
+			Annotations: &mcp.ToolAnnotations{
+				Title:        "Get Build by Commit",
+				ReadOnlyHint: true,
+			},
+		},
+		func(ctx context.Context, request *mcp.CallToolRequest, args GetBuildByCommitArgs) (*mcp.CallToolResult, any, error) {
+			ctx, span := trace.Start(ctx, "buildkite.GetBuildByCommit")
+			defer span.End()
+
+			span.SetAttributes(
+				attribute.String("org_slug", args.OrgSlug),
+				attribute.String("pipeline_slug", args.PipelineSlug),
+				attribute.String("commit", args.Commit),
+			)
+
+			if err := requireParams(map[string]string{
+				"org_slug":      args.OrgSlug,
+				"pipeline_slug": args.PipelineSlug,
+				"commit":        args.Commit,
+			}); err != nil {
+				return utils.NewToolResultError(err.Error()), nil, nil
+			}
+
+			deps := DepsFromContext(ctx)
+			build, err := latestBuild(ctx, deps.BuildsClient, args.OrgSlug, args.PipelineSlug, buildkite.BuildsListOptions{
+				Commit: args.Commit,
+			})
+			if err != nil {
+				return handleBuildkiteError(ctx, err)
+			}
+
+			span.SetAttributes(attribute.Bool("found", build != nil))
+			if build == nil {
+				return utils.NewToolResultText(fmt.Sprintf("No build of pipeline %q found for commit %s", args.PipelineSlug, args.Commit)), nil, nil
+			}
+
+			summary := summarizeBuild(*build)
+			return mcpTextResult(ctx, span, &summary)
+		}, []string{"read_builds"}
+}
+
+type GetLatestBuildArgs struct {
+	OrgSlug      string `json:"org_slug"`
+	PipelineSlug string `json:"pipeline_slug"`
+	Branch       string `json:"branch,omitempty" jsonschema:"Branch to get the latest build of. Defaults to the pipeline's default branch"`
+}
+
+func GetLatestBuild() (mcp.Tool, mcp.ToolHandlerFor[GetLatestBuildArgs, any], []string) {
+	return mcp.Tool{
+			Name:        "get_latest_build",
+			Description: "Get the most recent build of a pipeline on a branch, by default the pipeline's default branch, as a lightweight summary. The build may still be running; check its state",
+			Annotations: &mcp.ToolAnnotations{
+				Title:        "Get Latest Build",
+				ReadOnlyHint: true,
+			},
+		},
+		func(ctx context.Context, request *mcp.CallToolRequest, args GetLatestBuildArgs) (*mcp.CallToolResult, any, error) {
+			ctx, span := trace.Start(ctx, "buildkite.GetLatestBuild")
+			defer span.End()
+
+			span.SetAttributes(
+				attribute.String("org_slug", args.OrgSlug),
+				attribute.String("pipeline_slug", args.PipelineSlug),
+				attribute.String("branch", args.Branch),
+			)
+
+			if err := requireParams(map[string]string{
+				"org_slug":      args.OrgSlug,
+				"pipeline_slug": args.PipelineSlug,
+			}); err != nil {
+				return utils.NewToolResultError(err.Error()), nil, nil
+			}
+
+			deps := DepsFromContext(ctx)
+			branch := args.Branch
+			if branch == "" {
+				defaultBranch, err := pipelineDefaultBranch(ctx, args.OrgSlug, args.PipelineSlug)
+				if err != nil {
+					return handleBuildkiteError(ctx, err)
+				}
+				if defaultBranch == "" {
+					return utils.NewToolResultError(fmt.Sprintf("pipeline %q has no default branch, pass branch", args.PipelineSlug)), nil, nil
+				}
+				branch = defaultBranch
+				span.SetAttributes(attribute.String("default_branch", branch))
+			}
+
+			build, err := latestBuild(ctx, deps.BuildsClient, args.OrgSlug, args.PipelineSlug, buildkite.BuildsListOptions{
+				Branch: []string{branch},
+			})
+			if err != nil {
+				return handleBuildkiteError(ctx, err)
+			}
+
+			span.SetAttributes(attribute.Bool("found", build != nil))
+			if build == nil {
+				return utils.NewToolResultText(fmt.Sprintf("No build of pipeline %q found on branch %s", args.PipelineSlug, branch)), nil, nil
+			}
+
+			summary := summarizeBuild(*build)
+			return mcpTextResult(ctx, span, &summary)
+		}, []string{"read_builds", "read_pipelines"}
+}
+
+// pipelineDefaultBranch returns the default branch of a pipeline, or an empty
+// string when it has none.
+func pipelineDefaultBranch(ctx context.Context, org, pipelineSlug string) (string, error) {
+	pipeline, _, err := DepsFromContext(ctx).PipelinesClient.Get(ctx, org, pipelineSlug)
+	if err != nil {
+		return "", err
+	}
+	return pipeline.DefaultBranch, nil
+}
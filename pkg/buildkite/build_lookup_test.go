@@ -0,0 +1,118 @@
+package buildkite
+
+import (
+	"context"
+	"testing"
+
+	"github.com/buildkite/go-buildkite/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetBuildByCommit(t *testing.T) {
+	t.Run("Found", func(t *testing.T) {
+		assert := require.New(t)
+
+		client := &MockBuildsClient{
+			ListByPipelineFunc: func(ctx context.Context, org string, pipeline string, opt *buildkite.BuildsListOptions) ([]buildkite.Build, *buildkite.Response, error) {
+				assert.Equal("org", org)
+				assert.Equal("pipeline", pipeline)
+				assert.Equal("abc123", opt.Commit)
+				assert.Equal(1, opt.PerPage)
+				assert.True(opt.ExcludeJobs)
+				return []buildkite.Build{{Number: 7, State: "passed", Commit: "abc123"}}, &buildkite.Response{}, nil
+			},
+		}
+
+		ctx := ContextWithDeps(context.Background(), ToolDependencies{BuildsClient: client})
+		_, handler, _ := GetBuildByCommit()
+
+		result, _, err := handler(ctx, createMCPRequest(t, map[string]any{}), GetBuildByCommitArgs{
+			OrgSlug:      "org",
+			PipelineSlug: "pipeline",
+			Commit:       "abc123",
+		})
+		assert.NoError(err)
+
+		text := getTextResult(t, result).Text
+		assert.Contains(text, `"number":7`)
+		assert.Contains(text, `"state":"passed"`)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		assert := require.New(t)
+
+		client := &MockBuildsClient{
+			ListByPipelineFunc: func(ctx context.Context, org string, pipeline string, opt *buildkite.BuildsListOptions) ([]buildkite.Build, *buildkite.Response, error) {
+				return []buildkite.Build{}, &buildkite.Response{}, nil
+			},
+		}
+
+		ctx := ContextWithDeps(context.Background(), ToolDependencies{BuildsClient: client})
+		_, handler, _ := GetBuildByCommit()
+
+		result, _, err := handler(ctx, createMCPRequest(t, map[string]any{}), GetBuildByCommitArgs{
+			OrgSlug:      "org",
+			PipelineSlug: "pipeline",
+			Commit:       "abc123",
+		})
+		assert.NoError(err)
+		assert.False(result.IsError)
+		assert.Equal(`No build of pipeline "pipeline" found for commit abc123`, getTextResult(t, result).Text)
+	})
+}
+
+func TestGetLatestBuild(t *testing.T) {
+	t.Run("DefaultBranch", func(t *testing.T) {
+		assert := require.New(t)
+
+		pipelines := &MockPipelinesClient{
+			GetFunc: func(ctx context.Context, org string, pipeline string) (buildkite.Pipeline, *buildkite.Response, error) {
+				return buildkite.Pipeline{DefaultBranch: "main"}, &buildkite.Response{}, nil
+			},
+		}
+		builds := &MockBuildsClient{
+			ListByPipelineFunc: func(ctx context.Context, org string, pipeline string, opt *buildkite.BuildsListOptions) ([]buildkite.Build, *buildkite.Response, error) {
+				assert.Equal([]string{"main"}, opt.Branch)
+				assert.Equal(1, opt.PerPage)
+				return []buildkite.Build{{Number: 12, State: "failed", Branch: "main"}}, &buildkite.Response{}, nil
+			},
+		}
+
+		ctx := ContextWithDeps(context.Background(), ToolDependencies{BuildsClient: builds, PipelinesClient: pipelines})
+		_, handler, _ := GetLatestBuild()
+
+		result, _, err := handler(ctx, createMCPRequest(t, map[string]any{}), GetLatestBuildArgs{
+			OrgSlug:      "org",
+			PipelineSlug: "pipeline",
+		})
+		assert.NoError(err)
+
+		text := getTextResult(t, result).Text
+		assert.Contains(text, `"number":12`)
+		assert.Contains(text, `"state":"failed"`)
+	})
+
+	t.Run("Branch", func(t *testing.T) {
+		assert := require.New(t)
+
+		builds := &MockBuildsClient{
+			ListByPipelineFunc: func(ctx context.Context, org string, pipeline string, opt *buildkite.BuildsListOptions) ([]buildkite.Build, *buildkite.Response, error) {
+				assert.Equal([]string{"release"}, opt.Branch)
+				return nil, &buildkite.Response{}, nil
+			},
+		}
+
+		// Passing a branch doesn't look up the pipeline.
+		ctx := ContextWithDeps(context.Background(), ToolDependencies{BuildsClient: builds})
+		_, handler, _ := GetLatestBuild()
+
+		result, _, err := handler(ctx, createMCPRequest(t, map[string]any{}), GetLatestBuildArgs{
+			OrgSlug:      "org",
+			PipelineSlug: "pipeline",
+			Branch:       "release",
+		})
+		assert.NoError(err)
+		assert.False(result.IsError)
+		assert.Equal(`No build of pipeline "pipeline" found on branch release`, getTextResult(t, result).Text)
+	})
+}
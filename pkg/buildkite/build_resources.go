@@ -0,0 +1,43 @@
+package buildkite
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// BuildResourceURITemplate is the URI template of build resources.
+const BuildResourceURITemplate = ResourceScheme + "://{org}/{pipeline}/builds/{number}"
+
+// NewBuildResourceTemplate returns the resource template for builds and its
+// handler, which reads a build as get_build returns it.
+func NewBuildResourceTemplate() (*mcp.ResourceTemplate, mcp.ResourceHandler) {
+	template := &mcp.ResourceTemplate{
+		URITemplate: BuildResourceURITemplate,
+		Name:        "build",
+		Title:       "Build",
+		Description: "A build with lightweight annotation summaries, and how long it waited to start and ran for in seconds. The number is the build number, not its UUID",
+		MIMEType:    "application/json",
+	}
+
+	handler := func(ctx context.Context, request *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+		uri := request.Params.URI
+		segments, ok := resourceSegments(uri)
+		if !ok || len(segments) != 4 || segments[2] != "builds" {
+			return nil, mcp.ResourceNotFoundError(uri)
+		}
+		if number, err := strconv.Atoi(segments[3]); err != nil || number < 1 {
+			return nil, mcp.ResourceNotFoundError(uri)
+		}
+
+		build, err := getBuildDetail(ctx, segments[0], segments[1], segments[3])
+		if err != nil {
+			return nil, resourceError(uri, err)
+		}
+
+		return jsonResourceResult(ctx, uri, build)
+	}
+
+	return template, handler
+}
@@ -0,0 +1,62 @@
+package buildkite
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/buildkite/go-buildkite/v5"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewBuildResourceTemplate(t *testing.T) {
+	template, handler := NewBuildResourceTemplate()
+	require.Equal(t, "buildkite://{org}/{pipeline}/builds/{number}", template.URITemplate)
+
+	client := &MockBuildsClient{
+		GetFunc: func(ctx context.Context, org string, pipeline string, id string, opt *buildkite.BuildGetOptions) (buildkite.Build, *buildkite.Response, error) {
+			if id == "404" {
+				return buildkite.Build{}, nil, &buildkite.ErrorResponse{Response: &http.Response{StatusCode: http.StatusNotFound}}
+			}
+			require.True(t, opt.ExcludeJobs)
+			return buildkite.Build{Number: 42, State: "passed", Jobs: []buildkite.Job{{ID: "job-1"}}}, nil, nil
+		},
+	}
+	annotations := &MockAnnotationsClient{
+		ListByBuildFunc: func(ctx context.Context, org, pipelineSlug, buildNumber string, opts *buildkite.AnnotationListOptions) ([]buildkite.Annotation, *buildkite.Response, error) {
+			return []buildkite.Annotation{{Context: "lint", Style: "error"}}, nil, nil
+		},
+	}
+	ctx := ContextWithDeps(context.Background(), ToolDependencies{BuildsClient: client, AnnotationsClient: annotations})
+
+	read := func(uri string) (*mcp.ReadResourceResult, error) {
+		return handler(ctx, &mcp.ReadResourceRequest{Params: &mcp.ReadResourceParams{URI: uri}})
+	}
+
+	t.Run("reads the build", func(t *testing.T) {
+		result, err := read("buildkite://acme/web/builds/42")
+		require.NoError(t, err)
+		require.Len(t, result.Contents, 1)
+		require.Equal(t, "application/json", result.Contents[0].MIMEType)
+		require.Contains(t, result.Contents[0].Text, `"number":42`)
+		require.Contains(t, result.Contents[0].Text, `"context":"lint"`)
+		require.NotContains(t, result.Contents[0].Text, "job-1")
+	})
+
+	t.Run("missing build", func(t *testing.T) {
+		_, err := read("buildkite://acme/web/builds/404")
+		require.EqualError(t, err, mcp.ResourceNotFoundError("buildkite://acme/web/builds/404").Error())
+	})
+
+	for _, uri := range []string{
+		"buildkite://acme/web/builds/abc",
+		"buildkite://acme/web/builds/0",
+		"buildkite://acme/web/jobs/42",
+	} {
+		t.Run(uri, func(t *testing.T) {
+			_, err := read(uri)
+			require.EqualError(t, err, mcp.ResourceNotFoundError(uri).Error())
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package buildkite
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/buildkite/buildkite-mcp-server/pkg/trace"
+	"github.com/buildkite/buildkite-mcp-server/pkg/utils"
+	"github.com/buildkite/go-buildkite/v5"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	// defaultBuildPollInterval is the first wait between polls; it doubles
+	// after each poll up to maxBuildPollInterval.
+	defaultBuildPollInterval = 5 * time.Second
+	maxBuildPollInterval     = 30 * time.Second
+
+	// defaultBuildWaitTimeout stays under the HTTP server's default write
+	// timeout of 30 seconds, so a wait with the defaults can respond in
+	// HTTP mode.
+	defaultBuildWaitTimeout = 25 * time.Second
+	maxBuildWaitTimeout     = 15 * time.Minute
+)
+
+// Reasons wait_for_build returned a build.
+const (
+	waitReasonAlreadyFinished = "already_finished"
+	waitReasonFinished        = "finished"
+	waitReasonTimedOut        = "timed_out"
+)
+
+// finishedBuildStates are the states a build stays in until it is rebuilt or
+// unblocked. Blocked builds wait on a person, so polling them is pointless.
+var finishedBuildStates = []string{"passed", "failed", "canceled", "blocked", "skipped", "not_run"}
+
+type WaitForBuildArgs struct {
+	OrgSlug             string `json:"org_slug"`
+	PipelineSlug        string `json:"pipeline_slug"`
+	BuildNumber         string `json:"build_number"`
+	PollIntervalSeconds int    `json:"poll_interval_seconds,omitempty" jsonschema:"Seconds to wait before polling the build again (default 5). The wait doubles after each poll, up to 30 seconds"`
+	TimeoutSeconds      int    `json:"timeout_seconds,omitempty" jsonschema:"Seconds to wait for the build to finish before returning it as it is (default 25, max 900). Over HTTP, keep it under the server's write timeout"`
+}
+
+// WaitForBuildResult is a build as get_build returns it, and why the wait
+// returned: the build had already finished, finished while waiting, or the
+// wait timed out first.
+type WaitForBuildResult struct {
+	BuildDetail
+	Reason        string `json:"reason"`
+	TimedOut      bool   `json:"timed_out"`
+	WaitedSeconds int64  `json:"waited_seconds"`
+}
+
+func WaitForBuild() (mcp.Tool, mcp.ToolHandlerFor[WaitForBuildArgs, any], []string) {
+	return waitForBuild(time.Now, sleepContext)
+}
+
+// waitForBuild is WaitForBuild with the clock and sleep replaced, for tests.
+func waitForBuild(now func() time.Time, sleep func(context.Context, time.Duration) error) (mcp.Tool, mcp.ToolHandlerFor[WaitForBuildArgs, any], []string) {
+	return mcp.Tool{
+			Name:        "wait_for_build",
+			Description: "Wait for a build to finish, polling with a growing interval, and return it with its final state and the reason the wait returned. A build that has already finished or is blocked is returned at once. When the timeout passes first, returns the build as it is with timed_out set rather than failing, so you can decide whether to wait again. The build is returned as get_build returns it",
+			Annotations: &mcp.ToolAnnotations{
+				Title:        "Wait for Build",
+				ReadOnlyHint: true,
+			},
+		},
+		func(ctx context.Context, request *mcp.CallToolRequest, args WaitForBuildArgs) (*mcp.CallToolResult, any, error) {
+			ctx, span := trace.Start(ctx, "buildkite.WaitForBuild")
+			defer span.End()
+
+			span.SetAttributes(
+				attribute.String("org_slug", args.OrgSlug),
+				attribute.String("pipeline_slug", args.PipelineSlug),
+				attribute.String("build_number", args.BuildNumber),
+			)
+
+			if err := requireParams(map[string]string{
+				"org_slug":      args.OrgSlug,
+				"pipeline_slug": args.PipelineSlug,
+				"build_number":  args.BuildNumber,
+			}); err != nil {
+				return utils.NewToolResultError(err.Error()), nil, nil
+			}
+			if args.PollIntervalSeconds < 0 || args.TimeoutSeconds < 0 {
+				return utils.NewToolResultError("poll_interval_seconds and timeout_seconds must not be negative"), nil, nil
+			}
+
+			interval := defaultBuildPollInterval
+			if args.PollIntervalSeconds > 0 {
+				interval = min(time.Duration(args.PollIntervalSeconds)*time.Second, maxBuildPollInterval)
+			}
+			timeout := defaultBuildWaitTimeout
+			if args.TimeoutSeconds > 0 {
+				timeout = min(time.Duration(args.TimeoutSeconds)*time.Second, maxBuildWaitTimeout)
+			}
+
+			// Jobs are excluded, as polls only need the build's state. The
+			// result is read with getBuildDetail once the wait is over.
+			options := &buildkite.BuildGetOptions{
+				BuildsListOptions: buildkite.BuildsListOptions{
+					ExcludeJobs:     true,
+					ExcludePipeline: true,
+				},
+			}
+
+			deps := DepsFromContext(ctx)
+			start := now()
+			deadline := start.Add(timeout)
+			polls := 0
+			reason := ""
+			for reason == "" {
+				build, _, err := deps.BuildsClient.Get(ctx, args.OrgSlug, args.PipelineSlug, args.BuildNumber, options)
+				polls++
+				if err != nil {
+					if ctx.Err() != nil {
+						return nil, nil, fmt.Errorf("stopped waiting for build %s: %w", args.BuildNumber, ctx.Err())
+					}
+					return handleBuildkiteError(ctx, err)
+				}
+
+				remaining := deadline.Sub(now())
+				switch {
+				case isFinishedBuild(build) && polls == 1:
+					reason = waitReasonAlreadyFinished
+				case isFinishedBuild(build):
+					reason = waitReasonFinished
+				case remaining <= 0:
+					reason = waitReasonTimedOut
+				default:
+					// A disconnected client cancels ctx, which ends the wait.
+					if err := sleep(ctx, min(interval, remaining)); err != nil {
+						return nil, nil, fmt.Errorf("stopped waiting for build %s: %w", args.BuildNumber, err)
+					}
+					interval = min(interval*2, maxBuildPollInterval)
+				}
+			}
+
+			detail, err := getBuildDetail(ctx, args.OrgSlug, args.PipelineSlug, args.BuildNumber)
+			if err != nil {
+				return handleBuildkiteError(ctx, err)
+			}
+
+			result := WaitForBuildResult{
+				BuildDetail:   detail,
+				Reason:        reason,
+				TimedOut:      reason == waitReasonTimedOut,
+				WaitedSeconds: int64(now().Sub(start).Seconds()),
+			}
+
+			span.SetAttributes(
+				attribute.String("state", detail.State),
+				attribute.String("reason", reason),
+				attribute.Int("polls", polls),
+			)
+
+			return mcpTextResult(ctx, span, &result)
+		}, []string{"read_builds"}
+}
+
+// isFinishedBuild reports whether a build won't change state without someone
+// acting on it.
+func isFinishedBuild(build buildkite.Build) bool {
+	return build.FinishedAt != nil || slices.Contains(finishedBuildStates, build.State)
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
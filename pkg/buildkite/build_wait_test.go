@@ -0,0 +1,146 @@
+package buildkite
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/buildkite/go-buildkite/v5"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClock is a clock whose sleeps advance it, recording how long each was.
+type fakeClock struct {
+	now    time.Time
+	sleeps []time.Duration
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Sleep(ctx context.Context, d time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	c.sleeps = append(c.sleeps, d)
+	c.now = c.now.Add(d)
+	return nil
+}
+
+// buildStates returns a BuildsClient whose builds go through states, one per
+// call, staying in the last one. A build in a final state has finished.
+func buildStates(states ...string) (*MockBuildsClient, *int) {
+	calls := 0
+	return &MockBuildsClient{
+		GetFunc: func(ctx context.Context, org string, pipeline string, id string, opt *buildkite.BuildGetOptions) (buildkite.Build, *buildkite.Response, error) {
+			state := states[min(calls, len(states)-1)]
+			calls++
+			build := buildkite.Build{Number: 42, State: state}
+			if state == "passed" || state == "failed" {
+				build.FinishedAt = &buildkite.Timestamp{Time: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+			}
+			return build, &buildkite.Response{}, nil
+		},
+	}, &calls
+}
+
+func callWaitForBuild(t *testing.T, ctx context.Context, clock *fakeClock, builds BuildsClient, args WaitForBuildArgs) (WaitForBuildResult, error) {
+	t.Helper()
+
+	ctx = ContextWithDeps(ctx, ToolDependencies{BuildsClient: builds, AnnotationsClient: &MockAnnotationsClient{}})
+	_, handler, _ := waitForBuild(clock.Now, clock.Sleep)
+
+	args.OrgSlug, args.PipelineSlug, args.BuildNumber = "org", "pipeline", "42"
+	result, _, err := handler(ctx, createMCPRequest(t, map[string]any{}), args)
+	if err != nil {
+		return WaitForBuildResult{}, err
+	}
+	require.False(t, result.IsError, getTextResult(t, result).Text)
+
+	var waited WaitForBuildResult
+	require.NoError(t, json.Unmarshal([]byte(getTextResult(t, result).Text), &waited))
+	return waited, nil
+}
+
+func TestWaitForBuild(t *testing.T) {
+	t.Run("ToolDefinition", func(t *testing.T) {
+		tool, handler, scopes := WaitForBuild()
+		require.Equal(t, "wait_for_build", tool.Name)
+		require.True(t, tool.Annotations.ReadOnlyHint)
+		require.Equal(t, []string{"read_builds"}, scopes)
+		require.NotNil(t, handler)
+	})
+
+	t.Run("BacksOffUntilTheBuildFinishes", func(t *testing.T) {
+		clock := &fakeClock{now: time.Now()}
+		builds, calls := buildStates("scheduled", "running", "running", "running", "passed")
+
+		result, err := callWaitForBuild(t, context.Background(), clock, builds, WaitForBuildArgs{PollIntervalSeconds: 10, TimeoutSeconds: 300})
+		require.NoError(t, err)
+		require.Equal(t, "passed", result.State)
+		require.Equal(t, "finished", result.Reason)
+		require.False(t, result.TimedOut)
+		require.Equal(t, int64(90), result.WaitedSeconds)
+		require.Equal(t, []time.Duration{10 * time.Second, 20 * time.Second, 30 * time.Second, 30 * time.Second}, clock.sleeps)
+		// Five polls, then the build detail.
+		require.Equal(t, 6, *calls)
+	})
+
+	t.Run("ReturnsTheBuildWhenTimedOut", func(t *testing.T) {
+		clock := &fakeClock{now: time.Now()}
+		builds, _ := buildStates("running")
+
+		result, err := callWaitForBuild(t, context.Background(), clock, builds, WaitForBuildArgs{})
+		require.NoError(t, err)
+		require.Equal(t, "running", result.State)
+		require.Equal(t, "timed_out", result.Reason)
+		require.True(t, result.TimedOut)
+		require.Equal(t, int64(25), result.WaitedSeconds)
+		// The last wait is cut short at the timeout.
+		require.Equal(t, []time.Duration{5 * time.Second, 10 * time.Second, 10 * time.Second}, clock.sleeps)
+	})
+
+	t.Run("ReturnsFinishedBuildsAtOnce", func(t *testing.T) {
+		for _, state := range []string{"passed", "blocked"} {
+			clock := &fakeClock{now: time.Now()}
+			builds, calls := buildStates(state)
+
+			result, err := callWaitForBuild(t, context.Background(), clock, builds, WaitForBuildArgs{})
+			require.NoError(t, err)
+			require.Equal(t, state, result.State)
+			require.Equal(t, "already_finished", result.Reason)
+			require.False(t, result.TimedOut)
+			require.Zero(t, result.WaitedSeconds)
+			require.Empty(t, clock.sleeps)
+			require.Equal(t, 2, *calls)
+		}
+	})
+
+	t.Run("StopsWhenCancelled", func(t *testing.T) {
+		clock := &fakeClock{now: time.Now()}
+		builds, calls := buildStates("running")
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := callWaitForBuild(t, ctx, clock, builds, WaitForBuildArgs{TimeoutSeconds: 600})
+		require.ErrorIs(t, err, context.Canceled)
+		require.Equal(t, 1, *calls)
+		require.Empty(t, clock.sleeps)
+	})
+
+	t.Run("RejectsNegativeTimes", func(t *testing.T) {
+		ctx := ContextWithDeps(context.Background(), ToolDependencies{})
+		_, handler, _ := WaitForBuild()
+
+		result, _, err := handler(ctx, createMCPRequest(t, map[string]any{}), WaitForBuildArgs{
+			OrgSlug:        "org",
+			PipelineSlug:   "pipeline",
+			BuildNumber:    "42",
+			TimeoutSeconds: -1,
+		})
+		require.NoError(t, err)
+		require.True(t, result.IsError)
+	})
+}
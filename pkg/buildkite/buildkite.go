@@ -2,6 +2,7 @@ package buildkite
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 
@@ -17,6 +18,69 @@ import (
 type PaginatedResult[T any] struct {
 	Headers map[string]string `json:"headers"`
 	Items   []T               `json:"items"`
+	// Truncated is set when auto_paginate stopped at maxAutoPaginatePages
+	// with more pages remaining.
+	Truncated bool `json:"truncated,omitempty"`
+	*Pagination
+	*FilterCounts
+}
+
+// Pagination tells the caller of a list tool whether there are more pages.
+// It is only included when the tool is called with include_pagination, so
+// existing consumers see the same result shape.
+type Pagination struct {
+	// Page is the first page in the result.
+	Page     int  `json:"page"`
+	HasMore  bool `json:"has_more"`
+	NextPage int  `json:"next_page,omitempty"`
+}
+
+// FilterCounts tells the caller of a list tool that filters the items it
+// fetched how many matched. It is only included when such a filter is used.
+type FilterCounts struct {
+	Matched int `json:"matched"`
+	Total   int `json:"total"`
+}
+
+// paginationFor returns the pagination of a list response starting at page,
+// or nil unless include is set.
+func paginationFor(include bool, page int, resp *buildkite.Response) *Pagination {
+	if !include || resp == nil {
+		return nil
+	}
+	return &Pagination{
+		Page:     max(page, 1),
+		HasMore:  resp.NextPage > 0,
+		NextPage: resp.NextPage,
+	}
+}
+
+// maxAutoPaginatePages caps how many pages a list tool fetches when asked to
+// auto_paginate, so a huge organization can't produce an unbounded response.
+const maxAutoPaginatePages = 10
+
+// listPages calls fetch for page and, when autoPaginate is set, each following
+// page until the API reports no next page or maxAutoPaginatePages have been
+// fetched. It returns the items of every page, the last page's response and
+// whether pages were left unfetched.
+func listPages[T any](page int, autoPaginate bool, fetch func(page int) ([]T, *buildkite.Response, error)) ([]T, *buildkite.Response, bool, error) {
+	items, resp, err := fetch(page)
+	if err != nil || !autoPaginate {
+		return items, resp, false, err
+	}
+
+	for pages := 1; resp != nil && resp.NextPage > 0; pages++ {
+		if pages >= maxAutoPaginatePages {
+			return items, resp, true, nil
+		}
+		next, nextResp, err := fetch(resp.NextPage)
+		if err != nil {
+			return nil, nil, false, err
+		}
+		items = append(items, next...)
+		resp = nextResp
+	}
+	return items, resp, false, nil
 }
 
 // PaginationParams is embedded in tool args structs to provide pagination fields.
@@ -42,8 +106,8 @@ func boolPtr(b bool) *bool {
 	return &b
 }
 
-func mcpTextResult(span trace.Span, result any) (*mcp.CallToolResult, any, error) {
-	sanitized, err := marshalSanitizedJSON(result)
+func mcpTextResult(ctx context.Context, span trace.Span, result any) (*mcp.CallToolResult, any, error) {
+	sanitized, err := MarshalResult(ctx, result)
 	if err != nil {
 		return utils.NewToolResultError(err.Error()), nil, nil
 	}
@@ -51,6 +115,9 @@ func mcpTextResult(span trace.Span, result any) (*mcp.CallToolResult, any, error
 	return mcpSanitizedTextResult(span, sanitized)
 }
 
+// mcpTextResultWithByteLimit is mcpTextResult for results with a byte budget.
+// The budget is measured against compact JSON, so these results stay compact
+// even with PrettyJSON.
 func mcpTextResultWithByteLimit(span trace.Span, result any, limit int) (*mcp.CallToolResult, any, error) {
 	sanitized, err := marshalSanitizedJSON(result)
 	if err != nil {
@@ -65,6 +132,22 @@ func mcpTextResultWithByteLimit(span trace.Span, result any, limit int) (*mcp.Ca
 	return mcpSanitizedTextResult(span, sanitized)
 }
 
+// MarshalResult encodes a tool result as JSON with its strings sanitized. It
+// is compact to save tokens, or indented when the server runs with
+// --pretty-json (ToolDependencies.PrettyJSON).
+func MarshalResult(ctx context.Context, result any) ([]byte, error) {
+	sanitized, err := marshalSanitizedJSON(result)
+	if err != nil || !DepsFromContext(ctx).PrettyJSON {
+		return sanitized, err
+	}
+
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, sanitized, "", "  "); err != nil {
+		return nil, fmt.Errorf("failed to indent result: %v", err)
+	}
+	return indented.Bytes(), nil
+}
+
 func marshalSanitizedJSON(result any) ([]byte, error) {
 	r, err := json.Marshal(result)
 	if err != nil {
@@ -1,10 +1,13 @@
 package buildkite
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"strings"
 	"testing"
 
+	"github.com/buildkite/go-buildkite/v5"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/stretchr/testify/require"
 )
@@ -164,3 +167,87 @@ func TestLimitSanitizedJSONPayloadUpdatesNestedTruncationMetadata(t *testing.T)
 	require.True(t, result.TestRuns[0].ContentTruncated)
 	require.True(t, result.TestRuns[0].FailedExecutions[0].ContentTruncated)
 }
+
+func TestListPages(t *testing.T) {
+	// fetchPages serves lastPage pages of one item each, numbered by page.
+	fetchPages := func(lastPage int, fetched *[]int) func(page int) ([]int, *buildkite.Response, error) {
+		return func(page int) ([]int, *buildkite.Response, error) {
+			*fetched = append(*fetched, page)
+			resp := &buildkite.Response{}
+			if page < lastPage {
+				resp.NextPage = page + 1
+			}
+			return []int{page}, resp, nil
+		}
+	}
+
+	t.Run("single page by default", func(t *testing.T) {
+		assert := require.New(t)
+		var fetched []int
+		items, _, truncated, err := listPages(2, false, fetchPages(5, &fetched))
+		assert.NoError(err)
+		assert.Equal([]int{2}, items)
+		assert.Equal([]int{2}, fetched)
+		assert.False(truncated)
+	})
+
+	t.Run("follows next pages", func(t *testing.T) {
+		assert := require.New(t)
+		var fetched []int
+		items, resp, truncated, err := listPages(2, true, fetchPages(5, &fetched))
+		assert.NoError(err)
+		assert.Equal([]int{2, 3, 4, 5}, items)
+		assert.Zero(resp.NextPage)
+		assert.False(truncated)
+	})
+
+	t.Run("stops at the page cap", func(t *testing.T) {
+		assert := require.New(t)
+		var fetched []int
+		items, resp, truncated, err := listPages(1, true, fetchPages(100, &fetched))
+		assert.NoError(err)
+		assert.Len(items, maxAutoPaginatePages)
+		assert.Equal(maxAutoPaginatePages+1, resp.NextPage)
+		assert.True(truncated)
+	})
+
+	t.Run("returns errors from later pages", func(t *testing.T) {
+		assert := require.New(t)
+		_, _, _, err := listPages(1, true, func(page int) ([]int, *buildkite.Response, error) {
+			if page > 1 {
+				return nil, nil, errors.New("boom")
+			}
+			return []int{page}, &buildkite.Response{NextPage: 2}, nil
+		})
+		assert.EqualError(err, "boom")
+	})
+}
+
+func TestPaginationFor(t *testing.T) {
+	assert := require.New(t)
+
+	assert.Nil(paginationFor(false, 1, &buildkite.Response{NextPage: 2}))
+	assert.Equal(&Pagination{Page: 1, HasMore: true, NextPage: 2}, paginationFor(true, 0, &buildkite.Response{NextPage: 2}))
+	assert.Equal(&Pagination{Page: 4}, paginationFor(true, 4, &buildkite.Response{}))
+}
+
+func TestMarshalResult(t *testing.T) {
+	result := map[string]any{"name": "app", "tags": []string{"a"}}
+
+	t.Run("Compact", func(t *testing.T) {
+		assert := require.New(t)
+
+		out, err := MarshalResult(context.Background(), result)
+		assert.NoError(err)
+		assert.Equal(`{"name":"app","tags":["a"]}`, string(out))
+	})
+
+	t.Run("Pretty", func(t *testing.T) {
+		assert := require.New(t)
+
+		ctx := ContextWithDeps(context.Background(), ToolDependencies{PrettyJSON: true})
+		out, err := MarshalResult(ctx, result)
+		assert.NoError(err)
+		assert.Equal("{\n  \"name\": \"app\",\n  \"tags\": [\n    \"a\"\n  ]\n}", string(out))
+	})
+}
@@ -2,8 +2,14 @@ package buildkite
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/buildkite/buildkite-mcp-server/pkg/trace"
+	"github.com/buildkite/buildkite-mcp-server/pkg/utils"
 	"github.com/buildkite/go-buildkite/v5"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"go.opentelemetry.io/otel/attribute"
@@ -45,6 +51,8 @@ type AnnotationSummary struct {
 
 // BuildDetail includes useful build metadata and annotation summaries while
 // omitting jobs, env, pipeline configuration, and annotation bodies.
+// WaitTimeSeconds (scheduled to started) and RunTimeSeconds (started to
+// finished) are set once both of their timestamps are.
 type BuildDetail struct {
 	BuildSummary
 	Blocked              bool                          `json:"blocked"`
@@ -52,6 +60,8 @@ type BuildDetail struct {
 	ScheduledAt          *buildkite.Timestamp          `json:"scheduled_at,omitempty"`
 	StartedAt            *buildkite.Timestamp          `json:"started_at,omitempty"`
 	FinishedAt           *buildkite.Timestamp          `json:"finished_at,omitempty"`
+	WaitTimeSeconds      *int64                        `json:"wait_time_seconds,omitempty"`
+	RunTimeSeconds       *int64                        `json:"run_time_seconds,omitempty"`
 	MetaData             map[string]string             `json:"meta_data,omitempty"`
 	Creator              buildkite.Creator             `json:"creator"`
 	Source               string                        `json:"source,omitempty"`
@@ -65,14 +75,19 @@ type BuildDetail struct {
 
 // ListBuildsArgs struct with enhanced filtering
 type ListBuildsArgs struct {
-	OrgSlug      string `json:"org_slug"`
-	PipelineSlug string `json:"pipeline_slug,omitempty" jsonschema:"Filter builds by pipeline. When omitted, lists builds across all pipelines in the organization"`
-	Branch       string `json:"branch,omitempty" jsonschema:"Filter builds by git branch name"`
-	State        string `json:"state,omitempty" jsonschema:"Filter builds by state (scheduled, running, passed, failed, canceled, skipped)"`
-	Commit       string `json:"commit,omitempty" jsonschema:"Filter builds by specific commit SHA"`
-	Creator      string `json:"creator,omitempty" jsonschema:"Filter builds by build creator"`
-	Page         int    `json:"page,omitempty" jsonschema:"Page number for pagination (min 1)"`
-	PerPage      int    `json:"per_page,omitempty" jsonschema:"Results per page for pagination (min 1, max 100)"`
+	OrgSlug           string   `json:"org_slug"`
+	PipelineSlug      string   `json:"pipeline_slug,omitempty" jsonschema:"Filter builds by pipeline. When omitted, lists builds across all pipelines in the organization"`
+	Branch            string   `json:"branch,omitempty" jsonschema:"Filter builds by git branch name"`
+	State             string   `json:"state,omitempty" jsonschema:"Filter builds by state (scheduled, running, passed, failed, canceled, skipped)"`
+	Commit            string   `json:"commit,omitempty" jsonschema:"Filter builds by specific commit SHA"`
+	Creator           string   `json:"creator,omitempty" jsonschema:"Filter builds by build creator"`
+	Page              int      `json:"page,omitempty" jsonschema:"Page number for pagination (min 1)"`
+	PerPage           int      `json:"per_page,omitempty" jsonschema:"Results per page for pagination (min 1, max 100)"`
+	AutoPaginate      bool     `json:"auto_paginate,omitempty" jsonschema:"Also fetch the following pages, up to 10 in total, instead of only the requested page. truncated is set when more pages remain"`
+	IncludePagination bool     `json:"include_pagination,omitempty" jsonschema:"Add page, has_more and next_page to the result, so you can tell whether more pages exist"`
+	Fields            []string `json:"fields,omitempty" jsonschema:"Only return these keys of each item to save tokens, with dots for nested keys, e.g. number, state, creator.name"`
+	OutputFormat      string   `json:"output_format,omitempty" jsonschema:"Result format: 'json' (default), 'yaml', 'markdown' (a table), or 'ndjson' (one JSON object per item, a line each, then a _meta object of the other keys such as pagination)"`
+	TimeoutSeconds    int      `json:"timeout_seconds,omitempty" jsonschema:"Give up after this many seconds (default 300, max 1800)"`
 }
 
 // GetBuildArgs struct
@@ -80,6 +95,7 @@ type GetBuildArgs struct {
 	OrgSlug      string `json:"org_slug"`
 	PipelineSlug string `json:"pipeline_slug"`
 	BuildNumber  string `json:"build_number"`
+	OutputFormat string `json:"output_format,omitempty" jsonschema:"Result format: 'json' (default), 'yaml', 'markdown' (a table), or 'ndjson' (one JSON object per item, a line each)"`
 }
 
 // GetBuildTestEngineRunsArgs struct
@@ -113,6 +129,8 @@ func detailBuild(build buildkite.Build, annotations []buildkite.Annotation, anno
 		ScheduledAt:          build.ScheduledAt,
 		StartedAt:            build.StartedAt,
 		FinishedAt:           build.FinishedAt,
+		WaitTimeSeconds:      durationSeconds(build.ScheduledAt, build.StartedAt),
+		RunTimeSeconds:       durationSeconds(build.StartedAt, build.FinishedAt),
 		MetaData:             build.MetaData,
 		Creator:              build.Creator,
 		Source:               build.Source,
@@ -166,6 +184,9 @@ func ListBuilds() (mcp.Tool, mcp.ToolHandlerFor[ListBuildsArgs, any], []string)
 			ctx, span := trace.Start(ctx, "buildkite.ListBuilds")
 			defer span.End()
 
+			ctx, cancel := withCallTimeout(ctx, args.TimeoutSeconds)
+			defer cancel()
+
 			span.SetAttributes(
 				attribute.String("org_slug", args.OrgSlug),
 				attribute.String("pipeline_slug", args.PipelineSlug),
@@ -175,8 +196,13 @@ func ListBuilds() (mcp.Tool, mcp.ToolHandlerFor[ListBuildsArgs, any], []string)
 				attribute.String("creator", args.Creator),
 				attribute.Int("page", args.Page),
 				attribute.Int("per_page", args.PerPage),
+				attribute.Bool("auto_paginate", args.AutoPaginate),
 			)
 
+			if err := requireParams(map[string]string{"org_slug": args.OrgSlug}); err != nil {
+				return utils.NewToolResultError(err.Error()), nil, nil
+			}
+
 			// Set default pagination
 			page := args.Page
 			if page == 0 {
@@ -213,16 +239,15 @@ func ListBuilds() (mcp.Tool, mcp.ToolHandlerFor[ListBuildsArgs, any], []string)
 			}
 
 			deps := DepsFromContext(ctx)
-			var builds []buildkite.Build
-			var resp *buildkite.Response
-			var err error
-			if args.PipelineSlug != "" {
-				builds, resp, err = deps.BuildsClient.ListByPipeline(ctx, args.OrgSlug, args.PipelineSlug, options)
-			} else {
-				builds, resp, err = deps.BuildsClient.ListByOrg(ctx, args.OrgSlug, options)
-			}
+			builds, resp, truncated, err := listPages(page, args.AutoPaginate, func(page int) ([]buildkite.Build, *buildkite.Response, error) {
+				options.Page = page
+				if args.PipelineSlug != "" {
+					return deps.BuildsClient.ListByPipeline(ctx, args.OrgSlug, args.PipelineSlug, options)
+				}
+				return deps.BuildsClient.ListByOrg(ctx, args.OrgSlug, options)
+			})
 			if err != nil {
-				return handleBuildkiteError(err)
+				return handleBuildkiteError(ctx, err)
 			}
 
 			headers := map[string]string{
@@ -230,8 +255,10 @@ func ListBuilds() (mcp.Tool, mcp.ToolHandlerFor[ListBuildsArgs, any], []string)
 			}
 
 			result := createPaginatedBuildResult(builds, summarizeBuild, headers)
+			result.Truncated = truncated
+			result.Pagination = paginationFor(args.IncludePagination, page, resp)
 
-			return mcpTextResult(span, result)
+			return mcpListResult(ctx, span, result, args.Fields, args.OutputFormat, buildColumns)
 		}, []string{"read_builds"}
 }
 
@@ -263,7 +290,7 @@ func GetBuildTestEngineRuns() (mcp.Tool, mcp.ToolHandlerFor[GetBuildTestEngineRu
 				IncludeTestEngine: true,
 			})
 			if err != nil {
-				return handleBuildkiteError(err)
+				return handleBuildkiteError(ctx, err)
 			}
 
 			// Extract just the test engine runs data
@@ -272,14 +299,14 @@ func GetBuildTestEngineRuns() (mcp.Tool, mcp.ToolHandlerFor[GetBuildTestEngineRu
 				testEngineRuns = build.TestEngine.Runs
 			}
 
-			return mcpTextResult(span, &testEngineRuns)
+			return mcpTextResult(ctx, span, &testEngineRuns)
 		}, []string{"read_builds"}
 }
 
 func GetBuild() (mcp.Tool, mcp.ToolHandlerFor[GetBuildArgs, any], []string) {
 	return mcp.Tool{
 			Name:        "get_build",
-			Description: "Get a single build with lightweight annotation summaries. Annotation bodies and jobs are not included — use list_annotations to read annotations, and list_jobs or get_job for job detail",
+			Description: "Get a single build with lightweight annotation summaries, and how long it waited to start and ran for in seconds. Annotation bodies and jobs are not included — use list_annotations to read annotations, and list_jobs or get_job for job detail",
 			Annotations: &mcp.ToolAnnotations{
 				Title:        "Get Build",
 				ReadOnlyHint: true,
@@ -295,61 +322,86 @@ func GetBuild() (mcp.Tool, mcp.ToolHandlerFor[GetBuildArgs, any], []string) {
 				attribute.String("build_number", args.BuildNumber),
 			)
 
-			// Jobs are excluded; use list_jobs/get_job for job detail.
-			options := &buildkite.BuildGetOptions{
-				BuildsListOptions: buildkite.BuildsListOptions{
-					ExcludeJobs:     true,
-					ExcludePipeline: true,
-				},
-				IncludeTestEngine: true,
+			if err := requireParams(map[string]string{
+				"org_slug":      args.OrgSlug,
+				"pipeline_slug": args.PipelineSlug,
+				"build_number":  args.BuildNumber,
+			}); err != nil {
+				return utils.NewToolResultError(err.Error()), nil, nil
 			}
 
-			deps := DepsFromContext(ctx)
-			build, _, err := deps.BuildsClient.Get(ctx, args.OrgSlug, args.PipelineSlug, args.BuildNumber, options)
+			result, err := getBuildDetail(ctx, args.OrgSlug, args.PipelineSlug, args.BuildNumber)
 			if err != nil {
-				return handleBuildkiteError(err)
+				return handleBuildkiteError(ctx, err)
 			}
 
-			annotations, annotationsResponse, err := deps.AnnotationsClient.ListByBuild(ctx, args.OrgSlug, args.PipelineSlug, args.BuildNumber, &buildkite.AnnotationListOptions{
-				ListOptions: buildkite.ListOptions{Page: 1, PerPage: annotationSummaryPageSize},
-				Scope:       "all",
-				OmitBody:    boolPtr(true),
-			})
-			if err != nil {
-				return handleBuildkiteError(err)
-			}
-
-			annotationsTruncated := annotationsResponse != nil && annotationsResponse.NextPage > 0
 			span.SetAttributes(
-				attribute.Int("annotation_count", len(annotations)),
-				attribute.Bool("annotations_truncated", annotationsTruncated),
+				attribute.Int("annotation_count", len(result.Annotations)),
+				attribute.Bool("annotations_truncated", result.AnnotationsTruncated),
 			)
 
-			result := detailBuild(build, annotations, annotationsTruncated)
-			return mcpTextResult(span, &result)
+			return mcpStructuredResult(ctx, span, &result, args.OutputFormat, buildColumns)
 		}, []string{"read_builds"}
 }
 
+// getBuildDetail fetches a build with lightweight annotation summaries, as
+// get_build and build resources return it.
+func getBuildDetail(ctx context.Context, org, pipelineSlug, buildNumber string) (BuildDetail, error) {
+	// Jobs are excluded; use list_jobs/get_job for job detail.
+	options := &buildkite.BuildGetOptions{
+		BuildsListOptions: buildkite.BuildsListOptions{
+			ExcludeJobs:     true,
+			ExcludePipeline: true,
+		},
+		IncludeTestEngine: true,
+	}
+
+	deps := DepsFromContext(ctx)
+	build, _, err := deps.BuildsClient.Get(ctx, org, pipelineSlug, buildNumber, options)
+	if err != nil {
+		return BuildDetail{}, err
+	}
+
+	annotations, annotationsResponse, err := deps.AnnotationsClient.ListByBuild(ctx, org, pipelineSlug, buildNumber, &buildkite.AnnotationListOptions{
+		ListOptions: buildkite.ListOptions{Page: 1, PerPage: annotationSummaryPageSize},
+		Scope:       "all",
+		OmitBody:    boolPtr(true),
+	})
+	if err != nil {
+		return BuildDetail{}, err
+	}
+
+	annotationsTruncated := annotationsResponse != nil && annotationsResponse.NextPage > 0
+	return detailBuild(build, annotations, annotationsTruncated), nil
+}
+
 type Entry struct {
 	Key   string `json:"key"`
 	Value string `json:"value"`
 }
 
 type CreateBuildArgs struct {
-	OrgSlug             string  `json:"org_slug"`
-	PipelineSlug        string  `json:"pipeline_slug"`
-	Commit              string  `json:"commit" jsonschema:"The commit SHA to build"`
-	Branch              string  `json:"branch"`
-	Message             string  `json:"message"`
-	IgnoreBranchFilters bool    `json:"ignore_branch_filters,omitempty" jsonschema:"Whether to ignore branch filters when triggering the build"`
-	Environment         []Entry `json:"environment,omitempty" jsonschema:"Environment variables to set for the build"`
-	MetaData            []Entry `json:"metadata,omitempty" jsonschema:"Meta-data values to set for the build"`
+	OrgSlug               string            `json:"org_slug"`
+	PipelineSlug          string            `json:"pipeline_slug"`
+	Commit                string            `json:"commit" jsonschema:"The commit SHA to build"`
+	Branch                string            `json:"branch,omitempty" jsonschema:"The branch to build. Defaults to the pipeline's default branch"`
+	Message               string            `json:"message"`
+	IgnoreBranchFilters   bool              `json:"ignore_branch_filters,omitempty" jsonschema:"Whether to ignore branch filters when triggering the build"`
+	Environment           []Entry           `json:"environment,omitempty" jsonschema:"Environment variables to set for the build"`
+	MetaData              []Entry           `json:"metadata,omitempty" jsonschema:"Meta-data values to set for the build"`
+	Env                   map[string]string `json:"env,omitempty" jsonschema:"Environment variables to set for the build, as a map of name to value. Merged with environment"`
+	MetaDataMap           map[string]string `json:"meta_data,omitempty" jsonschema:"Meta-data values to set for the build, as a map of key to value. Merged with metadata"`
+	PullRequestID         int64             `json:"pull_request_id,omitempty" jsonschema:"Number of the pull request to associate the build with. Set commit and branch to the pull request's head commit and branch"`
+	PullRequestBaseBranch string            `json:"pull_request_base_branch,omitempty" jsonschema:"Branch the pull request merges into. Requires pull_request_id"`
+	PullRequestRepository string            `json:"pull_request_repository,omitempty" jsonschema:"Repository the pull request comes from, when it is a fork. Requires pull_request_id"`
+	IdempotencyKey        string            `json:"idempotency_key,omitempty" jsonschema:"A unique key for this build request. Retrying with the same key within 10 minutes returns the build already created instead of creating another"`
+	DryRun                bool              `json:"dry_run,omitempty" jsonschema:"Validate the arguments and return the request this would make, without making it"`
 }
 
 func CreateBuild() (mcp.Tool, mcp.ToolHandlerFor[CreateBuildArgs, any], []string) {
 	return mcp.Tool{
 			Name:        "create_build",
-			Description: "Trigger a new build on a Buildkite pipeline for a specific commit and branch, with optional environment variables, metadata, and author information",
+			Description: "Trigger a new build on a Buildkite pipeline for a specific commit and branch, with optional environment variables, metadata, and author information. Without a branch, the pipeline's default branch is built",
 			Annotations: &mcp.ToolAnnotations{
 				Title:           "Create Build",
 				DestructiveHint: boolPtr(false),
@@ -359,28 +411,103 @@ func CreateBuild() (mcp.Tool, mcp.ToolHandlerFor[CreateBuildArgs, any], []string
 			ctx, span := trace.Start(ctx, "buildkite.CreateBuild")
 			defer span.End()
 
+			if err := requireParams(map[string]string{
+				"org_slug":      args.OrgSlug,
+				"pipeline_slug": args.PipelineSlug,
+				"commit":        args.Commit,
+			}); err != nil {
+				return utils.NewToolResultError(err.Error()), nil, nil
+			}
+
+			if err := validatePullRequestArgs(args); err != nil {
+				return utils.NewToolResultError(err.Error()), nil, nil
+			}
+
+			env, err := mergeEntries("env", "environment", args.Env, args.Environment)
+			if err != nil {
+				return utils.NewToolResultError(err.Error()), nil, nil
+			}
+			metaData, err := mergeEntries("meta_data", "metadata", args.MetaDataMap, args.MetaData)
+			if err != nil {
+				return utils.NewToolResultError(err.Error()), nil, nil
+			}
+
+			// A dry run leaves the branch out rather than reading the pipeline.
+			branch := args.Branch
+			if branch == "" && !args.DryRun {
+				defaultBranch, err := pipelineDefaultBranch(ctx, args.OrgSlug, args.PipelineSlug)
+				if err != nil {
+					// Only this lookup needs read_pipelines, so a 403 names it
+					// instead of the tool's write_builds.
+					return handleBuildkiteError(ContextWithRequiredScopes(ctx, []string{"read_pipelines"}), err)
+				}
+				if defaultBranch == "" {
+					return utils.NewToolResultError(fmt.Sprintf("pipeline %q has no default branch, pass branch", args.PipelineSlug)), nil, nil
+				}
+				branch = defaultBranch
+				span.SetAttributes(attribute.String("default_branch", branch))
+			}
+
 			createBuild := buildkite.CreateBuild{
 				Commit:                      args.Commit,
-				Branch:                      args.Branch,
+				Branch:                      branch,
 				Message:                     args.Message,
-				Env:                         convertEntries(args.Environment),
-				MetaData:                    convertEntries(args.MetaData),
+				Env:                         env,
+				MetaData:                    metaData,
 				IgnorePipelineBranchFilters: args.IgnoreBranchFilters,
+				PullRequestID:               args.PullRequestID,
+				PullRequestBaseBranch:       args.PullRequestBaseBranch,
+				PullRequestRepository:       args.PullRequestRepository,
 			}
 
 			span.SetAttributes(
 				attribute.String("org", args.OrgSlug),
 				attribute.String("pipeline_slug", args.PipelineSlug),
 				attribute.Bool("ignore_branch_filters", args.IgnoreBranchFilters),
+				attribute.Bool("idempotent", args.IdempotencyKey != ""),
+				attribute.Int64("pull_request_id", args.PullRequestID),
 			)
 
+			if args.DryRun {
+				action := "Create a build"
+				if branch == "" {
+					action = "Create a build on the pipeline's default branch, which is looked up when the call is made"
+				}
+				return mcpDryRunResult(ctx, span, action, http.MethodPost, apiPath("v2/organizations/%s/pipelines/%s/builds", args.OrgSlug, args.PipelineSlug), createBuild)
+			}
+
 			deps := DepsFromContext(ctx)
-			build, _, err := deps.BuildsClient.Create(ctx, args.OrgSlug, args.PipelineSlug, createBuild)
+			create := func() (buildkite.Build, error) {
+				build, _, err := deps.BuildsClient.Create(ctx, args.OrgSlug, args.PipelineSlug, createBuild)
+				return build, err
+			}
+
+			var (
+				build    buildkite.Build
+				replayed bool
+			)
+			if args.IdempotencyKey != "" {
+				if deps.IdempotentBuilds == nil {
+					return utils.NewToolResultError("idempotency_key is not supported by this server, as callers don't share credentials"), nil, nil
+				}
+				request, hashErr := createBuildRequestHash(createBuild)
+				if hashErr != nil {
+					return nil, nil, fmt.Errorf("failed to hash build request: %w", hashErr)
+				}
+				key := createBuildIdempotencyKey(args.OrgSlug, args.PipelineSlug, args.IdempotencyKey)
+				build, replayed, err = deps.IdempotentBuilds.create(ctx, key, request, create)
+				if errors.Is(err, errIdempotencyKeyReused) {
+					return utils.NewToolResultError(fmt.Sprintf("idempotency_key %q was already used with different arguments; use a new key for a different build", args.IdempotencyKey)), nil, nil
+				}
+			} else {
+				build, err = create()
+			}
 			if err != nil {
-				return handleBuildkiteError(err)
+				return handleBuildkiteError(ctx, err)
 			}
 
-			return mcpTextResult(span, &build)
+			span.SetAttributes(attribute.Bool("idempotent_replay", replayed))
+			return mcpTextResult(ctx, span, &build)
 		}, []string{"write_builds"}
 }
 
@@ -388,6 +515,7 @@ type CancelBuildArgs struct {
 	OrgSlug      string `json:"org_slug"`
 	PipelineSlug string `json:"pipeline_slug"`
 	BuildNumber  string `json:"build_number"`
+	DryRun       bool   `json:"dry_run,omitempty" jsonschema:"Validate the arguments and return the request this would make, without making it"`
 }
 
 func CancelBuild() (mcp.Tool, mcp.ToolHandlerFor[CancelBuildArgs, any], []string) {
@@ -409,20 +537,157 @@ func CancelBuild() (mcp.Tool, mcp.ToolHandlerFor[CancelBuildArgs, any], []string
 				attribute.String("build_number", args.BuildNumber),
 			)
 
+			if err := requireParams(map[string]string{
+				"org_slug":      args.OrgSlug,
+				"pipeline_slug": args.PipelineSlug,
+				"build_number":  args.BuildNumber,
+			}); err != nil {
+				return utils.NewToolResultError(err.Error()), nil, nil
+			}
+
+			if args.DryRun {
+				return mcpDryRunResult(ctx, span, "Cancel a build", http.MethodPut, apiPath("v2/organizations/%s/pipelines/%s/builds/%s/cancel", args.OrgSlug, args.PipelineSlug, args.BuildNumber), nil)
+			}
+
 			deps := DepsFromContext(ctx)
 			build, err := deps.BuildsClient.Cancel(ctx, args.OrgSlug, args.PipelineSlug, args.BuildNumber)
 			if err != nil {
-				return handleBuildkiteError(err)
+				return handleBuildkiteError(ctx, err)
 			}
 
-			return mcpTextResult(span, &build)
+			return mcpTextResult(ctx, span, &build)
 		}, []string{"write_builds"}
 }
 
+type CancelRunningBuildsArgs struct {
+	OrgSlug      string `json:"org_slug"`
+	PipelineSlug string `json:"pipeline_slug"`
+	Confirm      bool   `json:"confirm" jsonschema:"Must be true to cancel the builds. Confirm with the user first, as every running build of the pipeline is canceled"`
+	DryRun       bool   `json:"dry_run,omitempty" jsonschema:"Find the running builds and return the requests this would make, without making them. Doesn't need confirm"`
+}
+
+// Outcomes of canceling a build with cancel_running_builds.
+const (
+	cancelStatusCanceled = "canceled"
+	cancelStatusFailed   = "failed"
+)
+
+// CanceledBuild is the outcome of canceling one running build.
+type CanceledBuild struct {
+	BuildNumber int    `json:"build_number"`
+	Branch      string `json:"branch"`
+	WebURL      string `json:"web_url"`
+	Status      string `json:"status"`
+	State       string `json:"state,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+// CancelRunningBuildsResult lists the outcome for each running build of a
+// pipeline, with a count of each outcome. Truncated is set when there were
+// more running builds than were read, which are left running.
+type CancelRunningBuildsResult struct {
+	Builds    []CanceledBuild `json:"builds"`
+	Canceled  int             `json:"canceled"`
+	Failed    int             `json:"failed"`
+	Truncated bool            `json:"truncated,omitempty"`
+}
+
+func CancelRunningBuilds() (mcp.Tool, mcp.ToolHandlerFor[CancelRunningBuildsArgs, any], []string) {
+	return mcp.Tool{
+			Name:        "cancel_running_builds",
+			Description: "Cancel every running or scheduled build of a Buildkite pipeline, and report the outcome for each build. Requires confirm to be true; use dry_run to see which builds would be canceled. Failures are reported per build rather than failing the call",
+			Annotations: &mcp.ToolAnnotations{
+				Title:           "Cancel Running Builds",
+				DestructiveHint: boolPtr(true),
+			},
+		},
+		func(ctx context.Context, request *mcp.CallToolRequest, args CancelRunningBuildsArgs) (*mcp.CallToolResult, any, error) {
+			ctx, span := trace.Start(ctx, "buildkite.CancelRunningBuilds")
+			defer span.End()
+
+			span.SetAttributes(
+				attribute.String("org_slug", args.OrgSlug),
+				attribute.String("pipeline_slug", args.PipelineSlug),
+				attribute.Bool("confirm", args.Confirm),
+			)
+
+			if err := requireParams(map[string]string{
+				"org_slug":      args.OrgSlug,
+				"pipeline_slug": args.PipelineSlug,
+			}); err != nil {
+				return utils.NewToolResultError(err.Error()), nil, nil
+			}
+			if !args.Confirm && !args.DryRun {
+				return utils.NewToolResultError("confirm must be true to cancel every running build of the pipeline. Use dry_run to list the builds that would be canceled"), nil, nil
+			}
+
+			deps := DepsFromContext(ctx)
+			options := &buildkite.BuildsListOptions{
+				State:       []string{"running", "scheduled"},
+				ExcludeJobs: true,
+			}
+			builds, _, truncated, err := listPages(1, true, func(page int) ([]buildkite.Build, *buildkite.Response, error) {
+				options.ListOptions = paginationFromArgs(page, 0)
+				return deps.BuildsClient.ListByPipeline(ctx, args.OrgSlug, args.PipelineSlug, options)
+			})
+			if err != nil {
+				return handleBuildkiteError(ctx, err)
+			}
+			span.SetAttributes(
+				attribute.Int("running_count", len(builds)),
+				attribute.Bool("truncated", truncated),
+			)
+
+			if args.DryRun {
+				// A dry run still lists the builds, to tell which it would cancel.
+				span.SetAttributes(attribute.Bool("dry_run", true))
+				requests := make([]DryRunResult, len(builds))
+				for i, build := range builds {
+					requests[i] = DryRunResult{
+						DryRun: true,
+						Action: fmt.Sprintf("Cancel build %d", build.Number),
+						Method: http.MethodPut,
+						Path:   apiPath("v2/organizations/%s/pipelines/%s/builds/%s/cancel", args.OrgSlug, args.PipelineSlug, strconv.Itoa(build.Number)),
+					}
+				}
+				return mcpTextResult(ctx, span, requests)
+			}
+
+			// Every build is attempted even after a failure, so one build that
+			// can't be canceled doesn't leave the others running.
+			result := CancelRunningBuildsResult{Builds: make([]CanceledBuild, 0, len(builds)), Truncated: truncated}
+			for _, build := range builds {
+				outcome := CanceledBuild{BuildNumber: build.Number, Branch: build.Branch, WebURL: build.WebURL}
+				canceled, err := deps.BuildsClient.Cancel(ctx, args.OrgSlug, args.PipelineSlug, strconv.Itoa(build.Number))
+				switch {
+				case isBuildkiteUnauthorized(err):
+					return nil, nil, ErrUnauthorized
+				case err != nil:
+					outcome.Status = cancelStatusFailed
+					outcome.Error, _ = buildkiteErrorMessage(err)
+					result.Failed++
+				default:
+					outcome.Status = cancelStatusCanceled
+					outcome.State = canceled.State
+					result.Canceled++
+				}
+				result.Builds = append(result.Builds, outcome)
+			}
+
+			span.SetAttributes(
+				attribute.Int("canceled_count", result.Canceled),
+				attribute.Int("failed_count", result.Failed),
+			)
+
+			return mcpTextResult(ctx, span, &result)
+		}, []string{"write_builds", "read_builds"}
+}
+
 type RebuildBuildArgs struct {
 	OrgSlug      string `json:"org_slug"`
 	PipelineSlug string `json:"pipeline_slug"`
 	BuildNumber  string `json:"build_number"`
+	DryRun       bool   `json:"dry_run,omitempty" jsonschema:"Validate the arguments and return the request this would make, without making it"`
 }
 
 func RebuildBuild() (mcp.Tool, mcp.ToolHandlerFor[RebuildBuildArgs, any], []string) {
@@ -444,16 +709,81 @@ func RebuildBuild() (mcp.Tool, mcp.ToolHandlerFor[RebuildBuildArgs, any], []stri
 				attribute.String("build_number", args.BuildNumber),
 			)
 
+			if err := requireParams(map[string]string{
+				"org_slug":      args.OrgSlug,
+				"pipeline_slug": args.PipelineSlug,
+				"build_number":  args.BuildNumber,
+			}); err != nil {
+				return utils.NewToolResultError(err.Error()), nil, nil
+			}
+
+			if args.DryRun {
+				return mcpDryRunResult(ctx, span, "Rebuild a build", http.MethodPut, apiPath("v2/organizations/%s/pipelines/%s/builds/%s/rebuild", args.OrgSlug, args.PipelineSlug, args.BuildNumber), nil)
+			}
+
 			deps := DepsFromContext(ctx)
 			build, err := deps.BuildsClient.Rebuild(ctx, args.OrgSlug, args.PipelineSlug, args.BuildNumber)
 			if err != nil {
-				return handleBuildkiteError(err)
+				return handleBuildkiteError(ctx, err)
 			}
 
-			return mcpTextResult(span, &build)
+			return mcpTextResult(ctx, span, &build)
 		}, []string{"write_builds"}
 }
 
+// validatePullRequestArgs checks the pull request parameters of create_build
+// go together. Buildkite builds the commit and branch given, so for a pull
+// request those must be its head rather than the base branch.
+func validatePullRequestArgs(args CreateBuildArgs) error {
+	if args.PullRequestID < 0 {
+		return fmt.Errorf("pull_request_id must be a positive pull request number, got %d", args.PullRequestID)
+	}
+	if args.PullRequestID == 0 {
+		var set []string
+		if args.PullRequestBaseBranch != "" {
+			set = append(set, "pull_request_base_branch")
+		}
+		if args.PullRequestRepository != "" {
+			set = append(set, "pull_request_repository")
+		}
+		if len(set) > 0 {
+			return fmt.Errorf("%s requires pull_request_id, the number of the pull request to build", strings.Join(set, " and "))
+		}
+		return nil
+	}
+	if err := requireParams(map[string]string{"commit": args.Commit, "branch": args.Branch}); err != nil {
+		return fmt.Errorf("%w: a pull request build needs the pull request's head commit and branch", err)
+	}
+	if args.PullRequestBaseBranch != "" && args.PullRequestBaseBranch == args.Branch {
+		return fmt.Errorf("branch is the pull request's base branch %q; set branch to the pull request's head branch", args.Branch)
+	}
+	return nil
+}
+
+// mergeEntries combines the map and list forms of create_build's env and
+// meta-data parameters. A key may appear in both only with the same value.
+func mergeEntries(mapParam, listParam string, values map[string]string, entries []Entry) (map[string]string, error) {
+	merged := convertEntries(entries)
+	if merged == nil && len(values) > 0 {
+		merged = make(map[string]string, len(values))
+	}
+	for key, value := range merged {
+		if strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("%s has an entry with an empty key", listParam)
+		}
+		if other, ok := values[key]; ok && other != value {
+			return nil, fmt.Errorf("%q is set to different values in %s and %s", key, mapParam, listParam)
+		}
+	}
+	for key, value := range values {
+		if strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("%s has an empty key", mapParam)
+		}
+		merged[key] = value
+	}
+	return merged, nil
+}
+
 func convertEntries(entries []Entry) map[string]string {
 	if entries == nil {
 		return nil
@@ -2,11 +2,15 @@ package buildkite
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/buildkite/go-buildkite/v5"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/stretchr/testify/require"
 )
 
@@ -297,6 +301,111 @@ func TestListBuilds(t *testing.T) {
 		assert.Nil(capturedOptions.Branch)
 	})
 
+	t.Run("AutoPaginate", func(t *testing.T) {
+		assert := require.New(t)
+
+		var pages []int
+		client := &MockBuildsClient{
+			ListByOrgFunc: func(ctx context.Context, org string, opt *buildkite.BuildsListOptions) ([]buildkite.Build, *buildkite.Response, error) {
+				pages = append(pages, opt.Page)
+				resp := &buildkite.Response{Response: &http.Response{StatusCode: 200}}
+				if opt.Page < 3 {
+					resp.NextPage = opt.Page + 1
+				}
+				return []buildkite.Build{{ID: fmt.Sprintf("build-%d", opt.Page), Number: opt.Page}}, resp, nil
+			},
+		}
+
+		ctx := ContextWithDeps(context.Background(), ToolDependencies{BuildsClient: client})
+		_, handler, _ := ListBuilds()
+
+		result, _, err := handler(ctx, createMCPRequest(t, map[string]any{}), ListBuildsArgs{
+			OrgSlug:      "org",
+			AutoPaginate: true,
+		})
+		assert.NoError(err)
+		assert.Equal([]int{1, 2, 3}, pages)
+
+		var got PaginatedResult[BuildSummary]
+		assert.NoError(json.Unmarshal([]byte(getTextResult(t, result).Text), &got))
+		assert.Len(got.Items, 3)
+		assert.Equal("build-3", got.Items[2].ID)
+		assert.False(got.Truncated)
+	})
+
+	t.Run("IncludePagination", func(t *testing.T) {
+		assert := require.New(t)
+
+		client := &MockBuildsClient{
+			ListByPipelineFunc: func(ctx context.Context, org string, pipeline string, opt *buildkite.BuildsListOptions) ([]buildkite.Build, *buildkite.Response, error) {
+				return []buildkite.Build{{ID: "123", Number: 1}}, &buildkite.Response{
+					Response: &http.Response{StatusCode: 200},
+					NextPage: 3,
+				}, nil
+			},
+		}
+
+		ctx := ContextWithDeps(context.Background(), ToolDependencies{BuildsClient: client})
+		_, handler, _ := ListBuilds()
+
+		result, _, err := handler(ctx, createMCPRequest(t, map[string]any{}), ListBuildsArgs{
+			OrgSlug:      "org",
+			PipelineSlug: "pipeline",
+			Page:         2,
+		})
+		assert.NoError(err)
+		assert.NotContains(getTextResult(t, result).Text, `"has_more"`)
+
+		result, _, err = handler(ctx, createMCPRequest(t, map[string]any{}), ListBuildsArgs{
+			OrgSlug:           "org",
+			PipelineSlug:      "pipeline",
+			Page:              2,
+			IncludePagination: true,
+		})
+		assert.NoError(err)
+
+		var got map[string]any
+		assert.NoError(json.Unmarshal([]byte(getTextResult(t, result).Text), &got))
+		assert.Equal(float64(2), got["page"])
+		assert.Equal(true, got["has_more"])
+		assert.Equal(float64(3), got["next_page"])
+		assert.Len(got["items"], 1)
+	})
+
+	t.Run("Fields", func(t *testing.T) {
+		assert := require.New(t)
+
+		client := &MockBuildsClient{
+			ListByPipelineFunc: func(ctx context.Context, org string, pipeline string, opt *buildkite.BuildsListOptions) ([]buildkite.Build, *buildkite.Response, error) {
+				return []buildkite.Build{
+					{ID: "123", Number: 1, State: "passed", Message: "first"},
+					{ID: "456", Number: 2, State: "failed"},
+				}, &buildkite.Response{
+					Response: &http.Response{StatusCode: 200},
+				}, nil
+			},
+		}
+
+		ctx := ContextWithDeps(context.Background(), ToolDependencies{BuildsClient: client})
+		_, handler, _ := ListBuilds()
+
+		result, _, err := handler(ctx, createMCPRequest(t, map[string]any{}), ListBuildsArgs{
+			OrgSlug:      "org",
+			PipelineSlug: "pipeline",
+			Fields:       []string{"number", "state"},
+		})
+		assert.NoError(err)
+
+		var got struct {
+			Items []map[string]any `json:"items"`
+		}
+		assert.NoError(json.Unmarshal([]byte(getTextResult(t, result).Text), &got))
+		assert.Len(got.Items, 2)
+		assert.Equal(map[string]any{"number": float64(1), "state": "passed"}, got.Items[0])
+		assert.Equal(float64(2), got.Items[1]["number"])
+		assert.NotContains(got.Items[1], "id")
+	})
+
 	t.Run("CustomPaginationAndFilters", func(t *testing.T) {
 		assert := require.New(t)
 
@@ -617,6 +726,88 @@ func TestCancelBuild(t *testing.T) {
 	})
 }
 
+func TestCancelRunningBuilds(t *testing.T) {
+	runningBuilds := &MockBuildsClient{
+		ListByPipelineFunc: func(ctx context.Context, org, pipelineSlug string, options *buildkite.BuildsListOptions) ([]buildkite.Build, *buildkite.Response, error) {
+			require.Equal(t, []string{"running", "scheduled"}, options.State)
+			return []buildkite.Build{
+				{Number: 41, State: "running", Branch: "main"},
+				{Number: 42, State: "scheduled", Branch: "feature"},
+			}, &buildkite.Response{}, nil
+		},
+	}
+
+	callTool := func(t *testing.T, client *MockBuildsClient, args CancelRunningBuildsArgs) *mcp.CallToolResult {
+		t.Helper()
+		ctx := ContextWithDeps(context.Background(), ToolDependencies{BuildsClient: client})
+		_, handler, _ := CancelRunningBuilds()
+
+		args.OrgSlug, args.PipelineSlug = "test-org", "test-pipeline"
+		result, _, err := handler(ctx, createMCPRequest(t, map[string]any{}), args)
+		require.NoError(t, err)
+		return result
+	}
+
+	t.Run("ToolDefinition", func(t *testing.T) {
+		tool, _, scopes := CancelRunningBuilds()
+		require.Equal(t, "cancel_running_builds", tool.Name)
+		require.False(t, tool.Annotations.ReadOnlyHint)
+		require.True(t, *tool.Annotations.DestructiveHint)
+		require.Equal(t, []string{"write_builds", "read_builds"}, scopes)
+	})
+
+	t.Run("RequiresConfirm", func(t *testing.T) {
+		client := &MockBuildsClient{
+			ListByPipelineFunc: func(ctx context.Context, org, pipelineSlug string, options *buildkite.BuildsListOptions) ([]buildkite.Build, *buildkite.Response, error) {
+				t.Fatal("builds listed without confirm")
+				return nil, nil, nil
+			},
+		}
+
+		result := callTool(t, client, CancelRunningBuildsArgs{})
+		require.True(t, result.IsError)
+		require.Contains(t, getTextResult(t, result).Text, "confirm must be true")
+	})
+
+	t.Run("CancelsEveryBuild", func(t *testing.T) {
+		client := *runningBuilds
+		client.CancelFunc = func(ctx context.Context, org, pipeline, buildNumber string) (buildkite.Build, error) {
+			if buildNumber == "41" {
+				return buildkite.Build{}, errors.New("build can't be canceled")
+			}
+			return buildkite.Build{Number: 42, State: "canceled"}, nil
+		}
+
+		result := callTool(t, &client, CancelRunningBuildsArgs{Confirm: true})
+		require.False(t, result.IsError)
+
+		var canceled CancelRunningBuildsResult
+		require.NoError(t, json.Unmarshal([]byte(getTextResult(t, result).Text), &canceled))
+		require.Equal(t, 1, canceled.Canceled)
+		require.Equal(t, 1, canceled.Failed)
+		require.Equal(t, []CanceledBuild{
+			{BuildNumber: 41, Branch: "main", Status: "failed", Error: "build can't be canceled"},
+			{BuildNumber: 42, Branch: "feature", Status: "canceled", State: "canceled"},
+		}, canceled.Builds)
+	})
+
+	t.Run("DryRun", func(t *testing.T) {
+		client := *runningBuilds
+		client.CancelFunc = func(ctx context.Context, org, pipeline, buildNumber string) (buildkite.Build, error) {
+			t.Fatal("build canceled in a dry run")
+			return buildkite.Build{}, nil
+		}
+
+		result := callTool(t, &client, CancelRunningBuildsArgs{DryRun: true})
+		require.False(t, result.IsError)
+
+		var requests []DryRunResult
+		require.NoError(t, json.Unmarshal([]byte(getTextResult(t, result).Text), &requests))
+		require.Len(t, requests, 2)
+		require.Equal(t, "v2/organizations/test-org/pipelines/test-pipeline/builds/42/cancel", requests[1].Path)
+	})
+}
+
 func TestRebuildBuild(t *testing.T) {
 	t.Run("ToolDefinition", func(t *testing.T) {
 		tool, _, _ := RebuildBuild()
@@ -680,3 +871,273 @@ func TestRebuildBuild(t *testing.T) {
 		assert.Contains(textContent.Text, "build not found")
 	})
 }
+
+func TestCreateBuildEnvAndMetaData(t *testing.T) {
+	t.Run("ForwardsMaps", func(t *testing.T) {
+		assert := require.New(t)
+
+		client := &MockBuildsClient{
+			CreateFunc: func(ctx context.Context, org string, pipeline string, b buildkite.CreateBuild) (buildkite.Build, *buildkite.Response, error) {
+				assert.Equal(map[string]string{"DEPLOY_ENV": "staging", "REGION": "us-east-1"}, b.Env)
+				assert.Equal(map[string]string{"release": "v1.2.3", "ticket": "OPS-42"}, b.MetaData)
+				return buildkite.Build{ID: "123"}, &buildkite.Response{}, nil
+			},
+		}
+
+		ctx := ContextWithDeps(context.Background(), ToolDependencies{BuildsClient: client})
+		_, handler, _ := CreateBuild()
+
+		result, _, err := handler(ctx, createMCPRequest(t, map[string]any{}), CreateBuildArgs{
+			OrgSlug:      "org",
+			PipelineSlug: "pipeline",
+			Commit:       "HEAD",
+			Branch:       "main",
+			Message:      "Deploy",
+			Env:          map[string]string{"DEPLOY_ENV": "staging"},
+			Environment:  []Entry{{Key: "REGION", Value: "us-east-1"}},
+			MetaDataMap:  map[string]string{"release": "v1.2.3", "ticket": "OPS-42"},
+		})
+		assert.NoError(err)
+		assert.False(result.IsError)
+	})
+
+	t.Run("ConflictingValues", func(t *testing.T) {
+		assert := require.New(t)
+
+		ctx := ContextWithDeps(context.Background(), ToolDependencies{BuildsClient: &MockBuildsClient{}})
+		_, handler, _ := CreateBuild()
+
+		result, _, err := handler(ctx, createMCPRequest(t, map[string]any{}), CreateBuildArgs{
+			OrgSlug:      "org",
+			PipelineSlug: "pipeline",
+			Commit:       "HEAD",
+			Branch:       "main",
+			Message:      "Deploy",
+			Env:          map[string]string{"DEPLOY_ENV": "staging"},
+			Environment:  []Entry{{Key: "DEPLOY_ENV", Value: "production"}},
+		})
+		assert.NoError(err)
+		assert.True(result.IsError)
+		assert.Contains(getTextResult(t, result).Text, `"DEPLOY_ENV" is set to different values in env and environment`)
+	})
+
+	t.Run("EmptyKey", func(t *testing.T) {
+		_, err := mergeEntries("meta_data", "metadata", map[string]string{" ": "x"}, nil)
+		require.EqualError(t, err, "meta_data has an empty key")
+	})
+}
+
+func TestCreateBuildPullRequest(t *testing.T) {
+	t.Run("ForwardsPullRequest", func(t *testing.T) {
+		assert := require.New(t)
+
+		client := &MockBuildsClient{
+			CreateFunc: func(ctx context.Context, org string, pipeline string, b buildkite.CreateBuild) (buildkite.Build, *buildkite.Response, error) {
+				assert.Equal(int64(42), b.PullRequestID)
+				assert.Equal("main", b.PullRequestBaseBranch)
+				assert.Equal("git@github.com:someone/fork.git", b.PullRequestRepository)
+				assert.Equal("feature", b.Branch)
+				return buildkite.Build{ID: "123"}, &buildkite.Response{}, nil
+			},
+		}
+
+		ctx := ContextWithDeps(context.Background(), ToolDependencies{BuildsClient: client})
+		_, handler, _ := CreateBuild()
+
+		result, _, err := handler(ctx, createMCPRequest(t, map[string]any{}), CreateBuildArgs{
+			OrgSlug:               "org",
+			PipelineSlug:          "pipeline",
+			Commit:                "abc123",
+			Branch:                "feature",
+			Message:               "Build PR #42",
+			PullRequestID:         42,
+			PullRequestBaseBranch: "main",
+			PullRequestRepository: "git@github.com:someone/fork.git",
+		})
+		assert.NoError(err)
+		assert.False(result.IsError)
+	})
+
+	tests := []struct {
+		name string
+		args CreateBuildArgs
+		want string
+	}{
+		{
+			name: "BaseBranchWithoutID",
+			args: CreateBuildArgs{Commit: "abc123", Branch: "feature", PullRequestBaseBranch: "main"},
+			want: "pull_request_base_branch requires pull_request_id, the number of the pull request to build",
+		},
+		{
+			name: "NegativeID",
+			args: CreateBuildArgs{Commit: "abc123", Branch: "feature", PullRequestID: -1},
+			want: "pull_request_id must be a positive pull request number, got -1",
+		},
+		{
+			name: "MissingHead",
+			args: CreateBuildArgs{PullRequestID: 42},
+			want: "missing required parameters: branch, commit: a pull request build needs the pull request's head commit and branch",
+		},
+		{
+			name: "BranchIsBase",
+			args: CreateBuildArgs{Commit: "abc123", Branch: "main", PullRequestID: 42, PullRequestBaseBranch: "main"},
+			want: `branch is the pull request's base branch "main"; set branch to the pull request's head branch`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.EqualError(t, validatePullRequestArgs(tt.args), tt.want)
+		})
+	}
+}
+
+func TestDetailBuildTimings(t *testing.T) {
+	assert := require.New(t)
+
+	detail := detailBuild(buildkite.Build{
+		ScheduledAt: testTimestamp(0),
+		StartedAt:   testTimestamp(45 * time.Second),
+		FinishedAt:  testTimestamp(10*time.Minute + 45*time.Second),
+	}, nil, false)
+	require.NotNil(t, detail.WaitTimeSeconds)
+	require.NotNil(t, detail.RunTimeSeconds)
+	assert.Equal(int64(45), *detail.WaitTimeSeconds)
+	assert.Equal(int64(600), *detail.RunTimeSeconds)
+	assert.NotNil(detail.StartedAt)
+
+	// A running build has waited but not finished.
+	detail = detailBuild(buildkite.Build{
+		ScheduledAt: testTimestamp(0),
+		StartedAt:   testTimestamp(time.Minute),
+	}, nil, false)
+	assert.Equal(int64(60), *detail.WaitTimeSeconds)
+	assert.Nil(detail.RunTimeSeconds)
+}
+
+func TestCreateBuildDefaultBranch(t *testing.T) {
+	t.Run("UsesPipelineDefaultBranch", func(t *testing.T) {
+		assert := require.New(t)
+
+		lookups := 0
+		pipelines := &MockPipelinesClient{
+			GetFunc: func(ctx context.Context, org string, pipeline string) (buildkite.Pipeline, *buildkite.Response, error) {
+				lookups++
+				assert.Equal("org", org)
+				assert.Equal("pipeline", pipeline)
+				return buildkite.Pipeline{DefaultBranch: "trunk"}, nil, nil
+			},
+		}
+		client := &MockBuildsClient{
+			CreateFunc: func(ctx context.Context, org string, pipeline string, b buildkite.CreateBuild) (buildkite.Build, *buildkite.Response, error) {
+				assert.Equal("trunk", b.Branch)
+				return buildkite.Build{ID: "123", Branch: b.Branch}, &buildkite.Response{}, nil
+			},
+		}
+
+		ctx := ContextWithDeps(context.Background(), ToolDependencies{BuildsClient: client, PipelinesClient: pipelines})
+		_, handler, _ := CreateBuild()
+
+		result, _, err := handler(ctx, createMCPRequest(t, map[string]any{}), CreateBuildArgs{
+			OrgSlug:      "org",
+			PipelineSlug: "pipeline",
+			Commit:       "HEAD",
+			Message:      "Build the default branch",
+		})
+		assert.NoError(err)
+		assert.False(result.IsError, getTextResult(t, result).Text)
+		assert.Equal(1, lookups)
+
+		// Dry runs don't read the pipeline.
+		result, _, err = handler(ctx, createMCPRequest(t, map[string]any{}), CreateBuildArgs{
+			OrgSlug:      "org",
+			PipelineSlug: "pipeline",
+			Commit:       "HEAD",
+			DryRun:       true,
+		})
+		assert.NoError(err)
+		assert.Equal(1, lookups)
+		assert.Contains(getTextResult(t, result).Text, "default branch, which is looked up when the call is made")
+		assert.Contains(getTextResult(t, result).Text, `"branch":""`)
+	})
+
+	t.Run("LookupForbiddenNamesReadPipelines", func(t *testing.T) {
+		assert := require.New(t)
+
+		tokens := &MockAccessTokenClient{
+			GetFunc: func(ctx context.Context) (buildkite.AccessToken, *buildkite.Response, error) {
+				return buildkite.AccessToken{Scopes: []string{"write_builds"}}, nil, nil
+			},
+		}
+		pipelines := &MockPipelinesClient{
+			GetFunc: func(ctx context.Context, org string, pipeline string) (buildkite.Pipeline, *buildkite.Response, error) {
+				return buildkite.Pipeline{}, nil, forbiddenError()
+			},
+		}
+
+		ctx := ContextWithDeps(context.Background(), ToolDependencies{PipelinesClient: pipelines, AccessTokensClient: tokens})
+		ctx = ContextWithRequiredScopes(ctx, []string{"write_builds"})
+		_, handler, scopes := CreateBuild()
+		assert.Equal([]string{"write_builds"}, scopes)
+
+		result, _, err := handler(ctx, createMCPRequest(t, map[string]any{}), CreateBuildArgs{
+			OrgSlug:      "org",
+			PipelineSlug: "pipeline",
+			Commit:       "HEAD",
+		})
+		assert.NoError(err)
+		assert.True(result.IsError)
+		assert.Contains(getTextResult(t, result).Text, "missing scopes this tool needs: read_pipelines")
+	})
+
+	t.Run("ExplicitBranchSkipsLookup", func(t *testing.T) {
+		assert := require.New(t)
+
+		client := &MockBuildsClient{
+			CreateFunc: func(ctx context.Context, org string, pipeline string, b buildkite.CreateBuild) (buildkite.Build, *buildkite.Response, error) {
+				assert.Equal("feature", b.Branch)
+				return buildkite.Build{ID: "123"}, &buildkite.Response{}, nil
+			},
+		}
+
+		// A nil PipelinesClient would panic if it were used.
+		ctx := ContextWithDeps(context.Background(), ToolDependencies{BuildsClient: client})
+		_, handler, _ := CreateBuild()
+
+		result, _, err := handler(ctx, createMCPRequest(t, map[string]any{}), CreateBuildArgs{
+			OrgSlug:      "org",
+			PipelineSlug: "pipeline",
+			Commit:       "HEAD",
+			Branch:       "feature",
+		})
+		assert.NoError(err)
+		assert.False(result.IsError)
+	})
+
+	t.Run("NoDefaultBranch", func(t *testing.T) {
+		assert := require.New(t)
+
+		pipelines := &MockPipelinesClient{
+			GetFunc: func(ctx context.Context, org string, pipeline string) (buildkite.Pipeline, *buildkite.Response, error) {
+				return buildkite.Pipeline{}, nil, nil
+			},
+		}
+		client := &MockBuildsClient{
+			CreateFunc: func(ctx context.Context, org string, pipeline string, b buildkite.CreateBuild) (buildkite.Build, *buildkite.Response, error) {
+				t.Fatal("Create should not be called")
+				return buildkite.Build{}, nil, nil
+			},
+		}
+
+		ctx := ContextWithDeps(context.Background(), ToolDependencies{BuildsClient: client, PipelinesClient: pipelines})
+		_, handler, _ := CreateBuild()
+
+		result, _, err := handler(ctx, createMCPRequest(t, map[string]any{}), CreateBuildArgs{
+			OrgSlug:      "org",
+			PipelineSlug: "pipeline",
+			Commit:       "HEAD",
+		})
+		assert.NoError(err)
+		assert.True(result.IsError)
+		assert.Equal(`pipeline "pipeline" has no default branch, pass branch`, getTextResult(t, result).Text)
+	})
+}
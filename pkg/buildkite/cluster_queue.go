@@ -2,6 +2,7 @@ package buildkite
 
 import (
 	"context"
+	"net/http"
 
 	"github.com/buildkite/buildkite-mcp-server/pkg/trace"
 	"github.com/buildkite/go-buildkite/v5"
@@ -19,10 +20,13 @@ type ClusterQueuesClient interface {
 }
 
 type ListClusterQueuesArgs struct {
-	OrgSlug   string `json:"org_slug"`
-	ClusterID string `json:"cluster_id"`
-	Page      int    `json:"page,omitempty" jsonschema:"Page number for pagination (min 1)"`
-	PerPage   int    `json:"per_page,omitempty" jsonschema:"Results per page for pagination (min 1, max 100)"`
+	OrgSlug           string `json:"org_slug"`
+	ClusterID         string `json:"cluster_id"`
+	Page              int    `json:"page,omitempty" jsonschema:"Page number for pagination (min 1)"`
+	PerPage           int    `json:"per_page,omitempty" jsonschema:"Results per page for pagination (min 1, max 100)"`
+	AutoPaginate      bool   `json:"auto_paginate,omitempty" jsonschema:"Also fetch the following pages, up to 10 in total, instead of only the requested page. truncated is set when more pages remain"`
+	IncludePagination bool   `json:"include_pagination,omitempty" jsonschema:"Add page, has_more and next_page to the result, so you can tell whether more pages exist"`
+	TimeoutSeconds    int    `json:"timeout_seconds,omitempty" jsonschema:"Give up after this many seconds (default 300, max 1800)"`
 }
 
 type GetClusterQueueArgs struct {
@@ -36,6 +40,7 @@ type CreateClusterQueueArgs struct {
 	ClusterID   string `json:"cluster_id"`
 	Key         string `json:"key"`
 	Description string `json:"description,omitempty" jsonschema:"Description of the queue"`
+	DryRun      bool   `json:"dry_run,omitempty" jsonschema:"Validate the arguments and return the request this would make, without making it"`
 }
 
 type UpdateClusterQueueArgs struct {
@@ -44,6 +49,7 @@ type UpdateClusterQueueArgs struct {
 	QueueID            string  `json:"queue_id"`
 	Description        *string `json:"description,omitempty" jsonschema:"New description for the queue"`
 	RetryAgentAffinity *string `json:"retry_agent_affinity,omitempty" jsonschema:"Agent retry affinity: prefer-warmest or prefer-different"`
+	DryRun             bool    `json:"dry_run,omitempty" jsonschema:"Validate the arguments and return the request this would make, without making it"`
 }
 
 type PauseClusterQueueDispatchArgs struct {
@@ -51,12 +57,14 @@ type PauseClusterQueueDispatchArgs struct {
 	ClusterID string `json:"cluster_id"`
 	QueueID   string `json:"queue_id"`
 	Note      string `json:"note,omitempty" jsonschema:"Reason for pausing dispatch"`
+	DryRun    bool   `json:"dry_run,omitempty" jsonschema:"Validate the arguments and return the request this would make, without making it"`
 }
 
 type ResumeClusterQueueDispatchArgs struct {
 	OrgSlug   string `json:"org_slug"`
 	ClusterID string `json:"cluster_id"`
 	QueueID   string `json:"queue_id"`
+	DryRun    bool   `json:"dry_run,omitempty" jsonschema:"Validate the arguments and return the request this would make, without making it"`
 }
 
 func ListClusterQueues() (mcp.Tool, mcp.ToolHandlerFor[ListClusterQueuesArgs, any], []string) {
@@ -71,6 +79,9 @@ func ListClusterQueues() (mcp.Tool, mcp.ToolHandlerFor[ListClusterQueuesArgs, an
 			ctx, span := trace.Start(ctx, "buildkite.ListClusterQueues")
 			defer span.End()
 
+			ctx, cancel := withCallTimeout(ctx, args.TimeoutSeconds)
+			defer cancel()
+
 			paginationParams := paginationFromArgs(args.Page, args.PerPage)
 
 			span.SetAttributes(
@@ -78,14 +89,17 @@ func ListClusterQueues() (mcp.Tool, mcp.ToolHandlerFor[ListClusterQueuesArgs, an
 				attribute.String("cluster_id", args.ClusterID),
 				attribute.Int("page", paginationParams.Page),
 				attribute.Int("per_page", paginationParams.PerPage),
+				attribute.Bool("auto_paginate", args.AutoPaginate),
 			)
 
 			deps := DepsFromContext(ctx)
-			queues, resp, err := deps.ClusterQueuesClient.List(ctx, args.OrgSlug, args.ClusterID, &buildkite.ClusterQueuesListOptions{
-				ListOptions: paginationParams,
+			queues, resp, truncated, err := listPages(paginationParams.Page, args.AutoPaginate, func(page int) ([]buildkite.ClusterQueue, *buildkite.Response, error) {
+				return deps.ClusterQueuesClient.List(ctx, args.OrgSlug, args.ClusterID, &buildkite.ClusterQueuesListOptions{
+					ListOptions: buildkite.ListOptions{Page: page, PerPage: paginationParams.PerPage},
+				})
 			})
 			if err != nil {
-				return handleBuildkiteError(err)
+				return handleBuildkiteError(ctx, err)
 			}
 
 			result := PaginatedResult[buildkite.ClusterQueue]{
@@ -93,13 +107,16 @@ func ListClusterQueues() (mcp.Tool, mcp.ToolHandlerFor[ListClusterQueuesArgs, an
 				Headers: map[string]string{
 					"Link": resp.Header.Get("Link"),
 				},
+				Truncated:  truncated,
+				Pagination: paginationFor(args.IncludePagination, paginationParams.Page, resp),
 			}
 
 			span.SetAttributes(
 				attribute.Int("item_count", len(queues)),
+				attribute.Bool("truncated", truncated),
 			)
 
-			return mcpTextResult(span, &result)
+			return mcpTextResult(ctx, span, &result)
 		}, []string{"read_clusters"}
 }
 
@@ -124,10 +141,10 @@ func GetClusterQueue() (mcp.Tool, mcp.ToolHandlerFor[GetClusterQueueArgs, any],
 			deps := DepsFromContext(ctx)
 			queue, _, err := deps.ClusterQueuesClient.Get(ctx, args.OrgSlug, args.ClusterID, args.QueueID)
 			if err != nil {
-				return handleBuildkiteError(err)
+				return handleBuildkiteError(ctx, err)
 			}
 
-			return mcpTextResult(span, &queue)
+			return mcpTextResult(ctx, span, &queue)
 		}, []string{"read_clusters"}
 }
 
@@ -149,16 +166,21 @@ func CreateClusterQueue() (mcp.Tool, mcp.ToolHandlerFor[CreateClusterQueueArgs,
 				attribute.String("key", args.Key),
 			)
 
-			deps := DepsFromContext(ctx)
-			queue, _, err := deps.ClusterQueuesClient.Create(ctx, args.OrgSlug, args.ClusterID, buildkite.ClusterQueueCreate{
+			create := buildkite.ClusterQueueCreate{
 				Key:         args.Key,
 				Description: args.Description,
-			})
+			}
+			if args.DryRun {
+				return mcpDryRunResult(ctx, span, "Create a cluster queue", http.MethodPost, apiPath("v2/organizations/%s/clusters/%s/queues", args.OrgSlug, args.ClusterID), create)
+			}
+
+			deps := DepsFromContext(ctx)
+			queue, _, err := deps.ClusterQueuesClient.Create(ctx, args.OrgSlug, args.ClusterID, create)
 			if err != nil {
-				return handleBuildkiteError(err)
+				return handleBuildkiteError(ctx, err)
 			}
 
-			return mcpTextResult(span, &queue)
+			return mcpTextResult(ctx, span, &queue)
 		}, []string{"write_clusters"}
 }
 
@@ -189,12 +211,16 @@ func UpdateClusterQueue() (mcp.Tool, mcp.ToolHandlerFor[UpdateClusterQueueArgs,
 				update.RetryAgentAffinity = buildkite.Some(buildkite.RetryAgentAffinity(*args.RetryAgentAffinity))
 			}
 
+			if args.DryRun {
+				return mcpDryRunResult(ctx, span, "Update a cluster queue", http.MethodPatch, apiPath("v2/organizations/%s/clusters/%s/queues/%s", args.OrgSlug, args.ClusterID, args.QueueID), update)
+			}
+
 			queue, _, err := deps.ClusterQueuesClient.Update(ctx, args.OrgSlug, args.ClusterID, args.QueueID, update)
 			if err != nil {
-				return handleBuildkiteError(err)
+				return handleBuildkiteError(ctx, err)
 			}
 
-			return mcpTextResult(span, &queue)
+			return mcpTextResult(ctx, span, &queue)
 		}, []string{"write_clusters"}
 }
 
@@ -216,15 +242,20 @@ func PauseClusterQueueDispatch() (mcp.Tool, mcp.ToolHandlerFor[PauseClusterQueue
 				attribute.String("queue_id", args.QueueID),
 			)
 
-			deps := DepsFromContext(ctx)
-			queue, _, err := deps.ClusterQueuesClient.Pause(ctx, args.OrgSlug, args.ClusterID, args.QueueID, buildkite.ClusterQueuePause{
+			pause := buildkite.ClusterQueuePause{
 				Note: args.Note,
-			})
+			}
+			if args.DryRun {
+				return mcpDryRunResult(ctx, span, "Pause dispatch on a cluster queue", http.MethodPost, apiPath("v2/organizations/%s/clusters/%s/queues/%s/pause_dispatch", args.OrgSlug, args.ClusterID, args.QueueID), pause)
+			}
+
+			deps := DepsFromContext(ctx)
+			queue, _, err := deps.ClusterQueuesClient.Pause(ctx, args.OrgSlug, args.ClusterID, args.QueueID, pause)
 			if err != nil {
-				return handleBuildkiteError(err)
+				return handleBuildkiteError(ctx, err)
 			}
 
-			return mcpTextResult(span, &queue)
+			return mcpTextResult(ctx, span, &queue)
 		}, []string{"write_clusters"}
 }
 
@@ -246,12 +277,16 @@ func ResumeClusterQueueDispatch() (mcp.Tool, mcp.ToolHandlerFor[ResumeClusterQue
 				attribute.String("queue_id", args.QueueID),
 			)
 
+			if args.DryRun {
+				return mcpDryRunResult(ctx, span, "Resume dispatch on a cluster queue", http.MethodPost, apiPath("v2/organizations/%s/clusters/%s/queues/%s/resume_dispatch", args.OrgSlug, args.ClusterID, args.QueueID), nil)
+			}
+
 			deps := DepsFromContext(ctx)
 			_, err := deps.ClusterQueuesClient.Resume(ctx, args.OrgSlug, args.ClusterID, args.QueueID)
 			if err != nil {
-				return handleBuildkiteError(err)
+				return handleBuildkiteError(ctx, err)
 			}
 
-			return mcpTextResult(span, "Cluster queue dispatch resumed successfully")
+			return mcpTextResult(ctx, span, "Cluster queue dispatch resumed successfully")
 		}, []string{"write_clusters"}
 }
@@ -2,6 +2,7 @@ package buildkite
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"testing"
@@ -406,3 +407,58 @@ func TestResumeClusterQueueDispatchWithError(t *testing.T) {
 	assert.True(result.IsError)
 	assert.Contains(result.Content[0].(*mcp.TextContent).Text, "API error")
 }
+
+func TestListClusterQueuesPagination(t *testing.T) {
+	var pages []int
+	client := &mockClusterQueuesClient{
+		ListFunc: func(ctx context.Context, org, clusterID string, opts *buildkite.ClusterQueuesListOptions) ([]buildkite.ClusterQueue, *buildkite.Response, error) {
+			pages = append(pages, opts.Page)
+			resp := &buildkite.Response{Response: &http.Response{StatusCode: 200}}
+			if opts.Page < 3 {
+				resp.NextPage = opts.Page + 1
+			}
+			return []buildkite.ClusterQueue{{ID: fmt.Sprintf("queue-%d", opts.Page)}}, resp, nil
+		},
+	}
+	ctx := ContextWithDeps(context.Background(), ToolDependencies{ClusterQueuesClient: client})
+	_, handler, _ := ListClusterQueues()
+
+	t.Run("SecondPage", func(t *testing.T) {
+		assert := require.New(t)
+		pages = nil
+
+		result, _, err := handler(ctx, createMCPRequest(t, map[string]any{}), ListClusterQueuesArgs{
+			OrgSlug:           "org",
+			ClusterID:         "cluster-id",
+			Page:              2,
+			PerPage:           1,
+			IncludePagination: true,
+		})
+		assert.NoError(err)
+		assert.Equal([]int{2}, pages)
+
+		var got PaginatedResult[buildkite.ClusterQueue]
+		assert.NoError(json.Unmarshal([]byte(getTextResult(t, result).Text), &got))
+		assert.Len(got.Items, 1)
+		assert.Equal("queue-2", got.Items[0].ID)
+		assert.Equal(&Pagination{Page: 2, HasMore: true, NextPage: 3}, got.Pagination)
+	})
+
+	t.Run("AutoPaginate", func(t *testing.T) {
+		assert := require.New(t)
+		pages = nil
+
+		result, _, err := handler(ctx, createMCPRequest(t, map[string]any{}), ListClusterQueuesArgs{
+			OrgSlug:      "org",
+			ClusterID:    "cluster-id",
+			AutoPaginate: true,
+		})
+		assert.NoError(err)
+		assert.Equal([]int{1, 2, 3}, pages)
+
+		var got PaginatedResult[buildkite.ClusterQueue]
+		assert.NoError(json.Unmarshal([]byte(getTextResult(t, result).Text), &got))
+		assert.Len(got.Items, 3)
+		assert.False(got.Truncated)
+	})
+}
@@ -2,6 +2,7 @@ package buildkite
 
 import (
 	"context"
+	"net/http"
 
 	"github.com/buildkite/buildkite-mcp-server/pkg/trace"
 	"github.com/buildkite/go-buildkite/v5"
@@ -17,9 +18,12 @@ type ClustersClient interface {
 }
 
 type ListClustersArgs struct {
-	OrgSlug string `json:"org_slug"`
-	Page    int    `json:"page,omitempty" jsonschema:"Page number for pagination (min 1)"`
-	PerPage int    `json:"per_page,omitempty" jsonschema:"Results per page for pagination (min 1, max 100)"`
+	OrgSlug           string `json:"org_slug"`
+	Page              int    `json:"page,omitempty" jsonschema:"Page number for pagination (min 1)"`
+	PerPage           int    `json:"per_page,omitempty" jsonschema:"Results per page for pagination (min 1, max 100)"`
+	AutoPaginate      bool   `json:"auto_paginate,omitempty" jsonschema:"Also fetch the following pages, up to 10 in total, instead of only the requested page. truncated is set when more pages remain"`
+	IncludePagination bool   `json:"include_pagination,omitempty" jsonschema:"Add page, has_more and next_page to the result, so you can tell whether more pages exist"`
+	TimeoutSeconds    int    `json:"timeout_seconds,omitempty" jsonschema:"Give up after this many seconds (default 300, max 1800)"`
 }
 
 type GetClusterArgs struct {
@@ -33,6 +37,7 @@ type CreateClusterArgs struct {
 	Description string `json:"description,omitempty" jsonschema:"Description of the cluster"`
 	Emoji       string `json:"emoji,omitempty" jsonschema:"Emoji for the cluster (e.g. :toolbox:)"`
 	Color       string `json:"color,omitempty" jsonschema:"Hex color code for the cluster (e.g. #A9CCE3)"`
+	DryRun      bool   `json:"dry_run,omitempty" jsonschema:"Validate the arguments and return the request this would make, without making it"`
 }
 
 type UpdateClusterArgs struct {
@@ -43,6 +48,7 @@ type UpdateClusterArgs struct {
 	Emoji          *string `json:"emoji,omitempty" jsonschema:"New emoji for the cluster"`
 	Color          *string `json:"color,omitempty" jsonschema:"New hex color code for the cluster"`
 	DefaultQueueID *string `json:"default_queue_id,omitempty" jsonschema:"ID of the default queue for the cluster"`
+	DryRun         bool    `json:"dry_run,omitempty" jsonschema:"Validate the arguments and return the request this would make, without making it"`
 }
 
 func ListClusters() (mcp.Tool, mcp.ToolHandlerFor[ListClustersArgs, any], []string) {
@@ -57,20 +63,26 @@ func ListClusters() (mcp.Tool, mcp.ToolHandlerFor[ListClustersArgs, any], []stri
 			ctx, span := trace.Start(ctx, "buildkite.ListClusters")
 			defer span.End()
 
+			ctx, cancel := withCallTimeout(ctx, args.TimeoutSeconds)
+			defer cancel()
+
 			paginationParams := paginationFromArgs(args.Page, args.PerPage)
 
 			span.SetAttributes(
 				attribute.String("org_slug", args.OrgSlug),
 				attribute.Int("page", paginationParams.Page),
 				attribute.Int("per_page", paginationParams.PerPage),
+				attribute.Bool("auto_paginate", args.AutoPaginate),
 			)
 
 			deps := DepsFromContext(ctx)
-			clusters, resp, err := deps.ClustersClient.List(ctx, args.OrgSlug, &buildkite.ClustersListOptions{
-				ListOptions: paginationParams,
+			clusters, resp, truncated, err := listPages(paginationParams.Page, args.AutoPaginate, func(page int) ([]buildkite.Cluster, *buildkite.Response, error) {
+				return deps.ClustersClient.List(ctx, args.OrgSlug, &buildkite.ClustersListOptions{
+					ListOptions: buildkite.ListOptions{Page: page, PerPage: paginationParams.PerPage},
+				})
 			})
 			if err != nil {
-				return handleBuildkiteError(err)
+				return handleBuildkiteError(ctx, err)
 			}
 
 			result := PaginatedResult[buildkite.Cluster]{
@@ -78,13 +90,16 @@ func ListClusters() (mcp.Tool, mcp.ToolHandlerFor[ListClustersArgs, any], []stri
 				Headers: map[string]string{
 					"Link": resp.Header.Get("Link"),
 				},
+				Truncated:  truncated,
+				Pagination: paginationFor(args.IncludePagination, paginationParams.Page, resp),
 			}
 
 			span.SetAttributes(
 				attribute.Int("item_count", len(clusters)),
+				attribute.Bool("truncated", truncated),
 			)
 
-			return mcpTextResult(span, &result)
+			return mcpTextResult(ctx, span, &result)
 		}, []string{"read_clusters"}
 }
 
@@ -108,10 +123,10 @@ func GetCluster() (mcp.Tool, mcp.ToolHandlerFor[GetClusterArgs, any], []string)
 			deps := DepsFromContext(ctx)
 			cluster, _, err := deps.ClustersClient.Get(ctx, args.OrgSlug, args.ClusterID)
 			if err != nil {
-				return handleBuildkiteError(err)
+				return handleBuildkiteError(ctx, err)
 			}
 
-			return mcpTextResult(span, &cluster)
+			return mcpTextResult(ctx, span, &cluster)
 		}, []string{"read_clusters"}
 }
 
@@ -132,18 +147,23 @@ func CreateCluster() (mcp.Tool, mcp.ToolHandlerFor[CreateClusterArgs, any], []st
 				attribute.String("name", args.Name),
 			)
 
-			deps := DepsFromContext(ctx)
-			cluster, _, err := deps.ClustersClient.Create(ctx, args.OrgSlug, buildkite.ClusterCreate{
+			create := buildkite.ClusterCreate{
 				Name:        args.Name,
 				Description: args.Description,
 				Emoji:       args.Emoji,
 				Color:       args.Color,
-			})
+			}
+			if args.DryRun {
+				return mcpDryRunResult(ctx, span, "Create a cluster", http.MethodPost, apiPath("v2/organizations/%s/clusters", args.OrgSlug), create)
+			}
+
+			deps := DepsFromContext(ctx)
+			cluster, _, err := deps.ClustersClient.Create(ctx, args.OrgSlug, create)
 			if err != nil {
-				return handleBuildkiteError(err)
+				return handleBuildkiteError(ctx, err)
 			}
 
-			return mcpTextResult(span, &cluster)
+			return mcpTextResult(ctx, span, &cluster)
 		}, []string{"write_clusters"}
 }
 
@@ -182,11 +202,15 @@ func UpdateCluster() (mcp.Tool, mcp.ToolHandlerFor[UpdateClusterArgs, any], []st
 				update.DefaultQueueID = buildkite.Some(*args.DefaultQueueID)
 			}
 
+			if args.DryRun {
+				return mcpDryRunResult(ctx, span, "Update a cluster", http.MethodPatch, apiPath("v2/organizations/%s/clusters/%s", args.OrgSlug, args.ClusterID), update)
+			}
+
 			cluster, _, err := deps.ClustersClient.Update(ctx, args.OrgSlug, args.ClusterID, update)
 			if err != nil {
-				return handleBuildkiteError(err)
+				return handleBuildkiteError(ctx, err)
 			}
 
-			return mcpTextResult(span, &cluster)
+			return mcpTextResult(ctx, span, &cluster)
 		}, []string{"write_clusters"}
 }
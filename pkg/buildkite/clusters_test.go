@@ -2,6 +2,7 @@ package buildkite
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"testing"
@@ -269,3 +270,58 @@ func TestUpdateClusterWithError(t *testing.T) {
 	assert.True(result.IsError)
 	assert.Contains(result.Content[0].(*mcp.TextContent).Text, "API error")
 }
+
+func TestListClustersPagination(t *testing.T) {
+	var pages []int
+	client := &mockClustersClient{
+		ListFunc: func(ctx context.Context, org string, opts *buildkite.ClustersListOptions) ([]buildkite.Cluster, *buildkite.Response, error) {
+			pages = append(pages, opts.Page)
+			resp := &buildkite.Response{Response: &http.Response{StatusCode: 200}}
+			if opts.Page < 3 {
+				resp.NextPage = opts.Page + 1
+			}
+			return []buildkite.Cluster{{ID: fmt.Sprintf("cluster-%d", opts.Page)}}, resp, nil
+		},
+	}
+	ctx := ContextWithDeps(context.Background(), ToolDependencies{ClustersClient: client})
+	_, handler, _ := ListClusters()
+
+	t.Run("SecondPage", func(t *testing.T) {
+		assert := require.New(t)
+		pages = nil
+
+		result, _, err := handler(ctx, createMCPRequest(t, map[string]any{}), ListClustersArgs{
+			OrgSlug:           "org",
+			Page:              2,
+			PerPage:           1,
+			IncludePagination: true,
+		})
+		assert.NoError(err)
+		assert.Equal([]int{2}, pages)
+
+		var got PaginatedResult[buildkite.Cluster]
+		assert.NoError(json.Unmarshal([]byte(getTextResult(t, result).Text), &got))
+		assert.Len(got.Items, 1)
+		assert.Equal("cluster-2", got.Items[0].ID)
+		assert.Equal(&Pagination{Page: 2, HasMore: true, NextPage: 3}, got.Pagination)
+	})
+
+	t.Run("AutoPaginate", func(t *testing.T) {
+		assert := require.New(t)
+		pages = nil
+
+		result, _, err := handler(ctx, createMCPRequest(t, map[string]any{}), ListClustersArgs{
+			OrgSlug:      "org",
+			Page:         2,
+			AutoPaginate: true,
+		})
+		assert.NoError(err)
+		assert.Equal([]int{2, 3}, pages)
+
+		var got PaginatedResult[buildkite.Cluster]
+		assert.NoError(json.Unmarshal([]byte(getTextResult(t, result).Text), &got))
+		assert.Len(got.Items, 2)
+		assert.Equal("cluster-3", got.Items[1].ID)
+		assert.False(got.Truncated)
+	})
+}
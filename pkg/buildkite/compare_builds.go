@@ -0,0 +1,226 @@
+package buildkite
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/buildkite/buildkite-mcp-server/pkg/trace"
+	"github.com/buildkite/buildkite-mcp-server/pkg/utils"
+	"github.com/buildkite/go-buildkite/v5"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.opentelemetry.io/otel/attribute"
+	"golang.org/x/sync/errgroup"
+)
+
+type CompareBuildsArgs struct {
+	OrgSlug         string `json:"org_slug"`
+	PipelineSlug    string `json:"pipeline_slug"`
+	BaseBuildNumber string `json:"base_build_number" jsonschema:"The earlier build to compare from"`
+	HeadBuildNumber string `json:"head_build_number" jsonschema:"The later build to compare to"`
+}
+
+type BuildComparisonBuild struct {
+	Number          int    `json:"number"`
+	State           string `json:"state"`
+	Branch          string `json:"branch"`
+	Commit          string `json:"commit"`
+	DurationSeconds *int64 `json:"duration_seconds,omitempty"`
+	WebURL          string `json:"web_url"`
+}
+
+// JobComparison is a job whose state differs between the builds. Change is
+// "regressed" when it passed in the base build and failed in the head,
+// "fixed" for the reverse, "added" or "removed" when it is only in one build,
+// and "changed" otherwise.
+type JobComparison struct {
+	Job                  string `json:"job"`
+	Change               string `json:"change"`
+	BaseState            string `json:"base_state,omitempty"`
+	HeadState            string `json:"head_state,omitempty"`
+	DurationDeltaSeconds *int64 `json:"duration_delta_seconds,omitempty"`
+}
+
+type BuildComparison struct {
+	Base                 BuildComparisonBuild `json:"base"`
+	Head                 BuildComparisonBuild `json:"head"`
+	DurationDeltaSeconds *int64               `json:"duration_delta_seconds,omitempty"`
+	Jobs                 []JobComparison      `json:"jobs"`
+	UnchangedJobs        int                  `json:"unchanged_jobs"`
+}
+
+// durationSeconds is how long something between started and finished took,
+// or nil if it hasn't both started and finished.
+func durationSeconds(started, finished *buildkite.Timestamp) *int64 {
+	if started == nil || finished == nil {
+		return nil
+	}
+	seconds := int64(finished.Sub(started.Time).Round(time.Second).Seconds())
+	return &seconds
+}
+
+func durationDelta(base, head *int64) *int64 {
+	if base == nil || head == nil {
+		return nil
+	}
+	delta := *head - *base
+	return &delta
+}
+
+func isFailedJobState(state string) bool {
+	switch state {
+	case "failed", "timed_out", "expired":
+		return true
+	default:
+		return false
+	}
+}
+
+// comparableJobs keys the jobs of a build that ran a step by its step key, or
+// its name where it has none, keeping the build's job order. Retried jobs are
+// left out in favour of their retry, and parallel jobs are told apart by
+// their index.
+func comparableJobs(build buildkite.Build) ([]string, map[string]buildkite.Job) {
+	var keys []string
+	jobs := make(map[string]buildkite.Job)
+	for _, job := range build.Jobs {
+		if job.Type == "waiter" || job.Retried {
+			continue
+		}
+		key := job.StepKey
+		if key == "" {
+			key = job.Name
+		}
+		if key == "" {
+			key = job.Label
+		}
+		if job.ParallelGroupIndex != nil {
+			key = fmt.Sprintf("%s #%d", key, *job.ParallelGroupIndex)
+		}
+		if _, ok := jobs[key]; ok {
+			key = fmt.Sprintf("%s (%s)", key, job.ID)
+		}
+		keys = append(keys, key)
+		jobs[key] = job
+	}
+	return keys, jobs
+}
+
+func compareBuilds(base, head buildkite.Build) BuildComparison {
+	comparisonBuild := func(build buildkite.Build) BuildComparisonBuild {
+		return BuildComparisonBuild{
+			Number:          build.Number,
+			State:           build.State,
+			Branch:          build.Branch,
+			Commit:          build.Commit,
+			DurationSeconds: durationSeconds(build.StartedAt, build.FinishedAt),
+			WebURL:          build.WebURL,
+		}
+	}
+
+	comparison := BuildComparison{
+		Base: comparisonBuild(base),
+		Head: comparisonBuild(head),
+		Jobs: []JobComparison{},
+	}
+	comparison.DurationDeltaSeconds = durationDelta(comparison.Base.DurationSeconds, comparison.Head.DurationSeconds)
+
+	baseKeys, baseJobs := comparableJobs(base)
+	headKeys, headJobs := comparableJobs(head)
+
+	for _, key := range headKeys {
+		headJob := headJobs[key]
+		baseJob, ok := baseJobs[key]
+		if !ok {
+			comparison.Jobs = append(comparison.Jobs, JobComparison{Job: key, Change: "added", HeadState: headJob.State})
+			continue
+		}
+		if baseJob.State == headJob.State {
+			comparison.UnchangedJobs++
+			continue
+		}
+
+		change := "changed"
+		switch {
+		case baseJob.State == "passed" && isFailedJobState(headJob.State):
+			change = "regressed"
+		case isFailedJobState(baseJob.State) && headJob.State == "passed":
+			change = "fixed"
+		}
+		comparison.Jobs = append(comparison.Jobs, JobComparison{
+			Job:       key,
+			Change:    change,
+			BaseState: baseJob.State,
+			HeadState: headJob.State,
+			DurationDeltaSeconds: durationDelta(
+				durationSeconds(baseJob.StartedAt, baseJob.FinishedAt),
+				durationSeconds(headJob.StartedAt, headJob.FinishedAt),
+			),
+		})
+	}
+	for _, key := range baseKeys {
+		if _, ok := headJobs[key]; !ok {
+			comparison.Jobs = append(comparison.Jobs, JobComparison{Job: key, Change: "removed", BaseState: baseJobs[key].State})
+		}
+	}
+
+	return comparison
+}
+
+func CompareBuilds() (mcp.Tool, mcp.ToolHandlerFor[CompareBuildsArgs, any], []string) {
+	return mcp.Tool{
+			Name:        "compare_builds",
+			Description: "Compare two builds of a pipeline: their states, the change in duration, and the jobs whose state changed, such as jobs that regressed from passed to failed. Jobs are matched by step key, or by name when they have none",
+			Annotations: &mcp.ToolAnnotations{
+				Title:        "Compare Builds",
+				ReadOnlyHint: true,
+			},
+		},
+		func(ctx context.Context, request *mcp.CallToolRequest, args CompareBuildsArgs) (*mcp.CallToolResult, any, error) {
+			ctx, span := trace.Start(ctx, "buildkite.CompareBuilds")
+			defer span.End()
+
+			span.SetAttributes(
+				attribute.String("org_slug", args.OrgSlug),
+				attribute.String("pipeline_slug", args.PipelineSlug),
+				attribute.String("base_build_number", args.BaseBuildNumber),
+				attribute.String("head_build_number", args.HeadBuildNumber),
+			)
+
+			if err := requireParams(map[string]string{
+				"org_slug":          args.OrgSlug,
+				"pipeline_slug":     args.PipelineSlug,
+				"base_build_number": args.BaseBuildNumber,
+				"head_build_number": args.HeadBuildNumber,
+			}); err != nil {
+				return utils.NewToolResultError(err.Error()), nil, nil
+			}
+
+			deps := DepsFromContext(ctx)
+			numbers := []string{args.BaseBuildNumber, args.HeadBuildNumber}
+			builds := make([]buildkite.Build, len(numbers))
+			group, groupCtx := errgroup.WithContext(ctx)
+			for i, number := range numbers {
+				group.Go(func() error {
+					build, _, err := deps.BuildsClient.Get(groupCtx, args.OrgSlug, args.PipelineSlug, number, &buildkite.BuildGetOptions{
+						BuildsListOptions: buildkite.BuildsListOptions{
+							ExcludePipeline: true,
+						},
+					})
+					builds[i] = build
+					return err
+				})
+			}
+			if err := group.Wait(); err != nil {
+				return handleBuildkiteError(ctx, err)
+			}
+
+			comparison := compareBuilds(builds[0], builds[1])
+			span.SetAttributes(
+				attribute.Int("changed_jobs", len(comparison.Jobs)),
+				attribute.Int("unchanged_jobs", comparison.UnchangedJobs),
+			)
+
+			return mcpTextResult(ctx, span, &comparison)
+		}, []string{"read_builds"}
+}
@@ -0,0 +1,97 @@
+package buildkite
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/buildkite/go-buildkite/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func testTimestamp(offset time.Duration) *buildkite.Timestamp {
+	return &buildkite.Timestamp{Time: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC).Add(offset)}
+}
+
+func TestCompareBuilds(t *testing.T) {
+	assert := require.New(t)
+
+	zero, one := 0, 1
+	base := buildkite.Build{
+		Number:     100,
+		State:      "passed",
+		StartedAt:  testTimestamp(0),
+		FinishedAt: testTimestamp(10 * time.Minute),
+		Jobs: []buildkite.Job{
+			{Type: "script", StepKey: "lint", State: "passed"},
+			{Type: "script", StepKey: "test", State: "passed", StartedAt: testTimestamp(0), FinishedAt: testTimestamp(5 * time.Minute)},
+			{Type: "waiter"},
+			{Type: "script", Name: "shard", ParallelGroupIndex: &zero, State: "failed"},
+			{Type: "script", Name: "shard", ParallelGroupIndex: &one, State: "passed"},
+			{Type: "script", StepKey: "docs", State: "passed"},
+		},
+	}
+	head := buildkite.Build{
+		Number:     101,
+		State:      "failed",
+		StartedAt:  testTimestamp(0),
+		FinishedAt: testTimestamp(12 * time.Minute),
+		Jobs: []buildkite.Job{
+			{Type: "script", StepKey: "lint", State: "passed"},
+			{Type: "script", StepKey: "test", State: "failed", Retried: true},
+			{Type: "script", StepKey: "test", State: "failed", StartedAt: testTimestamp(0), FinishedAt: testTimestamp(7 * time.Minute)},
+			{Type: "script", Name: "shard", ParallelGroupIndex: &zero, State: "passed"},
+			{Type: "script", Name: "shard", ParallelGroupIndex: &one, State: "canceled"},
+			{Type: "script", StepKey: "deploy", State: "blocked"},
+		},
+	}
+
+	comparison := compareBuilds(base, head)
+
+	assert.Equal(101, comparison.Head.Number)
+	assert.Equal(int64(600), *comparison.Base.DurationSeconds)
+	assert.Equal(int64(120), *comparison.DurationDeltaSeconds)
+	assert.Equal(1, comparison.UnchangedJobs)
+
+	delta := int64(120)
+	assert.Equal([]JobComparison{
+		{Job: "test", Change: "regressed", BaseState: "passed", HeadState: "failed", DurationDeltaSeconds: &delta},
+		{Job: "shard #0", Change: "fixed", BaseState: "failed", HeadState: "passed"},
+		{Job: "shard #1", Change: "changed", BaseState: "passed", HeadState: "canceled"},
+		{Job: "deploy", Change: "added", HeadState: "blocked"},
+		{Job: "docs", Change: "removed", BaseState: "passed"},
+	}, comparison.Jobs)
+}
+
+func TestCompareBuildsTool(t *testing.T) {
+	assert := require.New(t)
+
+	client := &MockBuildsClient{
+		GetFunc: func(ctx context.Context, org string, pipeline string, id string, opt *buildkite.BuildGetOptions) (buildkite.Build, *buildkite.Response, error) {
+			assert.False(opt.ExcludeJobs)
+			state := map[string]string{"100": "passed", "101": "failed"}[id]
+			return buildkite.Build{
+				Number: map[string]int{"100": 100, "101": 101}[id],
+				State:  state,
+				Jobs:   []buildkite.Job{{Type: "script", StepKey: "test", State: state}},
+			}, &buildkite.Response{}, nil
+		},
+	}
+
+	ctx := ContextWithDeps(context.Background(), ToolDependencies{BuildsClient: client})
+	_, handler, _ := CompareBuilds()
+
+	result, _, err := handler(ctx, createMCPRequest(t, map[string]any{}), CompareBuildsArgs{
+		OrgSlug:         "org",
+		PipelineSlug:    "pipeline",
+		BaseBuildNumber: "100",
+		HeadBuildNumber: "101",
+	})
+	assert.NoError(err)
+	assert.JSONEq(`{
+		"base": {"number": 100, "state": "passed", "branch": "", "commit": "", "web_url": ""},
+		"head": {"number": 101, "state": "failed", "branch": "", "commit": "", "web_url": ""},
+		"jobs": [{"job": "test", "change": "regressed", "base_state": "passed", "head_state": "failed"}],
+		"unchanged_jobs": 0
+	}`, getTextResult(t, result).Text)
+}
@@ -0,0 +1,64 @@
+package buildkite
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// OrgSlugParam is the tool parameter naming the Buildkite organization.
+const OrgSlugParam = "org_slug"
+
+// DefaultOrgMiddleware returns an mcp.Middleware that sets the org_slug
+// argument of calls to the tools for which takesOrg returns true to org when
+// the caller leaves it out or empty, so single-organization deployments don't
+// have to repeat it. An org_slug given by the caller is kept.
+func DefaultOrgMiddleware(org string, takesOrg func(toolName string) bool) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			params, ok := req.GetParams().(*mcp.CallToolParamsRaw)
+			if method != "tools/call" || !ok || params == nil || !takesOrg(params.Name) {
+				return next(ctx, method, req)
+			}
+
+			if arguments, ok := withDefaultOrg(params.Arguments, org); ok {
+				params.Arguments = arguments
+			}
+			return next(ctx, method, req)
+		}
+	}
+}
+
+// withDefaultOrg returns arguments with org_slug set to org, or false when
+// the arguments already name an organization or aren't a JSON object, which
+// is left for input validation to report.
+func withDefaultOrg(arguments json.RawMessage, org string) (json.RawMessage, bool) {
+	var args map[string]json.RawMessage
+	if len(arguments) > 0 {
+		if err := json.Unmarshal(arguments, &args); err != nil {
+			return nil, false
+		}
+	}
+	if args == nil {
+		args = map[string]json.RawMessage{}
+	}
+
+	var given string
+	if err := json.Unmarshal(args[OrgSlugParam], &given); err == nil && strings.TrimSpace(given) != "" {
+		return nil, false
+	}
+
+	encoded, err := json.Marshal(org)
+	if err != nil {
+		return nil, false
+	}
+	args[OrgSlugParam] = encoded
+
+	out, err := json.Marshal(args)
+	if err != nil {
+		return nil, false
+	}
+	return out, true
+}
@@ -0,0 +1,36 @@
+package buildkite
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithDefaultOrg(t *testing.T) {
+	tests := []struct {
+		name      string
+		arguments string
+		want      string
+		wantOK    bool
+	}{
+		{name: "no arguments", arguments: "", want: `{"org_slug":"acme"}`, wantOK: true},
+		{name: "null arguments", arguments: "null", want: `{"org_slug":"acme"}`, wantOK: true},
+		{name: "missing org", arguments: `{"page":2}`, want: `{"org_slug":"acme","page":2}`, wantOK: true},
+		{name: "empty org", arguments: `{"org_slug":" "}`, want: `{"org_slug":"acme"}`, wantOK: true},
+		{name: "given org", arguments: `{"org_slug":"other"}`},
+		{name: "not an object", arguments: `[1]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := require.New(t)
+
+			got, ok := withDefaultOrg(json.RawMessage(tt.arguments), "acme")
+			assert.Equal(tt.wantOK, ok)
+			if tt.wantOK {
+				assert.JSONEq(tt.want, string(got))
+			}
+		})
+	}
+}
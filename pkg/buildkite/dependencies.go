@@ -2,7 +2,9 @@ package buildkite
 
 import (
 	"context"
+	"time"
 
+	"github.com/buildkite/buildkite-mcp-server/pkg/trace"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
@@ -24,6 +26,61 @@ type ToolDependencies struct {
 	TestExecutionsClient    TestExecutionsClient
 	TestsClient             TestsClient
 	BuildkiteLogsClient     BuildkiteLogsClient
+	GraphQLClient           GraphQLClient
+
+	// MaxLogBytes caps the raw log size get_job_logs and
+	// get_build_failed_logs will download, as BuildkiteLogsClient does for
+	// the other log tools. Zero disables the check.
+	MaxLogBytes int64
+
+	// LogFetchConcurrency bounds how many job logs tools covering several
+	// jobs fetch at once. Zero uses failureSummaryConcurrency.
+	LogFetchConcurrency int
+
+	// IncludeRateLimit adds the API rate limit to tool result metadata; see
+	// RateLimitMiddleware.
+	IncludeRateLimit bool
+
+	// DefaultOrg is the org_slug of tool calls that leave it out. Empty
+	// keeps org_slug required; see DefaultOrgMiddleware.
+	DefaultOrg string
+
+	// PrettyJSON indents the JSON of tool results; see MarshalResult.
+	PrettyJSON bool
+
+	// ResponseCache, when set, caches the results of read-only tools. It is
+	// shared by every server created with these dependencies.
+	ResponseCache *ResponseCache
+
+	// IdempotentBuilds, when set, remembers the builds create_build created
+	// for an idempotency_key. Leave it nil when callers bring their own
+	// credentials, as keys would be shared between them.
+	IdempotentBuilds *IdempotentBuilds
+
+	// TokenScopes, when set, caches the API token's scopes, which 403 errors
+	// are checked against. Leave it nil when each request brings its own
+	// token.
+	TokenScopes *TokenScopes
+
+	// SlowToolThreshold and LargeToolResultBytes log a warning for tool
+	// calls that take longer or return more bytes. Zero disables either.
+	SlowToolThreshold    time.Duration
+	LargeToolResultBytes int
+
+	// ToolCallObserver, when set, is given the duration and result size of
+	// every tool call, such as to serve them as metrics.
+	ToolCallObserver trace.ToolCallObserver
+
+	// Redactor, when set, masks secrets in the text of tool results; see
+	// Redactor.Middleware.
+	Redactor *Redactor
+}
+
+func (d ToolDependencies) logFetchConcurrency() int {
+	if d.LogFetchConcurrency > 0 {
+		return d.LogFetchConcurrency
+	}
+	return failureSummaryConcurrency
 }
 
 type contextKey struct{}
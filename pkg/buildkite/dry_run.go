@@ -0,0 +1,44 @@
+package buildkite
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// DryRunResult describes the Buildkite API request a write tool would make.
+// Write tools called with dry_run return it after validating their arguments,
+// instead of making the request.
+type DryRunResult struct {
+	DryRun  bool   `json:"dry_run"`
+	Action  string `json:"action"`
+	Method  string `json:"method"`
+	Path    string `json:"path"`
+	Request any    `json:"request,omitempty"`
+}
+
+// mcpDryRunResult returns the DryRunResult of a write tool. request is the
+// body that would be sent, or nil for requests without one.
+func mcpDryRunResult(ctx context.Context, span trace.Span, action, method, path string, request any) (*mcp.CallToolResult, any, error) {
+	span.SetAttributes(attribute.Bool("dry_run", true))
+	return mcpTextResult(ctx, span, &DryRunResult{
+		DryRun:  true,
+		Action:  action,
+		Method:  method,
+		Path:    path,
+		Request: request,
+	})
+}
+
+// apiPath formats a Buildkite REST API path, escaping each segment.
+func apiPath(format string, segments ...string) string {
+	escaped := make([]any, len(segments))
+	for i, segment := range segments {
+		escaped[i] = url.PathEscape(segment)
+	}
+	return fmt.Sprintf(format, escaped...)
+}
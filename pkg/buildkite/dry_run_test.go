@@ -0,0 +1,92 @@
+package buildkite
+
+import (
+	"context"
+	"testing"
+
+	"github.com/buildkite/go-buildkite/v5"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateBuildDryRun(t *testing.T) {
+	assert := require.New(t)
+
+	client := &MockBuildsClient{
+		CreateFunc: func(ctx context.Context, org string, pipeline string, b buildkite.CreateBuild) (buildkite.Build, *buildkite.Response, error) {
+			t.Fatal("dry run created a build")
+			return buildkite.Build{}, nil, nil
+		},
+	}
+
+	ctx := ContextWithDeps(context.Background(), ToolDependencies{BuildsClient: client})
+	_, handler, _ := CreateBuild()
+
+	result, _, err := handler(ctx, createMCPRequest(t, map[string]any{}), CreateBuildArgs{
+		OrgSlug:      "org",
+		PipelineSlug: "my pipeline",
+		Commit:       "abc123",
+		Branch:       "main",
+		Message:      "Test build",
+		DryRun:       true,
+	})
+	assert.NoError(err)
+	assert.False(result.IsError)
+
+	textContent := getTextResult(t, result)
+	assert.JSONEq(`{
+		"dry_run": true,
+		"action": "Create a build",
+		"method": "POST",
+		"path": "v2/organizations/org/pipelines/my%20pipeline/builds",
+		"request": {"commit": "abc123", "branch": "main", "message": "Test build", "author": {}, "clean_checkout": false, "ignore_pipeline_branch_filters": false}
+	}`, textContent.Text)
+}
+
+func TestUnblockJobDryRunValidatesParams(t *testing.T) {
+	assert := require.New(t)
+
+	ctx := ContextWithDeps(context.Background(), ToolDependencies{JobsClient: &MockJobsClient{}})
+	_, handler, _ := UnblockJob()
+
+	result, _, err := handler(ctx, createMCPRequest(t, map[string]any{}), UnblockJobArgs{
+		OrgSlug: "org",
+		DryRun:  true,
+	})
+	assert.NoError(err)
+	assert.True(result.IsError)
+	assert.Contains(getTextResult(t, result).Text, "missing required parameters")
+}
+
+func TestBuildAndJobDryRunsValidateParams(t *testing.T) {
+	ctx := ContextWithDeps(context.Background(), ToolDependencies{BuildsClient: &MockBuildsClient{}, JobsClient: &MockJobsClient{}})
+	request := createMCPRequest(t, map[string]any{})
+
+	_, createBuild, _ := CreateBuild()
+	_, cancelBuild, _ := CancelBuild()
+	_, rebuildBuild, _ := RebuildBuild()
+	_, retryJob, _ := RetryJob()
+
+	tests := map[string]func() (*mcp.CallToolResult, any, error){
+		"create_build": func() (*mcp.CallToolResult, any, error) {
+			return createBuild(ctx, request, CreateBuildArgs{OrgSlug: "org", Branch: "main", DryRun: true})
+		},
+		"cancel_build": func() (*mcp.CallToolResult, any, error) {
+			return cancelBuild(ctx, request, CancelBuildArgs{OrgSlug: "org", DryRun: true})
+		},
+		"rebuild_build": func() (*mcp.CallToolResult, any, error) {
+			return rebuildBuild(ctx, request, RebuildBuildArgs{OrgSlug: "org", DryRun: true})
+		},
+		"retry_job": func() (*mcp.CallToolResult, any, error) {
+			return retryJob(ctx, request, RetryJobArgs{OrgSlug: "org", PipelineSlug: "pipeline", BuildNumber: "1", DryRun: true})
+		},
+	}
+	for name, call := range tests {
+		t.Run(name, func(t *testing.T) {
+			result, _, err := call()
+			require.NoError(t, err)
+			require.True(t, result.IsError)
+			require.Contains(t, getTextResult(t, result).Text, "missing required parameter")
+		})
+	}
+}
@@ -1,7 +1,9 @@
 package buildkite
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"net/http"
 
 	"github.com/buildkite/buildkite-mcp-server/pkg/utils"
@@ -35,24 +37,73 @@ func isBuildkiteUnauthorized(err error) bool {
 	return errors.As(err, &errResp) && errResp.Response != nil && errResp.Response.StatusCode == http.StatusUnauthorized
 }
 
+// APIErrorResult is the structured content of tool errors caused by a
+// Buildkite API response, telling agents whether trying again may succeed.
+type APIErrorResult struct {
+	Error     string `json:"error"`
+	Status    int    `json:"status"`
+	Retryable bool   `json:"retryable"`
+}
+
+// isRetryableStatus reports whether a request that failed with status may
+// succeed if sent again: timeouts, rate limits and server errors. Other
+// client errors will fail the same way.
+func isRetryableStatus(status int) bool {
+	return status == http.StatusRequestTimeout || status == http.StatusTooManyRequests || status >= http.StatusInternalServerError
+}
+
 // handleBuildkiteError converts a Buildkite API error into tool handler return values.
 // On a 401 it returns (nil, nil, ErrUnauthorized) so the error propagates as a
 // JSON-RPC error and can be intercepted by middleware. On other errors it returns
 // a tool result error so the tool call succeeds at the JSON-RPC level but with an
-// error body.
-func handleBuildkiteError(err error) (*mcp.CallToolResult, any, error) {
+// error body. Errors from an API response also have an APIErrorResult as
+// structured content, a 403 names the scopes the token is missing (see
+// missingScopesHint), and a timeout suggests raising timeout_seconds.
+func handleBuildkiteError(ctx context.Context, err error) (*mcp.CallToolResult, any, error) {
 	if isBuildkiteUnauthorized(err) {
 		return nil, nil, ErrUnauthorized
 	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return utils.NewToolResultError(fmt.Sprintf("the tool call timed out before it finished: %v. Try again with a larger timeout_seconds, or ask for less, such as fewer pages", err)), nil, nil
+	}
 
+	message, status := buildkiteErrorMessage(err)
+	if status == http.StatusForbidden {
+		if hint := missingScopesHint(ctx); hint != "" {
+			message += "\n\n" + hint
+		}
+	}
+
+	result := utils.NewToolResultError(message)
+	if status > 0 {
+		// The text is the sanitized message, so both say the same.
+		result.StructuredContent = APIErrorResult{
+			Error:     result.Content[0].(*mcp.TextContent).Text,
+			Status:    status,
+			Retryable: isRetryableStatus(status),
+		}
+	}
+	return result, nil, nil
+}
+
+// buildkiteErrorMessage returns the message of an error, preferring the body
+// of an API error response, and the response's status, or 0 when the error
+// didn't come from a response.
+func buildkiteErrorMessage(err error) (string, int) {
+	var message string
+	var status int
 	var errResp *buildkite.ErrorResponse
 	if errors.As(err, &errResp) {
+		message = errResp.Message
 		if errResp.RawBody != nil {
-			return utils.NewToolResultError(string(errResp.RawBody)), nil, nil
+			message = string(errResp.RawBody)
 		}
-		if errResp.Message != "" {
-			return utils.NewToolResultError(errResp.Message), nil, nil
+		if errResp.Response != nil {
+			status = errResp.Response.StatusCode
 		}
 	}
-	return utils.NewToolResultError(err.Error()), nil, nil
+	if message == "" {
+		message = err.Error()
+	}
+	return message, status
 }
@@ -1,11 +1,13 @@
 package buildkite
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"testing"
 
 	"github.com/buildkite/go-buildkite/v5"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/stretchr/testify/require"
 )
 
@@ -14,7 +16,7 @@ func TestHandleBuildkiteError_Unauthorized(t *testing.T) {
 		Response: &http.Response{StatusCode: http.StatusUnauthorized},
 	}
 
-	result, data, err := handleBuildkiteError(errResp)
+	result, data, err := handleBuildkiteError(context.Background(), errResp)
 
 	require.Nil(t, result)
 	require.Nil(t, data)
@@ -27,7 +29,7 @@ func TestHandleBuildkiteError_Forbidden(t *testing.T) {
 		Message:  "Your access token doesn't have the read_suites scope",
 	}
 
-	result, data, err := handleBuildkiteError(errResp)
+	result, data, err := handleBuildkiteError(context.Background(), errResp)
 
 	require.NoError(t, err)
 	require.Nil(t, data)
@@ -43,7 +45,7 @@ func TestHandleBuildkiteError_WithRawBody(t *testing.T) {
 		RawBody:  []byte(`{"message":"validation failed"}`),
 	}
 
-	result, data, err := handleBuildkiteError(errResp)
+	result, data, err := handleBuildkiteError(context.Background(), errResp)
 
 	require.NoError(t, err)
 	require.Nil(t, data)
@@ -57,7 +59,7 @@ func TestHandleBuildkiteError_WithRawBody(t *testing.T) {
 func TestHandleBuildkiteError_GenericError(t *testing.T) {
 	genericErr := fmt.Errorf("connection refused")
 
-	result, data, err := handleBuildkiteError(genericErr)
+	result, data, err := handleBuildkiteError(context.Background(), genericErr)
 
 	require.NoError(t, err)
 	require.Nil(t, data)
@@ -73,7 +75,7 @@ func TestHandleBuildkiteError_NonUnauthorizedWithMessage(t *testing.T) {
 		Message:  "pipeline not found",
 	}
 
-	result, data, err := handleBuildkiteError(errResp)
+	result, data, err := handleBuildkiteError(context.Background(), errResp)
 
 	require.NoError(t, err)
 	require.Nil(t, data)
@@ -91,7 +93,7 @@ func TestHandleBuildkiteError_NilResponse(t *testing.T) {
 		Message:  "connection reset",
 	}
 
-	result, data, err := handleBuildkiteError(errResp)
+	result, data, err := handleBuildkiteError(context.Background(), errResp)
 
 	require.NoError(t, err)
 	require.Nil(t, data)
@@ -105,3 +107,69 @@ func TestErrUnauthorized_IsWrappable(t *testing.T) {
 	wrapped := fmt.Errorf("wrapped: %w", ErrUnauthorized)
 	require.ErrorIs(t, wrapped, ErrUnauthorized)
 }
+
+func TestHandleBuildkiteError_Retryable(t *testing.T) {
+	tests := []struct {
+		status    int
+		retryable bool
+	}{
+		{http.StatusBadRequest, false},
+		{http.StatusForbidden, false},
+		{http.StatusNotFound, false},
+		{http.StatusRequestTimeout, true},
+		{http.StatusUnprocessableEntity, false},
+		{http.StatusTooManyRequests, true},
+		{http.StatusInternalServerError, true},
+		{http.StatusBadGateway, true},
+		{http.StatusServiceUnavailable, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			errResp := &buildkite.ErrorResponse{
+				Response: &http.Response{StatusCode: tt.status},
+				Message:  "something went wrong",
+			}
+
+			result, _, err := handleBuildkiteError(context.Background(), errResp)
+
+			require.NoError(t, err)
+			require.True(t, result.IsError)
+			require.Equal(t, "something went wrong", getTextResult(t, result).Text)
+			require.Equal(t, APIErrorResult{Error: "something went wrong", Status: tt.status, Retryable: tt.retryable}, result.StructuredContent)
+		})
+	}
+}
+
+func TestHandleBuildkiteError_NoStructuredContentWithoutStatus(t *testing.T) {
+	result, _, err := handleBuildkiteError(context.Background(), fmt.Errorf("connection refused"))
+
+	require.NoError(t, err)
+	require.Nil(t, result.StructuredContent)
+}
+
+func TestHandleBuildkiteError_StructuredContentReachesClient(t *testing.T) {
+	ctx := context.Background()
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "1.0.0"}, nil)
+	mcp.AddTool(server, &mcp.Tool{Name: "rate_limited"}, func(ctx context.Context, _ *mcp.CallToolRequest, _ any) (*mcp.CallToolResult, any, error) {
+		return handleBuildkiteError(ctx, &buildkite.ErrorResponse{
+			Response: &http.Response{StatusCode: http.StatusTooManyRequests},
+			Message:  "Too many requests",
+		})
+	})
+
+	t1, t2 := mcp.NewInMemoryTransports()
+	_, err := server.Connect(ctx, t1, nil)
+	require.NoError(t, err)
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil)
+	session, err := client.Connect(ctx, t2, nil)
+	require.NoError(t, err)
+	defer session.Close()
+
+	result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "rate_limited"})
+	require.NoError(t, err)
+	require.True(t, result.IsError)
+	require.Equal(t, "Too many requests", getTextResult(t, result).Text)
+	require.Equal(t, map[string]any{"error": "Too many requests", "status": float64(429), "retryable": true}, result.StructuredContent)
+}
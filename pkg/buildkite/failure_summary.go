@@ -55,6 +55,7 @@ type GetBuildFailureSummaryArgs struct {
 	IncludeAnnotations     *bool  `json:"include_annotations,omitempty" jsonschema:"Include error and warning annotation bodies (default true)"`
 	IncludeFailedTests     *bool  `json:"include_failed_tests,omitempty" jsonschema:"Include failed Test Engine executions when the build has Test Engine runs (default true)"`
 	IncludeFailureExpanded bool   `json:"include_failure_expanded,omitempty" jsonschema:"Include expanded test failure details such as stack traces within the summary's bounded test-content budget"`
+	TimeoutSeconds         int    `json:"timeout_seconds,omitempty" jsonschema:"Give up after this many seconds (default 300, max 1800)"`
 }
 
 type BuildFailureSummaryBuild struct {
@@ -288,7 +289,7 @@ func readFailureLogTail(ctx context.Context, client BuildkiteLogsClient, args Ge
 		if readErr != nil {
 			return nil, fileInfo.RowCount, startRow > 0, contentTruncated, 0, fmt.Errorf("read log tail: %w", readErr)
 		}
-		terse := toTerseEntry(entry)
+		terse := toTerseEntry(entry, false)
 		var entryContentTruncated bool
 		terse.C, entryContentTruncated = truncateUTF8Bytes(terse.C, failureSummaryEntryContentByteLimit)
 		entries = append(entries, FailureSummaryLogEntry{TerseLogEntry: terse, ContentTruncated: entryContentTruncated})
@@ -325,8 +326,8 @@ func boundFailureLogEntries(entries []FailureSummaryLogEntry, limit int) ([]Fail
 	return result, first, true
 }
 
-func loadFailureLogs(ctx context.Context, client BuildkiteLogsClient, args GetBuildFailureSummaryArgs, sourceJobs []buildkite.Job, jobs []FailureSummaryJob, tail int) error {
-	semaphore := make(chan struct{}, failureSummaryConcurrency)
+func loadFailureLogs(ctx context.Context, client BuildkiteLogsClient, args GetBuildFailureSummaryArgs, sourceJobs []buildkite.Job, jobs []FailureSummaryJob, tail, concurrency int) error {
+	semaphore := make(chan struct{}, max(concurrency, 1))
 	unauthorized := make(chan error, len(sourceJobs))
 	var waitGroup sync.WaitGroup
 
@@ -716,6 +717,9 @@ func GetBuildFailureSummary() (mcp.Tool, mcp.ToolHandlerFor[GetBuildFailureSumma
 			ctx, span := trace.Start(ctx, "buildkite.GetBuildFailureSummary")
 			defer span.End()
 
+			ctx, cancel := withCallTimeout(ctx, args.TimeoutSeconds)
+			defer cancel()
+
 			logTail := boundedValue(args.LogTail, defaultFailureSummaryLogTail, maxFailureSummaryLogTail)
 			maxJobs := boundedValue(args.MaxJobs, defaultFailureSummaryJobs, maxFailureSummaryJobs)
 			maxAnnotations := boundedValue(args.MaxAnnotations, defaultFailureSummaryAnnotations, maxFailureSummaryAnnotations)
@@ -745,7 +749,7 @@ func GetBuildFailureSummary() (mcp.Tool, mcp.ToolHandlerFor[GetBuildFailureSumma
 				IncludeTestEngine: true,
 			})
 			if err != nil {
-				return handleBuildkiteError(err)
+				return handleBuildkiteError(ctx, err)
 			}
 
 			result := BuildFailureSummary{Build: failureSummaryBuild(build)}
@@ -760,7 +764,7 @@ func GetBuildFailureSummary() (mcp.Tool, mcp.ToolHandlerFor[GetBuildFailureSumma
 				PerPage:            maxJobs + 1,
 			})
 			if err != nil {
-				return handleBuildkiteError(err)
+				return handleBuildkiteError(ctx, err)
 			}
 
 			sourceJobs := make([]buildkite.Job, 0, maxJobs)
@@ -783,7 +787,7 @@ func GetBuildFailureSummary() (mcp.Tool, mcp.ToolHandlerFor[GetBuildFailureSumma
 				PerPage:            remainingJobs + 1,
 			})
 			if err != nil {
-				return handleBuildkiteError(err)
+				return handleBuildkiteError(ctx, err)
 			}
 			jobsTruncated = jobsTruncated || canceledJobsList.Links.Next != ""
 			for _, job := range canceledJobsList.Items {
@@ -804,7 +808,7 @@ func GetBuildFailureSummary() (mcp.Tool, mcp.ToolHandlerFor[GetBuildFailureSumma
 				PerPage:            remainingJobs + 1,
 			})
 			if err != nil {
-				return handleBuildkiteError(err)
+				return handleBuildkiteError(ctx, err)
 			}
 			jobsTruncated = jobsTruncated || downstreamJobsList.Links.Next != ""
 			for _, job := range downstreamJobsList.Items {
@@ -824,7 +828,7 @@ func GetBuildFailureSummary() (mcp.Tool, mcp.ToolHandlerFor[GetBuildFailureSumma
 			result.JobsTruncated = jobsTruncated
 
 			if defaultTrue(args.IncludeLogs) && deps.BuildkiteLogsClient != nil {
-				if err := loadFailureLogs(ctx, deps.BuildkiteLogsClient, args, sourceJobs, result.Jobs, logTail); err != nil {
+				if err := loadFailureLogs(ctx, deps.BuildkiteLogsClient, args, sourceJobs, result.Jobs, logTail, deps.logFetchConcurrency()); err != nil {
 					return nil, nil, err
 				}
 			}
@@ -716,7 +716,7 @@ func TestFailureSummaryOptionalLoadersPropagateUnauthorized(t *testing.T) {
 		}
 		jobs := []FailureSummaryJob{{}}
 
-		err := loadFailureLogs(context.Background(), client, args, []buildkite.Job{{ID: "job", State: "failed"}}, jobs, 1)
+		err := loadFailureLogs(context.Background(), client, args, []buildkite.Job{{ID: "job", State: "failed"}}, jobs, 1, failureSummaryConcurrency)
 		require.ErrorIs(t, err, ErrUnauthorized)
 		require.Empty(t, jobs[0].LogError)
 	})
@@ -743,7 +743,7 @@ func TestFailureSummaryOptionalLoadersPreserveOrdinaryErrors(t *testing.T) {
 		},
 	}
 	jobs := []FailureSummaryJob{{}}
-	require.NoError(t, loadFailureLogs(context.Background(), logsClient, args, []buildkite.Job{{ID: "job", State: "failed"}}, jobs, 1))
+	require.NoError(t, loadFailureLogs(context.Background(), logsClient, args, []buildkite.Job{{ID: "job", State: "failed"}}, jobs, 1, failureSummaryConcurrency))
 	require.Contains(t, jobs[0].LogError, "logs unavailable")
 
 	testClient := &MockTestExecutionsClient{
@@ -0,0 +1,104 @@
+package buildkite
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/buildkite/buildkite-mcp-server/pkg/utils"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// fieldTree is a set of field paths, split on dots. A node without children
+// keeps the whole value at that path.
+type fieldTree map[string]fieldTree
+
+func newFieldTree(fields []string) fieldTree {
+	tree := fieldTree{}
+	for _, field := range fields {
+		node := tree
+		for _, key := range strings.Split(strings.TrimSpace(field), ".") {
+			if key == "" {
+				continue
+			}
+			child, ok := node[key]
+			if !ok {
+				child = fieldTree{}
+				node[key] = child
+			}
+			node = child
+		}
+	}
+	return tree
+}
+
+// project keeps only the paths in tree of value. Arrays are projected element
+// by element, so "jobs.state" keeps the state of every job.
+func (tree fieldTree) project(value any) any {
+	if len(tree) == 0 {
+		return value
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		projected := make(map[string]any, len(tree))
+		for key, child := range tree {
+			if field, ok := v[key]; ok {
+				projected[key] = child.project(field)
+			}
+		}
+		return projected
+	case []any:
+		projected := make([]any, len(v))
+		for i, item := range v {
+			projected[i] = tree.project(item)
+		}
+		return projected
+	default:
+		return value
+	}
+}
+
+// projectListFields returns a list tool result with only the given fields of
+// each of its items, leaving the rest of the result as is. Fields are JSON
+// keys of an item, with dots for nested keys (e.g. "creator.name"). Fields an
+// item doesn't have are left out.
+func projectListFields(result any, fields []string) (any, error) {
+	tree := newFieldTree(fields)
+	if len(tree) == 0 {
+		return result, nil
+	}
+
+	encoded, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal result: %w", err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.UseNumber()
+	var root map[string]any
+	if err := decoder.Decode(&root); err != nil {
+		return nil, fmt.Errorf("failed to decode result: %w", err)
+	}
+
+	if items, ok := root["items"].([]any); ok {
+		root["items"] = tree.project(items)
+	}
+	return root, nil
+}
+
+// mcpListResult is mcpFormattedResult for list tools, keeping only the given
+// fields of each item when any are set. A markdown table then shows those
+// fields instead of columns.
+func mcpListResult(ctx context.Context, span trace.Span, result any, fields []string, format string, columns []string) (*mcp.CallToolResult, any, error) {
+	projected, err := projectListFields(result, fields)
+	if err != nil {
+		return utils.NewToolResultError(err.Error()), nil, nil
+	}
+	if len(fields) > 0 {
+		columns = fields
+	}
+	return mcpFormattedResult(ctx, span, projected, format, columns)
+}
@@ -0,0 +1,80 @@
+package buildkite
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestProjectListFields(t *testing.T) {
+	type job struct {
+		ID    string `json:"id"`
+		State string `json:"state"`
+	}
+	type creator struct {
+		Name  string `json:"name"`
+		Email string `json:"email"`
+	}
+	type item struct {
+		Number  int      `json:"number"`
+		Message string   `json:"message"`
+		Creator *creator `json:"creator,omitempty"`
+		Jobs    []job    `json:"jobs"`
+	}
+
+	result := PaginatedResult[item]{
+		Headers: map[string]string{"Link": "next"},
+		Items: []item{
+			{
+				Number:  1,
+				Message: "first",
+				Creator: &creator{Name: "Ada", Email: "ada@example.com"},
+				Jobs:    []job{{ID: "a", State: "passed"}, {ID: "b", State: "failed"}},
+			},
+			{Number: 2, Message: "second"},
+		},
+	}
+
+	t.Run("NestedPaths", func(t *testing.T) {
+		assert := require.New(t)
+
+		projected, err := projectListFields(result, []string{"number", "creator.name", "jobs.state", "missing"})
+		assert.NoError(err)
+
+		out, err := json.Marshal(projected)
+		assert.NoError(err)
+		assert.JSONEq(`{
+			"headers": {"Link": "next"},
+			"items": [
+				{"number": 1, "creator": {"name": "Ada"}, "jobs": [{"state": "passed"}, {"state": "failed"}]},
+				{"number": 2, "jobs": null}
+			]
+		}`, string(out))
+	})
+
+	t.Run("WholeNestedValue", func(t *testing.T) {
+		assert := require.New(t)
+
+		projected, err := projectListFields(result, []string{"jobs", "jobs.state"})
+		assert.NoError(err)
+
+		out, err := json.Marshal(projected)
+		assert.NoError(err)
+		assert.JSONEq(`{
+			"headers": {"Link": "next"},
+			"items": [
+				{"jobs": [{"state": "passed"}, {"state": "failed"}]},
+				{"jobs": null}
+			]
+		}`, string(out))
+	})
+
+	t.Run("NoFields", func(t *testing.T) {
+		assert := require.New(t)
+
+		projected, err := projectListFields(result, []string{" ", "."})
+		assert.NoError(err)
+		assert.Equal(result, projected)
+	})
+}
@@ -0,0 +1,110 @@
+package buildkite
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/buildkite/buildkite-mcp-server/pkg/trace"
+	"github.com/buildkite/buildkite-mcp-server/pkg/utils"
+	"github.com/buildkite/go-buildkite/v5"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// DefaultGraphQLURL is the endpoint of the Buildkite GraphQL API.
+const DefaultGraphQLURL = "https://graphql.buildkite.com/v1"
+
+type GraphQLClient interface {
+	Query(ctx context.Context, request GraphQLRequest) (json.RawMessage, *buildkite.Response, error)
+}
+
+type GraphQLRequest struct {
+	Query     string         `json:"query"`
+	Variables map[string]any `json:"variables,omitempty"`
+}
+
+// GraphQLAPIClient sends GraphQL requests to URL with a buildkite.Client, so
+// they carry the same API token, user agent and HTTP client as REST requests.
+type GraphQLAPIClient struct {
+	*buildkite.Client
+
+	// URL is the GraphQL endpoint, DefaultGraphQLURL when empty. It isn't
+	// derived from the client's base URL.
+	URL string
+}
+
+func (c *GraphQLAPIClient) endpoint() string {
+	if c.URL == "" {
+		return DefaultGraphQLURL
+	}
+	return c.URL
+}
+
+// Query implements GraphQLClient. The response is returned as it is,
+// including any GraphQL errors, which come with a 200 status.
+func (c *GraphQLAPIClient) Query(ctx context.Context, request GraphQLRequest) (json.RawMessage, *buildkite.Response, error) {
+	req, err := c.NewRequest(ctx, http.MethodPost, c.endpoint(), request)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var result json.RawMessage
+	resp, err := c.Do(req, &result)
+	if err != nil {
+		return nil, resp, err
+	}
+	return result, resp, nil
+}
+
+type GraphQLQueryArgs struct {
+	Query     string         `json:"query" jsonschema:"The GraphQL query or mutation"`
+	Variables map[string]any `json:"variables,omitempty" jsonschema:"Values of the variables the query declares"`
+	DryRun    bool           `json:"dry_run,omitempty" jsonschema:"Validate the arguments and return the request this would make, without making it"`
+}
+
+func GraphQLQuery() (mcp.Tool, mcp.ToolHandlerFor[GraphQLQueryArgs, any], []string) {
+	return mcp.Tool{
+			Name:        "graphql_query",
+			Description: "Run a query against the Buildkite GraphQL API and return its JSON response, for questions the other tools can't answer. Mutations are sent as well, so check what a query does before running it. GraphQL errors are returned in the response's errors field",
+			Annotations: &mcp.ToolAnnotations{
+				Title:           "GraphQL Query",
+				DestructiveHint: boolPtr(true),
+				OpenWorldHint:   boolPtr(true),
+			},
+		},
+		func(ctx context.Context, request *mcp.CallToolRequest, args GraphQLQueryArgs) (*mcp.CallToolResult, any, error) {
+			ctx, span := trace.Start(ctx, "buildkite.GraphQLQuery")
+			defer span.End()
+
+			span.SetAttributes(
+				attribute.Int("query_length", len(args.Query)),
+				attribute.Int("variable_count", len(args.Variables)),
+			)
+
+			if err := requireParams(map[string]string{"query": args.Query}); err != nil {
+				return utils.NewToolResultError(err.Error()), nil, nil
+			}
+
+			deps := DepsFromContext(ctx)
+			if deps.GraphQLClient == nil {
+				return utils.NewToolResultError("the GraphQL API isn't configured on this server"), nil, nil
+			}
+
+			graphQLRequest := GraphQLRequest{Query: args.Query, Variables: args.Variables}
+			if args.DryRun {
+				endpoint := DefaultGraphQLURL
+				if client, ok := deps.GraphQLClient.(*GraphQLAPIClient); ok {
+					endpoint = client.endpoint()
+				}
+				return mcpDryRunResult(ctx, span, "Run a GraphQL query", http.MethodPost, endpoint, graphQLRequest)
+			}
+
+			result, _, err := deps.GraphQLClient.Query(ctx, graphQLRequest)
+			if err != nil {
+				return handleBuildkiteError(ctx, err)
+			}
+
+			return mcpTextResult(ctx, span, result)
+		}, []string{"graphql"}
+}
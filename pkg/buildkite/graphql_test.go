@@ -0,0 +1,89 @@
+package buildkite
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/buildkite/go-buildkite/v5"
+	"github.com/stretchr/testify/require"
+)
+
+type MockGraphQLClient struct {
+	QueryFunc func(ctx context.Context, request GraphQLRequest) (json.RawMessage, *buildkite.Response, error)
+}
+
+func (m *MockGraphQLClient) Query(ctx context.Context, request GraphQLRequest) (json.RawMessage, *buildkite.Response, error) {
+	if m.QueryFunc != nil {
+		return m.QueryFunc(ctx, request)
+	}
+	return nil, nil, nil
+}
+
+var _ GraphQLClient = (*MockGraphQLClient)(nil)
+
+func TestGraphQLQuery(t *testing.T) {
+	t.Run("ReturnsResponse", func(t *testing.T) {
+		assert := require.New(t)
+
+		client := &MockGraphQLClient{
+			QueryFunc: func(ctx context.Context, request GraphQLRequest) (json.RawMessage, *buildkite.Response, error) {
+				assert.Equal("query($slug: ID!) { organization(slug: $slug) { name } }", request.Query)
+				assert.Equal(map[string]any{"slug": "acme"}, request.Variables)
+				return json.RawMessage(`{"data":{"organization":{"name":"Acme"}}}`), &buildkite.Response{}, nil
+			},
+		}
+
+		ctx := ContextWithDeps(context.Background(), ToolDependencies{GraphQLClient: client})
+		_, handler, scopes := GraphQLQuery()
+		assert.Equal([]string{"graphql"}, scopes)
+
+		result, _, err := handler(ctx, createMCPRequest(t, map[string]any{}), GraphQLQueryArgs{
+			Query:     "query($slug: ID!) { organization(slug: $slug) { name } }",
+			Variables: map[string]any{"slug": "acme"},
+		})
+		assert.NoError(err)
+		assert.JSONEq(`{"data":{"organization":{"name":"Acme"}}}`, getTextResult(t, result).Text)
+	})
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		assert := require.New(t)
+
+		client := &MockGraphQLClient{
+			QueryFunc: func(ctx context.Context, request GraphQLRequest) (json.RawMessage, *buildkite.Response, error) {
+				return nil, nil, &buildkite.ErrorResponse{Response: &http.Response{StatusCode: http.StatusUnauthorized}}
+			},
+		}
+
+		ctx := ContextWithDeps(context.Background(), ToolDependencies{GraphQLClient: client})
+		_, handler, _ := GraphQLQuery()
+
+		_, _, err := handler(ctx, createMCPRequest(t, map[string]any{}), GraphQLQueryArgs{Query: "{ viewer { user { name } } }"})
+		assert.ErrorIs(err, ErrUnauthorized)
+	})
+
+	t.Run("DryRunUsesConfiguredURL", func(t *testing.T) {
+		assert := require.New(t)
+
+		client := &GraphQLAPIClient{URL: "https://graphql-proxy.internal/v1"}
+		ctx := ContextWithDeps(context.Background(), ToolDependencies{GraphQLClient: client})
+		_, handler, _ := GraphQLQuery()
+
+		result, _, err := handler(ctx, createMCPRequest(t, map[string]any{}), GraphQLQueryArgs{Query: "{ viewer { user { name } } }", DryRun: true})
+		assert.NoError(err)
+		assert.Contains(getTextResult(t, result).Text, "https://graphql-proxy.internal/v1")
+	})
+
+	t.Run("MissingQuery", func(t *testing.T) {
+		assert := require.New(t)
+
+		ctx := ContextWithDeps(context.Background(), ToolDependencies{GraphQLClient: &MockGraphQLClient{}})
+		_, handler, _ := GraphQLQuery()
+
+		result, _, err := handler(ctx, createMCPRequest(t, map[string]any{}), GraphQLQueryArgs{})
+		assert.NoError(err)
+		assert.True(result.IsError)
+		assert.Equal("missing required parameter: query", getTextResult(t, result).Text)
+	})
+}
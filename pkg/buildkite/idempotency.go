@@ -0,0 +1,129 @@
+package buildkite
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/buildkite/go-buildkite/v5"
+)
+
+// createBuildIdempotencyWindow is how long create_build remembers the build
+// created for an idempotency_key.
+const createBuildIdempotencyWindow = 10 * time.Minute
+
+// errIdempotencyKeyReused is returned when an idempotency key is used again
+// with a different request.
+var errIdempotencyKeyReused = errors.New("idempotency key was already used with different arguments")
+
+// IdempotentBuilds remembers the builds created for idempotency keys, so a
+// client retrying a create_build it didn't see the result of gets the original
+// build back instead of a duplicate. Keys are only held in memory for the
+// window: they are lost on restart and not shared between processes.
+type IdempotentBuilds struct {
+	window time.Duration
+	now    func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*idempotentBuild
+}
+
+type idempotentBuild struct {
+	done    chan struct{} // closed once the create has returned
+	request string        // hash of the request the key was first used with
+	build   buildkite.Build
+	created bool
+	expires time.Time
+}
+
+// NewIdempotentBuilds returns the store for create_build's idempotency keys.
+// Keys aren't scoped to the caller's credentials, so one store mustn't be
+// shared by callers with different tokens.
+func NewIdempotentBuilds() *IdempotentBuilds {
+	return newIdempotentBuilds(createBuildIdempotencyWindow)
+}
+
+func newIdempotentBuilds(window time.Duration) *IdempotentBuilds {
+	return &IdempotentBuilds{
+		window:  window,
+		now:     time.Now,
+		entries: make(map[string]*idempotentBuild),
+	}
+}
+
+// create returns the build created for key within the window, or calls
+// createFn and remembers its build. request identifies the arguments, and a
+// key used again with other arguments returns errIdempotencyKeyReused.
+// Concurrent calls with the same key wait for the first instead of creating a
+// build each; a failed create isn't remembered, so the next call tries again.
+// replayed reports whether the build came from an earlier call.
+func (b *IdempotentBuilds) create(ctx context.Context, key, request string, createFn func() (buildkite.Build, error)) (build buildkite.Build, replayed bool, err error) {
+	for {
+		b.mu.Lock()
+		now := b.now()
+		for k, entry := range b.entries {
+			if entry.created && !now.Before(entry.expires) {
+				delete(b.entries, k)
+			}
+		}
+
+		entry, ok := b.entries[key]
+		if !ok {
+			entry = &idempotentBuild{done: make(chan struct{}), request: request}
+			b.entries[key] = entry
+			b.mu.Unlock()
+			break
+		}
+		b.mu.Unlock()
+
+		if entry.request != request {
+			return buildkite.Build{}, false, errIdempotencyKeyReused
+		}
+
+		select {
+		case <-entry.done:
+		case <-ctx.Done():
+			return buildkite.Build{}, false, ctx.Err()
+		}
+		if entry.created {
+			return entry.build, true, nil
+		}
+	}
+
+	build, err = createFn()
+
+	b.mu.Lock()
+	entry := b.entries[key]
+	if err != nil {
+		delete(b.entries, key)
+	} else {
+		entry.build = build
+		entry.created = true
+		entry.expires = b.now().Add(b.window)
+	}
+	b.mu.Unlock()
+	close(entry.done)
+
+	return build, false, err
+}
+
+// createBuildIdempotencyKey scopes an idempotency key to the pipeline, so the
+// same key used on two pipelines creates a build on each.
+func createBuildIdempotencyKey(org, pipeline, key string) string {
+	return org + "\x00" + pipeline + "\x00" + key
+}
+
+// createBuildRequestHash identifies a create build request, so a reused
+// idempotency key can be checked against the arguments it was first used with.
+func createBuildRequestHash(createBuild buildkite.CreateBuild) (string, error) {
+	body, err := json.Marshal(createBuild)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:]), nil
+}
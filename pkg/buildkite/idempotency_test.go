@@ -0,0 +1,160 @@
+package buildkite
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/buildkite/go-buildkite/v5"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdempotentBuilds(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("RepeatWithinWindow", func(t *testing.T) {
+		assert := require.New(t)
+
+		now := time.Unix(0, 0)
+		builds := newIdempotentBuilds(time.Minute)
+		builds.now = func() time.Time { return now }
+
+		calls := 0
+		create := func() (buildkite.Build, error) {
+			calls++
+			return buildkite.Build{Number: calls}, nil
+		}
+
+		build, replayed, err := builds.create(ctx, "key", "request", create)
+		assert.NoError(err)
+		assert.False(replayed)
+		assert.Equal(1, build.Number)
+
+		now = now.Add(59 * time.Second)
+		build, replayed, err = builds.create(ctx, "key", "request", create)
+		assert.NoError(err)
+		assert.True(replayed)
+		assert.Equal(1, build.Number)
+
+		now = now.Add(time.Second)
+		build, replayed, err = builds.create(ctx, "key", "request", create)
+		assert.NoError(err)
+		assert.False(replayed)
+		assert.Equal(2, build.Number)
+	})
+
+	t.Run("FailureIsNotRemembered", func(t *testing.T) {
+		assert := require.New(t)
+
+		builds := newIdempotentBuilds(time.Minute)
+
+		_, _, err := builds.create(ctx, "key", "request", func() (buildkite.Build, error) {
+			return buildkite.Build{}, errors.New("timeout")
+		})
+		assert.EqualError(err, "timeout")
+
+		build, replayed, err := builds.create(ctx, "key", "request", func() (buildkite.Build, error) {
+			return buildkite.Build{Number: 7}, nil
+		})
+		assert.NoError(err)
+		assert.False(replayed)
+		assert.Equal(7, build.Number)
+	})
+
+	t.Run("DifferentRequestIsRejected", func(t *testing.T) {
+		assert := require.New(t)
+
+		builds := newIdempotentBuilds(time.Minute)
+		create := func() (buildkite.Build, error) {
+			return buildkite.Build{Number: 1}, nil
+		}
+
+		_, _, err := builds.create(ctx, "key", "request", create)
+		assert.NoError(err)
+
+		_, _, err = builds.create(ctx, "key", "other request", create)
+		assert.ErrorIs(err, errIdempotencyKeyReused)
+	})
+
+	t.Run("ConcurrentCallsCreateOnce", func(t *testing.T) {
+		assert := require.New(t)
+
+		builds := newIdempotentBuilds(time.Minute)
+
+		var (
+			mu    sync.Mutex
+			calls int
+			wg    sync.WaitGroup
+		)
+		numbers := make([]int, 10)
+		for i := range numbers {
+			wg.Go(func() {
+				build, _, err := builds.create(ctx, "key", "request", func() (buildkite.Build, error) {
+					mu.Lock()
+					defer mu.Unlock()
+					calls++
+					return buildkite.Build{Number: 3}, nil
+				})
+				assert.NoError(err)
+				numbers[i] = build.Number
+			})
+		}
+		wg.Wait()
+
+		assert.Equal(1, calls)
+		for _, number := range numbers {
+			assert.Equal(3, number)
+		}
+	})
+}
+
+func TestCreateBuildIdempotencyKey(t *testing.T) {
+	assert := require.New(t)
+
+	calls := 0
+	client := &MockBuildsClient{
+		CreateFunc: func(ctx context.Context, org string, pipeline string, b buildkite.CreateBuild) (buildkite.Build, *buildkite.Response, error) {
+			calls++
+			return buildkite.Build{ID: pipeline, Number: calls}, &buildkite.Response{}, nil
+		},
+	}
+
+	deps := ToolDependencies{BuildsClient: client, IdempotentBuilds: NewIdempotentBuilds()}
+	_, handler, _ := CreateBuild()
+
+	createBuild := func(deps ToolDependencies, pipeline, commit, key string) *mcp.CallToolResult {
+		result, _, err := handler(ContextWithDeps(context.Background(), deps), createMCPRequest(t, map[string]any{}), CreateBuildArgs{
+			OrgSlug:        "org",
+			PipelineSlug:   pipeline,
+			Commit:         commit,
+			Branch:         "main",
+			Message:        "Test build",
+			IdempotencyKey: key,
+		})
+		assert.NoError(err)
+		return result
+	}
+
+	first := getTextResult(t, createBuild(deps, "idempotency-pipeline", "abc123", "key")).Text
+	assert.Equal(first, getTextResult(t, createBuild(deps, "idempotency-pipeline", "abc123", "key")).Text)
+	assert.Equal(1, calls)
+
+	// The key is scoped to the pipeline, and calls without one always create.
+	createBuild(deps, "other-pipeline", "abc123", "key")
+	createBuild(deps, "idempotency-pipeline", "abc123", "")
+	assert.Equal(3, calls)
+
+	result := createBuild(deps, "idempotency-pipeline", "def456", "key")
+	assert.True(result.IsError)
+	assert.Contains(getTextResult(t, result).Text, "already used with different arguments")
+
+	// Without a store, as when callers bring their own tokens, keys are refused.
+	deps.IdempotentBuilds = nil
+	result = createBuild(deps, "idempotency-pipeline", "abc123", "key")
+	assert.True(result.IsError)
+	assert.Contains(getTextResult(t, result).Text, "idempotency_key is not supported")
+	assert.Equal(3, calls)
+}
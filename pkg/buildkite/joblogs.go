@@ -2,9 +2,14 @@ package buildkite
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"iter"
 	"regexp"
+	"slices"
+	"strings"
 	"time"
 
 	buildkitelogs "github.com/buildkite/buildkite-logs"
@@ -24,36 +29,45 @@ var _ BuildkiteLogsClient = (*buildkitelogs.Client)(nil)
 
 // Common parameter structures for log tools
 type JobLogsBaseParams struct {
-	OrgSlug      string `json:"org_slug"`
-	PipelineSlug string `json:"pipeline_slug"`
-	BuildNumber  string `json:"build_number"`
-	JobID        string `json:"job_id"`
-	CacheTTL     string `json:"cache_ttl,omitempty"`
-	ForceRefresh bool   `json:"force_refresh,omitempty"`
+	OrgSlug        string `json:"org_slug"`
+	PipelineSlug   string `json:"pipeline_slug"`
+	BuildNumber    string `json:"build_number"`
+	JobID          string `json:"job_id"`
+	CacheTTL       string `json:"cache_ttl,omitempty"`
+	ForceRefresh   bool   `json:"force_refresh,omitempty"`
+	PreserveANSI   bool   `json:"preserve_ansi,omitempty" jsonschema:"Keep ANSI color and formatting sequences instead of returning plain text, with the escape byte shown as ␛ (e.g. ␛[31m for red). Only useful when formatting (e.g. red error blocks) matters"`
+	TimeoutSeconds int    `json:"timeout_seconds,omitempty" jsonschema:"Give up after this many seconds (default 300, max 1800)"`
 }
 
 type SearchLogsParams struct {
 	JobLogsBaseParams
 	Pattern       string `json:"pattern"`
-	Context       int    `json:"context,omitempty"`
-	BeforeContext int    `json:"before_context,omitempty"`
-	AfterContext  int    `json:"after_context,omitempty"`
+	Context       int    `json:"context,omitempty" jsonschema:"Lines to show before and after each match, like grep -C. Overrides before_context and after_context"`
+	BeforeContext int    `json:"before_context,omitempty" jsonschema:"Lines to show before each match, like grep -B. Defaults to 0"`
+	AfterContext  int    `json:"after_context,omitempty" jsonschema:"Lines to show after each match, like grep -A. Defaults to 0"`
 	CaseSensitive bool   `json:"case_sensitive,omitempty"`
 	InvertMatch   bool   `json:"invert_match,omitempty"`
 	Reverse       bool   `json:"reverse,omitempty"`
 	SeekStart     int    `json:"seek_start,omitempty"`
 	Limit         int    `json:"limit,omitempty"`
+	MaxMatches    int    `json:"max_matches,omitempty" jsonschema:"Stop collecting matches after this many (default 100). When more matches exist the response sets truncated and estimated_total_matches"`
 }
 
 type TailLogsParams struct {
 	JobLogsBaseParams
-	Tail int `json:"tail,omitempty"`
+	Tail   int    `json:"tail,omitempty"`
+	Cursor string `json:"cursor,omitempty" jsonschema:"Opaque cursor from a previous tail_logs response. Returns only entries appended since that call, up to tail entries"`
 }
 
 type ReadLogsParams struct {
 	JobLogsBaseParams
-	Seek  int `json:"seek,omitempty"`
-	Limit int `json:"limit,omitempty"`
+	Seek          int    `json:"seek,omitempty"`
+	Limit         int    `json:"limit,omitempty"`
+	FromTimestamp string `json:"from_timestamp,omitempty" jsonschema:"Only return entries logged at or after this RFC 3339 time (e.g. 2025-04-22T10:00:00Z)"`
+	ToTimestamp   string `json:"to_timestamp,omitempty" jsonschema:"Only return entries logged at or before this RFC 3339 time (e.g. 2025-04-22T10:05:00Z)"`
+	StartLine     int    `json:"start_line,omitempty" jsonschema:"First line to return (1-based, inclusive). Cannot be combined with seek"`
+	EndLine       int    `json:"end_line,omitempty" jsonschema:"Last line to return (1-based, inclusive)"`
+	Section       string `json:"section,omitempty" jsonschema:"Only return lines inside log groups (---, +++ or ~~~ headers) whose name contains this text, case-insensitive (e.g. Running tests)"`
 }
 
 type TerseLogEntry struct {
@@ -64,8 +78,10 @@ type TerseLogEntry struct {
 
 // TerseSearchResult mirrors buildkitelogs.SearchResult but with entries
 // reduced to TerseLogEntry, so search_logs matches the {ts,c,rn} format
-// documented for tail_logs and read_logs.
+// documented for tail_logs and read_logs. Line is the 1-based line number of
+// the match, usable directly as read_logs start_line.
 type TerseSearchResult struct {
+	Line          int64           `json:"line"`
 	Match         TerseLogEntry   `json:"match"`
 	BeforeContext []TerseLogEntry `json:"before_context,omitempty"`
 	AfterContext  []TerseLogEntry `json:"after_context,omitempty"`
@@ -75,13 +91,26 @@ type TerseSearchResult struct {
 type SearchResult = buildkitelogs.SearchResult
 
 type LogResponse struct {
-	Results     any   `json:"results,omitempty"`
-	Entries     any   `json:"entries,omitempty"`
-	MatchCount  int   `json:"match_count,omitempty"`
-	TotalRows   int64 `json:"total_rows,omitempty"`
-	QueryTimeMS int64 `json:"query_time_ms"`
+	Results               any    `json:"results,omitempty"`
+	Entries               any    `json:"entries,omitempty"`
+	MatchCount            int    `json:"match_count,omitempty"`
+	Truncated             bool   `json:"truncated,omitempty"`
+	EstimatedTotalMatches int    `json:"estimated_total_matches,omitempty"`
+	TotalRows             int64  `json:"total_rows,omitempty"`
+	Cursor                string `json:"cursor,omitempty"`
+	QueryTimeMS           int64  `json:"query_time_ms"`
 }
 
+const (
+	// defaultSearchLogsMaxMatches bounds search_logs responses when the
+	// caller doesn't pass max_matches, so a broad pattern can't flood the
+	// context.
+	defaultSearchLogsMaxMatches = 100
+	// maxSearchLogsCountedMatches stops counting matches past max_matches
+	// once reached, making estimated_total_matches a lower bound on huge logs.
+	maxSearchLogsCountedMatches = 10000
+)
+
 // Use the library's SearchOptions
 type SearchOptions = buildkitelogs.SearchOptions
 
@@ -90,6 +119,9 @@ func newParquetReader(ctx context.Context, client BuildkiteLogsClient, params Jo
 	ttl := parseCacheTTL(params.CacheTTL)
 
 	reader, err := client.NewReader(ctx, params.OrgSlug, params.PipelineSlug, params.BuildNumber, params.JobID, ttl, params.ForceRefresh)
+	if errors.Is(err, buildkitelogs.ErrLogTooLarge) {
+		return nil, fmt.Errorf("failed to create log reader: %w; the server's BKLOG_MAX_LOG_BYTES limit must be raised to read this log", err)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to create log reader: %w", err)
 	}
@@ -97,6 +129,39 @@ func newParquetReader(ctx context.Context, client BuildkiteLogsClient, params Jo
 	return reader, nil
 }
 
+// tailCursor is the decoded form of the opaque cursor returned by tail_logs.
+// Row is the first row the next call should return. Cursors index into the
+// cached parquet log, so they are only valid within a single cache generation:
+// once the cached log is re-downloaded the rows may no longer line up.
+type tailCursor struct {
+	JobID string `json:"job_id"`
+	Row   int64  `json:"row"`
+}
+
+func encodeTailCursor(jobID string, row int64) string {
+	b, _ := json.Marshal(tailCursor{JobID: jobID, Row: row})
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeTailCursor(cursor, jobID string) (int64, error) {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, fmt.Errorf("invalid cursor: %w", err)
+	}
+
+	var decoded tailCursor
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		return 0, fmt.Errorf("invalid cursor: %w", err)
+	}
+	if decoded.JobID != jobID {
+		return 0, fmt.Errorf("cursor was issued for job %q, not %q", decoded.JobID, jobID)
+	}
+	if decoded.Row < 0 {
+		return 0, fmt.Errorf("invalid cursor: negative row %d", decoded.Row)
+	}
+	return decoded.Row, nil
+}
+
 func parseCacheTTL(ttlStr string) time.Duration {
 	if ttlStr == "" {
 		return 30 * time.Second
@@ -108,6 +173,19 @@ func parseCacheTTL(ttlStr string) time.Duration {
 	return duration
 }
 
+// parseLogTimestamp parses an RFC 3339 time into the millisecond timestamps
+// stored in parquet logs. An empty value returns 0, meaning no bound.
+func parseLogTimestamp(name, value string) (int64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q: expected an RFC 3339 time such as 2025-04-22T10:00:00Z", name, value)
+	}
+	return t.UnixMilli(), nil
+}
+
 func validateSearchPattern(pattern string) error {
 	_, err := regexp.Compile(pattern)
 	if err != nil {
@@ -116,33 +194,115 @@ func validateSearchPattern(pattern string) error {
 	return nil
 }
 
-func toTerseEntry(entry buildkitelogs.ParquetLogEntry) TerseLogEntry {
+// ansiEscapeReplacer makes preserved ANSI sequences survive response
+// sanitization, which strips raw control characters such as ESC.
+var ansiEscapeReplacer = strings.NewReplacer("\x1b", "\u241b")
+
+func toTerseEntry(entry buildkitelogs.ParquetLogEntry, preserveANSI bool) TerseLogEntry {
 	terse := TerseLogEntry{C: entry.CleanContent(true), RN: entry.RowNumber}
+	if preserveANSI {
+		terse.C = ansiEscapeReplacer.Replace(entry.CleanContent(false))
+	}
 	if entry.HasTime() {
 		terse.TS = entry.Timestamp
 	}
 	return terse
 }
 
-func toTerseEntries(entries []buildkitelogs.ParquetLogEntry) []TerseLogEntry {
+func toTerseEntries(entries []buildkitelogs.ParquetLogEntry, preserveANSI bool) []TerseLogEntry {
 	result := make([]TerseLogEntry, len(entries))
 	for i, entry := range entries {
-		result[i] = toTerseEntry(entry)
+		result[i] = toTerseEntry(entry, preserveANSI)
 	}
 	return result
 }
 
-func formatLogEntries(entries []buildkitelogs.ParquetLogEntry) any {
-	return toTerseEntries(entries)
+func formatLogEntries(entries []buildkitelogs.ParquetLogEntry, preserveANSI bool) any {
+	return toTerseEntries(entries, preserveANSI)
+}
+
+// sectionName returns a group header's display name, without ANSI codes or
+// the ---, +++ or ~~~ marker.
+func sectionName(group string) string {
+	name := strings.TrimSpace(buildkitelogs.StripANSI(group))
+	for _, marker := range []string{"~~~ ", "--- ", "+++ "} {
+		if after, ok := strings.CutPrefix(name, marker); ok {
+			return strings.TrimSpace(after)
+		}
+	}
+	return name
+}
+
+func sectionNameMatches(name, section string) bool {
+	return strings.Contains(strings.ToLower(name), strings.ToLower(section))
+}
+
+func sectionMatches(entry buildkitelogs.ParquetLogEntry, section string) bool {
+	return entry.Group != "" && sectionNameMatches(sectionName(entry.Group), section)
+}
+
+// logSectionNames lists the distinct group names in a log in order of first
+// appearance, so a read_logs section miss can tell the caller what exists.
+func logSectionNames(ctx context.Context, reader *buildkitelogs.ParquetReader) ([]string, error) {
+	var names []string
+	seen := make(map[string]bool)
+	for entry, err := range reader.ReadEntriesIter(ctx) {
+		if err != nil {
+			return nil, err
+		}
+		if entry.Group == "" {
+			continue
+		}
+		name := sectionName(entry.Group)
+		if name != "" && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// mergeSearchContext drops context lines that are themselves matches or that
+// were already emitted as context for an earlier result, so overlapping
+// windows read like grep output instead of repeating the same rows.
+func mergeSearchContext(results []SearchResult) []SearchResult {
+	matched := make(map[int64]bool, len(results))
+	for _, r := range results {
+		matched[r.Match.RowNumber] = true
+	}
+
+	seen := make(map[int64]bool)
+	keep := func(entries []buildkitelogs.ParquetLogEntry) []buildkitelogs.ParquetLogEntry {
+		var kept []buildkitelogs.ParquetLogEntry
+		for _, entry := range entries {
+			if matched[entry.RowNumber] || seen[entry.RowNumber] {
+				continue
+			}
+			seen[entry.RowNumber] = true
+			kept = append(kept, entry)
+		}
+		return kept
+	}
+
+	merged := make([]SearchResult, len(results))
+	for i, r := range results {
+		merged[i] = SearchResult{
+			Match:         r.Match,
+			BeforeContext: keep(r.BeforeContext),
+			AfterContext:  keep(r.AfterContext),
+		}
+	}
+	return merged
 }
 
-func formatSearchResults(results []SearchResult) []TerseSearchResult {
+func formatSearchResults(results []SearchResult, preserveANSI bool) []TerseSearchResult {
 	terse := make([]TerseSearchResult, len(results))
 	for i, r := range results {
 		terse[i] = TerseSearchResult{
-			Match:         toTerseEntry(r.Match),
-			BeforeContext: toTerseEntries(r.BeforeContext),
-			AfterContext:  toTerseEntries(r.AfterContext),
+			Line:          r.Match.RowNumber + 1,
+			Match:         toTerseEntry(r.Match, preserveANSI),
+			BeforeContext: toTerseEntries(r.BeforeContext, preserveANSI),
+			AfterContext:  toTerseEntries(r.AfterContext, preserveANSI),
 		}
 	}
 	return terse
@@ -152,7 +312,7 @@ func formatSearchResults(results []SearchResult) []TerseSearchResult {
 func SearchLogs() (mcp.Tool, mcp.ToolHandlerFor[SearchLogsParams, any], []string) {
 	return mcp.Tool{
 			Name:        "search_logs",
-			Description: "Search log entries using regex patterns with optional context lines. For recent failures, try 'tail_logs' first, then use search_logs with patterns like 'error|failed|exception' and limit: 10-20. The json format: {ts: timestamp_ms, c: content, rn: row_number}.",
+			Description: "Search log entries using regex patterns with optional context lines (context, before_context, after_context). Overlapping context windows are merged so each row appears at most once. For recent failures, try 'tail_logs' first, then use search_logs with patterns like 'error|failed|exception' and limit: 10-20. Each result carries 'line', the 1-based line number of the match; pass it to read_logs as start_line/end_line to drill into that region. The json format: {line: line_number, match: {ts: timestamp_ms, c: content, rn: row_number}, before_context: [...], after_context: [...]}.",
 			Annotations: &mcp.ToolAnnotations{
 				Title:        "Search Logs",
 				ReadOnlyHint: true,
@@ -162,8 +322,15 @@ func SearchLogs() (mcp.Tool, mcp.ToolHandlerFor[SearchLogsParams, any], []string
 			ctx, span := trace.Start(ctx, "buildkite.SearchLogs")
 			defer span.End()
 
+			ctx, cancel := withCallTimeout(ctx, params.TimeoutSeconds)
+			defer cancel()
+
 			startTime := time.Now()
 
+			if params.MaxMatches <= 0 {
+				params.MaxMatches = defaultSearchLogsMaxMatches
+			}
+
 			span.SetAttributes(
 				attribute.String("org_slug", params.OrgSlug),
 				attribute.String("pipeline_slug", params.PipelineSlug),
@@ -175,6 +342,7 @@ func SearchLogs() (mcp.Tool, mcp.ToolHandlerFor[SearchLogsParams, any], []string
 				attribute.Bool("invert_match", params.InvertMatch),
 				attribute.Bool("reverse", params.Reverse),
 				attribute.Int("limit", params.Limit),
+				attribute.Int("max_matches", params.MaxMatches),
 			)
 
 			if err := validateSearchPattern(params.Pattern); err != nil {
@@ -184,7 +352,7 @@ func SearchLogs() (mcp.Tool, mcp.ToolHandlerFor[SearchLogsParams, any], []string
 			deps := DepsFromContext(ctx)
 			reader, err := newParquetReader(ctx, deps.BuildkiteLogsClient, params.JobLogsBaseParams)
 			if err != nil {
-				return handleBuildkiteError(err)
+				return handleBuildkiteError(ctx, err)
 			}
 			defer reader.Close()
 
@@ -206,27 +374,39 @@ func SearchLogs() (mcp.Tool, mcp.ToolHandlerFor[SearchLogsParams, any], []string
 					return utils.NewToolResultError(fmt.Sprintf("Search error: %v", err)), nil, nil
 				}
 
-				results = append(results, result)
 				count++
+				if count <= params.MaxMatches {
+					results = append(results, result)
+				}
 
 				// Apply limit if specified
 				if params.Limit > 0 && count >= params.Limit {
 					break
 				}
+				// Keep counting past max_matches so the caller knows how much
+				// was left out, but stop once the estimate is good enough.
+				if count >= max(maxSearchLogsCountedMatches, params.MaxMatches+1) {
+					break
+				}
 			}
 
 			queryTime := time.Since(startTime)
 			response := LogResponse{
-				Results:     formatSearchResults(results),
+				Results:     formatSearchResults(mergeSearchContext(results), params.PreserveANSI),
 				MatchCount:  len(results),
 				QueryTimeMS: queryTime.Milliseconds(),
 			}
+			if count > len(results) {
+				response.Truncated = true
+				response.EstimatedTotalMatches = count
+			}
 
 			span.SetAttributes(
 				attribute.Int("item_count", len(results)),
+				attribute.Bool("truncated", response.Truncated),
 			)
 
-			return mcpTextResult(span, &response)
+			return mcpTextResult(ctx, span, &response)
 		},
 		[]string{"read_build_logs"}
 }
@@ -235,7 +415,7 @@ func SearchLogs() (mcp.Tool, mcp.ToolHandlerFor[SearchLogsParams, any], []string
 func TailLogs() (mcp.Tool, mcp.ToolHandlerFor[TailLogsParams, any], []string) {
 	return mcp.Tool{
 			Name:        "tail_logs",
-			Description: "Show the last N entries from the log file. RECOMMENDED for failure diagnosis - most build failures appear in the final log entries. More token-efficient than read_logs for recent issues. The json format: {ts: timestamp_ms, c: content, rn: row_number}.",
+			Description: "Show the last N entries from the log file. RECOMMENDED for failure diagnosis - most build failures appear in the final log entries. More token-efficient than read_logs for recent issues. To follow a running job, pass the returned cursor on the next call (with a short cache_ttl or force_refresh) to get only entries appended since; cursors are only valid while the same cached log is in use. The json format: {ts: timestamp_ms, c: content, rn: row_number}.",
 			Annotations: &mcp.ToolAnnotations{
 				Title:        "Tail Logs",
 				ReadOnlyHint: true,
@@ -245,6 +425,9 @@ func TailLogs() (mcp.Tool, mcp.ToolHandlerFor[TailLogsParams, any], []string) {
 			ctx, span := trace.Start(ctx, "buildkite.TailLogs")
 			defer span.End()
 
+			ctx, cancel := withCallTimeout(ctx, params.TimeoutSeconds)
+			defer cancel()
+
 			startTime := time.Now()
 
 			// Set defaults
@@ -258,12 +441,13 @@ func TailLogs() (mcp.Tool, mcp.ToolHandlerFor[TailLogsParams, any], []string) {
 				attribute.String("build_number", params.BuildNumber),
 				attribute.String("job_id", params.JobID),
 				attribute.Int("tail", params.Tail),
+				attribute.Bool("cursor", params.Cursor != ""),
 			)
 
 			deps := DepsFromContext(ctx)
 			reader, err := newParquetReader(ctx, deps.BuildkiteLogsClient, params.JobLogsBaseParams)
 			if err != nil {
-				return handleBuildkiteError(err)
+				return handleBuildkiteError(ctx, err)
 			}
 			defer reader.Close()
 
@@ -273,21 +457,40 @@ func TailLogs() (mcp.Tool, mcp.ToolHandlerFor[TailLogsParams, any], []string) {
 			}
 
 			startRow := max(fileInfo.RowCount-int64(params.Tail), 0)
+			if params.Cursor != "" {
+				cursorRow, err := decodeTailCursor(params.Cursor, params.JobID)
+				if err != nil {
+					return utils.NewToolResultError(err.Error()), nil, nil
+				}
+				if cursorRow > fileInfo.RowCount {
+					return utils.NewToolResultError("cursor is past the end of the log; the cached log may have been refreshed, call tail_logs again without a cursor"), nil, nil
+				}
+				startRow = cursorRow
+			}
 
 			var entries []buildkitelogs.ParquetLogEntry
-			for entry, err := range reader.SeekToRow(ctx, startRow) {
-				if err != nil {
-					return utils.NewToolResultError(fmt.Sprintf("Failed to read tail entries: %v", err)), nil, nil
+			if startRow < fileInfo.RowCount {
+				for entry, err := range reader.SeekToRow(ctx, startRow) {
+					if err != nil {
+						return utils.NewToolResultError(fmt.Sprintf("Failed to read tail entries: %v", err)), nil, nil
+					}
+					entries = append(entries, entry)
+
+					// When following a cursor, return at most tail entries so the
+					// next cursor picks up exactly where this page stopped.
+					if len(entries) >= params.Tail {
+						break
+					}
 				}
-				entries = append(entries, entry)
 			}
 
 			queryTime := time.Since(startTime)
-			formattedEntries := formatLogEntries(entries)
+			formattedEntries := formatLogEntries(entries, params.PreserveANSI)
 
 			response := LogResponse{
 				Entries:     formattedEntries,
 				TotalRows:   fileInfo.RowCount,
+				Cursor:      encodeTailCursor(params.JobID, startRow+int64(len(entries))),
 				QueryTimeMS: queryTime.Milliseconds(),
 			}
 
@@ -295,7 +498,7 @@ func TailLogs() (mcp.Tool, mcp.ToolHandlerFor[TailLogsParams, any], []string) {
 				attribute.Int("item_count", len(entries)),
 			)
 
-			return mcpTextResult(span, &response)
+			return mcpTextResult(ctx, span, &response)
 		},
 		[]string{"read_build_logs"}
 }
@@ -304,7 +507,7 @@ func TailLogs() (mcp.Tool, mcp.ToolHandlerFor[TailLogsParams, any], []string) {
 func ReadLogs() (mcp.Tool, mcp.ToolHandlerFor[ReadLogsParams, any], []string) {
 	return mcp.Tool{
 			Name:        "read_logs",
-			Description: "Read log entries from the file, optionally starting from a specific row number. ALWAYS use 'limit' parameter to avoid excessive tokens. For recent failures, use 'tail_logs' instead. Recommended limits: investigation (100-500), exploration (use seek + small limits). The json format: {ts: timestamp_ms, c: content, rn: row_number}.",
+			Description: "Read log entries from the file, optionally starting from a specific row number. ALWAYS use 'limit' parameter to avoid excessive tokens. For recent failures, use 'tail_logs' instead. Recommended limits: investigation (100-500), exploration (use seek + small limits). Use from_timestamp/to_timestamp (RFC 3339) to read only a time window, or start_line/end_line (1-based, inclusive) to read an exact slice; line N is row N-1, and total_rows is returned so you can paginate. Use section to read only one collapsed group such as \"Running tests\"; if nothing matches, the error lists the available section names. The json format: {ts: timestamp_ms, c: content, rn: row_number}.",
 			Annotations: &mcp.ToolAnnotations{
 				Title:        "Read Logs",
 				ReadOnlyHint: true,
@@ -314,6 +517,9 @@ func ReadLogs() (mcp.Tool, mcp.ToolHandlerFor[ReadLogsParams, any], []string) {
 			ctx, span := trace.Start(ctx, "buildkite.ReadLogs")
 			defer span.End()
 
+			ctx, cancel := withCallTimeout(ctx, params.TimeoutSeconds)
+			defer cancel()
+
 			startTime := time.Now()
 
 			span.SetAttributes(
@@ -323,30 +529,98 @@ func ReadLogs() (mcp.Tool, mcp.ToolHandlerFor[ReadLogsParams, any], []string) {
 				attribute.String("job_id", params.JobID),
 				attribute.Int("seek", params.Seek),
 				attribute.Int("limit", params.Limit),
+				attribute.String("from_timestamp", params.FromTimestamp),
+				attribute.String("to_timestamp", params.ToTimestamp),
+				attribute.Int("start_line", params.StartLine),
+				attribute.Int("end_line", params.EndLine),
+				attribute.String("section", params.Section),
 			)
 
+			if params.StartLine < 0 || params.EndLine < 0 {
+				return utils.NewToolResultError("start_line and end_line must be positive"), nil, nil
+			}
+			if params.EndLine > 0 && params.StartLine > params.EndLine {
+				return utils.NewToolResultError("start_line must not be after end_line"), nil, nil
+			}
+			if params.StartLine > 0 && params.Seek > 0 {
+				return utils.NewToolResultError("seek cannot be combined with start_line"), nil, nil
+			}
+			lineRange := params.StartLine > 0 || params.EndLine > 0
+
+			from, err := parseLogTimestamp("from_timestamp", params.FromTimestamp)
+			if err != nil {
+				return utils.NewToolResultError(err.Error()), nil, nil
+			}
+			to, err := parseLogTimestamp("to_timestamp", params.ToTimestamp)
+			if err != nil {
+				return utils.NewToolResultError(err.Error()), nil, nil
+			}
+			if from != 0 && to != 0 && from > to {
+				return utils.NewToolResultError("from_timestamp must not be after to_timestamp"), nil, nil
+			}
+
 			deps := DepsFromContext(ctx)
 			reader, err := newParquetReader(ctx, deps.BuildkiteLogsClient, params.JobLogsBaseParams)
 			if err != nil {
-				return handleBuildkiteError(err)
+				return handleBuildkiteError(ctx, err)
 			}
 			defer reader.Close()
 
+			var totalRows int64
+			if lineRange {
+				fileInfo, err := reader.GetFileInfo()
+				if err != nil {
+					return utils.NewToolResultError(fmt.Sprintf("Failed to get file info: %v", err)), nil, nil
+				}
+				totalRows = fileInfo.RowCount
+			}
+
+			// Lines are 1-based, rows are 0-based.
+			seek := int64(params.Seek)
+			if params.StartLine > 0 {
+				seek = int64(params.StartLine - 1)
+			}
+			endRow := int64(-1)
+			if params.EndLine > 0 {
+				endRow = int64(params.EndLine - 1)
+			}
+
 			var entries []buildkitelogs.ParquetLogEntry
 			count := 0
 
 			var entryIter iter.Seq2[buildkitelogs.ParquetLogEntry, error]
-			if params.Seek > 0 {
-				entryIter = reader.SeekToRow(ctx, int64(params.Seek))
+			if lineRange && seek >= totalRows {
+				entryIter = func(func(buildkitelogs.ParquetLogEntry, error) bool) {}
+			} else if seek > 0 {
+				entryIter = reader.SeekToRow(ctx, seek)
 			} else {
 				entryIter = reader.ReadEntriesIter(ctx)
 			}
 
+			// Lines without their own timestamp inherit the last one seen, so
+			// continuation lines stay with the entry that produced them.
+			var lastTimestamp int64
 			for entry, err := range entryIter {
 				if err != nil {
 					return utils.NewToolResultError(fmt.Sprintf("Failed to read entries: %v", err)), nil, nil
 				}
 
+				if endRow >= 0 && entry.RowNumber > endRow {
+					break
+				}
+				if entry.HasTime() {
+					lastTimestamp = entry.Timestamp
+				}
+				if from != 0 && lastTimestamp < from {
+					continue
+				}
+				if to != 0 && lastTimestamp > to {
+					break
+				}
+				if params.Section != "" && !sectionMatches(entry, params.Section) {
+					continue
+				}
+
 				entries = append(entries, entry)
 				count++
 
@@ -356,11 +630,25 @@ func ReadLogs() (mcp.Tool, mcp.ToolHandlerFor[ReadLogsParams, any], []string) {
 				}
 			}
 
+			if params.Section != "" && len(entries) == 0 {
+				sections, err := logSectionNames(ctx, reader)
+				if err != nil {
+					return utils.NewToolResultError(fmt.Sprintf("Failed to read entries: %v", err)), nil, nil
+				}
+				if !slices.ContainsFunc(sections, func(name string) bool { return sectionNameMatches(name, params.Section) }) {
+					if len(sections) == 0 {
+						return utils.NewToolResultError(fmt.Sprintf("No section matches %q; this log has no sections", params.Section)), nil, nil
+					}
+					return utils.NewToolResultError(fmt.Sprintf("No section matches %q; available sections: %s", params.Section, strings.Join(sections, "; "))), nil, nil
+				}
+			}
+
 			queryTime := time.Since(startTime)
-			formattedEntries := formatLogEntries(entries)
+			formattedEntries := formatLogEntries(entries, params.PreserveANSI)
 
 			response := LogResponse{
 				Entries:     formattedEntries,
+				TotalRows:   totalRows,
 				QueryTimeMS: queryTime.Milliseconds(),
 			}
 
@@ -368,7 +656,7 @@ func ReadLogs() (mcp.Tool, mcp.ToolHandlerFor[ReadLogsParams, any], []string) {
 				attribute.Int("item_count", len(entries)),
 			)
 
-			return mcpTextResult(span, &response)
+			return mcpTextResult(ctx, span, &response)
 		},
 		[]string{"read_build_logs"}
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"os"
 	"testing"
 	"time"
@@ -170,14 +171,6 @@ func TestSearchLogsHandler(t *testing.T) {
 func writeTestParquetFile(t *testing.T, filename string, contents []string) {
 	t.Helper()
 
-	f, err := os.Create(filename)
-	require.NoError(t, err)
-	defer f.Close()
-
-	writer, err := buildkitelogs.NewParquetWriter(f)
-	require.NoError(t, err)
-	defer writer.Close()
-
 	baseTime := time.Date(2025, 4, 22, 21, 43, 29, 0, time.UTC)
 	entries := make([]*logparser.Entry, len(contents))
 	for i, content := range contents {
@@ -188,6 +181,22 @@ func writeTestParquetFile(t *testing.T, filename string, contents []string) {
 		}
 	}
 
+	writeTestParquetEntries(t, filename, entries)
+}
+
+// writeTestParquetEntries writes pre-built entries, for tests that need control
+// over timestamps or groups.
+func writeTestParquetEntries(t *testing.T, filename string, entries []*logparser.Entry) {
+	t.Helper()
+
+	f, err := os.Create(filename)
+	require.NoError(t, err)
+	defer f.Close()
+
+	writer, err := buildkitelogs.NewParquetWriter(f)
+	require.NoError(t, err)
+	defer writer.Close()
+
 	require.NoError(t, writer.WriteBatch(entries))
 }
 
@@ -247,6 +256,7 @@ func TestSearchLogsHandler_SeekStart(t *testing.T) {
 	all := search(0)
 	assert.Len(all, 3)
 	assert.Equal(int64(4), all[0].Match.RN)
+	assert.Equal(int64(5), all[0].Line)
 
 	// With seek_start: 3, the search should start at row 3 and go backwards,
 	// so the match at row 4 (after the seek point) must be excluded.
@@ -295,6 +305,8 @@ func TestSearchLogsHandler_TerseFormat(t *testing.T) {
 	// The documented fields must be present under their terse names.
 	assert.Contains(text, `"rn":1`)
 	assert.Contains(text, `"c":"test failed: assertion error"`)
+	// The 1-based line number feeds read_logs start_line directly.
+	assert.Contains(text, `"line":2`)
 
 	// The library's raw field names, and its extra undocumented fields,
 	// must not leak through.
@@ -316,11 +328,52 @@ func TestToTerseEntry_RowZero(t *testing.T) {
 		Flags:     1, // HasTimestamp
 	}
 
-	b, err := json.Marshal(toTerseEntry(entry))
+	b, err := json.Marshal(toTerseEntry(entry, false))
 	require.NoError(t, err)
 	require.Contains(t, string(b), `"rn":0`)
 }
 
+func TestTailLogsHandler_PreserveANSI(t *testing.T) {
+	assert := require.New(t)
+
+	testFile := t.TempDir() + "/ansi.parquet"
+	writeTestParquetFile(t, testFile, []string{"\x1b[31mError: boom\x1b[0m"})
+
+	mockClient := &MockBuildkiteLogsClient{
+		NewReaderFunc: func(ctx context.Context, org, pipeline, build, job string, ttl time.Duration, forceRefresh bool) (*buildkitelogs.ParquetReader, error) {
+			return buildkitelogs.NewParquetReader(testFile), nil
+		},
+	}
+
+	ctx := ContextWithDeps(context.Background(), ToolDependencies{BuildkiteLogsClient: mockClient})
+	_, handler, _ := TailLogs()
+
+	tail := func(preserveANSI bool) string {
+		params := TailLogsParams{
+			JobLogsBaseParams: JobLogsBaseParams{
+				OrgSlug:      "test-org",
+				PipelineSlug: "test-pipeline",
+				BuildNumber:  "123",
+				JobID:        "job-456",
+				PreserveANSI: preserveANSI,
+			},
+		}
+		result, _, err := handler(ctx, createMCPRequest(t, map[string]any{}), params)
+		assert.NoError(err)
+		assert.False(result.IsError)
+
+		var resp struct {
+			Entries []TerseLogEntry `json:"entries"`
+		}
+		assert.NoError(json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &resp))
+		assert.Len(resp.Entries, 1)
+		return resp.Entries[0].C
+	}
+
+	assert.Equal("Error: boom", tail(false))
+	assert.Equal("␛[31mError: boom␛[0m", tail(true))
+}
+
 func TestTailLogsHandler(t *testing.T) {
 	assert := require.New(t)
 
@@ -437,3 +490,471 @@ func TestNewParquetReader(t *testing.T) {
 		assert.Contains(err.Error(), "failed to create log reader")
 	})
 }
+
+func TestSearchLogsHandler_MergesOverlappingContext(t *testing.T) {
+	assert := require.New(t)
+
+	testFile := t.TempDir() + "/overlapping_context.parquet"
+	writeTestParquetFile(t, testFile, []string{
+		"step one",         // row 0
+		"error: first",     // row 1
+		"between",          // row 2
+		"error: second",    // row 3
+		"after second",     // row 4
+		"still after",      // row 5
+		"unrelated output", // row 6
+	})
+
+	mockClient := &MockBuildkiteLogsClient{
+		NewReaderFunc: func(ctx context.Context, org, pipeline, build, job string, ttl time.Duration, forceRefresh bool) (*buildkitelogs.ParquetReader, error) {
+			return buildkitelogs.NewParquetReader(testFile), nil
+		},
+	}
+
+	ctx := ContextWithDeps(context.Background(), ToolDependencies{BuildkiteLogsClient: mockClient})
+	_, handler, _ := SearchLogs()
+
+	params := SearchLogsParams{
+		JobLogsBaseParams: JobLogsBaseParams{
+			OrgSlug:      "test-org",
+			PipelineSlug: "test-pipeline",
+			BuildNumber:  "123",
+			JobID:        "job-456",
+		},
+		Pattern:       "error",
+		BeforeContext: 1,
+		AfterContext:  2,
+	}
+
+	result, _, err := handler(ctx, createMCPRequest(t, map[string]any{}), params)
+	assert.NoError(err)
+
+	var resp struct {
+		Results []TerseSearchResult `json:"results"`
+	}
+	assert.NoError(json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &resp))
+	assert.Len(resp.Results, 2)
+
+	var rows []int64
+	for _, r := range resp.Results {
+		for _, e := range r.BeforeContext {
+			rows = append(rows, e.RN)
+		}
+		rows = append(rows, r.Match.RN)
+		for _, e := range r.AfterContext {
+			rows = append(rows, e.RN)
+		}
+	}
+
+	// Row 2 sits in both windows and row 3 is itself a match, so each must
+	// appear exactly once across the whole response.
+	assert.Equal([]int64{0, 1, 2, 3, 4, 5}, rows)
+}
+
+func TestSearchLogsHandler_Truncation(t *testing.T) {
+	assert := require.New(t)
+
+	testFile := t.TempDir() + "/truncation.parquet"
+	contents := make([]string, 0, 150)
+	for i := range 150 {
+		contents = append(contents, fmt.Sprintf("error: failure %d", i))
+	}
+	writeTestParquetFile(t, testFile, contents)
+
+	mockClient := &MockBuildkiteLogsClient{
+		NewReaderFunc: func(ctx context.Context, org, pipeline, build, job string, ttl time.Duration, forceRefresh bool) (*buildkitelogs.ParquetReader, error) {
+			return buildkitelogs.NewParquetReader(testFile), nil
+		},
+	}
+
+	ctx := ContextWithDeps(context.Background(), ToolDependencies{BuildkiteLogsClient: mockClient})
+	_, handler, _ := SearchLogs()
+
+	search := func(limit, maxMatches int) LogResponse {
+		params := SearchLogsParams{
+			JobLogsBaseParams: JobLogsBaseParams{
+				OrgSlug:      "test-org",
+				PipelineSlug: "test-pipeline",
+				BuildNumber:  "123",
+				JobID:        "job-456",
+			},
+			Pattern:    "error",
+			Limit:      limit,
+			MaxMatches: maxMatches,
+		}
+
+		result, _, err := handler(ctx, createMCPRequest(t, map[string]any{}), params)
+		assert.NoError(err)
+
+		var resp LogResponse
+		assert.NoError(json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &resp))
+		return resp
+	}
+
+	t.Run("default max_matches", func(t *testing.T) {
+		resp := search(0, 0)
+		assert.Equal(defaultSearchLogsMaxMatches, resp.MatchCount)
+		assert.True(resp.Truncated)
+		assert.Equal(150, resp.EstimatedTotalMatches)
+	})
+
+	t.Run("explicit max_matches", func(t *testing.T) {
+		resp := search(0, 5)
+		assert.Equal(5, resp.MatchCount)
+		assert.True(resp.Truncated)
+		assert.Equal(150, resp.EstimatedTotalMatches)
+	})
+
+	t.Run("max_matches above match count", func(t *testing.T) {
+		resp := search(0, 200)
+		assert.Equal(150, resp.MatchCount)
+		assert.False(resp.Truncated)
+		assert.Zero(resp.EstimatedTotalMatches)
+	})
+
+	t.Run("limit stops the search", func(t *testing.T) {
+		resp := search(5, 0)
+		assert.Equal(5, resp.MatchCount)
+		assert.False(resp.Truncated)
+	})
+
+	t.Run("limit above max_matches", func(t *testing.T) {
+		resp := search(120, 0)
+		assert.Equal(defaultSearchLogsMaxMatches, resp.MatchCount)
+		assert.True(resp.Truncated)
+		assert.Equal(120, resp.EstimatedTotalMatches)
+	})
+}
+
+func TestTailLogsHandler_Cursor(t *testing.T) {
+	assert := require.New(t)
+
+	testFile := t.TempDir() + "/cursor.parquet"
+	writeTestParquetFile(t, testFile, []string{"one", "two", "three", "four", "five"})
+
+	mockClient := &MockBuildkiteLogsClient{
+		NewReaderFunc: func(ctx context.Context, org, pipeline, build, job string, ttl time.Duration, forceRefresh bool) (*buildkitelogs.ParquetReader, error) {
+			return buildkitelogs.NewParquetReader(testFile), nil
+		},
+	}
+
+	ctx := ContextWithDeps(context.Background(), ToolDependencies{BuildkiteLogsClient: mockClient})
+	_, handler, _ := TailLogs()
+
+	tail := func(jobID, cursor string) *mcp.CallToolResult {
+		params := TailLogsParams{
+			JobLogsBaseParams: JobLogsBaseParams{
+				OrgSlug:      "test-org",
+				PipelineSlug: "test-pipeline",
+				BuildNumber:  "123",
+				JobID:        jobID,
+			},
+			Tail:   2,
+			Cursor: cursor,
+		}
+		result, _, err := handler(ctx, createMCPRequest(t, map[string]any{}), params)
+		assert.NoError(err)
+		return result
+	}
+
+	decode := func(result *mcp.CallToolResult) (string, []int64) {
+		var resp struct {
+			Entries []TerseLogEntry `json:"entries"`
+			Cursor  string          `json:"cursor"`
+		}
+		assert.NoError(json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &resp))
+		rows := make([]int64, len(resp.Entries))
+		for i, e := range resp.Entries {
+			rows[i] = e.RN
+		}
+		return resp.Cursor, rows
+	}
+
+	t.Run("initial tail returns a cursor at the end of the log", func(t *testing.T) {
+		cursor, rows := decode(tail("job-456", ""))
+		assert.Equal([]int64{3, 4}, rows)
+
+		// Nothing has been appended, so following the cursor returns no entries
+		// and hands back the same position.
+		next, rows := decode(tail("job-456", cursor))
+		assert.Empty(rows)
+		assert.Equal(cursor, next)
+	})
+
+	t.Run("cursor pages forward from its row", func(t *testing.T) {
+		cursor, rows := decode(tail("job-456", encodeTailCursor("job-456", 1)))
+		assert.Equal([]int64{1, 2}, rows)
+
+		_, rows = decode(tail("job-456", cursor))
+		assert.Equal([]int64{3, 4}, rows)
+	})
+
+	t.Run("cursor for a different job is rejected", func(t *testing.T) {
+		result := tail("job-456", encodeTailCursor("other-job", 1))
+		assert.True(result.IsError)
+		assert.Contains(result.Content[0].(*mcp.TextContent).Text, "cursor was issued for job")
+	})
+
+	t.Run("cursor past the end of the log is rejected", func(t *testing.T) {
+		result := tail("job-456", encodeTailCursor("job-456", 50))
+		assert.True(result.IsError)
+		assert.Contains(result.Content[0].(*mcp.TextContent).Text, "past the end of the log")
+	})
+
+	t.Run("malformed cursor is rejected", func(t *testing.T) {
+		result := tail("job-456", "not a cursor!")
+		assert.True(result.IsError)
+		assert.Contains(result.Content[0].(*mcp.TextContent).Text, "invalid cursor")
+	})
+}
+
+func TestReadLogsHandler_TimeRange(t *testing.T) {
+	assert := require.New(t)
+
+	base := time.Date(2025, 4, 22, 10, 0, 0, 0, time.UTC)
+	entry := func(offset time.Duration, content string) *logparser.Entry {
+		e := &logparser.Entry{Content: content, RawLine: []byte(content)}
+		if offset >= 0 {
+			e.Timestamp = base.Add(offset)
+		}
+		return e
+	}
+
+	testFile := t.TempDir() + "/time_range.parquet"
+	writeTestParquetEntries(t, testFile, []*logparser.Entry{
+		entry(0, "starting"),                 // row 0, 10:00
+		entry(2*time.Minute, "running"),      // row 1, 10:02
+		entry(-1, "continuation of running"), // row 2, no timestamp
+		entry(4*time.Minute, "still going"),  // row 3, 10:04
+		entry(6*time.Minute, "finished"),     // row 4, 10:06
+	})
+
+	mockClient := &MockBuildkiteLogsClient{
+		NewReaderFunc: func(ctx context.Context, org, pipeline, build, job string, ttl time.Duration, forceRefresh bool) (*buildkitelogs.ParquetReader, error) {
+			return buildkitelogs.NewParquetReader(testFile), nil
+		},
+	}
+
+	ctx := ContextWithDeps(context.Background(), ToolDependencies{BuildkiteLogsClient: mockClient})
+	_, handler, _ := ReadLogs()
+
+	read := func(from, to string) *mcp.CallToolResult {
+		params := ReadLogsParams{
+			JobLogsBaseParams: JobLogsBaseParams{
+				OrgSlug:      "test-org",
+				PipelineSlug: "test-pipeline",
+				BuildNumber:  "123",
+				JobID:        "job-456",
+			},
+			FromTimestamp: from,
+			ToTimestamp:   to,
+		}
+		result, _, err := handler(ctx, createMCPRequest(t, map[string]any{}), params)
+		assert.NoError(err)
+		return result
+	}
+
+	rows := func(result *mcp.CallToolResult) []int64 {
+		var resp struct {
+			Entries []TerseLogEntry `json:"entries"`
+		}
+		assert.NoError(json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &resp))
+		rns := make([]int64, len(resp.Entries))
+		for i, e := range resp.Entries {
+			rns[i] = e.RN
+		}
+		return rns
+	}
+
+	t.Run("window carries timestamps forward", func(t *testing.T) {
+		result := read("2025-04-22T10:01:00Z", "2025-04-22T10:05:00Z")
+		assert.False(result.IsError)
+		assert.Equal([]int64{1, 2, 3}, rows(result))
+	})
+
+	t.Run("open ended from", func(t *testing.T) {
+		assert.Equal([]int64{3, 4}, rows(read("2025-04-22T10:03:00Z", "")))
+	})
+
+	t.Run("open ended to", func(t *testing.T) {
+		assert.Equal([]int64{0, 1, 2}, rows(read("", "2025-04-22T10:02:00Z")))
+	})
+
+	t.Run("malformed time", func(t *testing.T) {
+		result := read("10:00", "")
+		assert.True(result.IsError)
+		assert.Contains(result.Content[0].(*mcp.TextContent).Text, "invalid from_timestamp")
+	})
+
+	t.Run("inverted range", func(t *testing.T) {
+		result := read("2025-04-22T10:05:00Z", "2025-04-22T10:01:00Z")
+		assert.True(result.IsError)
+	})
+}
+
+func TestReadLogsHandler_LineRange(t *testing.T) {
+	assert := require.New(t)
+
+	contents := make([]string, 20)
+	for i := range contents {
+		contents[i] = fmt.Sprintf("line %d", i+1)
+	}
+	testFile := t.TempDir() + "/line_range.parquet"
+	writeTestParquetFile(t, testFile, contents)
+
+	mockClient := &MockBuildkiteLogsClient{
+		NewReaderFunc: func(ctx context.Context, org, pipeline, build, job string, ttl time.Duration, forceRefresh bool) (*buildkitelogs.ParquetReader, error) {
+			return buildkitelogs.NewParquetReader(testFile), nil
+		},
+	}
+
+	ctx := ContextWithDeps(context.Background(), ToolDependencies{BuildkiteLogsClient: mockClient})
+	_, handler, _ := ReadLogs()
+
+	read := func(params ReadLogsParams) (*mcp.CallToolResult, LogResponse, []TerseLogEntry) {
+		params.JobLogsBaseParams = JobLogsBaseParams{
+			OrgSlug:      "test-org",
+			PipelineSlug: "test-pipeline",
+			BuildNumber:  "123",
+			JobID:        "job-456",
+		}
+		result, _, err := handler(ctx, createMCPRequest(t, map[string]any{}), params)
+		assert.NoError(err)
+
+		var resp LogResponse
+		var entries struct {
+			Entries []TerseLogEntry `json:"entries"`
+		}
+		if !result.IsError {
+			text := result.Content[0].(*mcp.TextContent).Text
+			assert.NoError(json.Unmarshal([]byte(text), &resp))
+			assert.NoError(json.Unmarshal([]byte(text), &entries))
+		}
+		return result, resp, entries.Entries
+	}
+
+	t.Run("window", func(t *testing.T) {
+		result, resp, entries := read(ReadLogsParams{StartLine: 5, EndLine: 7})
+		assert.False(result.IsError)
+		assert.Equal(int64(20), resp.TotalRows)
+		assert.Len(entries, 3)
+		assert.Equal("line 5", entries[0].C)
+		assert.Equal("line 7", entries[2].C)
+	})
+
+	t.Run("start only", func(t *testing.T) {
+		_, _, entries := read(ReadLogsParams{StartLine: 19})
+		assert.Len(entries, 2)
+		assert.Equal("line 20", entries[1].C)
+	})
+
+	t.Run("end only", func(t *testing.T) {
+		_, _, entries := read(ReadLogsParams{EndLine: 2})
+		assert.Len(entries, 2)
+		assert.Equal("line 1", entries[0].C)
+	})
+
+	t.Run("past end", func(t *testing.T) {
+		result, resp, entries := read(ReadLogsParams{StartLine: 50, EndLine: 60})
+		assert.False(result.IsError)
+		assert.Empty(entries)
+		assert.Equal(int64(20), resp.TotalRows)
+	})
+
+	t.Run("invalid ranges", func(t *testing.T) {
+		for _, params := range []ReadLogsParams{
+			{StartLine: 10, EndLine: 5},
+			{StartLine: -1},
+			{StartLine: 2, Seek: 3},
+		} {
+			result, _, _ := read(params)
+			assert.True(result.IsError, "%+v", params)
+		}
+	})
+}
+
+func TestReadLogsHandler_Section(t *testing.T) {
+	assert := require.New(t)
+
+	lines := []struct{ content, group string }{
+		{"preamble", ""},
+		{"--- Preparing working directory", "--- Preparing working directory"},
+		{"git clone", "--- Preparing working directory"},
+		{"+++ Running tests", "+++ Running tests"},
+		{"FAIL: TestThing", "+++ Running tests"},
+		{"~~~ Uploading artifacts", "~~~ Uploading artifacts"},
+	}
+	entries := make([]*logparser.Entry, len(lines))
+	for i, l := range lines {
+		entries[i] = &logparser.Entry{Content: l.content, RawLine: []byte(l.content), Group: l.group}
+	}
+	testFile := t.TempDir() + "/sections.parquet"
+	writeTestParquetEntries(t, testFile, entries)
+
+	mockClient := &MockBuildkiteLogsClient{
+		NewReaderFunc: func(ctx context.Context, org, pipeline, build, job string, ttl time.Duration, forceRefresh bool) (*buildkitelogs.ParquetReader, error) {
+			return buildkitelogs.NewParquetReader(testFile), nil
+		},
+	}
+
+	ctx := ContextWithDeps(context.Background(), ToolDependencies{BuildkiteLogsClient: mockClient})
+	_, handler, _ := ReadLogs()
+
+	read := func(section string) *mcp.CallToolResult {
+		params := ReadLogsParams{
+			JobLogsBaseParams: JobLogsBaseParams{
+				OrgSlug:      "test-org",
+				PipelineSlug: "test-pipeline",
+				BuildNumber:  "123",
+				JobID:        "job-456",
+			},
+			Section: section,
+		}
+		result, _, err := handler(ctx, createMCPRequest(t, map[string]any{}), params)
+		assert.NoError(err)
+		return result
+	}
+
+	t.Run("matching section", func(t *testing.T) {
+		result := read("running TESTS")
+		assert.False(result.IsError)
+
+		var resp struct {
+			Entries []TerseLogEntry `json:"entries"`
+		}
+		assert.NoError(json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &resp))
+		assert.Len(resp.Entries, 2)
+		assert.Equal(int64(3), resp.Entries[0].RN)
+		assert.Equal("FAIL: TestThing", resp.Entries[1].C)
+	})
+
+	t.Run("no match lists sections", func(t *testing.T) {
+		result := read("deploy")
+		assert.True(result.IsError)
+		text := result.Content[0].(*mcp.TextContent).Text
+		assert.Contains(text, "available sections: Preparing working directory; Running tests; Uploading artifacts")
+	})
+}
+
+func TestTailLogsHandler_LogTooLarge(t *testing.T) {
+	mockClient := &MockBuildkiteLogsClient{
+		NewReaderFunc: func(ctx context.Context, org, pipeline, build, job string, ttl time.Duration, forceRefresh bool) (*buildkitelogs.ParquetReader, error) {
+			return nil, fmt.Errorf("%w: 200 bytes exceeds limit of 100 bytes", buildkitelogs.ErrLogTooLarge)
+		},
+	}
+
+	ctx := ContextWithDeps(context.Background(), ToolDependencies{BuildkiteLogsClient: mockClient})
+	_, handler, _ := TailLogs()
+
+	result, _, err := handler(ctx, createMCPRequest(t, map[string]any{}), TailLogsParams{
+		JobLogsBaseParams: JobLogsBaseParams{
+			OrgSlug:      "test-org",
+			PipelineSlug: "test-pipeline",
+			BuildNumber:  "123",
+			JobID:        "job-456",
+		},
+	})
+	require.NoError(t, err)
+	require.True(t, result.IsError)
+	require.Contains(t, result.Content[0].(*mcp.TextContent).Text, "BKLOG_MAX_LOG_BYTES")
+}
@@ -2,8 +2,12 @@ package buildkite
 
 import (
 	"context"
+	"fmt"
+	"net/http"
 	"strings"
 
+	buildkitelogs "github.com/buildkite/buildkite-logs"
+	"github.com/buildkite/buildkite-logs/logparser"
 	"github.com/buildkite/buildkite-mcp-server/pkg/trace"
 	"github.com/buildkite/buildkite-mcp-server/pkg/utils"
 	"github.com/buildkite/go-buildkite/v5"
@@ -18,6 +22,8 @@ type JobsClient interface {
 	UnblockJob(ctx context.Context, org string, pipeline string, buildNumber string, jobID string, opt *buildkite.JobUnblockOptions) (buildkite.Job, *buildkite.Response, error)
 	RetryJob(ctx context.Context, org string, pipeline string, buildNumber string, jobID string) (buildkite.Job, *buildkite.Response, error)
 	GetJobEnvironmentVariables(ctx context.Context, org string, pipeline string, buildNumber string, jobID string) (buildkite.JobEnvs, *buildkite.Response, error)
+	GetJobLog(ctx context.Context, org string, pipeline string, buildNumber string, jobID string) (buildkite.JobLog, *buildkite.Response, error)
+	JobLogExists(ctx context.Context, org string, pipeline string, buildNumber string, jobID string) (bool, *buildkite.Response, error)
 }
 
 func redactUnusedJobFields(job *buildkite.Job) {
@@ -188,7 +194,7 @@ func ListJobs() (mcp.Tool, mcp.ToolHandlerFor[ListJobsArgs, any], []string) {
 			deps := DepsFromContext(ctx)
 			jobs, _, err := deps.JobsClient.ListByBuild(ctx, args.OrgSlug, args.PipelineSlug, args.BuildNumber, options)
 			if err != nil {
-				return handleBuildkiteError(err)
+				return handleBuildkiteError(ctx, err)
 			}
 
 			if args.DetailLevel != "summary" && !args.IncludeAgent && len(jobs.Items) > 0 {
@@ -214,7 +220,7 @@ func ListJobs() (mcp.Tool, mcp.ToolHandlerFor[ListJobsArgs, any], []string) {
 
 			span.SetAttributes(attribute.Int("item_count", len(jobs.Items)))
 
-			return mcpTextResult(span, &result)
+			return mcpTextResult(ctx, span, &result)
 		}, []string{"read_builds"}
 }
 
@@ -261,7 +267,7 @@ func GetJob() (mcp.Tool, mcp.ToolHandlerFor[GetJobArgs, any], []string) {
 				job, _, err = deps.JobsClient.GetJobByOrg(ctx, args.OrgSlug, args.JobID)
 			}
 			if err != nil {
-				return handleBuildkiteError(err)
+				return handleBuildkiteError(ctx, err)
 			}
 
 			redactUnusedJobFields(&job)
@@ -272,16 +278,150 @@ func GetJob() (mcp.Tool, mcp.ToolHandlerFor[GetJobArgs, any], []string) {
 				}
 			}
 
-			return mcpTextResult(span, &job)
+			return mcpTextResult(ctx, span, &job)
 		}, []string{"read_builds"}
 }
 
 // GetJobLogsArgs struct for typed parameters
 type GetJobLogsArgs struct {
-	OrgSlug      string `json:"org_slug"`
-	PipelineSlug string `json:"pipeline_slug"`
-	BuildNumber  string `json:"build_number"`
-	JobUUID      string `json:"job_uuid"`
+	OrgSlug        string `json:"org_slug"`
+	PipelineSlug   string `json:"pipeline_slug"`
+	BuildNumber    string `json:"build_number"`
+	JobUUID        string `json:"job_uuid"`
+	TimeoutSeconds int    `json:"timeout_seconds,omitempty" jsonschema:"Give up after this many seconds (default 300, max 1800)"`
+}
+
+// getJobLogsByteLimit caps the cleaned text returned by get_job_logs. The
+// tail is kept because failures are almost always at the end of a log.
+const getJobLogsByteLimit = 64 * 1024
+
+// JobLogResult is the cleaned output of a job log.
+type JobLogResult struct {
+	Content      string `json:"content"`
+	SizeBytes    int    `json:"size_bytes,omitempty"`
+	TotalLines   int    `json:"total_lines"`
+	OmittedLines int    `json:"omitted_lines,omitempty"`
+	Truncated    bool   `json:"truncated,omitempty"`
+}
+
+// cleanJobLog strips Buildkite timestamp markers and ANSI codes from raw log
+// content and keeps as many trailing lines as fit in limit bytes. When the
+// last line alone is over the limit, its end is kept instead.
+func cleanJobLog(content string, limit int) (JobLogResult, error) {
+	var lines []string
+	for entry, err := range logparser.New().All(strings.NewReader(content)) {
+		if err != nil {
+			return JobLogResult{}, err
+		}
+		lines = append(lines, buildkitelogs.StripANSI(entry.Content))
+	}
+
+	first := len(lines)
+	remaining := limit
+	for first > 0 && len(lines[first-1])+1 <= remaining {
+		remaining -= len(lines[first-1]) + 1
+		first--
+	}
+
+	result := JobLogResult{
+		Content:      strings.Join(lines[first:], "\n"),
+		TotalLines:   len(lines),
+		OmittedLines: first,
+		Truncated:    first > 0,
+	}
+	if first == len(lines) && first > 0 && limit > 0 {
+		last := lines[first-1]
+		result.Content = strings.ToValidUTF8(last[max(len(last)-limit, 0):], "")
+		result.OmittedLines = first - 1
+	}
+	return result, nil
+}
+
+// jobLogTooLarge describes a log over the server's BKLOG_MAX_LOG_BYTES limit.
+// The same limit applies to every log tool, so the only remedy is raising it.
+func jobLogTooLarge(size, maxBytes int64) string {
+	return fmt.Sprintf("job log is %d bytes, over the server's %d byte BKLOG_MAX_LOG_BYTES limit; the limit must be raised to read this log", size, maxBytes)
+}
+
+// jobLogSize returns the size of a job's log from a HEAD request, so a log
+// over maxBytes can be refused before it is downloaded. It returns -1 when
+// there is no limit or the size isn't known, such as when the request fails,
+// leaving the download to report any error.
+func jobLogSize(ctx context.Context, client JobsClient, org, pipeline, buildNumber, jobID string, maxBytes int64) int64 {
+	if maxBytes <= 0 {
+		return -1
+	}
+	exists, resp, err := client.JobLogExists(ctx, org, pipeline, buildNumber, jobID)
+	if err != nil || !exists || resp == nil || resp.Response == nil {
+		return -1
+	}
+	return resp.ContentLength
+}
+
+func GetJobLogs() (mcp.Tool, mcp.ToolHandlerFor[GetJobLogsArgs, any], []string) {
+	return mcp.Tool{
+			Name:        "get_job_logs",
+			Description: "Get the full log for a job as plain text in one call, with timestamps and ANSI codes removed. Output is capped at the last 64KB; 'truncated', 'omitted_lines' and 'size_bytes' report what was dropped, and logs over the server's size limit are refused. For large logs, use tail_logs, search_logs, or read_logs instead",
+			Annotations: &mcp.ToolAnnotations{
+				Title:        "Get Job Logs",
+				ReadOnlyHint: true,
+			},
+		},
+		func(ctx context.Context, request *mcp.CallToolRequest, args GetJobLogsArgs) (*mcp.CallToolResult, any, error) {
+			ctx, span := trace.Start(ctx, "buildkite.GetJobLogs")
+			defer span.End()
+
+			ctx, cancel := withCallTimeout(ctx, args.TimeoutSeconds)
+			defer cancel()
+
+			span.SetAttributes(
+				attribute.String("org_slug", args.OrgSlug),
+				attribute.String("pipeline_slug", args.PipelineSlug),
+				attribute.String("build_number", args.BuildNumber),
+				attribute.String("job_uuid", args.JobUUID),
+			)
+
+			deps := DepsFromContext(ctx)
+			tooLarge := func(size int64) *mcp.CallToolResult {
+				return utils.NewToolResultError(jobLogTooLarge(size, deps.MaxLogBytes))
+			}
+
+			if size := jobLogSize(ctx, deps.JobsClient, args.OrgSlug, args.PipelineSlug, args.BuildNumber, args.JobUUID, deps.MaxLogBytes); size > deps.MaxLogBytes {
+				span.SetAttributes(attribute.Int64("size_bytes", size))
+				return tooLarge(size), nil, nil
+			}
+
+			jobLog, _, err := deps.JobsClient.GetJobLog(ctx, args.OrgSlug, args.PipelineSlug, args.BuildNumber, args.JobUUID)
+			if err != nil {
+				return handleBuildkiteError(ctx, err)
+			}
+
+			size := jobLog.Size
+			if size == 0 {
+				size = len(jobLog.Content)
+			}
+			span.SetAttributes(attribute.Int("size_bytes", size))
+
+			// The size couldn't be checked before the download.
+			if deps.MaxLogBytes > 0 && int64(size) > deps.MaxLogBytes {
+				return tooLarge(int64(size)), nil, nil
+			}
+
+			result, err := cleanJobLog(jobLog.Content, getJobLogsByteLimit)
+			if err != nil {
+				return utils.NewToolResultError(fmt.Sprintf("failed to parse job log: %v", err)), nil, nil
+			}
+			if result.Truncated {
+				result.SizeBytes = size
+			}
+
+			span.SetAttributes(
+				attribute.Int("total_lines", result.TotalLines),
+				attribute.Bool("truncated", result.Truncated),
+			)
+
+			return mcpTextResult(ctx, span, &result)
+		}, []string{"read_build_logs"}
 }
 
 // UnblockJobArgs struct for typed parameters
@@ -291,6 +431,7 @@ type UnblockJobArgs struct {
 	BuildNumber  string            `json:"build_number"`
 	JobID        string            `json:"job_id"`
 	Fields       map[string]string `json:"fields,omitempty" jsonschema:"JSON object containing string values for block step fields"`
+	DryRun       bool              `json:"dry_run,omitempty" jsonschema:"Validate the arguments and return the request this would make, without making it"`
 }
 
 func UnblockJob() (mcp.Tool, mcp.ToolHandlerFor[UnblockJobArgs, any], []string) {
@@ -313,29 +454,173 @@ func UnblockJob() (mcp.Tool, mcp.ToolHandlerFor[UnblockJobArgs, any], []string)
 				attribute.String("job_id", args.JobID),
 			)
 
+			if err := requireParams(map[string]string{
+				"org_slug":      args.OrgSlug,
+				"pipeline_slug": args.PipelineSlug,
+				"build_number":  args.BuildNumber,
+				"job_id":        args.JobID,
+			}); err != nil {
+				return utils.NewToolResultError(err.Error()), nil, nil
+			}
+
 			// Prepare unblock options
 			unblockOptions := buildkite.JobUnblockOptions{}
 			if len(args.Fields) > 0 {
 				unblockOptions.Fields = args.Fields
 			}
 
+			if args.DryRun {
+				return mcpDryRunResult(ctx, span, "Unblock a job", http.MethodPut, apiPath("v2/organizations/%s/pipelines/%s/builds/%s/jobs/%s/unblock", args.OrgSlug, args.PipelineSlug, args.BuildNumber, args.JobID), unblockOptions)
+			}
+
 			// Unblock the job
 			deps := DepsFromContext(ctx)
 			job, _, err := deps.JobsClient.UnblockJob(ctx, args.OrgSlug, args.PipelineSlug, args.BuildNumber, args.JobID, &unblockOptions)
 			if err != nil {
-				return handleBuildkiteError(err)
+				return handleBuildkiteError(ctx, err)
 			}
 
-			return mcpTextResult(span, &job)
+			return mcpTextResult(ctx, span, &job)
 		}, []string{"write_builds"}
 }
 
+type UnblockAllJobsArgs struct {
+	OrgSlug         string            `json:"org_slug"`
+	PipelineSlug    string            `json:"pipeline_slug"`
+	BuildNumber     string            `json:"build_number"`
+	Fields          map[string]string `json:"fields,omitempty" jsonschema:"JSON object containing string values for block step fields, sent when unblocking each job"`
+	ContinueOnError bool              `json:"continue_on_error,omitempty" jsonschema:"Keep unblocking the remaining jobs after one fails. By default the first failure stops the rest, which are reported as skipped"`
+	DryRun          bool              `json:"dry_run,omitempty" jsonschema:"Find the blocked jobs and return the requests this would make, without making them"`
+}
+
+// Outcomes of unblocking a job with unblock_all_jobs.
+const (
+	unblockStatusUnblocked = "unblocked"
+	unblockStatusFailed    = "failed"
+	unblockStatusSkipped   = "skipped"
+)
+
+// UnblockedJob is the outcome of unblocking one job of a build.
+type UnblockedJob struct {
+	BlockedJob
+	Status string `json:"status"`
+	State  string `json:"state,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// UnblockAllJobsResult lists the outcome for each blocked job of a build, in
+// the order of the build's steps, with a count of each outcome.
+type UnblockAllJobsResult struct {
+	Jobs      []UnblockedJob `json:"jobs"`
+	Unblocked int            `json:"unblocked"`
+	Failed    int            `json:"failed"`
+	Skipped   int            `json:"skipped"`
+}
+
+func UnblockAllJobs() (mcp.Tool, mcp.ToolHandlerFor[UnblockAllJobsArgs, any], []string) {
+	return mcp.Tool{
+			Name:        "unblock_all_jobs",
+			Description: "Unblock every blocked job (block step) of a Buildkite build, sending the same fields to each, and report the outcome for each job. Failures are reported per job rather than failing the call; set continue_on_error to keep going after one",
+			Annotations: &mcp.ToolAnnotations{
+				Title:           "Unblock All Jobs",
+				DestructiveHint: boolPtr(true),
+			},
+		},
+		func(ctx context.Context, request *mcp.CallToolRequest, args UnblockAllJobsArgs) (*mcp.CallToolResult, any, error) {
+			ctx, span := trace.Start(ctx, "buildkite.UnblockAllJobs")
+			defer span.End()
+
+			span.SetAttributes(
+				attribute.String("org_slug", args.OrgSlug),
+				attribute.String("pipeline_slug", args.PipelineSlug),
+				attribute.String("build_number", args.BuildNumber),
+				attribute.Bool("continue_on_error", args.ContinueOnError),
+			)
+
+			if err := requireParams(map[string]string{
+				"org_slug":      args.OrgSlug,
+				"pipeline_slug": args.PipelineSlug,
+				"build_number":  args.BuildNumber,
+			}); err != nil {
+				return utils.NewToolResultError(err.Error()), nil, nil
+			}
+
+			deps := DepsFromContext(ctx)
+			build, _, err := deps.BuildsClient.Get(ctx, args.OrgSlug, args.PipelineSlug, args.BuildNumber, &buildkite.BuildGetOptions{
+				BuildsListOptions: buildkite.BuildsListOptions{ExcludePipeline: true},
+			})
+			if err != nil {
+				return handleBuildkiteError(ctx, err)
+			}
+			blocked := blockedJobs(build)
+			span.SetAttributes(attribute.Int("blocked_count", len(blocked)))
+
+			unblockOptions := buildkite.JobUnblockOptions{}
+			if len(args.Fields) > 0 {
+				unblockOptions.Fields = args.Fields
+			}
+
+			if args.DryRun {
+				// A dry run still reads the build, to tell which jobs it would
+				// unblock.
+				span.SetAttributes(attribute.Bool("dry_run", true))
+				requests := make([]DryRunResult, len(blocked))
+				for i, job := range blocked {
+					requests[i] = DryRunResult{
+						DryRun:  true,
+						Action:  fmt.Sprintf("Unblock %s", job.Label),
+						Method:  http.MethodPut,
+						Path:    apiPath("v2/organizations/%s/pipelines/%s/builds/%s/jobs/%s/unblock", args.OrgSlug, args.PipelineSlug, args.BuildNumber, job.JobID),
+						Request: unblockOptions,
+					}
+				}
+				return mcpTextResult(ctx, span, requests)
+			}
+
+			result := UnblockAllJobsResult{Jobs: make([]UnblockedJob, 0, len(blocked))}
+			for _, job := range blocked {
+				outcome := UnblockedJob{BlockedJob: job, Status: unblockStatusSkipped}
+				if result.Failed == 0 || args.ContinueOnError {
+					unblocked, _, err := deps.JobsClient.UnblockJob(ctx, args.OrgSlug, args.PipelineSlug, args.BuildNumber, job.JobID, &unblockOptions)
+					switch {
+					case isBuildkiteUnauthorized(err):
+						return nil, nil, ErrUnauthorized
+					case err != nil:
+						outcome.Status = unblockStatusFailed
+						outcome.Error, _ = buildkiteErrorMessage(err)
+					default:
+						outcome.Status = unblockStatusUnblocked
+						outcome.State = unblocked.State
+					}
+				}
+
+				switch outcome.Status {
+				case unblockStatusUnblocked:
+					result.Unblocked++
+				case unblockStatusFailed:
+					result.Failed++
+				default:
+					result.Skipped++
+				}
+				result.Jobs = append(result.Jobs, outcome)
+			}
+
+			span.SetAttributes(
+				attribute.Int("unblocked_count", result.Unblocked),
+				attribute.Int("failed_count", result.Failed),
+			)
+
+			return mcpTextResult(ctx, span, &result)
+		}, []string{"write_builds", "read_builds"}
+}
+
 // RetryJobArgs struct for typed parameters
 type RetryJobArgs struct {
 	OrgSlug      string `json:"org_slug"`
 	PipelineSlug string `json:"pipeline_slug"`
 	BuildNumber  string `json:"build_number"`
 	JobID        string `json:"job_id"`
+	DryRun       bool   `json:"dry_run,omitempty" jsonschema:"Validate the arguments and return the request this would make, without making it"`
 }
 
 func RetryJob() (mcp.Tool, mcp.ToolHandlerFor[RetryJobArgs, any], []string) {
@@ -358,13 +643,26 @@ func RetryJob() (mcp.Tool, mcp.ToolHandlerFor[RetryJobArgs, any], []string) {
 				attribute.String("job_id", args.JobID),
 			)
 
+			if err := requireParams(map[string]string{
+				"org_slug":      args.OrgSlug,
+				"pipeline_slug": args.PipelineSlug,
+				"build_number":  args.BuildNumber,
+				"job_id":        args.JobID,
+			}); err != nil {
+				return utils.NewToolResultError(err.Error()), nil, nil
+			}
+
+			if args.DryRun {
+				return mcpDryRunResult(ctx, span, "Retry a job", http.MethodPut, apiPath("v2/organizations/%s/pipelines/%s/builds/%s/jobs/%s/retry", args.OrgSlug, args.PipelineSlug, args.BuildNumber, args.JobID), nil)
+			}
+
 			deps := DepsFromContext(ctx)
 			job, _, err := deps.JobsClient.RetryJob(ctx, args.OrgSlug, args.PipelineSlug, args.BuildNumber, args.JobID)
 			if err != nil {
-				return handleBuildkiteError(err)
+				return handleBuildkiteError(ctx, err)
 			}
 
-			return mcpTextResult(span, &job)
+			return mcpTextResult(ctx, span, &job)
 		}, []string{"write_builds"}
 }
 
@@ -399,9 +697,9 @@ func GetJobEnvironmentVariables() (mcp.Tool, mcp.ToolHandlerFor[GetJobEnvironmen
 			deps := DepsFromContext(ctx)
 			jobEnvs, _, err := deps.JobsClient.GetJobEnvironmentVariables(ctx, args.OrgSlug, args.PipelineSlug, args.BuildNumber, args.JobID)
 			if err != nil {
-				return handleBuildkiteError(err)
+				return handleBuildkiteError(ctx, err)
 			}
 
-			return mcpTextResult(span, &jobEnvs)
+			return mcpTextResult(ctx, span, &jobEnvs)
 		}, []string{"read_job_env"}
 }
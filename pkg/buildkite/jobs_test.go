@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
+	"strings"
 	"testing"
 	"time"
 
@@ -22,6 +23,8 @@ type MockJobsClient struct {
 	UnblockJobFunc                 func(ctx context.Context, org string, pipeline string, buildNumber string, jobID string, opt *buildkite.JobUnblockOptions) (buildkite.Job, *buildkite.Response, error)
 	RetryJobFunc                   func(ctx context.Context, org string, pipeline string, buildNumber string, jobID string) (buildkite.Job, *buildkite.Response, error)
 	GetJobEnvironmentVariablesFunc func(ctx context.Context, org string, pipeline string, buildNumber string, jobID string) (buildkite.JobEnvs, *buildkite.Response, error)
+	GetJobLogFunc                  func(ctx context.Context, org string, pipeline string, buildNumber string, jobID string) (buildkite.JobLog, *buildkite.Response, error)
+	JobLogExistsFunc               func(ctx context.Context, org string, pipeline string, buildNumber string, jobID string) (bool, *buildkite.Response, error)
 }
 
 func (m *MockJobsClient) ListByBuild(ctx context.Context, org string, pipeline string, buildNumber string, opt *buildkite.JobsListOptions) (buildkite.JobsList, *buildkite.Response, error) {
@@ -66,6 +69,20 @@ func (m *MockJobsClient) GetJobEnvironmentVariables(ctx context.Context, org str
 	return buildkite.JobEnvs{}, &buildkite.Response{}, nil
 }
 
+func (m *MockJobsClient) GetJobLog(ctx context.Context, org string, pipeline string, buildNumber string, jobID string) (buildkite.JobLog, *buildkite.Response, error) {
+	if m.GetJobLogFunc != nil {
+		return m.GetJobLogFunc(ctx, org, pipeline, buildNumber, jobID)
+	}
+	return buildkite.JobLog{}, &buildkite.Response{}, nil
+}
+
+func (m *MockJobsClient) JobLogExists(ctx context.Context, org string, pipeline string, buildNumber string, jobID string) (bool, *buildkite.Response, error) {
+	if m.JobLogExistsFunc != nil {
+		return m.JobLogExistsFunc(ctx, org, pipeline, buildNumber, jobID)
+	}
+	return false, &buildkite.Response{}, nil
+}
+
 var _ JobsClient = (*MockJobsClient)(nil)
 
 func testJobAgent() buildkite.Agent {
@@ -316,6 +333,133 @@ func TestGetJobEnvironmentVariables(t *testing.T) {
 	})
 }
 
+func TestGetJobLogs(t *testing.T) {
+	t.Run("ToolDefinition", func(t *testing.T) {
+		tool, _, scopes := GetJobLogs()
+		assert.Equal(t, "get_job_logs", tool.Name)
+		assert.True(t, tool.Annotations.ReadOnlyHint)
+		assert.Equal(t, []string{"read_build_logs"}, scopes)
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		mockJobs := &MockJobsClient{
+			GetJobLogFunc: func(ctx context.Context, org string, pipeline string, buildNumber string, jobID string) (buildkite.JobLog, *buildkite.Response, error) {
+				assert.Equal(t, "test-org", org)
+				assert.Equal(t, "test-pipeline", pipeline)
+				assert.Equal(t, "123", buildNumber)
+				assert.Equal(t, "job-456", jobID)
+
+				return buildkite.JobLog{
+					Content: "\x1b_bk;t=1745358209000\x07~~~ Running tests\n\x1b_bk;t=1745358209100\x07\x1b[31mFAIL\x1b[0m TestThing\n",
+				}, &buildkite.Response{}, nil
+			},
+		}
+
+		ctx := ContextWithDeps(context.Background(), ToolDependencies{JobsClient: mockJobs})
+		_, handler, _ := GetJobLogs()
+
+		result, _, err := handler(ctx, createMCPRequest(t, map[string]any{}), GetJobLogsArgs{
+			OrgSlug:      "test-org",
+			PipelineSlug: "test-pipeline",
+			BuildNumber:  "123",
+			JobUUID:      "job-456",
+		})
+		require.NoError(t, err)
+
+		var got JobLogResult
+		require.NoError(t, json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &got))
+		assert.Equal(t, "~~~ Running tests\nFAIL TestThing", got.Content)
+		assert.Equal(t, 2, got.TotalLines)
+		assert.False(t, got.Truncated)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		mockJobs := &MockJobsClient{
+			GetJobLogFunc: func(ctx context.Context, org string, pipeline string, buildNumber string, jobID string) (buildkite.JobLog, *buildkite.Response, error) {
+				return buildkite.JobLog{}, nil, errors.New("log not found")
+			},
+		}
+
+		ctx := ContextWithDeps(context.Background(), ToolDependencies{JobsClient: mockJobs})
+		_, handler, _ := GetJobLogs()
+
+		result, _, err := handler(ctx, createMCPRequest(t, map[string]any{}), GetJobLogsArgs{
+			OrgSlug:      "test-org",
+			PipelineSlug: "test-pipeline",
+			BuildNumber:  "123",
+			JobUUID:      "job-456",
+		})
+		require.NoError(t, err)
+		assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "log not found")
+	})
+}
+
+func TestGetJobLogs_SizeLimit(t *testing.T) {
+	mockJobs := &MockJobsClient{
+		GetJobLogFunc: func(ctx context.Context, org string, pipeline string, buildNumber string, jobID string) (buildkite.JobLog, *buildkite.Response, error) {
+			return buildkite.JobLog{Content: "too much output\n", Size: 2048}, &buildkite.Response{}, nil
+		},
+	}
+
+	ctx := ContextWithDeps(context.Background(), ToolDependencies{JobsClient: mockJobs, MaxLogBytes: 1024})
+	_, handler, _ := GetJobLogs()
+
+	result, _, err := handler(ctx, createMCPRequest(t, map[string]any{}), GetJobLogsArgs{
+		OrgSlug:      "test-org",
+		PipelineSlug: "test-pipeline",
+		BuildNumber:  "123",
+		JobUUID:      "job-456",
+	})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	text := result.Content[0].(*mcp.TextContent).Text
+	assert.Contains(t, text, "2048 bytes")
+	assert.Contains(t, text, "BKLOG_MAX_LOG_BYTES")
+}
+
+func TestGetJobLogs_SizeLimitBeforeDownload(t *testing.T) {
+	mockJobs := &MockJobsClient{
+		JobLogExistsFunc: func(ctx context.Context, org string, pipeline string, buildNumber string, jobID string) (bool, *buildkite.Response, error) {
+			return true, &buildkite.Response{Response: &http.Response{StatusCode: http.StatusOK, ContentLength: 4096}}, nil
+		},
+		GetJobLogFunc: func(ctx context.Context, org string, pipeline string, buildNumber string, jobID string) (buildkite.JobLog, *buildkite.Response, error) {
+			t.Fatal("the log shouldn't be downloaded")
+			return buildkite.JobLog{}, nil, nil
+		},
+	}
+
+	ctx := ContextWithDeps(context.Background(), ToolDependencies{JobsClient: mockJobs, MaxLogBytes: 1024})
+	_, handler, _ := GetJobLogs()
+
+	result, _, err := handler(ctx, createMCPRequest(t, map[string]any{}), GetJobLogsArgs{
+		OrgSlug:      "test-org",
+		PipelineSlug: "test-pipeline",
+		BuildNumber:  "123",
+		JobUUID:      "job-456",
+	})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "job log is 4096 bytes, over the server's 1024 byte BKLOG_MAX_LOG_BYTES limit")
+}
+
+func TestCleanJobLog_KeepsTail(t *testing.T) {
+	result, err := cleanJobLog("first\nsecond\nthird\n", len("second\nthird\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "second\nthird", result.Content)
+	assert.Equal(t, 3, result.TotalLines)
+	assert.Equal(t, 1, result.OmittedLines)
+	assert.True(t, result.Truncated)
+}
+
+func TestCleanJobLog_TruncatesLongLastLine(t *testing.T) {
+	result, err := cleanJobLog("first\n"+strings.Repeat("x", 20)+"error: boom\n", len("error: boom"))
+	require.NoError(t, err)
+	assert.Equal(t, "error: boom", result.Content)
+	assert.Equal(t, 2, result.TotalLines)
+	assert.Equal(t, 1, result.OmittedLines)
+	assert.True(t, result.Truncated)
+}
+
 func TestListJobs(t *testing.T) {
 	t.Run("ToolDefinition", func(t *testing.T) {
 		tool, handler, scopes := ListJobs()
@@ -807,3 +951,121 @@ func TestGetJob(t *testing.T) {
 		assert.Contains(t, getTextResult(t, result).Text, "provide both")
 	})
 }
+
+func TestUnblockAllJobs(t *testing.T) {
+	builds := &MockBuildsClient{
+		GetFunc: func(ctx context.Context, org string, pipeline string, id string, opt *buildkite.BuildGetOptions) (buildkite.Build, *buildkite.Response, error) {
+			return buildkite.Build{
+				Number: 123,
+				Jobs: []buildkite.Job{
+					{ID: "job-1", Type: "manual", State: "blocked", Label: "Deploy staging"},
+					{ID: "job-2", Type: "script", State: "passed", Name: "Tests"},
+					{ID: "job-3", Type: "manual", State: "blocked", Label: "Deploy production"},
+					{ID: "job-4", Type: "manual", State: "unblocked", Label: "Approve"},
+					{ID: "job-5", Type: "manual", State: "blocked", Label: "Announce"},
+				},
+			}, &buildkite.Response{}, nil
+		},
+	}
+	args := UnblockAllJobsArgs{
+		OrgSlug:      "test-org",
+		PipelineSlug: "test-pipeline",
+		BuildNumber:  "123",
+		Fields:       map[string]string{"release": "v1.0.0"},
+	}
+
+	// unblockFailing fails to unblock job-3 and records the jobs it was asked
+	// to unblock.
+	unblockFailing := func(unblocked *[]string) *MockJobsClient {
+		return &MockJobsClient{
+			UnblockJobFunc: func(ctx context.Context, org string, pipeline string, buildNumber string, jobID string, opt *buildkite.JobUnblockOptions) (buildkite.Job, *buildkite.Response, error) {
+				assert.Equal(t, "v1.0.0", opt.Fields["release"])
+				*unblocked = append(*unblocked, jobID)
+				if jobID == "job-3" {
+					return buildkite.Job{}, nil, &buildkite.ErrorResponse{
+						Response: &http.Response{StatusCode: http.StatusUnprocessableEntity},
+						Message:  "This job can't be unblocked",
+					}
+				}
+				return buildkite.Job{ID: jobID, State: "unblocked"}, &buildkite.Response{}, nil
+			},
+		}
+	}
+
+	callUnblockAllJobs := func(t *testing.T, jobs JobsClient, args UnblockAllJobsArgs) string {
+		t.Helper()
+		ctx := ContextWithDeps(context.Background(), ToolDependencies{BuildsClient: builds, JobsClient: jobs})
+		_, handler, _ := UnblockAllJobs()
+
+		result, _, err := handler(ctx, createMCPRequest(t, map[string]any{}), args)
+		require.NoError(t, err)
+		require.False(t, result.IsError)
+		return getTextResult(t, result).Text
+	}
+
+	t.Run("ToolDefinition", func(t *testing.T) {
+		tool, _, scopes := UnblockAllJobs()
+		assert.Equal(t, "unblock_all_jobs", tool.Name)
+		assert.Equal(t, boolPtr(true), tool.Annotations.DestructiveHint)
+		assert.False(t, tool.Annotations.ReadOnlyHint)
+		assert.Equal(t, []string{"write_builds", "read_builds"}, scopes)
+	})
+
+	t.Run("StopsAtFirstFailure", func(t *testing.T) {
+		var unblocked []string
+		text := callUnblockAllJobs(t, unblockFailing(&unblocked), args)
+
+		assert.Equal(t, []string{"job-1", "job-3"}, unblocked)
+		assert.JSONEq(t, `{
+			"jobs": [
+				{"job_id": "job-1", "label": "Deploy staging", "status": "unblocked", "state": "unblocked"},
+				{"job_id": "job-3", "label": "Deploy production", "status": "failed", "error": "This job can't be unblocked"},
+				{"job_id": "job-5", "label": "Announce", "status": "skipped"}
+			],
+			"unblocked": 1,
+			"failed": 1,
+			"skipped": 1
+		}`, text)
+	})
+
+	t.Run("ContinuesOnError", func(t *testing.T) {
+		var unblocked []string
+		continueArgs := args
+		continueArgs.ContinueOnError = true
+		text := callUnblockAllJobs(t, unblockFailing(&unblocked), continueArgs)
+
+		assert.Equal(t, []string{"job-1", "job-3", "job-5"}, unblocked)
+		var result UnblockAllJobsResult
+		require.NoError(t, json.Unmarshal([]byte(text), &result))
+		assert.Equal(t, 2, result.Unblocked)
+		assert.Equal(t, 1, result.Failed)
+		assert.Zero(t, result.Skipped)
+	})
+
+	t.Run("DryRun", func(t *testing.T) {
+		var unblocked []string
+		dryRunArgs := args
+		dryRunArgs.DryRun = true
+		text := callUnblockAllJobs(t, unblockFailing(&unblocked), dryRunArgs)
+
+		assert.Empty(t, unblocked)
+		var requests []DryRunResult
+		require.NoError(t, json.Unmarshal([]byte(text), &requests))
+		require.Len(t, requests, 3)
+		assert.Equal(t, "Unblock Deploy staging", requests[0].Action)
+		assert.Equal(t, "v2/organizations/test-org/pipelines/test-pipeline/builds/123/jobs/job-1/unblock", requests[0].Path)
+	})
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		jobs := &MockJobsClient{
+			UnblockJobFunc: func(ctx context.Context, org string, pipeline string, buildNumber string, jobID string, opt *buildkite.JobUnblockOptions) (buildkite.Job, *buildkite.Response, error) {
+				return buildkite.Job{}, nil, &buildkite.ErrorResponse{Response: &http.Response{StatusCode: http.StatusUnauthorized}}
+			},
+		}
+		ctx := ContextWithDeps(context.Background(), ToolDependencies{BuildsClient: builds, JobsClient: jobs})
+		_, handler, _ := UnblockAllJobs()
+
+		_, _, err := handler(ctx, createMCPRequest(t, map[string]any{}), args)
+		require.ErrorIs(t, err, ErrUnauthorized)
+	})
+}
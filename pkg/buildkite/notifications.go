@@ -0,0 +1,138 @@
+package buildkite
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/buildkite/buildkite-mcp-server/pkg/trace"
+	"github.com/buildkite/buildkite-mcp-server/pkg/utils"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.opentelemetry.io/otel/attribute"
+	"gopkg.in/yaml.v3"
+)
+
+type ListPipelineNotificationServicesArgs struct {
+	OrgSlug      string `json:"org_slug"`
+	PipelineSlug string `json:"pipeline_slug"`
+}
+
+// PipelineNotification is a single notify entry from a pipeline's
+// configuration, either pipeline-wide or attached to a step.
+type PipelineNotification struct {
+	Service   string `json:"service"`
+	Target    any    `json:"target,omitempty"`
+	Condition string `json:"if,omitempty"`
+	Step      string `json:"step,omitempty"`
+}
+
+type PipelineNotificationsResult struct {
+	Notifications []PipelineNotification `json:"notifications"`
+}
+
+// parsePipelineNotifications extracts notify entries from pipeline YAML,
+// including those on steps nested inside groups.
+func parsePipelineNotifications(configuration string) ([]PipelineNotification, error) {
+	notifications := []PipelineNotification{}
+	if configuration == "" {
+		return notifications, nil
+	}
+
+	var config map[string]any
+	if err := yaml.Unmarshal([]byte(configuration), &config); err != nil {
+		return nil, err
+	}
+
+	notifications = appendNotifyEntries(notifications, config["notify"], "")
+	return appendStepNotifications(notifications, config["steps"]), nil
+}
+
+func appendStepNotifications(notifications []PipelineNotification, steps any) []PipelineNotification {
+	list, _ := steps.([]any)
+	for _, s := range list {
+		// Steps can be plain strings such as "wait", which carry no notify.
+		step, ok := s.(map[string]any)
+		if !ok {
+			continue
+		}
+
+		name, _ := step["key"].(string)
+		if name == "" {
+			name, _ = step["label"].(string)
+		}
+		if name == "" {
+			name, _ = step["group"].(string)
+		}
+
+		notifications = appendNotifyEntries(notifications, step["notify"], name)
+		notifications = appendStepNotifications(notifications, step["steps"])
+	}
+	return notifications
+}
+
+func appendNotifyEntries(notifications []PipelineNotification, notify any, step string) []PipelineNotification {
+	entries, _ := notify.([]any)
+	for _, e := range entries {
+		entry, ok := e.(map[string]any)
+		if !ok {
+			continue
+		}
+		condition, _ := entry["if"].(string)
+
+		// An entry names its service by its one non-"if" key, e.g. {slack: "#ci"}.
+		services := make([]string, 0, len(entry))
+		for key := range entry {
+			if key != "if" {
+				services = append(services, key)
+			}
+		}
+		sort.Strings(services)
+
+		for _, service := range services {
+			notifications = append(notifications, PipelineNotification{
+				Service:   service,
+				Target:    entry[service],
+				Condition: condition,
+				Step:      step,
+			})
+		}
+	}
+	return notifications
+}
+
+func ListPipelineNotificationServices() (mcp.Tool, mcp.ToolHandlerFor[ListPipelineNotificationServicesArgs, any], []string) {
+	return mcp.Tool{
+			Name:        "list_pipeline_notification_services",
+			Description: "List where a pipeline sends notifications (Slack, webhook, email, PagerDuty, GitHub commit status, etc.), taken from the notify blocks in the pipeline's saved configuration, both pipeline-wide and per step. Notifications added by dynamically uploaded steps are not visible here",
+			Annotations: &mcp.ToolAnnotations{
+				Title:        "List Pipeline Notification Services",
+				ReadOnlyHint: true,
+			},
+		},
+		func(ctx context.Context, request *mcp.CallToolRequest, args ListPipelineNotificationServicesArgs) (*mcp.CallToolResult, any, error) {
+			ctx, span := trace.Start(ctx, "buildkite.ListPipelineNotificationServices")
+			defer span.End()
+
+			span.SetAttributes(
+				attribute.String("org_slug", args.OrgSlug),
+				attribute.String("pipeline_slug", args.PipelineSlug),
+			)
+
+			deps := DepsFromContext(ctx)
+			pipeline, _, err := deps.PipelinesClient.Get(ctx, args.OrgSlug, args.PipelineSlug)
+			if err != nil {
+				return handleBuildkiteError(ctx, err)
+			}
+
+			notifications, err := parsePipelineNotifications(pipeline.Configuration)
+			if err != nil {
+				return utils.NewToolResultError(fmt.Sprintf("failed to parse pipeline configuration: %v", err)), nil, nil
+			}
+
+			span.SetAttributes(
+				attribute.Int("item_count", len(notifications)),
+			)
+
+			return mcpTextResult(ctx, span, &PipelineNotificationsResult{Notifications: notifications})
+		}, []string{"read_pipelines"}
+}
@@ -0,0 +1,92 @@
+package buildkite
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/buildkite/go-buildkite/v5"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+const notifyPipelineConfiguration = `
+notify:
+  - slack: "#ci-alerts"
+    if: build.state == "failed"
+  - email: "team@example.com"
+steps:
+  - label: ":hammer: Build"
+    command: make build
+  - wait
+  - group: Deploy
+    steps:
+      - key: deploy-prod
+        command: make deploy
+        notify:
+          - webhook: https://example.com/hooks/deploy
+`
+
+func TestListPipelineNotificationServices(t *testing.T) {
+	assert := require.New(t)
+
+	mockPipelines := &MockPipelinesClient{
+		GetFunc: func(ctx context.Context, org string, pipeline string) (buildkite.Pipeline, *buildkite.Response, error) {
+			assert.Equal("test-org", org)
+			assert.Equal("test-pipeline", pipeline)
+			return buildkite.Pipeline{Configuration: notifyPipelineConfiguration}, &buildkite.Response{}, nil
+		},
+	}
+
+	ctx := ContextWithDeps(context.Background(), ToolDependencies{PipelinesClient: mockPipelines})
+	tool, handler, scopes := ListPipelineNotificationServices()
+	assert.Equal("list_pipeline_notification_services", tool.Name)
+	assert.True(tool.Annotations.ReadOnlyHint)
+	assert.Equal([]string{"read_pipelines"}, scopes)
+
+	result, _, err := handler(ctx, createMCPRequest(t, map[string]any{}), ListPipelineNotificationServicesArgs{
+		OrgSlug:      "test-org",
+		PipelineSlug: "test-pipeline",
+	})
+	assert.NoError(err)
+	assert.False(result.IsError)
+
+	var got PipelineNotificationsResult
+	assert.NoError(json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &got))
+	assert.Equal([]PipelineNotification{
+		{Service: "slack", Target: "#ci-alerts", Condition: `build.state == "failed"`},
+		{Service: "email", Target: "team@example.com"},
+		{Service: "webhook", Target: "https://example.com/hooks/deploy", Step: "deploy-prod"},
+	}, got.Notifications)
+}
+
+func TestListPipelineNotificationServices_Errors(t *testing.T) {
+	assert := require.New(t)
+
+	t.Run("api error", func(t *testing.T) {
+		mockPipelines := &MockPipelinesClient{
+			GetFunc: func(ctx context.Context, org string, pipeline string) (buildkite.Pipeline, *buildkite.Response, error) {
+				return buildkite.Pipeline{}, nil, errors.New("pipeline not found")
+			},
+		}
+
+		ctx := ContextWithDeps(context.Background(), ToolDependencies{PipelinesClient: mockPipelines})
+		_, handler, _ := ListPipelineNotificationServices()
+		result, _, err := handler(ctx, createMCPRequest(t, map[string]any{}), ListPipelineNotificationServicesArgs{OrgSlug: "test-org", PipelineSlug: "missing"})
+		assert.NoError(err)
+		assert.True(result.IsError)
+		assert.Contains(result.Content[0].(*mcp.TextContent).Text, "pipeline not found")
+	})
+
+	t.Run("no configuration", func(t *testing.T) {
+		notifications, err := parsePipelineNotifications("")
+		assert.NoError(err)
+		assert.Empty(notifications)
+	})
+
+	t.Run("invalid yaml", func(t *testing.T) {
+		_, err := parsePipelineNotifications("steps: [")
+		assert.Error(err)
+	})
+}
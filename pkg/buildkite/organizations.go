@@ -30,14 +30,14 @@ func UserTokenOrganization() (mcp.Tool, mcp.ToolHandlerFor[UserTokenOrganization
 			deps := DepsFromContext(ctx)
 			orgs, _, err := deps.OrganizationsClient.List(ctx, &buildkite.OrganizationListOptions{})
 			if err != nil {
-				return handleBuildkiteError(err)
+				return handleBuildkiteError(ctx, err)
 			}
 
 			if len(orgs) == 0 {
 				return utils.NewToolResultError("no organization found for the current user token"), nil, nil
 			}
 
-			return mcpTextResult(span, &orgs[0])
+			return mcpTextResult(ctx, span, &orgs[0])
 		}, []string{"read_organizations"}
 }
 
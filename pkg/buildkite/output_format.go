@@ -0,0 +1,231 @@
+package buildkite
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/buildkite/buildkite-mcp-server/pkg/utils"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	outputFormatJSON     = "json"
+	outputFormatYAML     = "yaml"
+	outputFormatMarkdown = "markdown"
+	outputFormatNDJSON   = "ndjson"
+)
+
+// Columns of the markdown table for each resource type. Lists get a row per
+// item; a single resource gets a row per column.
+var (
+	pipelineColumns = []string{"slug", "name", "repository", "default_branch", "visibility", "web_url"}
+	buildColumns    = []string{"number", "state", "branch", "commit", "message", "created_at", "web_url"}
+	artifactColumns = []string{"id", "job_id", "path", "file_size", "mime_type", "state"}
+)
+
+// maxMarkdownCellLength keeps long values such as commit messages from
+// stretching a table row over many lines.
+const maxMarkdownCellLength = 80
+
+// mcpFormattedResult is mcpTextResult with the result rendered in format:
+// "json" (the default), "yaml", "markdown" or "ndjson". columns are the keys
+// shown in a markdown table.
+func mcpFormattedResult(ctx context.Context, span trace.Span, result any, format string, columns []string) (*mcp.CallToolResult, any, error) {
+	sanitized, err := MarshalResult(ctx, result)
+	if err != nil {
+		return utils.NewToolResultError(err.Error()), nil, nil
+	}
+
+	formatted, err := formatResult(sanitized, format, columns)
+	if err != nil {
+		return utils.NewToolResultError(err.Error()), nil, nil
+	}
+
+	span.SetAttributes(attribute.String("output_format", format))
+	return mcpSanitizedTextResult(span, formatted)
+}
+
+// mcpStructuredResult is mcpFormattedResult that also returns the result as
+// structured content, so clients that support it needn't parse the text. The
+// structured content is compact JSON whatever the format.
+func mcpStructuredResult(ctx context.Context, span trace.Span, result any, format string, columns []string) (*mcp.CallToolResult, any, error) {
+	res, out, err := mcpFormattedResult(ctx, span, result, format, columns)
+	if err != nil || res.IsError {
+		return res, out, err
+	}
+
+	structured, err := marshalSanitizedJSON(result)
+	if err != nil {
+		return utils.NewToolResultError(err.Error()), nil, nil
+	}
+	res.StructuredContent = json.RawMessage(structured)
+
+	return res, out, nil
+}
+
+// formatResult renders a sanitized JSON result in format.
+func formatResult(sanitized []byte, format string, columns []string) ([]byte, error) {
+	switch format {
+	case "", outputFormatJSON:
+		return sanitized, nil
+	case outputFormatYAML:
+		var value any
+		if err := json.Unmarshal(sanitized, &value); err != nil {
+			return nil, fmt.Errorf("failed to decode result: %w", err)
+		}
+		out, err := yaml.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render result as YAML: %w", err)
+		}
+		return out, nil
+	case outputFormatMarkdown:
+		decoder := json.NewDecoder(bytes.NewReader(sanitized))
+		decoder.UseNumber()
+		var value any
+		if err := decoder.Decode(&value); err != nil {
+			return nil, fmt.Errorf("failed to decode result: %w", err)
+		}
+		return []byte(markdownResult(value, columns)), nil
+	case outputFormatNDJSON:
+		return ndjsonResult(sanitized)
+	default:
+		return nil, fmt.Errorf("unknown output_format %q, expected json, yaml, markdown or ndjson", format)
+	}
+}
+
+// markdownResult renders a list result as a table with a row per item,
+// followed by its pagination keys, and any other object as a table of
+// columns and their values.
+func markdownResult(value any, columns []string) string {
+	root, ok := value.(map[string]any)
+	if !ok {
+		return markdownCell(value)
+	}
+
+	var sb strings.Builder
+	items, isList := root["items"].([]any)
+	if !isList {
+		sb.WriteString("| Field | Value |\n| --- | --- |\n")
+		for _, column := range columns {
+			if cell, ok := lookupPath(root, column); ok {
+				fmt.Fprintf(&sb, "| %s | %s |\n", column, markdownCell(cell))
+			}
+		}
+		return sb.String()
+	}
+
+	if len(items) == 0 {
+		sb.WriteString("_No results._\n")
+	} else {
+		sb.WriteString("| " + strings.Join(columns, " | ") + " |\n")
+		sb.WriteString("|" + strings.Repeat(" --- |", len(columns)) + "\n")
+		for _, item := range items {
+			cells := make([]string, len(columns))
+			for i, column := range columns {
+				if cell, ok := lookupPath(item, column); ok {
+					cells[i] = markdownCell(cell)
+				}
+			}
+			sb.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+		}
+	}
+
+	var keys []string
+	for key := range root {
+		if key != "items" && key != "headers" {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	if len(keys) > 0 {
+		sb.WriteString("\n")
+	}
+	for _, key := range keys {
+		fmt.Fprintf(&sb, "- %s: %s\n", key, markdownCell(root[key]))
+	}
+	return sb.String()
+}
+
+// ndjsonResult renders the items of a list result as newline-delimited JSON,
+// one compact object per line with no enclosing array, so a stream processor
+// can handle each as it arrives. Everything else in the result, such as
+// headers, pagination and truncated, follows on a last line as the keys of a
+// "_meta" object. Any other result is a single line.
+func ndjsonResult(sanitized []byte) ([]byte, error) {
+	lines := []json.RawMessage{sanitized}
+	var root map[string]json.RawMessage
+	if err := json.Unmarshal(sanitized, &root); err == nil && root["items"] != nil {
+		if err := json.Unmarshal(root["items"], &lines); err != nil {
+			return nil, fmt.Errorf("failed to decode result items: %w", err)
+		}
+
+		delete(root, "items")
+		if len(root) > 0 {
+			meta, err := json.Marshal(map[string]any{"_meta": root})
+			if err != nil {
+				return nil, fmt.Errorf("failed to render result metadata: %w", err)
+			}
+			lines = append(lines, meta)
+		}
+	}
+
+	var out bytes.Buffer
+	for _, line := range lines {
+		if err := json.Compact(&out, line); err != nil {
+			return nil, fmt.Errorf("failed to render result as NDJSON: %w", err)
+		}
+		out.WriteByte('\n')
+	}
+	return out.Bytes(), nil
+}
+
+// lookupPath returns the value at a dotted path of a decoded JSON object.
+func lookupPath(value any, path string) (any, bool) {
+	for key := range strings.SplitSeq(path, ".") {
+		object, ok := value.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if value, ok = object[key]; !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// markdownCell renders a decoded JSON value on a single line of a table.
+// Strings keep their first line only, and nested values are compact JSON.
+func markdownCell(value any) string {
+	var cell string
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		cell, _, _ = strings.Cut(v, "\n")
+		if cell != v {
+			cell += " …"
+		}
+	case json.Number:
+		cell = v.String()
+	case bool:
+		cell = fmt.Sprint(v)
+	default:
+		out, err := json.Marshal(v)
+		if err != nil {
+			return ""
+		}
+		cell = string(out)
+	}
+
+	if len(cell) > maxMarkdownCellLength {
+		cell = strings.ToValidUTF8(cell[:maxMarkdownCellLength], "") + "…"
+	}
+	return strings.ReplaceAll(strings.TrimSpace(cell), "|", `\|`)
+}
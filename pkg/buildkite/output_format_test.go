@@ -0,0 +1,158 @@
+package buildkite
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/buildkite/go-buildkite/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatResult(t *testing.T) {
+	list := []byte(`{"headers":{"Link":""},"items":[{"number":12,"state":"passed","message":"Fix | pipes\nwith details","creator":{"name":"Ada"}},{"number":13,"state":"failed"}],"has_more":true,"next_page":2}`)
+
+	t.Run("JSON", func(t *testing.T) {
+		assert := require.New(t)
+
+		for _, format := range []string{"", outputFormatJSON} {
+			out, err := formatResult(list, format, buildColumns)
+			assert.NoError(err)
+			assert.Equal(list, out)
+		}
+	})
+
+	t.Run("YAML", func(t *testing.T) {
+		assert := require.New(t)
+
+		out, err := formatResult([]byte(`{"number":12,"state":"passed","tags":["a","b"]}`), outputFormatYAML, nil)
+		assert.NoError(err)
+		assert.Equal("number: 12\nstate: passed\ntags:\n    - a\n    - b\n", string(out))
+	})
+
+	t.Run("MarkdownList", func(t *testing.T) {
+		assert := require.New(t)
+
+		out, err := formatResult(list, outputFormatMarkdown, []string{"number", "state", "message", "creator.name"})
+		assert.NoError(err)
+		assert.Equal(`| number | state | message | creator.name |
+| --- | --- | --- | --- |
+| 12 | passed | Fix \| pipes … | Ada |
+| 13 | failed |  |  |
+
+- has_more: true
+- next_page: 2
+`, string(out))
+	})
+
+	t.Run("MarkdownEmptyList", func(t *testing.T) {
+		assert := require.New(t)
+
+		out, err := formatResult([]byte(`{"headers":{},"items":[]}`), outputFormatMarkdown, buildColumns)
+		assert.NoError(err)
+		assert.Equal("_No results._\n", string(out))
+	})
+
+	t.Run("MarkdownObject", func(t *testing.T) {
+		assert := require.New(t)
+
+		out, err := formatResult([]byte(`{"slug":"app","name":"App","visibility":"private","steps":[1,2]}`), outputFormatMarkdown, pipelineColumns)
+		assert.NoError(err)
+		assert.Equal("| Field | Value |\n| --- | --- |\n| slug | app |\n| name | App |\n| visibility | private |\n", string(out))
+	})
+
+	t.Run("NDJSON", func(t *testing.T) {
+		assert := require.New(t)
+
+		out, err := formatResult([]byte("{\n  \"headers\": {},\n  \"items\": [\n    {\"number\": 2, \"state\": \"passed\"},\n    {\"number\": 1, \"state\": \"failed\"}\n  ],\n  \"has_more\": true\n}"), outputFormatNDJSON, nil)
+		assert.NoError(err)
+		assert.Equal("{\"number\":2,\"state\":\"passed\"}\n{\"number\":1,\"state\":\"failed\"}\n{\"_meta\":{\"has_more\":true,\"headers\":{}}}\n", string(out))
+
+		out, err = formatResult([]byte(`{"items":[]}`), outputFormatNDJSON, nil)
+		assert.NoError(err)
+		assert.Empty(out)
+
+		out, err = formatResult([]byte(`{"slug":"app","name":"App"}`), outputFormatNDJSON, nil)
+		assert.NoError(err)
+		assert.Equal("{\"slug\":\"app\",\"name\":\"App\"}\n", string(out))
+	})
+
+	t.Run("UnknownFormat", func(t *testing.T) {
+		assert := require.New(t)
+
+		_, err := formatResult(list, "xml", buildColumns)
+		assert.ErrorContains(err, `unknown output_format "xml"`)
+	})
+}
+
+func TestListBuildsOutputFormat(t *testing.T) {
+	assert := require.New(t)
+
+	client := &MockBuildsClient{
+		ListByPipelineFunc: func(ctx context.Context, org string, pipeline string, opt *buildkite.BuildsListOptions) ([]buildkite.Build, *buildkite.Response, error) {
+			return []buildkite.Build{{ID: "123", Number: 1, State: "passed", Branch: "main"}}, &buildkite.Response{
+				Response: &http.Response{StatusCode: 200},
+			}, nil
+		},
+	}
+
+	ctx := ContextWithDeps(context.Background(), ToolDependencies{BuildsClient: client})
+	_, handler, _ := ListBuilds()
+
+	result, _, err := handler(ctx, createMCPRequest(t, map[string]any{}), ListBuildsArgs{
+		OrgSlug:      "org",
+		PipelineSlug: "pipeline",
+		OutputFormat: outputFormatMarkdown,
+		Fields:       []string{"number", "state", "branch"},
+	})
+	assert.NoError(err)
+	assert.Equal("| number | state | branch |\n| --- | --- | --- |\n| 1 | passed | main |\n", getTextResult(t, result).Text)
+
+	result, _, err = handler(ctx, createMCPRequest(t, map[string]any{}), ListBuildsArgs{
+		OrgSlug:      "org",
+		PipelineSlug: "pipeline",
+		OutputFormat: "xml",
+	})
+	assert.NoError(err)
+	assert.True(result.IsError)
+}
+
+func TestGetBuildStructuredContent(t *testing.T) {
+	assert := require.New(t)
+
+	client := &MockBuildsClient{
+		GetFunc: func(ctx context.Context, org string, pipeline string, id string, opt *buildkite.BuildGetOptions) (buildkite.Build, *buildkite.Response, error) {
+			return buildkite.Build{Number: 1, State: "passed", Branch: "main"}, nil, nil
+		},
+	}
+	ctx := ContextWithDeps(context.Background(), ToolDependencies{BuildsClient: client, AnnotationsClient: &MockAnnotationsClient{}})
+	_, handler, _ := GetBuild()
+
+	result, _, err := handler(ctx, createMCPRequest(t, map[string]any{}), GetBuildArgs{
+		OrgSlug:      "org",
+		PipelineSlug: "pipeline",
+		BuildNumber:  "1",
+		OutputFormat: outputFormatYAML,
+	})
+	assert.NoError(err)
+	assert.Contains(getTextResult(t, result).Text, "state: passed\n")
+
+	// The structured content stays JSON whatever the output format.
+	structured, ok := result.StructuredContent.(json.RawMessage)
+	assert.True(ok)
+	var build BuildDetail
+	assert.NoError(json.Unmarshal(structured, &build))
+	assert.Equal(1, build.Number)
+	assert.Equal("passed", build.State)
+
+	result, _, err = handler(ctx, createMCPRequest(t, map[string]any{}), GetBuildArgs{
+		OrgSlug:      "org",
+		PipelineSlug: "pipeline",
+		BuildNumber:  "1",
+		OutputFormat: "xml",
+	})
+	assert.NoError(err)
+	assert.True(result.IsError)
+	assert.Nil(result.StructuredContent)
+}
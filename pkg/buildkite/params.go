@@ -0,0 +1,48 @@
+package buildkite
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultCallTimeout bounds the tools that take timeout_seconds when the
+	// caller leaves it out, so a slow call can't hold up a session forever.
+	defaultCallTimeout = 5 * time.Minute
+	maxCallTimeout     = 30 * time.Minute
+)
+
+// requireParams returns an error naming every parameter in params whose value
+// is empty, so a caller can fix them all at once rather than one per call.
+// params maps parameter names to their values.
+func requireParams(params map[string]string) error {
+	var missing []string
+	for name, value := range params {
+		if strings.TrimSpace(value) == "" {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	slices.Sort(missing)
+	if len(missing) == 1 {
+		return fmt.Errorf("missing required parameter: %s", missing[0])
+	}
+	return fmt.Errorf("missing required parameters: %s", strings.Join(missing, ", "))
+}
+
+// withCallTimeout returns a copy of ctx that is canceled after timeoutSeconds,
+// capped at maxCallTimeout, or after defaultCallTimeout when timeoutSeconds
+// isn't positive. The caller must call cancel once the tool call is done.
+func withCallTimeout(ctx context.Context, timeoutSeconds int) (context.Context, context.CancelFunc) {
+	timeout := defaultCallTimeout
+	if timeoutSeconds > 0 {
+		timeout = min(time.Duration(timeoutSeconds)*time.Second, maxCallTimeout)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
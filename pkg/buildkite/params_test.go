@@ -0,0 +1,96 @@
+package buildkite
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/buildkite/go-buildkite/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequireParams(t *testing.T) {
+	assert := require.New(t)
+
+	assert.NoError(requireParams(map[string]string{"org_slug": "org", "pipeline_slug": "app"}))
+	assert.EqualError(requireParams(map[string]string{"org_slug": "org", "pipeline_slug": " "}), "missing required parameter: pipeline_slug")
+	assert.EqualError(requireParams(map[string]string{
+		"org_slug":      "",
+		"pipeline_slug": "app",
+		"build_number":  "",
+		"job_id":        "",
+	}), "missing required parameters: build_number, job_id, org_slug")
+}
+
+func TestUnblockJobRequiresParams(t *testing.T) {
+	assert := require.New(t)
+
+	client := &MockJobsClient{}
+	ctx := ContextWithDeps(context.Background(), ToolDependencies{JobsClient: client})
+	_, handler, _ := UnblockJob()
+
+	result, _, err := handler(ctx, createMCPRequest(t, map[string]any{}), UnblockJobArgs{
+		OrgSlug:     "org",
+		BuildNumber: "1",
+	})
+	assert.NoError(err)
+	assert.True(result.IsError)
+	assert.Equal("missing required parameters: job_id, pipeline_slug", getTextResult(t, result).Text)
+}
+
+func TestWithCallTimeout(t *testing.T) {
+	tests := []struct {
+		name           string
+		timeoutSeconds int
+		want           time.Duration
+	}{
+		{"Default", 0, defaultCallTimeout},
+		{"NegativeUsesDefault", -5, defaultCallTimeout},
+		{"Given", 30, 30 * time.Second},
+		{"Capped", 7200, maxCallTimeout},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Now()
+			ctx, cancel := withCallTimeout(context.Background(), tt.timeoutSeconds)
+			deadline, ok := ctx.Deadline()
+			require.True(t, ok)
+			require.WithinDuration(t, start.Add(tt.want), deadline, time.Second)
+
+			cancel()
+			require.ErrorIs(t, ctx.Err(), context.Canceled)
+		})
+	}
+}
+
+func TestListBuildsTimeout(t *testing.T) {
+	var listCtx context.Context
+	client := &MockBuildsClient{
+		ListByPipelineFunc: func(ctx context.Context, org string, pipeline string, opt *buildkite.BuildsListOptions) ([]buildkite.Build, *buildkite.Response, error) {
+			listCtx = ctx
+			return nil, nil, &url.Error{Op: "Get", URL: "https://api.buildkite.com/v2/builds", Err: context.DeadlineExceeded}
+		},
+	}
+	ctx := ContextWithDeps(context.Background(), ToolDependencies{BuildsClient: client})
+	_, handler, _ := ListBuilds()
+
+	start := time.Now()
+	result, _, err := handler(ctx, createMCPRequest(t, map[string]any{}), ListBuildsArgs{
+		OrgSlug:        "org",
+		PipelineSlug:   "pipeline",
+		AutoPaginate:   true,
+		TimeoutSeconds: 60,
+	})
+	require.NoError(t, err)
+	require.True(t, result.IsError)
+	require.Contains(t, getTextResult(t, result).Text, "timed out before it finished")
+	require.Contains(t, getTextResult(t, result).Text, "timeout_seconds")
+
+	deadline, ok := listCtx.Deadline()
+	require.True(t, ok)
+	require.WithinDuration(t, start.Add(time.Minute), deadline, time.Second)
+	// The bounded context is released when the call returns.
+	require.ErrorIs(t, listCtx.Err(), context.Canceled)
+}
@@ -0,0 +1,173 @@
+package buildkite
+
+import (
+	"context"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/buildkite/buildkite-mcp-server/pkg/trace"
+	"github.com/buildkite/buildkite-mcp-server/pkg/utils"
+	"github.com/buildkite/go-buildkite/v5"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	pipelineMetricsDefaultDays   = 7
+	pipelineMetricsMaxDays       = 90
+	pipelineMetricsDefaultBuilds = 100
+	pipelineMetricsMaxBuilds     = 500
+	pipelineMetricsPageSize      = 100
+)
+
+type GetPipelineMetricsArgs struct {
+	OrgSlug      string `json:"org_slug"`
+	PipelineSlug string `json:"pipeline_slug"`
+	Days         int    `json:"days,omitempty" jsonschema:"How many days back to look at builds that finished. Default 7, max 90"`
+	Branch       string `json:"branch,omitempty" jsonschema:"Only include builds of this branch. Defaults to every branch"`
+	MaxBuilds    int    `json:"max_builds,omitempty" jsonschema:"The most builds to sample, newest first. Default 100, max 500"`
+}
+
+// PipelineMetrics summarizes the finished builds of a pipeline. Pass rate
+// counts passed and failed builds only, and durations are of those builds
+// from start to finish, so canceled builds don't skew either.
+type PipelineMetrics struct {
+	Days                   int      `json:"days"`
+	Branch                 string   `json:"branch,omitempty"`
+	BuildsSampled          int      `json:"builds_sampled"`
+	Truncated              bool     `json:"truncated,omitempty"`
+	Passed                 int      `json:"passed"`
+	Failed                 int      `json:"failed"`
+	Canceled               int      `json:"canceled"`
+	PassRate               *float64 `json:"pass_rate,omitempty"`
+	AverageDurationSeconds *int64   `json:"average_duration_seconds,omitempty"`
+	MedianDurationSeconds  *int64   `json:"median_duration_seconds,omitempty"`
+}
+
+func pipelineMetrics(builds []buildkite.Build) PipelineMetrics {
+	metrics := PipelineMetrics{BuildsSampled: len(builds)}
+
+	var durations []int64
+	for _, build := range builds {
+		switch build.State {
+		case "passed":
+			metrics.Passed++
+		case "failed":
+			metrics.Failed++
+		case "canceled":
+			metrics.Canceled++
+			continue
+		default:
+			continue
+		}
+		if duration := durationSeconds(build.StartedAt, build.FinishedAt); duration != nil {
+			durations = append(durations, *duration)
+		}
+	}
+
+	if decided := metrics.Passed + metrics.Failed; decided > 0 {
+		passRate := math.Round(float64(metrics.Passed)/float64(decided)*1000) / 1000
+		metrics.PassRate = &passRate
+	}
+
+	if len(durations) > 0 {
+		slices.Sort(durations)
+		var total int64
+		for _, duration := range durations {
+			total += duration
+		}
+		average := total / int64(len(durations))
+		median := durations[len(durations)/2]
+		if len(durations)%2 == 0 {
+			median = (durations[len(durations)/2-1] + median) / 2
+		}
+		metrics.AverageDurationSeconds = &average
+		metrics.MedianDurationSeconds = &median
+	}
+
+	return metrics
+}
+
+func GetPipelineMetrics() (mcp.Tool, mcp.ToolHandlerFor[GetPipelineMetricsArgs, any], []string) {
+	return mcp.Tool{
+			Name:        "get_pipeline_metrics",
+			Description: "Get the health of a pipeline over recent days: pass rate, average and median build duration, and how many builds passed, failed or were canceled. Computed from a bounded sample of the newest finished builds; truncated is set when the window holds more",
+			Annotations: &mcp.ToolAnnotations{
+				Title:        "Get Pipeline Metrics",
+				ReadOnlyHint: true,
+			},
+		},
+		func(ctx context.Context, request *mcp.CallToolRequest, args GetPipelineMetricsArgs) (*mcp.CallToolResult, any, error) {
+			ctx, span := trace.Start(ctx, "buildkite.GetPipelineMetrics")
+			defer span.End()
+
+			days := boundedValue(args.Days, pipelineMetricsDefaultDays, pipelineMetricsMaxDays)
+			maxBuilds := boundedValue(args.MaxBuilds, pipelineMetricsDefaultBuilds, pipelineMetricsMaxBuilds)
+
+			span.SetAttributes(
+				attribute.String("org_slug", args.OrgSlug),
+				attribute.String("pipeline_slug", args.PipelineSlug),
+				attribute.String("branch", args.Branch),
+				attribute.Int("days", days),
+				attribute.Int("max_builds", maxBuilds),
+			)
+
+			if err := requireParams(map[string]string{
+				"org_slug":      args.OrgSlug,
+				"pipeline_slug": args.PipelineSlug,
+			}); err != nil {
+				return utils.NewToolResultError(err.Error()), nil, nil
+			}
+
+			options := &buildkite.BuildsListOptions{
+				FinishedFrom:    time.Now().AddDate(0, 0, -days),
+				State:           []string{"passed", "failed", "canceled"},
+				ExcludeJobs:     true,
+				ExcludePipeline: true,
+				ListOptions: buildkite.ListOptions{
+					Page:    1,
+					PerPage: min(maxBuilds, pipelineMetricsPageSize),
+				},
+			}
+			if args.Branch != "" {
+				options.Branch = []string{args.Branch}
+			}
+
+			deps := DepsFromContext(ctx)
+			var (
+				builds    []buildkite.Build
+				truncated bool
+			)
+			for {
+				page, resp, err := deps.BuildsClient.ListByPipeline(ctx, args.OrgSlug, args.PipelineSlug, options)
+				if err != nil {
+					return handleBuildkiteError(ctx, err)
+				}
+				builds = append(builds, page...)
+
+				more := resp != nil && resp.NextPage > 0
+				if len(builds) >= maxBuilds {
+					truncated = more || len(builds) > maxBuilds
+					builds = builds[:maxBuilds]
+					break
+				}
+				if !more {
+					break
+				}
+				options.Page = resp.NextPage
+			}
+
+			metrics := pipelineMetrics(builds)
+			metrics.Days = days
+			metrics.Branch = args.Branch
+			metrics.Truncated = truncated
+
+			span.SetAttributes(
+				attribute.Int("builds_sampled", metrics.BuildsSampled),
+				attribute.Bool("truncated", truncated),
+			)
+
+			return mcpTextResult(ctx, span, &metrics)
+		}, []string{"read_builds"}
+}
@@ -0,0 +1,82 @@
+package buildkite
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/buildkite/go-buildkite/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func finishedBuild(state string, duration time.Duration) buildkite.Build {
+	return buildkite.Build{State: state, StartedAt: testTimestamp(0), FinishedAt: testTimestamp(duration)}
+}
+
+func TestPipelineMetrics(t *testing.T) {
+	assert := require.New(t)
+
+	metrics := pipelineMetrics([]buildkite.Build{
+		finishedBuild("passed", 60*time.Second),
+		finishedBuild("passed", 120*time.Second),
+		finishedBuild("failed", 300*time.Second),
+		finishedBuild("canceled", time.Hour),
+	})
+
+	assert.Equal(4, metrics.BuildsSampled)
+	assert.Equal(2, metrics.Passed)
+	assert.Equal(1, metrics.Failed)
+	assert.Equal(1, metrics.Canceled)
+	assert.Equal(0.667, *metrics.PassRate)
+	assert.Equal(int64(160), *metrics.AverageDurationSeconds)
+	assert.Equal(int64(120), *metrics.MedianDurationSeconds)
+
+	empty := pipelineMetrics(nil)
+	assert.Nil(empty.PassRate)
+	assert.Nil(empty.MedianDurationSeconds)
+}
+
+func TestGetPipelineMetrics(t *testing.T) {
+	assert := require.New(t)
+
+	var pages []int
+	client := &MockBuildsClient{
+		ListByPipelineFunc: func(ctx context.Context, org string, pipeline string, opt *buildkite.BuildsListOptions) ([]buildkite.Build, *buildkite.Response, error) {
+			pages = append(pages, opt.Page)
+			assert.Equal(3, opt.PerPage)
+			assert.Equal([]string{"main"}, opt.Branch)
+			assert.WithinDuration(time.Now().AddDate(0, 0, -14), opt.FinishedFrom, time.Minute)
+			return []buildkite.Build{
+				finishedBuild("passed", time.Minute),
+				finishedBuild("failed", time.Minute),
+			}, &buildkite.Response{NextPage: opt.Page + 1}, nil
+		},
+	}
+
+	ctx := ContextWithDeps(context.Background(), ToolDependencies{BuildsClient: client})
+	_, handler, _ := GetPipelineMetrics()
+
+	result, _, err := handler(ctx, createMCPRequest(t, map[string]any{}), GetPipelineMetricsArgs{
+		OrgSlug:      "org",
+		PipelineSlug: "pipeline",
+		Days:         14,
+		Branch:       "main",
+		MaxBuilds:    3,
+	})
+	assert.NoError(err)
+
+	// The sample stops at max_builds, with more builds left in the window.
+	assert.Equal([]int{1, 2}, pages)
+	assert.JSONEq(`{
+		"days": 14,
+		"branch": "main",
+		"builds_sampled": 3,
+		"truncated": true,
+		"passed": 2,
+		"failed": 1,
+		"canceled": 0,
+		"pass_rate": 0.667,
+		"average_duration_seconds": 60,
+		"median_duration_seconds": 60
+	}`, getTextResult(t, result).Text)
+}
@@ -0,0 +1,204 @@
+package buildkite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/buildkite/go-buildkite/v5"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rs/zerolog/log"
+)
+
+// ResourceScheme is the URI scheme of the Buildkite resources.
+const ResourceScheme = "buildkite"
+
+// PipelineResourceURITemplate is the URI template of pipeline resources.
+const PipelineResourceURITemplate = ResourceScheme + "://{org}/{pipeline}"
+
+// pipelineResourcesPerPage is the page size used to list pipelines for
+// resources/list, which fetches at most maxAutoPaginatePages of them.
+const pipelineResourcesPerPage = 100
+
+// NewPipelineResourceTemplate returns the resource template for pipelines and
+// its handler, which reads a pipeline from the API.
+func NewPipelineResourceTemplate() (*mcp.ResourceTemplate, mcp.ResourceHandler) {
+	template := &mcp.ResourceTemplate{
+		URITemplate: PipelineResourceURITemplate,
+		Name:        "pipeline",
+		Title:       "Pipeline",
+		Description: "A pipeline's configuration, steps, environment variables, and build statistics",
+		MIMEType:    "application/json",
+	}
+
+	handler := func(ctx context.Context, request *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+		uri := request.Params.URI
+		segments, ok := resourceSegments(uri)
+		if !ok || len(segments) != 2 {
+			return nil, mcp.ResourceNotFoundError(uri)
+		}
+
+		deps := DepsFromContext(ctx)
+		pipeline, _, err := deps.PipelinesClient.Get(ctx, segments[0], segments[1])
+		if err != nil {
+			return nil, resourceError(uri, err)
+		}
+
+		return jsonResourceResult(ctx, uri, pipeline)
+	}
+
+	return template, handler
+}
+
+// PipelineResourcesMiddleware adds the pipelines of the organization to the
+// last page of resources/list, so clients can browse them without a tool
+// call. The organization is ToolDependencies.DefaultOrg, or else the token's
+// organization. Listing pipelines is best effort: when it fails, for example
+// because the request has no valid token, the other resources are still
+// listed.
+//
+// It must run inside InjectDepsMiddleware.
+func PipelineResourcesMiddleware() mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			result, err := next(ctx, method, req)
+			if err != nil || method != "resources/list" {
+				return result, err
+			}
+
+			list, ok := result.(*mcp.ListResourcesResult)
+			if !ok || list.NextCursor != "" {
+				return result, err
+			}
+
+			resources, err := pipelineResources(ctx)
+			if err != nil {
+				log.Ctx(ctx).Warn().Err(err).Msg("Failed to list pipelines as resources")
+				return result, nil
+			}
+			list.Resources = append(list.Resources, resources...)
+
+			return list, nil
+		}
+	}
+}
+
+func pipelineResources(ctx context.Context) ([]*mcp.Resource, error) {
+	deps := DepsFromContext(ctx)
+	if deps.PipelinesClient == nil {
+		return nil, nil
+	}
+
+	org, err := resourceOrg(ctx, deps)
+	if err != nil || org == "" {
+		return nil, err
+	}
+
+	pipelines, _, truncated, err := listPages(1, true, func(page int) ([]buildkite.Pipeline, *buildkite.Response, error) {
+		return deps.PipelinesClient.List(ctx, org, &buildkite.PipelineListOptions{
+			ListOptions: buildkite.ListOptions{
+				Page:    page,
+				PerPage: pipelineResourcesPerPage,
+			},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	if truncated {
+		log.Ctx(ctx).Debug().Str("org", org).Int("count", len(pipelines)).Msg("Listing only the first pipelines as resources")
+	}
+
+	resources := make([]*mcp.Resource, 0, len(pipelines))
+	for _, pipeline := range pipelines {
+		resources = append(resources, &mcp.Resource{
+			URI:         resourceURI(org, pipeline.Slug),
+			Name:        pipeline.Slug,
+			Title:       pipeline.Name,
+			Description: pipeline.Description,
+			MIMEType:    "application/json",
+		})
+	}
+	return resources, nil
+}
+
+// resourceOrg returns the organization whose resources are listed. An empty
+// result means the token has no organization.
+func resourceOrg(ctx context.Context, deps ToolDependencies) (string, error) {
+	if deps.DefaultOrg != "" {
+		return deps.DefaultOrg, nil
+	}
+	if deps.OrganizationsClient == nil {
+		return "", nil
+	}
+
+	orgs, _, err := deps.OrganizationsClient.List(ctx, &buildkite.OrganizationListOptions{})
+	if err != nil || len(orgs) == 0 {
+		return "", err
+	}
+	return orgs[0].Slug, nil
+}
+
+// resourceURI returns the URI of the resource at the given path segments,
+// escaping each of them.
+func resourceURI(segments ...string) string {
+	escaped := make([]string, len(segments))
+	for i, segment := range segments {
+		escaped[i] = url.PathEscape(segment)
+	}
+	return ResourceScheme + "://" + strings.Join(escaped, "/")
+}
+
+// resourceSegments splits a resource URI into its unescaped path segments,
+// the first of which is the organization.
+func resourceSegments(uri string) ([]string, bool) {
+	scheme, rest, ok := strings.Cut(uri, "://")
+	if !ok || scheme != ResourceScheme {
+		return nil, false
+	}
+
+	segments := strings.Split(rest, "/")
+	for i, segment := range segments {
+		unescaped, err := url.PathUnescape(segment)
+		if err != nil || unescaped == "" {
+			return nil, false
+		}
+		segments[i] = unescaped
+	}
+	return segments, true
+}
+
+// resourceError converts a Buildkite API error into a resource read error. A
+// 401 becomes ErrUnauthorized, like in tool handlers, and a 404 the MCP
+// resource-not-found error.
+func resourceError(uri string, err error) error {
+	if isBuildkiteUnauthorized(err) {
+		return ErrUnauthorized
+	}
+
+	var errResp *buildkite.ErrorResponse
+	if errors.As(err, &errResp) && errResp.Response != nil && errResp.Response.StatusCode == http.StatusNotFound {
+		return mcp.ResourceNotFoundError(uri)
+	}
+	return fmt.Errorf("failed to read resource %s: %w", uri, err)
+}
+
+func jsonResourceResult(ctx context.Context, uri string, result any) (*mcp.ReadResourceResult, error) {
+	r, err := MarshalResult(ctx, result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{
+			{
+				URI:      uri,
+				MIMEType: "application/json",
+				Text:     string(r),
+			},
+		},
+	}, nil
+}
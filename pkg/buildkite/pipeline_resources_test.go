@@ -0,0 +1,137 @@
+package buildkite
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/buildkite/go-buildkite/v5"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+func listResources(t *testing.T, deps ToolDependencies) *mcp.ListResourcesResult {
+	t.Helper()
+
+	static := func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		return &mcp.ListResourcesResult{Resources: []*mcp.Resource{{URI: "buildkite://debug-logs-guide"}}}, nil
+	}
+	handler := InjectDepsMiddleware(deps)(PipelineResourcesMiddleware()(static))
+
+	result, err := handler(context.Background(), "resources/list", &mcp.ListResourcesRequest{})
+	require.NoError(t, err)
+	return result.(*mcp.ListResourcesResult)
+}
+
+func TestPipelineResourcesMiddleware(t *testing.T) {
+	t.Run("lists the pipelines of every page", func(t *testing.T) {
+		assert := require.New(t)
+
+		var pages []int
+		client := &MockPipelinesClient{
+			ListFunc: func(ctx context.Context, org string, opt *buildkite.PipelineListOptions) ([]buildkite.Pipeline, *buildkite.Response, error) {
+				assert.Equal("acme", org)
+				pages = append(pages, opt.Page)
+				resp := &buildkite.Response{Response: &http.Response{StatusCode: http.StatusOK}}
+				if opt.Page == 1 {
+					resp.NextPage = 2
+					return []buildkite.Pipeline{{Slug: "web", Name: "Web", Description: "The website"}}, resp, nil
+				}
+				return []buildkite.Pipeline{{Slug: "api", Name: "API"}}, resp, nil
+			},
+		}
+		orgs := &MockOrganizationsClient{
+			ListFunc: func(ctx context.Context, options *buildkite.OrganizationListOptions) ([]buildkite.Organization, *buildkite.Response, error) {
+				return []buildkite.Organization{{Slug: "acme"}}, nil, nil
+			},
+		}
+
+		result := listResources(t, ToolDependencies{PipelinesClient: client, OrganizationsClient: orgs})
+
+		assert.Equal([]int{1, 2}, pages)
+		assert.Len(result.Resources, 3)
+		assert.Equal(&mcp.Resource{
+			URI:         "buildkite://acme/web",
+			Name:        "web",
+			Title:       "Web",
+			Description: "The website",
+			MIMEType:    "application/json",
+		}, result.Resources[1])
+		assert.Equal("buildkite://acme/api", result.Resources[2].URI)
+	})
+
+	t.Run("uses the default organization", func(t *testing.T) {
+		assert := require.New(t)
+
+		client := &MockPipelinesClient{
+			ListFunc: func(ctx context.Context, org string, opt *buildkite.PipelineListOptions) ([]buildkite.Pipeline, *buildkite.Response, error) {
+				assert.Equal("default", org)
+				return nil, &buildkite.Response{Response: &http.Response{StatusCode: http.StatusOK}}, nil
+			},
+		}
+
+		result := listResources(t, ToolDependencies{PipelinesClient: client, DefaultOrg: "default"})
+		assert.Len(result.Resources, 1)
+	})
+
+	t.Run("keeps the other resources when the token is rejected", func(t *testing.T) {
+		orgs := &MockOrganizationsClient{
+			ListFunc: func(ctx context.Context, options *buildkite.OrganizationListOptions) ([]buildkite.Organization, *buildkite.Response, error) {
+				return nil, nil, ErrUnauthorized
+			},
+		}
+
+		result := listResources(t, ToolDependencies{PipelinesClient: &MockPipelinesClient{}, OrganizationsClient: orgs})
+		require.Len(t, result.Resources, 1)
+	})
+
+	t.Run("lists nothing for a token without organizations", func(t *testing.T) {
+		result := listResources(t, ToolDependencies{PipelinesClient: &MockPipelinesClient{}, OrganizationsClient: &MockOrganizationsClient{}})
+		require.Len(t, result.Resources, 1)
+	})
+}
+
+func TestNewPipelineResourceTemplate(t *testing.T) {
+	template, handler := NewPipelineResourceTemplate()
+	require.Equal(t, "buildkite://{org}/{pipeline}", template.URITemplate)
+
+	client := &MockPipelinesClient{
+		GetFunc: func(ctx context.Context, org string, pipeline string) (buildkite.Pipeline, *buildkite.Response, error) {
+			if pipeline == "missing" {
+				return buildkite.Pipeline{}, nil, &buildkite.ErrorResponse{Response: &http.Response{StatusCode: http.StatusNotFound}}
+			}
+			return buildkite.Pipeline{Slug: pipeline, Name: org + " " + pipeline}, nil, nil
+		},
+	}
+	ctx := ContextWithDeps(context.Background(), ToolDependencies{PipelinesClient: client})
+
+	read := func(uri string) (*mcp.ReadResourceResult, error) {
+		return handler(ctx, &mcp.ReadResourceRequest{Params: &mcp.ReadResourceParams{URI: uri}})
+	}
+
+	t.Run("reads the pipeline", func(t *testing.T) {
+		result, err := read("buildkite://acme/my%20pipeline")
+		require.NoError(t, err)
+		require.Len(t, result.Contents, 1)
+		require.Equal(t, "application/json", result.Contents[0].MIMEType)
+		require.Contains(t, result.Contents[0].Text, `"name":"acme my pipeline"`)
+	})
+
+	t.Run("missing pipeline", func(t *testing.T) {
+		_, err := read("buildkite://acme/missing")
+		require.EqualError(t, err, mcp.ResourceNotFoundError("buildkite://acme/missing").Error())
+	})
+
+	t.Run("malformed URI", func(t *testing.T) {
+		_, err := read("buildkite://acme")
+		require.Error(t, err)
+	})
+
+	t.Run("rejected token", func(t *testing.T) {
+		client.GetFunc = func(ctx context.Context, org string, pipeline string) (buildkite.Pipeline, *buildkite.Response, error) {
+			return buildkite.Pipeline{}, nil, ErrUnauthorized
+		}
+		_, err := read("buildkite://acme/web")
+		require.ErrorIs(t, err, ErrUnauthorized)
+	})
+}
@@ -2,6 +2,7 @@ package buildkite
 
 import (
 	"context"
+	"net/http"
 
 	"github.com/buildkite/buildkite-mcp-server/pkg/trace"
 	"github.com/buildkite/go-buildkite/v5"
@@ -17,10 +18,11 @@ type PipelineSchedulesClient interface {
 }
 
 type ListPipelineSchedulesArgs struct {
-	OrgSlug      string `json:"org_slug"`
-	PipelineSlug string `json:"pipeline_slug"`
-	Page         int    `json:"page,omitempty" jsonschema:"Page number for pagination (min 1)"`
-	PerPage      int    `json:"per_page,omitempty" jsonschema:"Results per page for pagination (min 1, max 100)"`
+	OrgSlug           string `json:"org_slug"`
+	PipelineSlug      string `json:"pipeline_slug"`
+	Page              int    `json:"page,omitempty" jsonschema:"Page number for pagination (min 1)"`
+	PerPage           int    `json:"per_page,omitempty" jsonschema:"Results per page for pagination (min 1, max 100)"`
+	IncludePagination bool   `json:"include_pagination,omitempty" jsonschema:"Add page, has_more and next_page to the result, so you can tell whether more pages exist"`
 }
 
 func ListPipelineSchedules() (mcp.Tool, mcp.ToolHandlerFor[ListPipelineSchedulesArgs, any], []string) {
@@ -49,7 +51,7 @@ func ListPipelineSchedules() (mcp.Tool, mcp.ToolHandlerFor[ListPipelineSchedules
 				ListOptions: paginationParams,
 			})
 			if err != nil {
-				return handleBuildkiteError(err)
+				return handleBuildkiteError(ctx, err)
 			}
 
 			result := PaginatedResult[buildkite.PipelineSchedule]{
@@ -57,13 +59,14 @@ func ListPipelineSchedules() (mcp.Tool, mcp.ToolHandlerFor[ListPipelineSchedules
 				Headers: map[string]string{
 					"Link": resp.Header.Get("Link"),
 				},
+				Pagination: paginationFor(args.IncludePagination, paginationParams.Page, resp),
 			}
 
 			span.SetAttributes(
 				attribute.Int("item_count", len(schedules)),
 			)
 
-			return mcpTextResult(span, &result)
+			return mcpTextResult(ctx, span, &result)
 		}, []string{"read_pipelines"}
 }
 
@@ -94,10 +97,10 @@ func GetPipelineSchedule() (mcp.Tool, mcp.ToolHandlerFor[GetPipelineScheduleArgs
 			deps := DepsFromContext(ctx)
 			schedule, _, err := deps.PipelineSchedulesClient.Get(ctx, args.OrgSlug, args.PipelineSlug, args.ScheduleID)
 			if err != nil {
-				return handleBuildkiteError(err)
+				return handleBuildkiteError(ctx, err)
 			}
 
-			return mcpTextResult(span, &schedule)
+			return mcpTextResult(ctx, span, &schedule)
 		}, []string{"read_pipelines"}
 }
 
@@ -111,6 +114,7 @@ type CreatePipelineScheduleArgs struct {
 	Branch       string            `json:"branch,omitempty" jsonschema:"Target branch (defaults to the pipeline default branch)"`
 	Env          map[string]string `json:"env,omitempty" jsonschema:"Environment variables to set on triggered builds"`
 	Enabled      *bool             `json:"enabled,omitempty" jsonschema:"Whether the schedule is active. Defaults to true if unset."`
+	DryRun       bool              `json:"dry_run,omitempty" jsonschema:"Validate the arguments and return the request this would make, without making it"`
 }
 
 func CreatePipelineSchedule() (mcp.Tool, mcp.ToolHandlerFor[CreatePipelineScheduleArgs, any], []string) {
@@ -141,13 +145,17 @@ func CreatePipelineSchedule() (mcp.Tool, mcp.ToolHandlerFor[CreatePipelineSchedu
 				Enabled:  args.Enabled,
 			}
 
+			if args.DryRun {
+				return mcpDryRunResult(ctx, span, "Create a pipeline schedule", http.MethodPost, apiPath("v2/organizations/%s/pipelines/%s/schedules", args.OrgSlug, args.PipelineSlug), create)
+			}
+
 			deps := DepsFromContext(ctx)
 			schedule, _, err := deps.PipelineSchedulesClient.Create(ctx, args.OrgSlug, args.PipelineSlug, create)
 			if err != nil {
-				return handleBuildkiteError(err)
+				return handleBuildkiteError(ctx, err)
 			}
 
-			return mcpTextResult(span, &schedule)
+			return mcpTextResult(ctx, span, &schedule)
 		}, []string{"write_pipelines"}
 }
 
@@ -162,6 +170,7 @@ type UpdatePipelineScheduleArgs struct {
 	Branch       *string           `json:"branch,omitempty"`
 	Env          map[string]string `json:"env,omitempty" jsonschema:"Environment variables to set on triggered builds. Providing this field REPLACES the existing env map entirely — include all keys you want to retain."`
 	Enabled      *bool             `json:"enabled,omitempty" jsonschema:"Whether the schedule is active. Re-enabling clears previous failure data."`
+	DryRun       bool              `json:"dry_run,omitempty" jsonschema:"Validate the arguments and return the request this would make, without making it"`
 }
 
 func UpdatePipelineSchedule() (mcp.Tool, mcp.ToolHandlerFor[UpdatePipelineScheduleArgs, any], []string) {
@@ -205,12 +214,16 @@ func UpdatePipelineSchedule() (mcp.Tool, mcp.ToolHandlerFor[UpdatePipelineSchedu
 				update.Enabled = buildkite.Some(*args.Enabled)
 			}
 
+			if args.DryRun {
+				return mcpDryRunResult(ctx, span, "Update a pipeline schedule", http.MethodPatch, apiPath("v2/organizations/%s/pipelines/%s/schedules/%s", args.OrgSlug, args.PipelineSlug, args.ScheduleID), update)
+			}
+
 			deps := DepsFromContext(ctx)
 			schedule, _, err := deps.PipelineSchedulesClient.Update(ctx, args.OrgSlug, args.PipelineSlug, args.ScheduleID, update)
 			if err != nil {
-				return handleBuildkiteError(err)
+				return handleBuildkiteError(ctx, err)
 			}
 
-			return mcpTextResult(span, &schedule)
+			return mcpTextResult(ctx, span, &schedule)
 		}, []string{"write_pipelines"}
 }
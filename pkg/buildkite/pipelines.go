@@ -2,6 +2,9 @@ package buildkite
 
 import (
 	"context"
+	"net/http"
+	"slices"
+	"strings"
 
 	"github.com/buildkite/buildkite-mcp-server/pkg/trace"
 	"github.com/buildkite/go-buildkite/v5"
@@ -18,12 +21,19 @@ type PipelinesClient interface {
 }
 
 type ListPipelinesArgs struct {
-	OrgSlug     string `json:"org_slug"`
-	Name        string `json:"name,omitempty" jsonschema:"Filter pipelines by name"`
-	Repository  string `json:"repository,omitempty" jsonschema:"Filter pipelines by repository URL"`
-	Page        int    `json:"page,omitempty" jsonschema:"Page number for pagination (min 1)"`
-	PerPage     int    `json:"per_page,omitempty" jsonschema:"Results per page for pagination (min 1, max 100)"`
-	DetailLevel string `json:"detail_level,omitempty" jsonschema:"Response detail level: 'summary' (default), 'detailed', or 'full'"`
+	OrgSlug           string   `json:"org_slug"`
+	Name              string   `json:"name,omitempty" jsonschema:"Filter pipelines by name"`
+	Repository        string   `json:"repository,omitempty" jsonschema:"Filter pipelines by repository URL"`
+	NameFilter        string   `json:"name_filter,omitempty" jsonschema:"Only return pipelines whose name or slug contains this, ignoring case. Unlike name, which the API matches against the pipeline name, this is applied client-side after fetching, so it can match slugs but scans at most 10 pages from page; matched and total count the pipelines kept and scanned"`
+	Tag               string   `json:"tag,omitempty" jsonschema:"Only return pipelines with this tag. Like name_filter, it scans up to 10 pages"`
+	Page              int      `json:"page,omitempty" jsonschema:"Page number for pagination (min 1)"`
+	PerPage           int      `json:"per_page,omitempty" jsonschema:"Results per page for pagination (min 1, max 100)"`
+	DetailLevel       string   `json:"detail_level,omitempty" jsonschema:"Response detail level: 'summary' (default), 'detailed', or 'full'"`
+	AutoPaginate      bool     `json:"auto_paginate,omitempty" jsonschema:"Also fetch the following pages, up to 10 in total, instead of only the requested page. truncated is set when more pages remain"`
+	IncludePagination bool     `json:"include_pagination,omitempty" jsonschema:"Add page, has_more and next_page to the result, so you can tell whether more pages exist"`
+	Fields            []string `json:"fields,omitempty" jsonschema:"Only return these keys of each item to save tokens, with dots for nested keys, e.g. number, state, creator.name"`
+	OutputFormat      string   `json:"output_format,omitempty" jsonschema:"Result format: 'json' (default), 'yaml', 'markdown' (a table), or 'ndjson' (one JSON object per item, a line each, then a _meta object of the other keys such as pagination)"`
+	TimeoutSeconds    int      `json:"timeout_seconds,omitempty" jsonschema:"Give up after this many seconds (default 300, max 1800)"`
 }
 
 type CreatePipelineResult struct {
@@ -49,6 +59,9 @@ func ListPipelines() (mcp.Tool, mcp.ToolHandlerFor[ListPipelinesArgs, any], []st
 			ctx, span := trace.Start(ctx, "buildkite.ListPipelines")
 			defer span.End()
 
+			ctx, cancel := withCallTimeout(ctx, args.TimeoutSeconds)
+			defer cancel()
+
 			// Set defaults
 			if args.DetailLevel == "" {
 				args.DetailLevel = "summary"
@@ -67,38 +80,56 @@ func ListPipelines() (mcp.Tool, mcp.ToolHandlerFor[ListPipelinesArgs, any], []st
 				attribute.String("detail_level", args.DetailLevel),
 				attribute.Int("page", args.Page),
 				attribute.Int("per_page", args.PerPage),
+				attribute.Bool("auto_paginate", args.AutoPaginate),
+				attribute.String("name_substring_filter", args.NameFilter),
+				attribute.String("tag", args.Tag),
 			)
 
+			// The API can't filter by slug or tag, so those filters are
+			// applied to every page up to maxAutoPaginatePages.
+			filtered := args.NameFilter != "" || args.Tag != ""
+
 			deps := DepsFromContext(ctx)
-			pipelines, resp, err := deps.PipelinesClient.List(ctx, args.OrgSlug, &buildkite.PipelineListOptions{
-				ListOptions: buildkite.ListOptions{
-					Page:    args.Page,
-					PerPage: args.PerPage,
-				},
-				Name:       args.Name,
-				Repository: args.Repository,
+			pipelines, resp, truncated, err := listPages(args.Page, args.AutoPaginate || filtered, func(page int) ([]buildkite.Pipeline, *buildkite.Response, error) {
+				return deps.PipelinesClient.List(ctx, args.OrgSlug, &buildkite.PipelineListOptions{
+					ListOptions: buildkite.ListOptions{
+						Page:    page,
+						PerPage: args.PerPage,
+					},
+					Name:       args.Name,
+					Repository: args.Repository,
+				})
 			})
 			if err != nil {
-				return handleBuildkiteError(err)
+				return handleBuildkiteError(ctx, err)
 			}
 
 			headers := map[string]string{"Link": resp.Header.Get("Link")}
+			pagination := paginationFor(args.IncludePagination, args.Page, resp)
+
+			var counts *FilterCounts
+			if filtered {
+				total := len(pipelines)
+				pipelines = filterPipelines(pipelines, args.NameFilter, args.Tag)
+				counts = &FilterCounts{Matched: len(pipelines), Total: total}
+			}
 
 			var result any
 			switch args.DetailLevel {
 			case "summary":
-				result = createPaginatedResult(pipelines, summarizePipeline, headers)
+				result = createPaginatedResult(pipelines, summarizePipeline, headers, truncated, pagination, counts)
 			case "detailed":
-				result = createPaginatedResult(pipelines, detailPipeline, headers)
+				result = createPaginatedResult(pipelines, detailPipeline, headers, truncated, pagination, counts)
 			default: // "full"
-				result = createPaginatedResult(pipelines, func(p buildkite.Pipeline) buildkite.Pipeline { return p }, headers)
+				result = createPaginatedResult(pipelines, func(p buildkite.Pipeline) buildkite.Pipeline { return p }, headers, truncated, pagination, counts)
 			}
 
 			span.SetAttributes(
 				attribute.Int("item_count", len(pipelines)),
+				attribute.Bool("truncated", truncated),
 			)
 
-			return mcpTextResult(span, &result)
+			return mcpListResult(ctx, span, result, args.Fields, args.OutputFormat, pipelineColumns)
 		}, []string{"read_pipelines"}
 }
 
@@ -106,6 +137,7 @@ type GetPipelineArgs struct {
 	OrgSlug      string `json:"org_slug"`
 	PipelineSlug string `json:"pipeline_slug"`
 	DetailLevel  string `json:"detail_level,omitempty" jsonschema:"Response detail level: 'summary', 'detailed', or 'full' (default)"`
+	OutputFormat string `json:"output_format,omitempty" jsonschema:"Result format: 'json' (default), 'yaml', 'markdown' (a table), or 'ndjson' (one JSON object per item, a line each)"`
 }
 
 func GetPipeline() (mcp.Tool, mcp.ToolHandlerFor[GetPipelineArgs, any], []string) {
@@ -135,7 +167,7 @@ func GetPipeline() (mcp.Tool, mcp.ToolHandlerFor[GetPipelineArgs, any], []string
 			deps := DepsFromContext(ctx)
 			pipeline, _, err := deps.PipelinesClient.Get(ctx, args.OrgSlug, args.PipelineSlug)
 			if err != nil {
-				return handleBuildkiteError(err)
+				return handleBuildkiteError(ctx, err)
 			}
 
 			var result any
@@ -148,7 +180,7 @@ func GetPipeline() (mcp.Tool, mcp.ToolHandlerFor[GetPipelineArgs, any], []string
 				result = pipeline
 			}
 
-			return mcpTextResult(span, &result)
+			return mcpStructuredResult(ctx, span, result, args.OutputFormat, pipelineColumns)
 		}, []string{"read_pipelines"}
 }
 
@@ -226,17 +258,39 @@ func detailPipeline(p buildkite.Pipeline) PipelineDetail {
 }
 
 // createPaginatedResult is a generic helper to convert pipelines and wrap in paginated result
-func createPaginatedResult[T any](pipelines []buildkite.Pipeline, converter func(buildkite.Pipeline) T, headers map[string]string) PaginatedResult[T] {
+func createPaginatedResult[T any](pipelines []buildkite.Pipeline, converter func(buildkite.Pipeline) T, headers map[string]string, truncated bool, pagination *Pagination, counts *FilterCounts) PaginatedResult[T] {
 	items := make([]T, len(pipelines))
 	for i, p := range pipelines {
 		items[i] = converter(p)
 	}
 	return PaginatedResult[T]{
-		Items:   items,
-		Headers: headers,
+		Items:        items,
+		Headers:      headers,
+		Truncated:    truncated,
+		Pagination:   pagination,
+		FilterCounts: counts,
 	}
 }
 
+// filterPipelines returns the pipelines whose name or slug contains
+// nameFilter, ignoring case, and that have tag. Empty filters match every
+// pipeline.
+func filterPipelines(pipelines []buildkite.Pipeline, nameFilter, tag string) []buildkite.Pipeline {
+	nameFilter = strings.ToLower(nameFilter)
+
+	matched := make([]buildkite.Pipeline, 0, len(pipelines))
+	for _, p := range pipelines {
+		if nameFilter != "" && !strings.Contains(strings.ToLower(p.Name), nameFilter) && !strings.Contains(strings.ToLower(p.Slug), nameFilter) {
+			continue
+		}
+		if tag != "" && !slices.Contains(p.Tags, tag) {
+			continue
+		}
+		matched = append(matched, p)
+	}
+	return matched
+}
+
 type CreatePipelineArgs struct {
 	OrgSlug                   string   `json:"org_slug"`
 	Name                      string   `json:"name"`
@@ -249,6 +303,7 @@ type CreatePipelineArgs struct {
 	CancelRunningBranchBuilds bool     `json:"cancel_running_branch_builds,omitempty" jsonschema:"Cancel running builds when new builds are created on the same branch"`
 	Tags                      []string `json:"tags,omitempty" jsonschema:"Tags to apply to the pipeline for filtering and organization"`
 	CreateWebhook             bool     `json:"create_webhook,omitempty" jsonschema:"Create a GitHub webhook to trigger builds on pull-request and push events"`
+	DryRun                    bool     `json:"dry_run,omitempty" jsonschema:"Validate the arguments and return the request this would make, without making it"`
 }
 
 func CreatePipeline() (mcp.Tool, mcp.ToolHandlerFor[CreatePipelineArgs, any], []string) {
@@ -285,10 +340,18 @@ func CreatePipeline() (mcp.Tool, mcp.ToolHandlerFor[CreatePipelineArgs, any], []
 				create.DefaultBranch = args.DefaultBranch
 			}
 
+			if args.DryRun {
+				action := "Create a pipeline"
+				if args.CreateWebhook {
+					action = "Create a pipeline, then add a webhook to its repository"
+				}
+				return mcpDryRunResult(ctx, span, action, http.MethodPost, apiPath("v2/organizations/%s/pipelines", args.OrgSlug), create)
+			}
+
 			deps := DepsFromContext(ctx)
 			pipeline, _, err := deps.PipelinesClient.Create(ctx, args.OrgSlug, create)
 			if err != nil {
-				return handleBuildkiteError(err)
+				return handleBuildkiteError(ctx, err)
 			}
 
 			if args.CreateWebhook {
@@ -306,13 +369,13 @@ func CreatePipeline() (mcp.Tool, mcp.ToolHandlerFor[CreatePipelineArgs, any], []
 					result.Webhook.Note = "Pipeline created successfully, but webhook creation failed."
 				}
 
-				return mcpTextResult(span, &result)
+				return mcpTextResult(ctx, span, &result)
 			}
 
 			result := CreatePipelineResult{
 				Pipeline: pipeline,
 			}
-			return mcpTextResult(span, &result)
+			return mcpTextResult(ctx, span, &result)
 		}, []string{"write_pipelines"}
 }
 
@@ -328,6 +391,7 @@ type UpdatePipelineArgs struct {
 	SkipQueuedBranchBuilds    *bool    `json:"skip_queued_branch_builds,omitempty" jsonschema:"Skip intermediate builds when new builds are created on the same branch"`
 	CancelRunningBranchBuilds *bool    `json:"cancel_running_branch_builds,omitempty" jsonschema:"Cancel running builds when new builds are created on the same branch"`
 	Tags                      []string `json:"tags,omitempty" jsonschema:"Tags to apply to the pipeline for filtering and organization"`
+	DryRun                    bool     `json:"dry_run,omitempty" jsonschema:"Validate the arguments and return the request this would make, without making it"`
 }
 
 func UpdatePipeline() (mcp.Tool, mcp.ToolHandlerFor[UpdatePipelineArgs, any], []string) {
@@ -377,12 +441,16 @@ func UpdatePipeline() (mcp.Tool, mcp.ToolHandlerFor[UpdatePipelineArgs, any], []
 				update.Tags = buildkite.Some(args.Tags)
 			}
 
+			if args.DryRun {
+				return mcpDryRunResult(ctx, span, "Update a pipeline", http.MethodPatch, apiPath("v2/organizations/%s/pipelines/%s", args.OrgSlug, args.PipelineSlug), update)
+			}
+
 			deps := DepsFromContext(ctx)
 			pipeline, _, err := deps.PipelinesClient.Update(ctx, args.OrgSlug, args.PipelineSlug, update)
 			if err != nil {
-				return handleBuildkiteError(err)
+				return handleBuildkiteError(ctx, err)
 			}
 
-			return mcpTextResult(span, &pipeline)
+			return mcpTextResult(ctx, span, &pipeline)
 		}, []string{"write_pipelines"}
 }
@@ -2,6 +2,7 @@ package buildkite
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"net/http"
 	"testing"
@@ -132,6 +133,10 @@ func TestGetPipeline(t *testing.T) {
 	textContent := getTextResult(t, result)
 
 	assert.JSONEq(`{"id":"123","name":"Test Pipeline","slug":"test-pipeline","created_at":"0001-01-01T00:00:00Z","skip_queued_branch_builds":false,"cancel_running_branch_builds":false,"provider":{"id":"","webhook_url":"","settings":null}}`, textContent.Text)
+
+	structured, ok := result.StructuredContent.(json.RawMessage)
+	assert.True(ok)
+	assert.JSONEq(textContent.Text, string(structured))
 }
 
 func TestCreatePipeline(t *testing.T) {
@@ -518,3 +523,71 @@ func TestUpdatePipelineSendsEmptyTags(t *testing.T) {
 	textContent := getTextResult(t, result)
 	assert.JSONEq(`{"id":"123","slug":"test-pipeline","skip_queued_branch_builds":false,"cancel_running_branch_builds":false,"provider":{"id":"","webhook_url":"","settings":null}}`, textContent.Text)
 }
+
+func TestListPipelinesFilters(t *testing.T) {
+	var pages []int
+	client := &MockPipelinesClient{
+		ListFunc: func(ctx context.Context, org string, opt *buildkite.PipelineListOptions) ([]buildkite.Pipeline, *buildkite.Response, error) {
+			pages = append(pages, opt.Page)
+			resp := &buildkite.Response{Response: &http.Response{StatusCode: 200}}
+			if opt.Page == 1 {
+				resp.NextPage = 2
+				return []buildkite.Pipeline{
+					{Slug: "payments-api", Name: "Payments API", Tags: []string{"backend"}},
+					{Slug: "web", Name: "Web", Tags: []string{"frontend"}},
+				}, resp, nil
+			}
+			return []buildkite.Pipeline{
+				{Slug: "billing", Name: "PAYMENTS billing"},
+				{Slug: "docs", Name: "Docs", Tags: []string{"backend"}},
+			}, resp, nil
+		},
+	}
+	ctx := ContextWithDeps(context.Background(), ToolDependencies{PipelinesClient: client})
+	_, handler, _ := ListPipelines()
+
+	list := func(t *testing.T, args ListPipelinesArgs) PaginatedResult[PipelineSummary] {
+		t.Helper()
+		pages = nil
+		args.OrgSlug = "org"
+		result, _, err := handler(ctx, createMCPRequest(t, map[string]any{}), args)
+		require.NoError(t, err)
+
+		var got PaginatedResult[PipelineSummary]
+		require.NoError(t, json.Unmarshal([]byte(getTextResult(t, result).Text), &got))
+		return got
+	}
+
+	t.Run("NameFilterMatchesNameOrSlug", func(t *testing.T) {
+		assert := require.New(t)
+
+		got := list(t, ListPipelinesArgs{NameFilter: "payments"})
+		assert.Equal([]int{1, 2}, pages)
+		assert.Len(got.Items, 2)
+		assert.Equal("payments-api", got.Items[0].Slug)
+		assert.Equal("billing", got.Items[1].Slug)
+		assert.Equal(&FilterCounts{Matched: 2, Total: 4}, got.FilterCounts)
+
+		got = list(t, ListPipelinesArgs{NameFilter: "DOC"})
+		assert.Len(got.Items, 1)
+		assert.Equal("docs", got.Items[0].Slug)
+	})
+
+	t.Run("Tag", func(t *testing.T) {
+		assert := require.New(t)
+
+		got := list(t, ListPipelinesArgs{Tag: "backend", NameFilter: "api"})
+		assert.Len(got.Items, 1)
+		assert.Equal("payments-api", got.Items[0].Slug)
+		assert.Equal(&FilterCounts{Matched: 1, Total: 4}, got.FilterCounts)
+	})
+
+	t.Run("NoFilter", func(t *testing.T) {
+		assert := require.New(t)
+
+		got := list(t, ListPipelinesArgs{})
+		assert.Equal([]int{1}, pages)
+		assert.Len(got.Items, 2)
+		assert.Nil(got.FilterCounts)
+	})
+}
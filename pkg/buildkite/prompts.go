@@ -0,0 +1,176 @@
+package buildkite
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const diagnoseFailedBuildPromptName = "diagnose_failed_build"
+
+const diagnoseFailedBuildPromptDescription = "Work out why a Buildkite build failed, using the failure summary first and only reading full logs when it isn't enough"
+
+const diagnoseFailedBuildPromptTemplate = `Diagnose why build %s of the Buildkite pipeline %s in the organization %s failed.
+
+Work through these steps, stopping as soon as the cause is clear:
+1. Call get_build_failure_summary for the build. It returns the failed jobs with a log tail each, error annotations, and failed Test Engine tests in one call.
+2. If a log tail doesn't show the error, call search_logs on that job for terms like "error", "failed" or "exit status", then read_logs around the match. Avoid get_job_logs for large logs.
+3. If tests failed and you need stack traces, call get_failed_executions with include_failure_expanded for the Test Engine run.
+4. To tell a new breakage from an existing one, find the last passed build on the same branch with list_builds (state "passed", per_page 1) and call compare_builds with it as the base.
+
+Then report:
+- The failing job or test, quoting only the few log lines that show the error.
+- The likely root cause, and whether it looks like a code change, a flaky test, or an infrastructure problem.
+- A suggested fix or next step.
+
+Do not retry jobs, rebuild, or cancel builds unless the user asks.`
+
+const summarizeRecentFailuresPromptName = "summarize_recent_failures"
+
+const summarizeRecentFailuresPromptDescription = "Summarize the Buildkite builds that failed recently, such as overnight, grouped by cause"
+
+const summarizeRecentFailuresPromptTemplate = `Summarize the Buildkite builds that failed in the last %s hours in %s, within the organization %s.
+
+Work through these steps:
+1. Call list_builds with state "failed" (and the pipeline, if one is given). Builds are listed newest first, so page through them until you reach builds created before the window, then stop.
+2. Call get_build_failure_summary for each failed build, with a small log_tail to save tokens. If many builds failed, start with the most recent build of each pipeline and branch.
+3. Group the failures by cause: the same failing job, test, or error message across builds is one problem.
+4. For a single pipeline, call get_pipeline_metrics with days set to 1 to compare against its usual pass rate.
+
+Then report, most widespread problem first:
+- Each problem, with the pipelines, branches, and build numbers it affected and a short excerpt of the error.
+- Whether it still fails on the latest build of that branch (use get_latest_build).
+- Failures that look flaky or infrastructure related, separately from real breakages.
+
+Keep the summary short enough to read at the start of the day.`
+
+const findFlakyTestPromptName = "find_flaky_test"
+
+const findFlakyTestPromptDescription = "Find tests in a Buildkite Test Engine suite that fail intermittently, using recent runs"
+
+const findFlakyTestPromptTemplate = `Find flaky tests in the Buildkite Test Engine suite %s in the organization %s.%s
+
+Work through these steps:
+1. Call list_test_runs for the suite to get the recent runs.
+2. Call get_failed_executions for each of the recent runs, up to about 20, to collect the failed tests.
+3. A test is likely flaky when it fails in some runs but passes in others on the same commit or branch, or fails with timeouts or ordering-dependent errors rather than assertion failures. Use get_test_run to compare the commit and branch of runs.
+4. For each candidate, call get_test for its details, and get_failed_executions with include_failure_expanded to compare its failure messages across runs.
+
+Then report each flaky test with:
+- Its name and location.
+- How many of the inspected runs it failed, and which commits or branches.
+- The failure messages, and what they suggest about the cause (timing, shared state, external services, test order).
+
+Say how many runs you inspected, and don't call a test flaky on the strength of a single failure.`
+
+// NewDiagnoseFailedBuildPrompt returns the diagnose_failed_build prompt and
+// its handler, which guide the model through the tools for finding out why a
+// build failed.
+func NewDiagnoseFailedBuildPrompt() (*mcp.Prompt, mcp.PromptHandler) {
+	prompt := &mcp.Prompt{
+		Name:        diagnoseFailedBuildPromptName,
+		Description: diagnoseFailedBuildPromptDescription,
+		Arguments: []*mcp.PromptArgument{
+			{Name: "org_slug", Description: "The organization slug", Required: true},
+			{Name: "pipeline_slug", Description: "The pipeline slug", Required: true},
+			{Name: "build_number", Description: "The number of the failed build", Required: true},
+		},
+	}
+
+	handler := func(ctx context.Context, request *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		text := fmt.Sprintf(diagnoseFailedBuildPromptTemplate,
+			promptArgument(request, "build_number", "(ask the user which build)"),
+			promptArgument(request, "pipeline_slug", "(ask the user which pipeline)"),
+			promptArgument(request, "org_slug", "(look it up with user_token_organization)"),
+		)
+		return promptResult(diagnoseFailedBuildPromptDescription, text), nil
+	}
+
+	return prompt, handler
+}
+
+// NewSummarizeRecentFailuresPrompt returns the summarize_recent_failures
+// prompt and its handler. The window defaults to the last 12 hours, which
+// covers the builds of the night before.
+func NewSummarizeRecentFailuresPrompt() (*mcp.Prompt, mcp.PromptHandler) {
+	prompt := &mcp.Prompt{
+		Name:        summarizeRecentFailuresPromptName,
+		Description: summarizeRecentFailuresPromptDescription,
+		Arguments: []*mcp.PromptArgument{
+			{Name: "org_slug", Description: "The organization slug", Required: true},
+			{Name: "pipeline_slug", Description: "Only summarize this pipeline. When omitted, covers every pipeline in the organization"},
+			{Name: "hours", Description: "How many hours back to look (default 12)"},
+		},
+	}
+
+	handler := func(ctx context.Context, request *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		scope := "every pipeline"
+		if pipeline := promptArgument(request, "pipeline_slug", ""); pipeline != "" {
+			scope = "the pipeline " + pipeline
+		}
+
+		text := fmt.Sprintf(summarizeRecentFailuresPromptTemplate,
+			promptArgument(request, "hours", "12"),
+			scope,
+			promptArgument(request, "org_slug", "(look it up with user_token_organization)"),
+		)
+		return promptResult(summarizeRecentFailuresPromptDescription, text), nil
+	}
+
+	return prompt, handler
+}
+
+// NewFindFlakyTestPrompt returns the find_flaky_test prompt and its handler,
+// which guide the model through comparing recent Test Engine runs.
+func NewFindFlakyTestPrompt() (*mcp.Prompt, mcp.PromptHandler) {
+	prompt := &mcp.Prompt{
+		Name:        findFlakyTestPromptName,
+		Description: findFlakyTestPromptDescription,
+		Arguments: []*mcp.PromptArgument{
+			{Name: "org_slug", Description: "The organization slug", Required: true},
+			{Name: "test_suite_slug", Description: "The Test Engine suite slug", Required: true},
+			{Name: "test", Description: "Only look at tests whose name contains this"},
+		},
+	}
+
+	handler := func(ctx context.Context, request *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		focus := ""
+		if test := promptArgument(request, "test", ""); test != "" {
+			focus = fmt.Sprintf(" Only consider tests whose name contains %q.", test)
+		}
+
+		text := fmt.Sprintf(findFlakyTestPromptTemplate,
+			promptArgument(request, "test_suite_slug", "(ask the user which suite)"),
+			promptArgument(request, "org_slug", "(look it up with user_token_organization)"),
+			focus,
+		)
+		return promptResult(findFlakyTestPromptDescription, text), nil
+	}
+
+	return prompt, handler
+}
+
+// promptArgument returns the named argument of a prompt request, or fallback
+// when the client left it out.
+func promptArgument(request *mcp.GetPromptRequest, name, fallback string) string {
+	if request == nil || request.Params == nil {
+		return fallback
+	}
+	if value := request.Params.Arguments[name]; value != "" {
+		return value
+	}
+	return fallback
+}
+
+func promptResult(description, text string) *mcp.GetPromptResult {
+	return &mcp.GetPromptResult{
+		Description: description,
+		Messages: []*mcp.PromptMessage{
+			{
+				Role:    mcp.Role("user"),
+				Content: &mcp.TextContent{Text: text},
+			},
+		},
+	}
+}
@@ -0,0 +1,82 @@
+package buildkite
+
+import (
+	"context"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+func getPromptText(t *testing.T, handler mcp.PromptHandler, name string, arguments map[string]string) string {
+	t.Helper()
+
+	result, err := handler(context.Background(), &mcp.GetPromptRequest{
+		Params: &mcp.GetPromptParams{Name: name, Arguments: arguments},
+	})
+	require.NoError(t, err)
+	require.Len(t, result.Messages, 1)
+
+	text, ok := result.Messages[0].Content.(*mcp.TextContent)
+	require.True(t, ok)
+	return text.Text
+}
+
+func TestNewDiagnoseFailedBuildPrompt(t *testing.T) {
+	require := require.New(t)
+
+	prompt, handler := NewDiagnoseFailedBuildPrompt()
+	require.Equal("diagnose_failed_build", prompt.Name)
+	require.Len(prompt.Arguments, 3)
+
+	text := getPromptText(t, handler, prompt.Name, map[string]string{
+		"org_slug":      "org",
+		"pipeline_slug": "pipeline",
+		"build_number":  "42",
+	})
+	require.Contains(text, "build 42 of the Buildkite pipeline pipeline in the organization org")
+	require.Contains(text, "get_build_failure_summary")
+	require.Contains(text, "compare_builds")
+}
+
+func TestNewSummarizeRecentFailuresPrompt(t *testing.T) {
+	t.Run("defaults to the last 12 hours of every pipeline", func(t *testing.T) {
+		_, handler := NewSummarizeRecentFailuresPrompt()
+
+		text := getPromptText(t, handler, "summarize_recent_failures", map[string]string{"org_slug": "org"})
+		require.Contains(t, text, "in the last 12 hours in every pipeline, within the organization org")
+		require.Contains(t, text, `list_builds with state "failed"`)
+	})
+
+	t.Run("narrows to a pipeline", func(t *testing.T) {
+		_, handler := NewSummarizeRecentFailuresPrompt()
+
+		text := getPromptText(t, handler, "summarize_recent_failures", map[string]string{
+			"org_slug":      "org",
+			"pipeline_slug": "pipeline",
+			"hours":         "24",
+		})
+		require.Contains(t, text, "in the last 24 hours in the pipeline pipeline")
+	})
+}
+
+func TestNewFindFlakyTestPrompt(t *testing.T) {
+	require := require.New(t)
+
+	prompt, handler := NewFindFlakyTestPrompt()
+	require.Equal("find_flaky_test", prompt.Name)
+
+	text := getPromptText(t, handler, prompt.Name, map[string]string{
+		"org_slug":        "org",
+		"test_suite_slug": "suite",
+		"test":            "checkout",
+	})
+	require.Contains(text, "suite suite in the organization org")
+	require.Contains(text, `Only consider tests whose name contains "checkout".`)
+	require.Contains(text, "get_failed_executions")
+
+	// Missing arguments point the model at how to fill them in.
+	text = getPromptText(t, handler, prompt.Name, nil)
+	require.Contains(text, "(ask the user which suite)")
+	require.NotContains(text, "Only consider tests")
+}
@@ -0,0 +1,104 @@
+package buildkite
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// RateLimitMetaKey is the tool result metadata key holding the rate limit
+// from the last Buildkite API response of a tool call.
+const RateLimitMetaKey = "_rate_limit"
+
+// RateLimit is the state of the Buildkite API rate limit, from the
+// RateLimit-* headers of a response.
+type RateLimit struct {
+	Limit        int `json:"limit,omitempty"`
+	Remaining    int `json:"remaining"`
+	ResetSeconds int `json:"reset_seconds"`
+}
+
+// ParseRateLimit reads the rate limit headers of a Buildkite API response. It
+// returns false when the response doesn't include them.
+func ParseRateLimit(header http.Header) (RateLimit, bool) {
+	remaining, err := strconv.Atoi(header.Get("RateLimit-Remaining"))
+	if err != nil {
+		return RateLimit{}, false
+	}
+	limit, _ := strconv.Atoi(header.Get("RateLimit-Limit"))
+	reset, _ := strconv.Atoi(header.Get("RateLimit-Reset"))
+	return RateLimit{Limit: limit, Remaining: remaining, ResetSeconds: reset}, true
+}
+
+// rateLimitRecorder holds the rate limit of the last API response seen while
+// handling a tool call.
+type rateLimitRecorder struct {
+	mu        sync.Mutex
+	rateLimit *RateLimit
+}
+
+func (r *rateLimitRecorder) record(rateLimit RateLimit) {
+	r.mu.Lock()
+	r.rateLimit = &rateLimit
+	r.mu.Unlock()
+}
+
+func (r *rateLimitRecorder) last() *RateLimit {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rateLimit
+}
+
+type rateLimitContextKey struct{}
+
+// NewRateLimitTransport records the rate limit headers of API responses for
+// RateLimitMiddleware. Requests made outside a tool call pass through as is.
+func NewRateLimitTransport(next http.RoundTripper) http.RoundTripper {
+	return rateLimitTransport{next: next}
+}
+
+type rateLimitTransport struct {
+	next http.RoundTripper
+}
+
+func (t rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	res, err := t.next.RoundTrip(req)
+	if err != nil {
+		return res, err
+	}
+	if recorder, ok := req.Context().Value(rateLimitContextKey{}).(*rateLimitRecorder); ok {
+		if rateLimit, ok := ParseRateLimit(res.Header); ok {
+			recorder.record(rateLimit)
+		}
+	}
+	return res, nil
+}
+
+// RateLimitMiddleware returns an mcp.Middleware that adds the rate limit from
+// the last API response of each tool call to the result's metadata, under
+// RateLimitMetaKey. The API client must use NewRateLimitTransport.
+func RateLimitMiddleware() mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			if method != "tools/call" {
+				return next(ctx, method, req)
+			}
+
+			recorder := &rateLimitRecorder{}
+			res, err := next(context.WithValue(ctx, rateLimitContextKey{}, recorder), method, req)
+
+			result, ok := res.(*mcp.CallToolResult)
+			rateLimit := recorder.last()
+			if ok && result != nil && rateLimit != nil {
+				if result.Meta == nil {
+					result.Meta = mcp.Meta{}
+				}
+				result.Meta[RateLimitMetaKey] = rateLimit
+			}
+			return res, err
+		}
+	}
+}
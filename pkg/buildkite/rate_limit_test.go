@@ -0,0 +1,77 @@
+package buildkite
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRateLimit(t *testing.T) {
+	assert := require.New(t)
+
+	rateLimit, ok := ParseRateLimit(http.Header{
+		"Ratelimit-Limit":     {"200"},
+		"Ratelimit-Remaining": {"12"},
+		"Ratelimit-Reset":     {"31"},
+	})
+	assert.True(ok)
+	assert.Equal(RateLimit{Limit: 200, Remaining: 12, ResetSeconds: 31}, rateLimit)
+
+	_, ok = ParseRateLimit(http.Header{"Ratelimit-Reset": {"31"}})
+	assert.False(ok)
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+
+	remaining := []string{"9", "8"}
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("RateLimit-Remaining", remaining[0])
+		w.Header().Set("RateLimit-Reset", "42")
+		remaining = remaining[1:]
+	}))
+	defer api.Close()
+
+	httpClient := &http.Client{Transport: NewRateLimitTransport(http.DefaultTransport)}
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "1.0.0"}, nil)
+	server.AddReceivingMiddleware(RateLimitMiddleware())
+	mcp.AddTool(server, &mcp.Tool{Name: "two_requests"}, func(ctx context.Context, _ *mcp.CallToolRequest, _ any) (*mcp.CallToolResult, any, error) {
+		for range 2 {
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, api.URL, nil)
+			if err != nil {
+				return nil, nil, err
+			}
+			res, err := httpClient.Do(req)
+			if err != nil {
+				return nil, nil, err
+			}
+			_ = res.Body.Close()
+		}
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "ok"}}}, nil, nil
+	})
+	mcp.AddTool(server, &mcp.Tool{Name: "no_requests"}, func(context.Context, *mcp.CallToolRequest, any) (*mcp.CallToolResult, any, error) {
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "ok"}}}, nil, nil
+	})
+
+	t1, t2 := mcp.NewInMemoryTransports()
+	_, err := server.Connect(ctx, t1, nil)
+	assert.NoError(err)
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil)
+	session, err := client.Connect(ctx, t2, nil)
+	assert.NoError(err)
+	defer session.Close()
+
+	result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "two_requests"})
+	assert.NoError(err)
+	assert.Equal(map[string]any{"remaining": float64(8), "reset_seconds": float64(42)}, result.Meta[RateLimitMetaKey])
+
+	result, err = session.CallTool(ctx, &mcp.CallToolParams{Name: "no_requests"})
+	assert.NoError(err)
+	assert.NotContains(result.Meta, RateLimitMetaKey)
+}
@@ -0,0 +1,130 @@
+package buildkite
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// RedactedText replaces the secrets a Redactor finds.
+const RedactedText = "[REDACTED]"
+
+// DefaultRedactPatterns match common secrets in build environments and logs.
+// A pattern with a capture group only masks the group, so the name of a
+// variable or header stays readable.
+var DefaultRedactPatterns = []string{
+	// AWS access key IDs, long-lived and temporary.
+	`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`,
+	// AWS secret access keys assigned to a variable.
+	`(?i)aws_secret_access_key\\?["']?\s*[:=]\s*\\?["']?([A-Za-z0-9/+=]{40})`,
+	// Bearer tokens, such as in an Authorization header.
+	`(?i)\bbearer\s+([A-Za-z0-9\-._~+/]+=*)`,
+	// Buildkite API access tokens.
+	`\bbkua_[A-Za-z0-9]+`,
+	// Secrets in BUILDKITE_* variables, such as BUILDKITE_AGENT_TOKEN.
+	`\bBUILDKITE_[A-Z0-9_]*(?:TOKEN|SECRET|KEY|PASSWORD)[A-Z0-9_]*\\?["']?\s*[:=]\s*\\?["']?([^\s"'\\,]+)`,
+}
+
+// Redactor masks text matching a set of secret patterns. It is heuristic:
+// secrets that don't match a pattern are returned as they are.
+type Redactor struct {
+	patterns []*regexp.Regexp
+}
+
+// NewRedactor returns a Redactor for the given regular expressions, such as
+// DefaultRedactPatterns.
+func NewRedactor(patterns []string) (*Redactor, error) {
+	r := &Redactor{}
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern %q: %w", pattern, err)
+		}
+		r.patterns = append(r.patterns, re)
+	}
+	return r, nil
+}
+
+// Redact returns text with every match of the patterns replaced by
+// RedactedText, or only the first capture group of patterns that have one.
+func (r *Redactor) Redact(text string) string {
+	for _, re := range r.patterns {
+		if re.NumSubexp() == 0 {
+			text = re.ReplaceAllLiteralString(text, RedactedText)
+			continue
+		}
+		text = redactGroup(re, text)
+	}
+	return text
+}
+
+// redactGroup replaces the first capture group of each match of re.
+func redactGroup(re *regexp.Regexp, text string) string {
+	matches := re.FindAllStringSubmatchIndex(text, -1)
+	if len(matches) == 0 {
+		return text
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, loc := range matches {
+		start, end := loc[2], loc[3]
+		if start < 0 {
+			continue
+		}
+		b.WriteString(text[last:start])
+		b.WriteString(RedactedText)
+		last = end
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// Middleware returns an mcp.Middleware that redacts the text and structured
+// content of every tool result, including errors, and the text of resources
+// read, such as pipelines and builds, so each tool and resource is covered
+// without redacting its own output.
+func (r *Redactor) Middleware() mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			res, err := next(ctx, method, req)
+
+			switch result := res.(type) {
+			case *mcp.CallToolResult:
+				if method == "tools/call" && result != nil {
+					r.redactToolResult(result)
+				}
+			case *mcp.ReadResourceResult:
+				if method == "resources/read" && result != nil {
+					for _, contents := range result.Contents {
+						if contents != nil {
+							contents.Text = r.Redact(contents.Text)
+						}
+					}
+				}
+			}
+			return res, err
+		}
+	}
+}
+
+func (r *Redactor) redactToolResult(result *mcp.CallToolResult) {
+	for _, content := range result.Content {
+		if text, ok := content.(*mcp.TextContent); ok {
+			text.Text = r.Redact(text.Text)
+		}
+	}
+	// Structured content repeats the text as an object, so it is redacted
+	// as the JSON it is sent as.
+	if result.StructuredContent != nil {
+		if out, err := json.Marshal(result.StructuredContent); err == nil {
+			if redacted := r.Redact(string(out)); redacted != string(out) {
+				result.StructuredContent = json.RawMessage(redacted)
+			}
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package buildkite
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rs/zerolog/log"
+)
+
+// ResponseCache holds the results of read-only tool calls in memory for a
+// fixed time, so repeated calls with the same arguments don't hit the API
+// again. Entries only expire; nothing invalidates them early, so a cached
+// result can be up to the TTL out of date. The cache is per process and shared
+// by every session of the server, so it must not be used when callers bring
+// their own credentials.
+type ResponseCache struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]cachedResult
+}
+
+type cachedResult struct {
+	result  []byte // the marshalled *mcp.CallToolResult
+	expires time.Time
+}
+
+// NewResponseCache returns a cache that keeps tool results for ttl.
+func NewResponseCache(ttl time.Duration) *ResponseCache {
+	return &ResponseCache{
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[string]cachedResult),
+	}
+}
+
+func (c *ResponseCache) get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if !c.now().Before(entry.expires) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	return entry.result, true
+}
+
+func (c *ResponseCache) set(key string, result []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	for k, entry := range c.entries {
+		if !now.Before(entry.expires) {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = cachedResult{result: result, expires: now.Add(c.ttl)}
+}
+
+// Middleware returns an mcp.Middleware that serves calls of the tools for
+// which cacheable returns true from the cache, and caches their successful
+// results. Calls are keyed by tool name and arguments, with argument order
+// ignored.
+func (c *ResponseCache) Middleware(cacheable func(toolName string) bool) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			params, ok := req.GetParams().(*mcp.CallToolParamsRaw)
+			if method != "tools/call" || !ok || params == nil || !cacheable(params.Name) {
+				return next(ctx, method, req)
+			}
+
+			key, err := responseCacheKey(params)
+			if err != nil {
+				return next(ctx, method, req)
+			}
+
+			if cached, ok := c.get(key); ok {
+				result := &mcp.CallToolResult{}
+				if err := json.Unmarshal(cached, result); err == nil {
+					log.Ctx(ctx).Debug().Str("tool", params.Name).Msg("Serving tool result from cache")
+					return result, nil
+				}
+			}
+
+			res, err := next(ctx, method, req)
+			if result, ok := res.(*mcp.CallToolResult); err == nil && ok && result != nil && !result.IsError {
+				if out, err := json.Marshal(result); err == nil {
+					c.set(key, out)
+				}
+			}
+			return res, err
+		}
+	}
+}
+
+// responseCacheKey identifies a tool call by its name and arguments.
+// Re-marshalling the arguments sorts object keys, so the same arguments in a
+// different order share an entry.
+func responseCacheKey(params *mcp.CallToolParamsRaw) (string, error) {
+	var args any
+	if len(params.Arguments) > 0 {
+		if err := json.Unmarshal(params.Arguments, &args); err != nil {
+			return "", err
+		}
+	}
+	if args == nil {
+		args = map[string]any{}
+	}
+	normalized, err := json.Marshal(args)
+	if err != nil {
+		return "", err
+	}
+	return params.Name + "\x00" + string(normalized), nil
+}
@@ -0,0 +1,67 @@
+package buildkite
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponseCache(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+
+	now := time.Now()
+	cache := NewResponseCache(time.Minute)
+	cache.now = func() time.Time { return now }
+
+	calls := map[string]int{}
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "1.0.0"}, nil)
+	server.AddReceivingMiddleware(cache.Middleware(func(name string) bool { return name != "create_build" }))
+	for _, name := range []string{"get_pipeline", "create_build"} {
+		mcp.AddTool(server, &mcp.Tool{Name: name}, func(context.Context, *mcp.CallToolRequest, any) (*mcp.CallToolResult, any, error) {
+			calls[name]++
+			return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: name}}}, nil, nil
+		})
+	}
+	mcp.AddTool(server, &mcp.Tool{Name: "get_build"}, func(context.Context, *mcp.CallToolRequest, any) (*mcp.CallToolResult, any, error) {
+		calls["get_build"]++
+		return &mcp.CallToolResult{IsError: true, Content: []mcp.Content{&mcp.TextContent{Text: "not found"}}}, nil, nil
+	})
+
+	t1, t2 := mcp.NewInMemoryTransports()
+	_, err := server.Connect(ctx, t1, nil)
+	assert.NoError(err)
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil)
+	session, err := client.Connect(ctx, t2, nil)
+	assert.NoError(err)
+	defer session.Close()
+
+	call := func(name string, args map[string]any) *mcp.CallToolResult {
+		result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: name, Arguments: args})
+		assert.NoError(err)
+		return result
+	}
+
+	first := call("get_pipeline", map[string]any{"org_slug": "acme", "pipeline_slug": "app"})
+	second := call("get_pipeline", map[string]any{"pipeline_slug": "app", "org_slug": "acme"})
+	assert.Equal(first.Content, second.Content)
+	assert.Equal(1, calls["get_pipeline"])
+
+	call("get_pipeline", map[string]any{"org_slug": "acme", "pipeline_slug": "other"})
+	assert.Equal(2, calls["get_pipeline"])
+
+	now = now.Add(time.Minute)
+	call("get_pipeline", map[string]any{"org_slug": "acme", "pipeline_slug": "app"})
+	assert.Equal(3, calls["get_pipeline"])
+
+	call("create_build", map[string]any{"org_slug": "acme"})
+	call("create_build", map[string]any{"org_slug": "acme"})
+	assert.Equal(2, calls["create_build"])
+
+	call("get_build", map[string]any{"build_number": "1"})
+	call("get_build", map[string]any{"build_number": "1"})
+	assert.Equal(2, calls["get_build"])
+}
@@ -123,7 +123,14 @@ func TestUpdatePipelineScheduleArgsSchema(t *testing.T) {
 
 func TestCreateBuildArgsSchema(t *testing.T) {
 	req := sortedRequired[CreateBuildArgs](t)
-	require.Equal(t, []string{"branch", "commit", "message", "org_slug", "pipeline_slug"}, req)
+	require.Equal(t, []string{"commit", "message", "org_slug", "pipeline_slug"}, req)
+
+	// env and meta_data only accept string values
+	s := schemaFor[CreateBuildArgs](t)
+	for _, name := range []string{"env", "meta_data"} {
+		require.Equal(t, "object", s.Properties[name].Type)
+		require.Equal(t, "string", s.Properties[name].AdditionalProperties.Type)
+	}
 }
 
 func TestListAnnotationsArgsSchema(t *testing.T) {
@@ -0,0 +1,106 @@
+package buildkite
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rs/zerolog/log"
+)
+
+// tokenScopesTTL is how long TokenScopes keeps the token's scopes, so scopes
+// added to the token are picked up without restarting the server.
+const tokenScopesTTL = 5 * time.Minute
+
+type requiredScopesContextKey struct{}
+
+// ContextWithRequiredScopes returns a context holding the API token scopes
+// needed by the tool being called, which 403 errors are checked against.
+func ContextWithRequiredScopes(ctx context.Context, scopes []string) context.Context {
+	return context.WithValue(ctx, requiredScopesContextKey{}, scopes)
+}
+
+func requiredScopesFromContext(ctx context.Context) []string {
+	scopes, _ := ctx.Value(requiredScopesContextKey{}).([]string)
+	return scopes
+}
+
+// RequiredScopesMiddleware returns an mcp.Middleware that stores the scopes
+// requiredScopes returns for the called tool in the context of each tool
+// call, so a 403 from the API can name the scopes the token is missing.
+func RequiredScopesMiddleware(requiredScopes func(toolName string) []string) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			params, ok := req.GetParams().(*mcp.CallToolParamsRaw)
+			if method != "tools/call" || !ok || params == nil {
+				return next(ctx, method, req)
+			}
+
+			if scopes := requiredScopes(params.Name); len(scopes) > 0 {
+				ctx = ContextWithRequiredScopes(ctx, scopes)
+			}
+			return next(ctx, method, req)
+		}
+	}
+}
+
+// TokenScopes caches the scopes granted to the server's API token for
+// tokenScopesTTL. The zero value is ready to use. A nil *TokenScopes reads the
+// scopes on every use, for servers where each request brings its own token.
+type TokenScopes struct {
+	mu        sync.Mutex
+	scopes    []string
+	fetchedAt time.Time
+}
+
+func (t *TokenScopes) get(ctx context.Context, client AccessTokenClient) ([]string, error) {
+	if t == nil {
+		token, _, err := client.Get(ctx)
+		return token.Scopes, err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.fetchedAt.IsZero() || time.Since(t.fetchedAt) > tokenScopesTTL {
+		token, _, err := client.Get(ctx)
+		if err != nil {
+			return nil, err
+		}
+		t.scopes = token.Scopes
+		t.fetchedAt = time.Now()
+	}
+	return t.scopes, nil
+}
+
+// missingScopesHint explains a 403 from the API by comparing the scopes the
+// tool needs with those granted to the token. It is empty when the tool's
+// scopes aren't known or the token's scopes can't be read.
+func missingScopesHint(ctx context.Context) string {
+	required := requiredScopesFromContext(ctx)
+	deps := DepsFromContext(ctx)
+	if len(required) == 0 || deps.AccessTokensClient == nil {
+		return ""
+	}
+
+	granted, err := deps.TokenScopes.get(ctx, deps.AccessTokensClient)
+	if err != nil {
+		log.Ctx(ctx).Debug().Err(err).Msg("Failed to read API token scopes")
+		return ""
+	}
+
+	var missing []string
+	for _, scope := range required {
+		if !slices.Contains(granted, scope) {
+			missing = append(missing, scope)
+		}
+	}
+	if len(missing) == 0 {
+		return fmt.Sprintf("The API token has every scope this tool needs (%s), so access was denied by the organization or the permissions of the token's user.", strings.Join(required, ", "))
+	}
+	return fmt.Sprintf("The API token is missing scopes this tool needs: %s. Add them to the token and try again.", strings.Join(missing, ", "))
+}
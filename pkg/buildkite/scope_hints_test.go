@@ -0,0 +1,126 @@
+package buildkite
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/buildkite/go-buildkite/v5"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+func forbiddenError() error {
+	return &buildkite.ErrorResponse{
+		Response: &http.Response{StatusCode: http.StatusForbidden},
+		Message:  "Forbidden",
+	}
+}
+
+func TestHandleBuildkiteError_ForbiddenNamesMissingScopes(t *testing.T) {
+	tokens := &MockAccessTokenClient{
+		GetFunc: func(ctx context.Context) (buildkite.AccessToken, *buildkite.Response, error) {
+			return buildkite.AccessToken{Scopes: []string{"read_builds", "read_pipelines"}}, nil, nil
+		},
+	}
+	ctx := ContextWithDeps(context.Background(), ToolDependencies{AccessTokensClient: tokens})
+
+	t.Run("MissingScope", func(t *testing.T) {
+		ctx := ContextWithRequiredScopes(ctx, []string{"write_builds", "read_pipelines"})
+
+		result, _, err := handleBuildkiteError(ctx, forbiddenError())
+		require.NoError(t, err)
+		require.True(t, result.IsError)
+		require.Equal(t, "Forbidden\n\nThe API token is missing scopes this tool needs: write_builds. Add them to the token and try again.", getTextResult(t, result).Text)
+	})
+
+	t.Run("ScopesGranted", func(t *testing.T) {
+		ctx := ContextWithRequiredScopes(ctx, []string{"read_builds"})
+
+		result, _, err := handleBuildkiteError(ctx, forbiddenError())
+		require.NoError(t, err)
+		require.Contains(t, getTextResult(t, result).Text, "The API token has every scope this tool needs (read_builds)")
+	})
+
+	t.Run("RequiredScopesUnknown", func(t *testing.T) {
+		result, _, err := handleBuildkiteError(ctx, forbiddenError())
+		require.NoError(t, err)
+		require.Equal(t, "Forbidden", getTextResult(t, result).Text)
+	})
+
+	t.Run("TokenUnreadable", func(t *testing.T) {
+		tokens := &MockAccessTokenClient{
+			GetFunc: func(ctx context.Context) (buildkite.AccessToken, *buildkite.Response, error) {
+				return buildkite.AccessToken{}, nil, errors.New("boom")
+			},
+		}
+		ctx := ContextWithDeps(context.Background(), ToolDependencies{AccessTokensClient: tokens})
+		ctx = ContextWithRequiredScopes(ctx, []string{"write_builds"})
+
+		result, _, err := handleBuildkiteError(ctx, forbiddenError())
+		require.NoError(t, err)
+		require.Equal(t, "Forbidden", getTextResult(t, result).Text)
+	})
+}
+
+func TestTokenScopesCachesScopes(t *testing.T) {
+	calls := 0
+	tokens := &MockAccessTokenClient{
+		GetFunc: func(ctx context.Context) (buildkite.AccessToken, *buildkite.Response, error) {
+			calls++
+			if calls == 1 {
+				return buildkite.AccessToken{}, nil, errors.New("boom")
+			}
+			return buildkite.AccessToken{Scopes: []string{"read_builds"}}, nil, nil
+		},
+	}
+	cache := &TokenScopes{}
+
+	// Failures aren't cached.
+	_, err := cache.get(context.Background(), tokens)
+	require.Error(t, err)
+
+	for range 2 {
+		scopes, err := cache.get(context.Background(), tokens)
+		require.NoError(t, err)
+		require.Equal(t, []string{"read_builds"}, scopes)
+	}
+	require.Equal(t, 2, calls)
+}
+
+func TestRequiredScopesMiddleware(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "1.0.0"}, nil)
+	server.AddReceivingMiddleware(RequiredScopesMiddleware(func(name string) []string {
+		if name == "create_build" {
+			return []string{"write_builds"}
+		}
+		return nil
+	}))
+	for _, name := range []string{"create_build", "other"} {
+		mcp.AddTool(server, &mcp.Tool{Name: name}, func(ctx context.Context, _ *mcp.CallToolRequest, _ any) (*mcp.CallToolResult, any, error) {
+			text := strings.Join(requiredScopesFromContext(ctx), ",")
+			return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: text}}}, nil, nil
+		})
+	}
+
+	t1, t2 := mcp.NewInMemoryTransports()
+	_, err := server.Connect(ctx, t1, nil)
+	assert.NoError(err)
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil)
+	session, err := client.Connect(ctx, t2, nil)
+	assert.NoError(err)
+	defer session.Close()
+
+	result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "create_build"})
+	assert.NoError(err)
+	assert.Equal("write_builds", getTextResult(t, result).Text)
+
+	result, err = session.CallTool(ctx, &mcp.CallToolParams{Name: "other"})
+	assert.NoError(err)
+	assert.Empty(getTextResult(t, result).Text)
+}
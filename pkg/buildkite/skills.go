@@ -68,7 +68,7 @@ func ListSkills() (mcp.Tool, mcp.ToolHandlerFor[ListSkillsArgs, any], []string)
 				}
 			}
 
-			return mcpTextResult(span, results)
+			return mcpTextResult(ctx, span, results)
 		}, []string{}
 }
 
@@ -20,6 +20,7 @@ type GetFailedTestExecutionsArgs struct {
 	IncludeFailureExpanded bool   `json:"include_failure_expanded,omitempty" jsonschema:"Include expanded failure details such as full error messages and stack traces"`
 	Page                   int    `json:"page,omitempty" jsonschema:"Page number for pagination (min 1)"`
 	PerPage                int    `json:"per_page,omitempty" jsonschema:"Results per page for pagination (min 1, max 100)"`
+	IncludePagination      bool   `json:"include_pagination,omitempty" jsonschema:"Add page, has_more and next_page to the result, so you can tell whether more pages exist"`
 }
 
 func GetFailedTestExecutions() (mcp.Tool, mcp.ToolHandlerFor[GetFailedTestExecutionsArgs, any], []string) {
@@ -53,7 +54,7 @@ func GetFailedTestExecutions() (mcp.Tool, mcp.ToolHandlerFor[GetFailedTestExecut
 			deps := DepsFromContext(ctx)
 			failedExecutions, resp, err := deps.TestExecutionsClient.GetFailedExecutions(ctx, args.OrgSlug, args.TestSuiteSlug, args.RunID, options)
 			if err != nil {
-				return handleBuildkiteError(err)
+				return handleBuildkiteError(ctx, err)
 			}
 
 			result := PaginatedResult[buildkite.FailedExecution]{
@@ -61,12 +62,13 @@ func GetFailedTestExecutions() (mcp.Tool, mcp.ToolHandlerFor[GetFailedTestExecut
 				Headers: map[string]string{
 					"Link": resp.Header.Get("Link"),
 				},
+				Pagination: paginationFor(args.IncludePagination, args.Page, resp),
 			}
 
 			span.SetAttributes(
 				attribute.Int("item_count", len(failedExecutions)),
 			)
 
-			return mcpTextResult(span, &result)
+			return mcpTextResult(ctx, span, &result)
 		}, []string{"read_suites"}
 }
@@ -20,10 +20,11 @@ type TestRunsClient interface {
 }
 
 type ListTestRunsArgs struct {
-	OrgSlug       string `json:"org_slug"`
-	TestSuiteSlug string `json:"test_suite_slug"`
-	Page          int    `json:"page,omitempty" jsonschema:"Page number for pagination (min 1)"`
-	PerPage       int    `json:"per_page,omitempty" jsonschema:"Results per page for pagination (min 1, max 100)"`
+	OrgSlug           string `json:"org_slug"`
+	TestSuiteSlug     string `json:"test_suite_slug"`
+	Page              int    `json:"page,omitempty" jsonschema:"Page number for pagination (min 1)"`
+	PerPage           int    `json:"per_page,omitempty" jsonschema:"Results per page for pagination (min 1, max 100)"`
+	IncludePagination bool   `json:"include_pagination,omitempty" jsonschema:"Add page, has_more and next_page to the result, so you can tell whether more pages exist"`
 }
 
 type GetTestRunArgs struct {
@@ -61,7 +62,7 @@ func ListTestRuns() (mcp.Tool, mcp.ToolHandlerFor[ListTestRunsArgs, any], []stri
 			deps := DepsFromContext(ctx)
 			testRuns, resp, err := deps.TestRunsClient.List(ctx, args.OrgSlug, args.TestSuiteSlug, options)
 			if err != nil {
-				return handleBuildkiteError(err)
+				return handleBuildkiteError(ctx, err)
 			}
 
 			result := PaginatedResult[buildkite.TestRun]{
@@ -69,13 +70,14 @@ func ListTestRuns() (mcp.Tool, mcp.ToolHandlerFor[ListTestRunsArgs, any], []stri
 				Headers: map[string]string{
 					"Link": resp.Header.Get("Link"),
 				},
+				Pagination: paginationFor(args.IncludePagination, paginationParams.Page, resp),
 			}
 
 			span.SetAttributes(
 				attribute.Int("item_count", len(testRuns)),
 			)
 
-			return mcpTextResult(span, &result)
+			return mcpTextResult(ctx, span, &result)
 		}, []string{"read_suites"}
 }
 
@@ -101,7 +103,7 @@ func GetTestRun() (mcp.Tool, mcp.ToolHandlerFor[GetTestRunArgs, any], []string)
 			deps := DepsFromContext(ctx)
 			testRun, resp, err := deps.TestRunsClient.Get(ctx, args.OrgSlug, args.TestSuiteSlug, args.RunID)
 			if err != nil {
-				return handleBuildkiteError(err)
+				return handleBuildkiteError(ctx, err)
 			}
 
 			if resp.StatusCode != http.StatusOK {
@@ -112,6 +114,6 @@ func GetTestRun() (mcp.Tool, mcp.ToolHandlerFor[GetTestRunArgs, any], []string)
 				return utils.NewToolResultError(fmt.Sprintf("failed to get test run: %s", string(body))), nil, nil
 			}
 
-			return mcpTextResult(span, &testRun)
+			return mcpTextResult(ctx, span, &testRun)
 		}, []string{"read_suites"}
 }
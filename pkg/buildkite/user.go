@@ -6,6 +6,7 @@ import (
 	"github.com/buildkite/buildkite-mcp-server/pkg/trace"
 	"github.com/buildkite/go-buildkite/v5"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 type UserClient interface {
@@ -14,10 +15,17 @@ type UserClient interface {
 
 type CurrentUserArgs struct{}
 
+// CurrentUserResult is the current user, with the scopes of the API token
+// when the token can be read.
+type CurrentUserResult struct {
+	buildkite.User
+	Scopes []string `json:"scopes,omitempty"`
+}
+
 func CurrentUser() (mcp.Tool, mcp.ToolHandlerFor[CurrentUserArgs, any], []string) {
 	tool := mcp.Tool{
 		Name:        "current_user",
-		Description: "Get details about the user account that owns the API token, including name, email, avatar, and account creation date",
+		Description: "Get details about the user account that owns the API token, including name, email, avatar, account creation date, and the scopes granted to the token",
 		Annotations: &mcp.ToolAnnotations{
 			Title:        "Get Current User",
 			ReadOnlyHint: true,
@@ -30,10 +38,21 @@ func CurrentUser() (mcp.Tool, mcp.ToolHandlerFor[CurrentUserArgs, any], []string
 		deps := DepsFromContext(ctx)
 		user, _, err := deps.UserClient.CurrentUser(ctx)
 		if err != nil {
-			return handleBuildkiteError(err)
+			return handleBuildkiteError(ctx, err)
+		}
+
+		result := CurrentUserResult{User: user}
+		if deps.AccessTokensClient != nil {
+			// The scopes are a convenience, so the user is returned without
+			// them when the token can't be read.
+			token, _, err := deps.AccessTokensClient.Get(ctx)
+			if err == nil {
+				result.Scopes = sortedScopes(token.Scopes)
+			}
+			span.SetAttributes(attribute.Bool("scopes_included", err == nil))
 		}
 
-		return mcpTextResult(span, &user)
+		return mcpTextResult(ctx, span, &result)
 	}
 	scopes := []string{"read_user"}
 	return tool, handler, scopes
@@ -2,6 +2,7 @@ package buildkite
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"testing"
 
@@ -53,3 +54,39 @@ func TestCurrentUser(t *testing.T) {
 
 	assert.JSONEq(`{"id":"123","name":"Test User","email":"user@example.com","created_at":"0001-01-01T00:00:00Z"}`, textContent.Text)
 }
+
+func TestCurrentUserScopes(t *testing.T) {
+	users := &MockUserClient{
+		CurrentUserFunc: func(ctx context.Context) (buildkite.User, *buildkite.Response, error) {
+			return buildkite.User{ID: "123", Name: "Test User"}, nil, nil
+		},
+	}
+	_, handler, _ := CurrentUser()
+
+	t.Run("IncludesSortedScopes", func(t *testing.T) {
+		tokens := &MockAccessTokenClient{
+			GetFunc: func(ctx context.Context) (buildkite.AccessToken, *buildkite.Response, error) {
+				return buildkite.AccessToken{Scopes: []string{"write_builds", "read_builds", "read_pipelines"}}, nil, nil
+			},
+		}
+		ctx := ContextWithDeps(context.Background(), ToolDependencies{UserClient: users, AccessTokensClient: tokens})
+
+		result, _, err := handler(ctx, createMCPRequest(t, map[string]any{}), CurrentUserArgs{})
+		require.NoError(t, err)
+		require.JSONEq(t, `{"id":"123","name":"Test User","scopes":["read_builds","read_pipelines","write_builds"]}`, getTextResult(t, result).Text)
+	})
+
+	t.Run("OmitsScopesWhenTokenCannotBeRead", func(t *testing.T) {
+		tokens := &MockAccessTokenClient{
+			GetFunc: func(ctx context.Context) (buildkite.AccessToken, *buildkite.Response, error) {
+				return buildkite.AccessToken{}, nil, errors.New("forbidden")
+			},
+		}
+		ctx := ContextWithDeps(context.Background(), ToolDependencies{UserClient: users, AccessTokensClient: tokens})
+
+		result, _, err := handler(ctx, createMCPRequest(t, map[string]any{}), CurrentUserArgs{})
+		require.NoError(t, err)
+		require.False(t, result.IsError)
+		require.JSONEq(t, `{"id":"123","name":"Test User"}`, getTextResult(t, result).Text)
+	})
+}
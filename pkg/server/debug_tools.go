@@ -0,0 +1,78 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+
+	"github.com/buildkite/buildkite-mcp-server/pkg/toolsets"
+)
+
+// DebugTool is a tool in the /debug/tools report.
+type DebugTool struct {
+	Name     string   `json:"name"`
+	Toolset  string   `json:"toolset"`
+	ReadOnly bool     `json:"read_only"`
+	Scopes   []string `json:"scopes"`
+}
+
+// DebugToolsReport lists the tools a request to /mcp would be served, with
+// the toolsets they come from.
+type DebugToolsReport struct {
+	EnabledToolsets []string                   `json:"enabled_toolsets"`
+	ReadOnly        bool                       `json:"read_only"`
+	Toolsets        []toolsets.ToolsetMetadata `json:"toolsets"`
+	Tools           []DebugTool                `json:"tools"`
+}
+
+// NewDebugToolsHandler returns an http.Handler that reports, as JSON, the
+// tools an MCP request like this one would be served. The toolsets and
+// read-only mode are chosen as NewPerRequestServerFactory chooses them, so a
+// token policy or X-Buildkite-Toolsets header on the request is reflected.
+func NewDebugToolsHandler(defaultToolsets []string, defaultReadOnly bool, disabledToolsets ...string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		enabledToolsets, readOnly := requestToolsets(r, defaultToolsets, defaultReadOnly, disabledToolsets)
+		report := debugToolsReport(toolsets.NewDefaultRegistry(), enabledToolsets, readOnly)
+
+		w.Header().Set("Content-Type", "application/json")
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		_ = encoder.Encode(report)
+	})
+}
+
+func debugToolsReport(registry *toolsets.ToolsetRegistry, enabledToolsets []string, readOnly bool) DebugToolsReport {
+	report := DebugToolsReport{
+		EnabledToolsets: enabledToolsets,
+		ReadOnly:        readOnly,
+		Toolsets:        []toolsets.ToolsetMetadata{},
+		Tools:           []DebugTool{},
+	}
+
+	var served []string
+	for _, tool := range registry.GetEnabledTools(enabledToolsets, readOnly) {
+		toolset, _ := registry.GetToolsetName(tool.Tool.Name)
+		if !slices.Contains(served, toolset) {
+			served = append(served, toolset)
+		}
+		report.Tools = append(report.Tools, DebugTool{
+			Name:     tool.Tool.Name,
+			Toolset:  toolset,
+			ReadOnly: tool.IsReadOnly(),
+			Scopes:   tool.RequiredScopes,
+		})
+	}
+
+	for _, metadata := range registry.GetMetadata() {
+		if slices.Contains(served, metadata.Name) {
+			report.Toolsets = append(report.Toolsets, metadata)
+		}
+	}
+	return report
+}
@@ -0,0 +1,68 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/buildkite/buildkite-mcp-server/pkg/toolsets"
+	"github.com/stretchr/testify/require"
+)
+
+func getDebugTools(t *testing.T, handler http.Handler, header http.Header) DebugToolsReport {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodGet, "/debug/tools", nil)
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	var report DebugToolsReport
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+	return report
+}
+
+func TestDebugToolsHandler(t *testing.T) {
+	handler := NewDebugToolsHandler([]string{toolsets.ToolsetBuilds}, true)
+
+	t.Run("defaults", func(t *testing.T) {
+		assert := require.New(t)
+
+		report := getDebugTools(t, handler, nil)
+		assert.Equal([]string{toolsets.ToolsetBuilds}, report.EnabledToolsets)
+		assert.True(report.ReadOnly)
+		assert.Len(report.Toolsets, 1)
+		assert.Equal(toolsets.ToolsetBuilds, report.Toolsets[0].Name)
+		assert.Len(report.Tools, report.Toolsets[0].ReadOnlyCount)
+		for _, tool := range report.Tools {
+			assert.Equal(toolsets.ToolsetBuilds, tool.Toolset)
+			assert.True(tool.ReadOnly, tool.Name)
+		}
+	})
+
+	t.Run("request headers", func(t *testing.T) {
+		assert := require.New(t)
+
+		report := getDebugTools(t, handler, http.Header{
+			HeaderToolsets: {toolsets.ToolsetUser},
+			HeaderReadOnly: {"false"},
+		})
+		assert.Equal([]string{toolsets.ToolsetUser}, report.EnabledToolsets)
+		assert.False(report.ReadOnly)
+		assert.NotEmpty(report.Tools)
+		for _, tool := range report.Tools {
+			assert.Equal(toolsets.ToolsetUser, tool.Toolset)
+		}
+	})
+
+	t.Run("only GET", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/debug/tools", nil))
+		require.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	})
+}
@@ -23,7 +23,9 @@ const (
 
 // NewPerRequestServerFactory returns a function that creates an mcp.Server per HTTP request.
 // It reads X-Buildkite-Toolsets and X-Buildkite-Read-Only headers from the request,
-// falling back to the provided defaults when headers are absent.
+// falling back to the provided defaults when headers are absent. When the request
+// context carries a TokenPolicy, its toolsets and read-only mode replace the
+// defaults, and the headers can only narrow them further.
 func NewPerRequestServerFactory(
 	version string,
 	deps buildkite.ToolDependencies,
@@ -32,22 +34,7 @@ func NewPerRequestServerFactory(
 	disabledToolsets ...string,
 ) func(*http.Request) *mcp.Server {
 	return func(r *http.Request) *mcp.Server {
-		enabledToolsets := defaultToolsets
-		readOnly := defaultReadOnly
-
-		if header := r.Header.Get(HeaderToolsets); header != "" {
-			parsed := ParseToolsetsHeader(header)
-			if err := toolsets.ValidateToolsets(parsed); err != nil {
-				log.Warn().Err(err).Str("header", header).Msg("Invalid toolsets in header, using server defaults")
-			} else {
-				enabledToolsets = parsed
-			}
-		}
-
-		if header := r.Header.Get(HeaderReadOnly); header != "" {
-			readOnly = strings.EqualFold(strings.TrimSpace(header), "true")
-		}
-		enabledToolsets = withoutToolsets(enabledToolsets, disabledToolsets)
+		enabledToolsets, readOnly := requestToolsets(r, defaultToolsets, defaultReadOnly, disabledToolsets)
 
 		return NewMCPServer(version, deps,
 			WithToolsets(enabledToolsets...),
@@ -56,13 +43,46 @@ func NewPerRequestServerFactory(
 	}
 }
 
+// requestToolsets returns the toolsets and read-only mode for a request, from
+// its token policy and headers, falling back to the defaults.
+func requestToolsets(r *http.Request, defaultToolsets []string, defaultReadOnly bool, disabledToolsets []string) ([]string, bool) {
+	enabledToolsets := defaultToolsets
+	readOnly := defaultReadOnly
+
+	policy, hasPolicy := TokenPolicyFromContext(r.Context())
+	if hasPolicy {
+		enabledToolsets = policy.Toolsets
+		readOnly = policy.ReadOnly
+	}
+
+	if header := r.Header.Get(HeaderToolsets); header != "" {
+		parsed := ParseToolsetsHeader(header)
+		if err := toolsets.ValidateToolsets(parsed); err != nil {
+			log.Warn().Err(err).Str("header", header).Msg("Invalid toolsets in header, using server defaults")
+		} else if hasPolicy {
+			enabledToolsets = restrictToolsets(parsed, policy.Toolsets)
+		} else {
+			enabledToolsets = parsed
+		}
+	}
+
+	if header := r.Header.Get(HeaderReadOnly); header != "" {
+		readOnly = strings.EqualFold(strings.TrimSpace(header), "true") || (hasPolicy && policy.ReadOnly)
+	}
+	return withoutToolsets(enabledToolsets, disabledToolsets), readOnly
+}
+
 func withoutToolsets(enabled, disabled []string) []string {
 	if len(disabled) == 0 {
 		return enabled
 	}
 	if slices.Contains(enabled, toolsets.ToolsetAll) {
-		enabled = toolsets.ValidToolsets
+		enabled = slices.DeleteFunc(slices.Clone(toolsets.ValidToolsets), func(name string) bool {
+			return toolsets.IsOptInToolset(name) && !slices.Contains(enabled, name)
+		})
 	}
+	enabled = toolsets.ExpandToolsetAliases(enabled)
+	disabled = toolsets.ExpandToolsetAliases(disabled)
 
 	filtered := make([]string, 0, len(enabled))
 	for _, name := range enabled {
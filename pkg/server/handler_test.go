@@ -171,3 +171,12 @@ func listToolNames(t *testing.T, server *mcp.Server) []string {
 	}
 	return names
 }
+
+func TestWithoutToolsets_ExpandsAliases(t *testing.T) {
+	assert.Equal(t, []string{"builds", "annotations"}, withoutToolsets([]string{"ci"}, []string{"logs"}))
+}
+
+func TestWithoutToolsets_KeepsOptInToolsetsOutOfAll(t *testing.T) {
+	assert.NotContains(t, withoutToolsets([]string{"all"}, []string{"logs"}), "graphql")
+	assert.Contains(t, withoutToolsets([]string{"all", "graphql"}, []string{"logs"}), "graphql")
+}
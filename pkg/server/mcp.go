@@ -3,11 +3,15 @@ package server
 import (
 	"context"
 	"errors"
+	"fmt"
+	"slices"
 	"strings"
+	"sync"
 
 	"github.com/buildkite/buildkite-mcp-server/pkg/buildkite"
 	"github.com/buildkite/buildkite-mcp-server/pkg/toolsets"
 	"github.com/buildkite/buildkite-mcp-server/pkg/trace"
+	"github.com/google/jsonschema-go/jsonschema"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
@@ -20,7 +24,18 @@ type ToolsetOption func(*ToolsetConfig)
 type ToolsetConfig struct {
 	EnabledToolsets []string
 	ReadOnly        bool
+	DynamicToolsets bool
+	EnabledTools    []string
+	DisabledTools   []string
 	OnUnauthorized  func()
+
+	// logger is injected into the context of requests that don't already
+	// have one; it defaults to the global logger.
+	logger *zerolog.Logger
+
+	// defaultOrg makes org_slug optional; it is set from
+	// ToolDependencies.DefaultOrg.
+	defaultOrg string
 }
 
 // WithToolsets enables specific toolsets
@@ -37,6 +52,121 @@ func WithReadOnly(readOnly bool) ToolsetOption {
 	}
 }
 
+// WithEnabledTools limits the server to the named tools from the enabled
+// toolsets. A tool also named in WithDisabledTools stays disabled.
+func WithEnabledTools(names ...string) ToolsetOption {
+	return func(cfg *ToolsetConfig) {
+		cfg.EnabledTools = names
+	}
+}
+
+// WithDisabledTools removes the named tools from the enabled toolsets. It takes
+// precedence over WithEnabledTools.
+func WithDisabledTools(names ...string) ToolsetOption {
+	return func(cfg *ToolsetConfig) {
+		cfg.DisabledTools = names
+	}
+}
+
+// enabledTools returns the built-in tools selected by toolset, read-only mode
+// and the tool filters.
+func (cfg *ToolsetConfig) enabledTools() []toolsets.ToolDefinition {
+	registry := toolsets.NewDefaultRegistry()
+	return cfg.applyDefaultOrg(cfg.filterTools(registry.GetEnabledTools(cfg.EnabledToolsets, cfg.ReadOnly)))
+}
+
+// filterTools applies EnabledTools and DisabledTools to tools selected by toolset.
+func (cfg *ToolsetConfig) filterTools(tools []toolsets.ToolDefinition) []toolsets.ToolDefinition {
+	if len(cfg.EnabledTools) == 0 && len(cfg.DisabledTools) == 0 {
+		return tools
+	}
+
+	filtered := make([]toolsets.ToolDefinition, 0, len(tools))
+	for _, tool := range tools {
+		name := tool.Tool.Name
+		if slices.Contains(cfg.DisabledTools, name) {
+			continue
+		}
+		if len(cfg.EnabledTools) > 0 && !slices.Contains(cfg.EnabledTools, name) {
+			continue
+		}
+		filtered = append(filtered, tool)
+	}
+	return filtered
+}
+
+// applyDefaultOrg drops org_slug from the required parameters of tools when a
+// default organization is set, as DefaultOrgMiddleware fills it in. The input
+// schemas are changed in place, as each tool's Register shares them.
+func (cfg *ToolsetConfig) applyDefaultOrg(tools []toolsets.ToolDefinition) []toolsets.ToolDefinition {
+	if cfg.defaultOrg == "" {
+		return tools
+	}
+
+	for _, tool := range tools {
+		schema, ok := tool.Tool.InputSchema.(*jsonschema.Schema)
+		if !ok || schema.Properties[buildkite.OrgSlugParam] == nil {
+			continue
+		}
+		schema.Required = slices.DeleteFunc(slices.Clone(schema.Required), func(name string) bool {
+			return name == buildkite.OrgSlugParam
+		})
+		schema.Properties[buildkite.OrgSlugParam].Description = fmt.Sprintf("Defaults to %q when omitted", cfg.defaultOrg)
+	}
+	return tools
+}
+
+// orgTools reports whether a tool takes org_slug, including tools that
+// enable_toolset may load later. The built-in tools don't change, so the
+// lookup is built once per process.
+var orgTools = sync.OnceValue(func() func(name string) bool {
+	names := make(map[string]bool)
+	for _, toolset := range toolsets.CreateBuiltinToolsets() {
+		for _, tool := range toolset.Tools {
+			if schema, ok := tool.Tool.InputSchema.(*jsonschema.Schema); ok && schema.Properties[buildkite.OrgSlugParam] != nil {
+				names[tool.Tool.Name] = true
+			}
+		}
+	}
+	return func(name string) bool {
+		return names[name]
+	}
+})
+
+// toolScopes returns the API token scopes a tool needs, including tools that
+// enable_toolset may load later. Like orgTools, it is built once per process.
+var toolScopes = sync.OnceValue(func() func(name string) []string {
+	scopes := make(map[string][]string)
+	for _, toolset := range toolsets.CreateBuiltinToolsets() {
+		for _, tool := range toolset.Tools {
+			scopes[tool.Tool.Name] = tool.RequiredScopes
+		}
+	}
+	return func(name string) []string {
+		return scopes[name]
+	}
+})
+
+// toolToolsets maps each built-in tool to the name of its toolset, built once
+// per process.
+var toolToolsets = sync.OnceValue(func() map[string]string {
+	names := make(map[string]string)
+	for toolsetName, toolset := range toolsets.CreateBuiltinToolsets() {
+		for _, tool := range toolset.Tools {
+			names[tool.Tool.Name] = toolsetName
+		}
+	}
+	return names
+})
+
+// WithDynamicToolsets starts the server with only the discovery tools and
+// enable_toolset, which loads the enabled toolsets on demand.
+func WithDynamicToolsets(dynamic bool) ToolsetOption {
+	return func(cfg *ToolsetConfig) {
+		cfg.DynamicToolsets = dynamic
+	}
+}
+
 // WithOnUnauthorized registers a callback that fires when the Buildkite API returns a
 // 401. Library consumers use this to invalidate stored tokens and trigger reauth.
 func WithOnUnauthorized(cb func()) ToolsetOption {
@@ -45,6 +175,14 @@ func WithOnUnauthorized(cb func()) ToolsetOption {
 	}
 }
 
+// WithLogger sets the logger tools get from log.Ctx, for requests whose
+// context doesn't already carry one.
+func WithLogger(logger zerolog.Logger) ToolsetOption {
+	return func(cfg *ToolsetConfig) {
+		cfg.logger = &logger
+	}
+}
+
 // unauthorizedMiddleware intercepts ErrUnauthorized propagated from tool handlers.
 // It signals the HTTP layer (if present) and calls the optional library callback.
 func unauthorizedMiddleware(cb func()) mcp.Middleware {
@@ -83,7 +221,7 @@ var instructionSections = []instructionSection{
 		toolset: toolsets.ToolsetSkills,
 		text:    "Skill discovery: Always call list_skills early in a session — it's cheap (names and one-line descriptions only) and surfaces guidance not visible in any tool's name or schema. When a task matches a listed skill (e.g. debugging a build failure, tuning search_logs), call load_skill for that guide — it covers parameter tuning, caching behavior, and details beyond the summaries below.",
 	},
-	{text: "Authorization: Tools available depend on the scopes and organization access granted to the configured API token. A 401 response means the token is invalid, expired, or revoked and requires reauthentication. A 403 response means the credentials were accepted but access was denied, commonly because the token lacks a required scope or organization access, or the user lacks permission."},
+	{text: "Authorization: Tools available depend on the scopes and organization access granted to the configured API token. A 401 response means the token is invalid, expired, or revoked and requires reauthentication. A 403 response means the credentials were accepted but access was denied, commonly because the token lacks a required scope or organization access, or the user lacks permission. The error of a 403 names the scopes the tool needs that the token is missing, if any."},
 	{text: "Common pitfalls:\n\nbuild_number is a sequential integer string (e.g. \"42\"), not a UUID. Build, job, artifact, and log tools all require this identifier — do not use the build's UUID id field."},
 	{
 		toolset: toolsets.ToolsetInvestigations,
@@ -132,6 +270,10 @@ func NewMCPServer(version string, deps buildkite.ToolDependencies, opts ...Tools
 	for _, opt := range opts {
 		opt(cfg)
 	}
+	cfg.defaultOrg = deps.DefaultOrg
+	if cfg.logger == nil {
+		cfg.logger = &log.Logger
+	}
 
 	s := mcp.NewServer(&mcp.Implementation{
 		Name:    "buildkite-mcp-server",
@@ -143,25 +285,52 @@ func NewMCPServer(version string, deps buildkite.ToolDependencies, opts ...Tools
 	log.Info().Str("version", version).Msg("Starting Buildkite MCP server")
 
 	// Add middleware
+	pipelineResources := toolsets.IsToolsetEnabled(cfg.EnabledToolsets, toolsets.ToolsetPipelines)
+	if pipelineResources {
+		// Added first, so it runs inside InjectDepsMiddleware.
+		s.AddReceivingMiddleware(buildkite.PipelineResourcesMiddleware())
+	}
 	s.AddReceivingMiddleware(
-		injectLoggerMiddleware(log.Logger),
-		trace.NewMiddleware(),
+		injectLoggerMiddleware(*cfg.logger),
+		trace.NewMiddleware(
+			trace.WithArgumentValues(trace.SafeArgumentKeys...),
+			trace.WithToolThresholds(deps.SlowToolThreshold, deps.LargeToolResultBytes),
+			trace.WithToolCallObserver(deps.ToolCallObserver),
+		),
 		buildkite.InjectDepsMiddleware(deps),
 		unauthorizedMiddleware(cfg.OnUnauthorized),
+		buildkite.RequiredScopesMiddleware(toolScopes()),
 	)
+	if deps.Redactor != nil {
+		s.AddReceivingMiddleware(deps.Redactor.Middleware())
+	}
+	if deps.IncludeRateLimit {
+		s.AddReceivingMiddleware(buildkite.RateLimitMiddleware())
+	}
+	if deps.ResponseCache != nil {
+		// Added after the rate limit, so cached results are served without
+		// the API rate limit of the call that filled the cache.
+		s.AddReceivingMiddleware(deps.ResponseCache.Middleware(cfg.cacheableTools()))
+	}
+	if deps.DefaultOrg != "" {
+		// Added last, so org_slug is filled in before the cache key is made
+		// and calls with and without it share entries.
+		s.AddReceivingMiddleware(buildkite.DefaultOrgMiddleware(deps.DefaultOrg, orgTools()))
+	}
 
 	// Register tools
 	RegisterTools(s, cfg)
 
 	// Register prompts
-	s.AddPrompt(&mcp.Prompt{
-		Name:        "user_token_organization_prompt",
-		Description: "When asked for detail of a user's pipelines start by looking up the user's token organization",
-	}, buildkite.HandleUserTokenOrganizationPrompt)
-
 	reportIssuePrompt, reportIssueHandler := buildkite.NewReportIssuePrompt(version)
 	s.AddPrompt(reportIssuePrompt, reportIssueHandler)
 
+	for _, prompt := range toolPrompts {
+		if cfg.promptToolsetsEnabled(prompt.tools) {
+			s.AddPrompt(prompt.newPrompt())
+		}
+	}
+
 	// Register resource
 	s.AddResource(&mcp.Resource{
 		URI:         "buildkite://debug-logs-guide",
@@ -169,31 +338,115 @@ func NewMCPServer(version string, deps buildkite.ToolDependencies, opts ...Tools
 		Description: "Comprehensive guide for debugging Buildkite build failures using logs",
 	}, buildkite.HandleDebugLogsGuideResource)
 
+	if pipelineResources {
+		s.AddResourceTemplate(buildkite.NewPipelineResourceTemplate())
+	}
+	if toolsets.IsToolsetEnabled(cfg.EnabledToolsets, toolsets.ToolsetBuilds) {
+		s.AddResourceTemplate(buildkite.NewBuildResourceTemplate())
+	}
+
 	return s
 }
 
+// toolPrompts are the prompts that walk the model through tools, with the
+// tools their steps rely on. Tools a prompt only mentions as a fallback, such
+// as user_token_organization for a missing org_slug, aren't listed.
+var toolPrompts = []struct {
+	newPrompt func() (*mcp.Prompt, mcp.PromptHandler)
+	tools     []string
+}{
+	{
+		newPrompt: func() (*mcp.Prompt, mcp.PromptHandler) {
+			return &mcp.Prompt{
+				Name:        "user_token_organization_prompt",
+				Description: "When asked for detail of a user's pipelines start by looking up the user's token organization",
+			}, buildkite.HandleUserTokenOrganizationPrompt
+		},
+		tools: []string{"user_token_organization"},
+	},
+	{
+		newPrompt: buildkite.NewDiagnoseFailedBuildPrompt,
+		tools:     []string{"get_build_failure_summary", "search_logs", "read_logs", "list_builds", "compare_builds"},
+	},
+	{
+		newPrompt: buildkite.NewSummarizeRecentFailuresPrompt,
+		tools:     []string{"list_builds", "get_build_failure_summary", "get_latest_build"},
+	},
+	{
+		newPrompt: buildkite.NewFindFlakyTestPrompt,
+		tools:     []string{"list_test_runs", "get_failed_executions", "get_test_run", "get_test"},
+	},
+}
+
+// promptToolsetsEnabled reports whether the toolsets of every tool are
+// enabled, so a prompt isn't offered when the tools it asks for are missing.
+func (cfg *ToolsetConfig) promptToolsetsEnabled(tools []string) bool {
+	toolsetNames := toolToolsets()
+	for _, tool := range tools {
+		toolset, ok := toolsetNames[tool]
+		if !ok || !toolsets.IsToolsetEnabled(cfg.EnabledToolsets, toolset) {
+			return false
+		}
+	}
+	return true
+}
+
+// uncacheableTools are read-only tools whose results go stale while cached,
+// such as a wait that timed out before the build finished.
+var uncacheableTools = []string{"wait_for_build"}
+
+// cacheableTools returns the read-only tools registered up front, whose
+// results can be cached. Tools loaded by enable_toolset aren't included, as
+// the cache is consulted before the server knows whether a tool exists.
+func (cfg *ToolsetConfig) cacheableTools() func(name string) bool {
+	names := make(map[string]bool)
+	if !cfg.DynamicToolsets {
+		for _, tool := range cfg.enabledTools() {
+			if tool.IsReadOnly() && !slices.Contains(uncacheableTools, tool.Tool.Name) {
+				names[tool.Tool.Name] = true
+			}
+		}
+	}
+	return func(name string) bool {
+		return names[name]
+	}
+}
+
 // injectLoggerMiddleware returns middleware that injects a zerolog logger into the request context.
+// A logger already in the context, such as the request-scoped one the HTTP
+// server adds with the request ID and client IP, is kept.
 func injectLoggerMiddleware(logger zerolog.Logger) mcp.Middleware {
 	return func(next mcp.MethodHandler) mcp.MethodHandler {
 		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
-			ctx = logger.WithContext(ctx)
+			if !hasContextLogger(ctx) {
+				ctx = logger.WithContext(ctx)
+			}
 			return next(ctx, method, req)
 		}
 	}
 }
 
+// hasContextLogger reports whether ctx carries an enabled logger of its own,
+// rather than the default log.Ctx falls back to.
+func hasContextLogger(ctx context.Context) bool {
+	l := zerolog.Ctx(ctx)
+	return l != zerolog.DefaultContextLogger && l.GetLevel() != zerolog.Disabled
+}
+
 // RegisterTools registers tools from enabled toolsets onto the server
 func RegisterTools(s *mcp.Server, cfg *ToolsetConfig) {
-	registry := toolsets.NewToolsetRegistry()
-	registry.RegisterToolsets(toolsets.CreateBuiltinToolsets())
+	if cfg.DynamicToolsets {
+		registerDynamicTools(s, cfg)
+		return
+	}
 
-	enabledTools := registry.GetEnabledTools(cfg.EnabledToolsets, cfg.ReadOnly)
+	enabledTools := cfg.enabledTools()
 
 	for _, toolDef := range enabledTools {
 		toolDef.Register(s)
 	}
 
-	scopes := registry.GetRequiredScopes(cfg.EnabledToolsets, cfg.ReadOnly)
+	scopes := toolsets.Toolset{Tools: enabledTools}.GetRequiredScopes()
 
 	log.Info().
 		Strs("enabled_toolsets", cfg.EnabledToolsets).
@@ -202,3 +455,28 @@ func RegisterTools(s *mcp.Server, cfg *ToolsetConfig) {
 		Strs("required_scopes", scopes).
 		Msg("Registered tools from toolsets")
 }
+
+// registerDynamicTools registers only the discovery tools and enable_toolset,
+// which can load any of the enabled toolsets later in the session.
+func registerDynamicTools(s *mcp.Server, cfg *ToolsetConfig) {
+	registry := toolsets.NewToolsetRegistry()
+	for name, toolset := range toolsets.CreateBuiltinToolsets() {
+		if toolsets.IsToolsetEnabled(cfg.EnabledToolsets, name) {
+			toolset.Tools = cfg.applyDefaultOrg(cfg.filterTools(toolset.Tools))
+			registry.Register(name, toolset)
+		}
+	}
+
+	dynamic := toolsets.NewDynamicToolset(registry, s, cfg.ReadOnly)
+	registry.Register(toolsets.ToolsetDiscovery, dynamic)
+
+	for _, toolDef := range dynamic.Tools {
+		toolDef.Register(s)
+	}
+
+	log.Info().
+		Strs("enabled_toolsets", cfg.EnabledToolsets).
+		Bool("read_only", cfg.ReadOnly).
+		Int("tool_count", len(dynamic.Tools)).
+		Msg("Registered dynamic toolset discovery tools")
+}
@@ -1,12 +1,23 @@
 package server
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/buildkite/buildkite-mcp-server/internal/middleware"
 	"github.com/buildkite/buildkite-mcp-server/pkg/buildkite"
+	"github.com/buildkite/buildkite-mcp-server/pkg/toolsets"
+	gobuildkite "github.com/buildkite/go-buildkite/v5"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
 	"github.com/stretchr/testify/require"
 )
 
@@ -147,3 +158,435 @@ func TestUnauthorizedMiddleware_PassesThroughOnSuccess(t *testing.T) {
 	require.Nil(t, result)
 	require.False(t, called)
 }
+
+func TestNewMCPServer_DynamicToolsets(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+
+	s := NewMCPServer("test", buildkite.ToolDependencies{},
+		WithToolsets("builds", "logs"),
+		WithReadOnly(true),
+		WithDynamicToolsets(true),
+	)
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := s.Connect(ctx, serverTransport, nil)
+	assert.NoError(err)
+	t.Cleanup(func() { _ = serverSession.Close() })
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test", Version: "test"}, nil)
+	clientSession, err := client.Connect(ctx, clientTransport, nil)
+	assert.NoError(err)
+	t.Cleanup(func() { _ = clientSession.Close() })
+
+	toolNames := func() []string {
+		result, err := clientSession.ListTools(ctx, nil)
+		assert.NoError(err)
+		names := make([]string, 0, len(result.Tools))
+		for _, tool := range result.Tools {
+			names = append(names, tool.Name)
+		}
+		return names
+	}
+
+	// Only discovery tools are registered up front.
+	assert.ElementsMatch([]string{"list_toolsets", "search_tools", "describe_tool", "get_required_scopes", "enable_toolset"}, toolNames())
+
+	result, err := clientSession.CallTool(ctx, &mcp.CallToolParams{Name: "enable_toolset", Arguments: map[string]any{"toolset": "builds"}})
+	assert.NoError(err)
+	assert.False(result.IsError)
+	text := result.Content[0].(*mcp.TextContent).Text
+	assert.Contains(text, `"get_build"`)
+	assert.NotContains(text, `"create_build"`)
+
+	names := toolNames()
+	assert.Contains(names, "get_build")
+	assert.NotContains(names, "create_build")
+	assert.NotContains(names, "search_logs")
+
+	// Enabling twice adds nothing new.
+	result, err = clientSession.CallTool(ctx, &mcp.CallToolParams{Name: "enable_toolset", Arguments: map[string]any{"toolset": "builds"}})
+	assert.NoError(err)
+	assert.Contains(result.Content[0].(*mcp.TextContent).Text, `"already_enabled":true`)
+
+	// Toolsets outside the enabled set can't be loaded.
+	result, err = clientSession.CallTool(ctx, &mcp.CallToolParams{Name: "enable_toolset", Arguments: map[string]any{"toolset": "clusters"}})
+	assert.NoError(err)
+	assert.True(result.IsError)
+	assert.Contains(result.Content[0].(*mcp.TextContent).Text, `unknown toolset "clusters"; available toolsets are: builds, discovery, logs`)
+}
+
+func TestNewMCPServer_ToolFilters(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []ToolsetOption
+		want    []string
+		notWant []string
+	}{
+		{
+			name:    "disabled tools are removed from their toolset",
+			opts:    []ToolsetOption{WithToolsets("builds"), WithDisabledTools("create_build")},
+			want:    []string{"get_build", "list_builds", "cancel_build"},
+			notWant: []string{"create_build"},
+		},
+		{
+			name:    "enabled tools limit the selected toolsets",
+			opts:    []ToolsetOption{WithToolsets("builds", "logs"), WithEnabledTools("get_build", "tail_logs")},
+			want:    []string{"get_build", "tail_logs"},
+			notWant: []string{"list_builds", "search_logs"},
+		},
+		{
+			name:    "enabled tools outside the selected toolsets are not added",
+			opts:    []ToolsetOption{WithToolsets("builds"), WithEnabledTools("get_build", "tail_logs")},
+			want:    []string{"get_build"},
+			notWant: []string{"tail_logs"},
+		},
+		{
+			name:    "disable wins over enable",
+			opts:    []ToolsetOption{WithToolsets("builds"), WithEnabledTools("get_build", "list_builds"), WithDisabledTools("list_builds")},
+			want:    []string{"get_build"},
+			notWant: []string{"list_builds"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			toolNames := listToolNames(t, NewMCPServer("test", buildkite.ToolDependencies{}, tt.opts...))
+			for _, name := range tt.want {
+				require.Contains(t, toolNames, name)
+			}
+			for _, name := range tt.notWant {
+				require.NotContains(t, toolNames, name)
+			}
+		})
+	}
+}
+
+func TestToolsetConfig_CacheableTools(t *testing.T) {
+	assert := require.New(t)
+
+	cfg := &ToolsetConfig{EnabledToolsets: []string{"builds"}}
+	cacheable := cfg.cacheableTools()
+	assert.True(cacheable("get_build"))
+	assert.False(cacheable("create_build"), "write tools aren't cached")
+	assert.False(cacheable("wait_for_build"), "waits that timed out go stale")
+	assert.False(cacheable("get_pipeline"), "tools outside the enabled toolsets aren't cached")
+
+	cfg = &ToolsetConfig{EnabledToolsets: []string{"builds"}, DynamicToolsets: true}
+	assert.False(cfg.cacheableTools()("get_build"))
+}
+
+// orgRecordingPipelinesClient records the organization pipelines are listed for.
+type orgRecordingPipelinesClient struct {
+	buildkite.PipelinesClient
+	orgs []string
+}
+
+func (c *orgRecordingPipelinesClient) List(ctx context.Context, org string, options *gobuildkite.PipelineListOptions) ([]gobuildkite.Pipeline, *gobuildkite.Response, error) {
+	c.orgs = append(c.orgs, org)
+	return nil, &gobuildkite.Response{Response: &http.Response{StatusCode: http.StatusOK, Header: http.Header{}}}, nil
+}
+
+func TestNewMCPServer_DefaultOrg(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+
+	pipelines := &orgRecordingPipelinesClient{}
+	s := NewMCPServer("test", buildkite.ToolDependencies{PipelinesClient: pipelines, DefaultOrg: "acme"},
+		WithToolsets("pipelines", "skills"),
+	)
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := s.Connect(ctx, serverTransport, nil)
+	assert.NoError(err)
+	t.Cleanup(func() { _ = serverSession.Close() })
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test", Version: "test"}, nil)
+	clientSession, err := client.Connect(ctx, clientTransport, nil)
+	assert.NoError(err)
+	t.Cleanup(func() { _ = clientSession.Close() })
+
+	tools, err := clientSession.ListTools(ctx, nil)
+	assert.NoError(err)
+	for _, tool := range tools.Tools {
+		if tool.Name != "list_pipelines" {
+			continue
+		}
+		schema, err := json.Marshal(tool.InputSchema)
+		assert.NoError(err)
+		var decoded struct {
+			Required   []string                   `json:"required"`
+			Properties map[string]json.RawMessage `json:"properties"`
+		}
+		assert.NoError(json.Unmarshal(schema, &decoded))
+		assert.NotContains(decoded.Required, "org_slug")
+		assert.Contains(string(decoded.Properties["org_slug"]), `Defaults to \"acme\"`)
+	}
+
+	result, err := clientSession.CallTool(ctx, &mcp.CallToolParams{Name: "list_pipelines", Arguments: map[string]any{}})
+	assert.NoError(err)
+	assert.False(result.IsError)
+
+	result, err = clientSession.CallTool(ctx, &mcp.CallToolParams{Name: "list_pipelines", Arguments: map[string]any{"org_slug": "other"}})
+	assert.NoError(err)
+	assert.False(result.IsError)
+
+	assert.Equal([]string{"acme", "other"}, pipelines.orgs)
+
+	// Tools without org_slug are called unchanged.
+	result, err = clientSession.CallTool(ctx, &mcp.CallToolParams{Name: "list_skills", Arguments: map[string]any{}})
+	assert.NoError(err)
+	assert.False(result.IsError)
+}
+
+func TestNewMCPServer_DefaultOrgSharesCache(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+
+	pipelines := &orgRecordingPipelinesClient{}
+	s := NewMCPServer("test", buildkite.ToolDependencies{
+		PipelinesClient: pipelines,
+		DefaultOrg:      "acme",
+		ResponseCache:   buildkite.NewResponseCache(time.Minute),
+	}, WithToolsets("pipelines"))
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := s.Connect(ctx, serverTransport, nil)
+	assert.NoError(err)
+	t.Cleanup(func() { _ = serverSession.Close() })
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test", Version: "test"}, nil)
+	clientSession, err := client.Connect(ctx, clientTransport, nil)
+	assert.NoError(err)
+	t.Cleanup(func() { _ = clientSession.Close() })
+
+	for _, arguments := range []map[string]any{{}, {"org_slug": "acme"}} {
+		result, err := clientSession.CallTool(ctx, &mcp.CallToolParams{Name: "list_pipelines", Arguments: arguments})
+		assert.NoError(err)
+		assert.False(result.IsError)
+	}
+
+	assert.Equal([]string{"acme"}, pipelines.orgs)
+}
+
+// resourcePipelinesClient serves a single pipeline.
+type resourcePipelinesClient struct {
+	buildkite.PipelinesClient
+}
+
+func (resourcePipelinesClient) List(ctx context.Context, org string, options *gobuildkite.PipelineListOptions) ([]gobuildkite.Pipeline, *gobuildkite.Response, error) {
+	return []gobuildkite.Pipeline{{Slug: "web", Name: "Web"}}, &gobuildkite.Response{Response: &http.Response{StatusCode: http.StatusOK, Header: http.Header{}}}, nil
+}
+
+func (resourcePipelinesClient) Get(ctx context.Context, org, pipelineSlug string) (gobuildkite.Pipeline, *gobuildkite.Response, error) {
+	return gobuildkite.Pipeline{Slug: pipelineSlug, Name: "Web", Repository: "git@github.com:acme/web.git"}, nil, nil
+}
+
+func TestNewMCPServer_PipelineResources(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+
+	s := NewMCPServer("test", buildkite.ToolDependencies{PipelinesClient: resourcePipelinesClient{}, DefaultOrg: "acme"},
+		WithToolsets("pipelines"),
+	)
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := s.Connect(ctx, serverTransport, nil)
+	assert.NoError(err)
+	t.Cleanup(func() { _ = serverSession.Close() })
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test", Version: "test"}, nil)
+	clientSession, err := client.Connect(ctx, clientTransport, nil)
+	assert.NoError(err)
+	t.Cleanup(func() { _ = clientSession.Close() })
+
+	resources, err := clientSession.ListResources(ctx, nil)
+	assert.NoError(err)
+	var uris []string
+	for _, resource := range resources.Resources {
+		uris = append(uris, resource.URI)
+	}
+	assert.Equal([]string{"buildkite://debug-logs-guide", "buildkite://acme/web"}, uris)
+
+	result, err := clientSession.ReadResource(ctx, &mcp.ReadResourceParams{URI: "buildkite://acme/web"})
+	assert.NoError(err)
+	assert.Len(result.Contents, 1)
+	assert.Equal("application/json", result.Contents[0].MIMEType)
+	assert.Contains(result.Contents[0].Text, `"repository":"git@github.com:acme/web.git"`)
+
+	// Without the pipelines toolset, pipelines aren't resources.
+	s = NewMCPServer("test", buildkite.ToolDependencies{PipelinesClient: resourcePipelinesClient{}, DefaultOrg: "acme"},
+		WithToolsets("clusters"),
+	)
+	serverTransport, clientTransport = mcp.NewInMemoryTransports()
+	serverSession, err = s.Connect(ctx, serverTransport, nil)
+	assert.NoError(err)
+	t.Cleanup(func() { _ = serverSession.Close() })
+	clientSession, err = client.Connect(ctx, clientTransport, nil)
+	assert.NoError(err)
+	t.Cleanup(func() { _ = clientSession.Close() })
+
+	resources, err = clientSession.ListResources(ctx, nil)
+	assert.NoError(err)
+	assert.Len(resources.Resources, 1)
+}
+
+// resourceBuildsClient serves builds with the requested number.
+type resourceBuildsClient struct {
+	buildkite.BuildsClient
+}
+
+func (resourceBuildsClient) Get(ctx context.Context, org, pipelineSlug, id string, options *gobuildkite.BuildGetOptions) (gobuildkite.Build, *gobuildkite.Response, error) {
+	return gobuildkite.Build{Number: 7, Message: "Build of " + pipelineSlug}, nil, nil
+}
+
+type emptyAnnotationsClient struct {
+	buildkite.AnnotationsClient
+}
+
+func (emptyAnnotationsClient) ListByBuild(ctx context.Context, org, pipelineSlug, buildNumber string, opts *gobuildkite.AnnotationListOptions) ([]gobuildkite.Annotation, *gobuildkite.Response, error) {
+	return nil, nil, nil
+}
+
+func TestNewMCPServer_BuildResources(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+
+	s := NewMCPServer("test", buildkite.ToolDependencies{
+		BuildsClient:      resourceBuildsClient{},
+		AnnotationsClient: emptyAnnotationsClient{},
+		PipelinesClient:   resourcePipelinesClient{},
+		DefaultOrg:        "acme",
+	}, WithToolsets("builds", "pipelines"))
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := s.Connect(ctx, serverTransport, nil)
+	assert.NoError(err)
+	t.Cleanup(func() { _ = serverSession.Close() })
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test", Version: "test"}, nil)
+	clientSession, err := client.Connect(ctx, clientTransport, nil)
+	assert.NoError(err)
+	t.Cleanup(func() { _ = clientSession.Close() })
+
+	templates, err := clientSession.ListResourceTemplates(ctx, nil)
+	assert.NoError(err)
+	var uriTemplates []string
+	for _, template := range templates.ResourceTemplates {
+		uriTemplates = append(uriTemplates, template.URITemplate)
+	}
+	assert.ElementsMatch([]string{"buildkite://{org}/{pipeline}", "buildkite://{org}/{pipeline}/builds/{number}"}, uriTemplates)
+
+	result, err := clientSession.ReadResource(ctx, &mcp.ReadResourceParams{URI: "buildkite://acme/web/builds/7"})
+	assert.NoError(err)
+	assert.Len(result.Contents, 1)
+	assert.Contains(result.Contents[0].Text, `"message":"Build of web"`)
+}
+
+func TestToolLogsCarryRequestFields(t *testing.T) {
+	assert := require.New(t)
+
+	var logs bytes.Buffer
+	logger := zerolog.New(&logs)
+
+	factory := func(*http.Request) *mcp.Server {
+		s := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "1.0.0"}, nil)
+		s.AddReceivingMiddleware(injectLoggerMiddleware(zerolog.Nop()))
+		mcp.AddTool(s, &mcp.Tool{Name: "log_line"}, func(ctx context.Context, _ *mcp.CallToolRequest, _ any) (*mcp.CallToolResult, any, error) {
+			log.Ctx(ctx).Info().Msg("from tool")
+			return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "ok"}}}, nil, nil
+		})
+		return s
+	}
+	var handler http.Handler = mcp.NewStreamableHTTPHandler(factory, &mcp.StreamableHTTPOptions{Stateless: true})
+	handler = middleware.RequestLog(logger)(handler)
+	handler = middleware.RequestID()(handler)
+	httpServer := httptest.NewServer(handler)
+	defer httpServer.Close()
+
+	httpClient := httpServer.Client()
+	httpClient.Transport = requestIDTransport{next: httpClient.Transport, id: "req-123"}
+	transport := &mcp.StreamableClientTransport{Endpoint: httpServer.URL, HTTPClient: httpClient, DisableStandaloneSSE: true}
+	session, err := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil).Connect(context.Background(), transport, nil)
+	assert.NoError(err)
+	defer session.Close()
+
+	_, err = session.CallTool(context.Background(), &mcp.CallToolParams{Name: "log_line"})
+	assert.NoError(err)
+
+	var toolLine map[string]any
+	for line := range strings.SplitSeq(strings.TrimSpace(logs.String()), "\n") {
+		var entry map[string]any
+		assert.NoError(json.Unmarshal([]byte(line), &entry))
+		if entry["message"] == "from tool" {
+			toolLine = entry
+		}
+	}
+	assert.NotNil(toolLine, logs.String())
+	assert.Equal("req-123", toolLine["request_id"])
+	assert.Equal("127.0.0.1", toolLine["remote_ip"])
+}
+
+func TestInjectLoggerMiddlewareWithoutContextLogger(t *testing.T) {
+	var logs bytes.Buffer
+	handler := injectLoggerMiddleware(zerolog.New(&logs))(func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		log.Ctx(ctx).Info().Msg("from tool")
+		return nil, nil
+	})
+
+	_, err := handler(context.Background(), "tools/call", &mcp.CallToolRequest{})
+	require.NoError(t, err)
+	require.Contains(t, logs.String(), "from tool")
+}
+
+type requestIDTransport struct {
+	next http.RoundTripper
+	id   string
+}
+
+func (t requestIDTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	cloned := req.Clone(req.Context())
+	cloned.Header.Set(middleware.HeaderRequestID, t.id)
+	return t.next.RoundTrip(cloned)
+}
+
+func TestNewMCPServer_PromptsFollowToolsets(t *testing.T) {
+	ctx := context.Background()
+
+	promptNames := func(t *testing.T, enabled ...string) []string {
+		s := NewMCPServer("test", buildkite.ToolDependencies{}, WithToolsets(enabled...))
+
+		serverTransport, clientTransport := mcp.NewInMemoryTransports()
+		serverSession, err := s.Connect(ctx, serverTransport, nil)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = serverSession.Close() })
+
+		client := mcp.NewClient(&mcp.Implementation{Name: "test", Version: "test"}, nil)
+		clientSession, err := client.Connect(ctx, clientTransport, nil)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = clientSession.Close() })
+
+		prompts, err := clientSession.ListPrompts(ctx, nil)
+		require.NoError(t, err)
+		var names []string
+		for _, prompt := range prompts.Prompts {
+			names = append(names, prompt.Name)
+		}
+		return names
+	}
+
+	assert := require.New(t)
+	assert.ElementsMatch([]string{"report_issue", "user_token_organization_prompt", "diagnose_failed_build", "summarize_recent_failures", "find_flaky_test"}, promptNames(t, "all"))
+	assert.Equal([]string{"report_issue"}, promptNames(t, "pipelines"))
+	assert.ElementsMatch([]string{"report_issue", "find_flaky_test"}, promptNames(t, "tests"))
+}
+
+func TestToolPromptsNameRegisteredTools(t *testing.T) {
+	registry := toolsets.NewDefaultRegistry()
+	for _, prompt := range toolPrompts {
+		for _, tool := range prompt.tools {
+			_, ok := registry.GetToolByName(tool)
+			require.True(t, ok, "unknown tool %q", tool)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/buildkite/buildkite-mcp-server/pkg/toolsets"
+)
+
+// TokenPolicy restricts what an HTTP client presenting a particular bearer
+// token can use, overriding the server's default toolsets and read-only mode.
+type TokenPolicy struct {
+	Toolsets []string `json:"toolsets"`
+	ReadOnly bool     `json:"read_only"`
+}
+
+// ParseTokenPolicies parses a JSON object mapping bearer tokens to policies, e.g.
+//
+//	{"<token>": {"toolsets": ["builds", "logs"], "read_only": true}}
+func ParseTokenPolicies(data []byte) (map[string]TokenPolicy, error) {
+	var policies map[string]TokenPolicy
+	if err := json.Unmarshal(data, &policies); err != nil {
+		return nil, fmt.Errorf("failed to parse token policies: %w", err)
+	}
+
+	// Errors describe the policy rather than naming its token, so secrets
+	// don't end up in logs.
+	for token, policy := range policies {
+		if token == "" {
+			return nil, fmt.Errorf("token policies must not have an empty token")
+		}
+		if len(policy.Toolsets) == 0 {
+			return nil, fmt.Errorf("token policy must list at least one toolset")
+		}
+		if err := toolsets.ValidateToolsets(policy.Toolsets); err != nil {
+			return nil, fmt.Errorf("invalid token policy: %w", err)
+		}
+	}
+	return policies, nil
+}
+
+// LoadTokenPolicies reads token policies from a JSON file.
+func LoadTokenPolicies(path string) (map[string]TokenPolicy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token policies: %w", err)
+	}
+	return ParseTokenPolicies(data)
+}
+
+type tokenPolicyContextKey struct{}
+
+// ContextWithTokenPolicy returns a context carrying policy for the per-request
+// server factory.
+func ContextWithTokenPolicy(ctx context.Context, policy TokenPolicy) context.Context {
+	return context.WithValue(ctx, tokenPolicyContextKey{}, policy)
+}
+
+// TokenPolicyFromContext returns the token policy for the current request, if any.
+func TokenPolicyFromContext(ctx context.Context) (TokenPolicy, bool) {
+	policy, ok := ctx.Value(tokenPolicyContextKey{}).(TokenPolicy)
+	return policy, ok
+}
+
+// NewHTTPTokenPolicyHandler wraps an HTTP handler to attach the policy for the
+// request's bearer token to its context. Requests whose token has no policy
+// are passed through unchanged and get the server defaults.
+func NewHTTPTokenPolicyHandler(handler http.Handler, policies map[string]TokenPolicy) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+		if ok && strings.EqualFold(scheme, "Bearer") {
+			if policy, exists := policies[strings.TrimSpace(token)]; exists {
+				r = r.WithContext(ContextWithTokenPolicy(r.Context(), policy))
+			}
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// restrictToolsets narrows requested to the toolsets allowed by a policy.
+func restrictToolsets(requested, allowed []string) []string {
+	if slices.Contains(requested, toolsets.ToolsetAll) {
+		return allowed
+	}
+
+	restricted := []string{}
+	for _, name := range toolsets.ExpandToolsetAliases(requested) {
+		if toolsets.IsToolsetEnabled(allowed, name) {
+			restricted = append(restricted, name)
+		}
+	}
+	return restricted
+}
@@ -0,0 +1,109 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTokenPolicies(t *testing.T) {
+	assert := require.New(t)
+
+	policies, err := ParseTokenPolicies([]byte(`{"team-a": {"toolsets": ["ci"], "read_only": true}, "team-b": {"toolsets": ["all"]}}`))
+	assert.NoError(err)
+	assert.Equal(map[string]TokenPolicy{
+		"team-a": {Toolsets: []string{"ci"}, ReadOnly: true},
+		"team-b": {Toolsets: []string{"all"}},
+	}, policies)
+
+	_, err = ParseTokenPolicies([]byte(`{"secret-token": {"toolsets": ["bulds"]}}`))
+	assert.ErrorContains(err, "invalid token policy: invalid toolset names: [bulds]")
+	assert.NotContains(err.Error(), "secret-token")
+
+	_, err = ParseTokenPolicies([]byte(`{"secret-token": {"read_only": true}}`))
+	assert.ErrorContains(err, "must list at least one toolset")
+
+	_, err = ParseTokenPolicies([]byte(`[`))
+	assert.ErrorContains(err, "failed to parse token policies")
+}
+
+func TestNewHTTPTokenPolicyHandler(t *testing.T) {
+	policies := map[string]TokenPolicy{
+		"team-a": {Toolsets: []string{"builds", "logs"}, ReadOnly: true},
+	}
+	factory := NewPerRequestServerFactory("test", emptyDeps(), []string{"all"}, false)
+
+	var toolNames []string
+	handler := NewHTTPTokenPolicyHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		toolNames = listToolNames(t, factory(r))
+	}), policies)
+
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    []string
+		notWant []string
+	}{
+		{
+			name:    "token with a policy is limited to its toolsets and read-only",
+			headers: map[string]string{"Authorization": "Bearer team-a"},
+			want:    []string{"get_build", "search_logs"},
+			notWant: []string{"create_build", "list_pipelines"},
+		},
+		{
+			name:    "toolsets header narrows the policy",
+			headers: map[string]string{"Authorization": "Bearer team-a", HeaderToolsets: "logs"},
+			want:    []string{"search_logs"},
+			notWant: []string{"get_build"},
+		},
+		{
+			name:    "toolsets header cannot widen the policy",
+			headers: map[string]string{"Authorization": "Bearer team-a", HeaderToolsets: "pipelines,logs"},
+			want:    []string{"search_logs"},
+			notWant: []string{"list_pipelines"},
+		},
+		{
+			name:    "read-only header cannot lift the policy's read-only mode",
+			headers: map[string]string{"Authorization": "Bearer team-a", HeaderReadOnly: "false"},
+			want:    []string{"get_build"},
+			notWant: []string{"create_build"},
+		},
+		{
+			name:    "token without a policy gets the server defaults",
+			headers: map[string]string{"Authorization": "Bearer someone-else"},
+			want:    []string{"create_build", "list_pipelines"},
+		},
+		{
+			name: "no token gets the server defaults",
+			want: []string{"create_build", "list_pipelines"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+			for key, value := range tt.headers {
+				req.Header.Set(key, value)
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			for _, name := range tt.want {
+				require.Contains(t, toolNames, name)
+			}
+			for _, name := range tt.notWant {
+				require.NotContains(t, toolNames, name)
+			}
+		})
+	}
+}
+
+func TestRestrictToolsets(t *testing.T) {
+	assert := require.New(t)
+
+	assert.Equal([]string{"builds"}, restrictToolsets([]string{"builds", "clusters"}, []string{"ci"}))
+	assert.Equal([]string{"ci"}, restrictToolsets([]string{"all"}, []string{"ci"}))
+	assert.Equal([]string{"clusters"}, restrictToolsets([]string{"clusters"}, []string{"all"}))
+	assert.Empty(restrictToolsets([]string{"clusters"}, []string{"builds"}))
+}
@@ -0,0 +1,93 @@
+package toolsets
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/buildkite/buildkite-mcp-server/pkg/trace"
+	"github.com/buildkite/buildkite-mcp-server/pkg/utils"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type EnableToolsetArgs struct {
+	Toolset string `json:"toolset" jsonschema:"Name of the toolset to enable (e.g. builds, logs). See list_toolsets for names"`
+}
+
+// EnableToolsetResult reports the tools enable_toolset added to the session.
+type EnableToolsetResult struct {
+	Toolset        string   `json:"toolset"`
+	AddedTools     []string `json:"added_tools"`
+	AlreadyEnabled bool     `json:"already_enabled,omitempty"`
+}
+
+// EnableToolset returns the enable_toolset tool, which registers a toolset
+// from registry on the running server s. In readOnly mode only the toolset's
+// read-only tools are added. Toolsets named in enabled are reported as
+// already enabled rather than registered again.
+func EnableToolset(registry *ToolsetRegistry, s *mcp.Server, readOnly bool, enabled ...string) (mcp.Tool, mcp.ToolHandlerFor[EnableToolsetArgs, any], []string) {
+	var mu sync.Mutex
+	enabledToolsets := make(map[string]bool, len(enabled))
+	for _, name := range enabled {
+		enabledToolsets[name] = true
+	}
+
+	return mcp.Tool{
+			Name:        "enable_toolset",
+			Description: "Add the tools from a toolset to this session so they can be called. Use list_toolsets or search_tools to find the toolset you need first. Returns the names of the tools that were added",
+			Annotations: &mcp.ToolAnnotations{
+				Title:          "Enable Toolset",
+				IdempotentHint: true,
+			},
+		},
+		func(ctx context.Context, request *mcp.CallToolRequest, args EnableToolsetArgs) (*mcp.CallToolResult, any, error) {
+			_, span := trace.Start(ctx, "toolsets.EnableToolset")
+			defer span.End()
+
+			span.SetAttributes(
+				attribute.String("toolset", args.Toolset),
+				attribute.Bool("read_only", readOnly),
+			)
+
+			toolset, ok := registry.Get(args.Toolset)
+			if !ok {
+				return utils.NewToolResultError(fmt.Sprintf("unknown toolset %q; available toolsets are: %s", args.Toolset, strings.Join(registry.List(), ", "))), nil, nil
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if enabledToolsets[args.Toolset] {
+				return jsonToolResult(ctx, EnableToolsetResult{Toolset: args.Toolset, AddedTools: []string{}, AlreadyEnabled: true})
+			}
+
+			tools := toolset.GetAllTools()
+			if readOnly {
+				tools = toolset.GetReadOnlyTools()
+			}
+
+			added := make([]string, 0, len(tools))
+			for _, tool := range tools {
+				tool.Register(s)
+				added = append(added, tool.Tool.Name)
+			}
+			enabledToolsets[args.Toolset] = true
+
+			span.SetAttributes(attribute.Int("item_count", len(added)))
+
+			return jsonToolResult(ctx, EnableToolsetResult{Toolset: args.Toolset, AddedTools: added})
+		}, []string{}
+}
+
+// NewDynamicToolset builds the discovery toolset together with
+// enable_toolset, for servers that start with only these tools and let the
+// client load the rest of registry on demand.
+func NewDynamicToolset(registry *ToolsetRegistry, s *mcp.Server, readOnly bool) Toolset {
+	toolset := NewDiscoveryToolset(registry)
+	toolset.Tools = append(toolset.Tools, newToolDef(func() (mcp.Tool, mcp.ToolHandlerFor[EnableToolsetArgs, any], []string) {
+		return EnableToolset(registry, s, readOnly, ToolsetDiscovery)
+	}))
+	return toolset
+}
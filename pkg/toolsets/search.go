@@ -0,0 +1,386 @@
+package toolsets
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/buildkite/buildkite-mcp-server/pkg/buildkite"
+	"github.com/buildkite/buildkite-mcp-server/pkg/trace"
+	"github.com/buildkite/buildkite-mcp-server/pkg/utils"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const defaultSearchToolsLimit = 10
+
+// SearchOptions controls SearchToolsWithMetadata.
+type SearchOptions struct {
+	// Limit caps the number of results. Zero means no limit.
+	Limit int
+	// Fuzzy enables typo-tolerant matching when no tool contains the query.
+	Fuzzy bool
+	// Toolset restricts the search to one toolset. Empty or ToolsetAll
+	// searches every toolset.
+	Toolset string
+}
+
+// toolsetNames returns the names of the toolsets a search covers. The caller
+// must hold tr.mu.
+func (opts SearchOptions) toolsetNames(tr *ToolsetRegistry) []string {
+	if opts.Toolset == "" || opts.Toolset == ToolsetAll {
+		return tr.names()
+	}
+	return []string{opts.Toolset}
+}
+
+// Relevance scores for substring matches. Fuzzy matches score below all of
+// these, by edit distance.
+const (
+	searchScoreExactName   = 100
+	searchScoreNamePrefix  = 75
+	searchScoreName        = 50
+	searchScoreDescription = 25
+	searchScoreFuzzy       = 10
+)
+
+// SearchResult describes a tool matched by SearchToolsWithMetadata.
+type SearchResult struct {
+	Name        string `json:"name"`
+	ToolsetName string `json:"toolset"`
+	Description string `json:"description"`
+	ReadOnly    bool   `json:"read_only"`
+	Score       int    `json:"score"`
+}
+
+// SearchToolsWithMetadata finds tools whose name or description contains
+// query, case-insensitively, most relevant first: an exact name match, then
+// names starting with the query, other name matches, and description matches,
+// with ties broken by name and then toolset. A tool registered in several
+// toolsets is returned once for each. When nothing matches and opts.Fuzzy is set, tools
+// are instead matched by edit distance against the words in their name and
+// description, closest first.
+func (tr *ToolsetRegistry) SearchToolsWithMetadata(query string, opts SearchOptions) []SearchResult {
+	query = strings.ToLower(strings.TrimSpace(query))
+
+	tr.mu.RLock()
+	defer tr.mu.RUnlock()
+
+	var results []SearchResult
+	for _, toolsetName := range opts.toolsetNames(tr) {
+		for _, tool := range tr.toolsets[toolsetName].Tools {
+			if score := substringScore(query, tool); score > 0 {
+				result := newSearchResult(toolsetName, tool)
+				result.Score = score
+				results = append(results, result)
+			}
+		}
+	}
+	sortSearchResults(results)
+
+	if len(results) == 0 && opts.Fuzzy && query != "" {
+		results = tr.fuzzySearchTools(query, opts)
+	}
+
+	if opts.Limit > 0 && len(results) > opts.Limit {
+		results = results[:opts.Limit]
+	}
+	return results
+}
+
+func substringScore(query string, tool ToolDefinition) int {
+	name := strings.ToLower(tool.Tool.Name)
+	switch {
+	case name == query:
+		return searchScoreExactName
+	case strings.HasPrefix(name, query):
+		return searchScoreNamePrefix
+	case strings.Contains(name, query):
+		return searchScoreName
+	case strings.Contains(strings.ToLower(tool.Tool.Description), query):
+		return searchScoreDescription
+	}
+	return 0
+}
+
+func sortSearchResults(results []SearchResult) {
+	slices.SortFunc(results, func(a, b SearchResult) int {
+		if a.Score != b.Score {
+			return b.Score - a.Score
+		}
+		if a.Name != b.Name {
+			return strings.Compare(a.Name, b.Name)
+		}
+		return strings.Compare(a.ToolsetName, b.ToolsetName)
+	})
+}
+
+func newSearchResult(toolsetName string, tool ToolDefinition) SearchResult {
+	return SearchResult{
+		Name:        tool.Tool.Name,
+		ToolsetName: toolsetName,
+		Description: tool.Tool.Description,
+		ReadOnly:    tool.IsReadOnly(),
+	}
+}
+
+// fuzzySearchTools ranks tools by the smallest edit distance between query
+// and any word in the tool's name or description. A word longer than the
+// query is also compared by its prefix, so "anotate" reaches "annotations".
+// The caller must hold tr.mu.
+func (tr *ToolsetRegistry) fuzzySearchTools(query string, opts SearchOptions) []SearchResult {
+	maxDistance := max(1, len([]rune(query))/3)
+
+	var results []SearchResult
+	for _, toolsetName := range opts.toolsetNames(tr) {
+		for _, tool := range tr.toolsets[toolsetName].Tools {
+			distance := maxDistance + 1
+			for _, word := range searchWords(tool.Tool.Name + " " + tool.Tool.Description) {
+				distance = min(distance, wordDistance(query, word))
+			}
+			if distance <= maxDistance {
+				result := newSearchResult(toolsetName, tool)
+				result.Score = searchScoreFuzzy - distance
+				results = append(results, result)
+			}
+		}
+	}
+	sortSearchResults(results)
+	return results
+}
+
+// searchWords splits text into lowercase words on anything that isn't a
+// letter or digit, so tool names split on underscores.
+func searchWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !('a' <= r && r <= 'z' || '0' <= r && r <= '9')
+	})
+}
+
+func wordDistance(query, word string) int {
+	distance := levenshtein(query, word)
+	if q, w := []rune(query), []rune(word); len(w) > len(q) {
+		distance = min(distance, levenshtein(query, string(w[:len(q)])))
+	}
+	return distance
+}
+
+func levenshtein(a, b string) int {
+	ar, br := []rune(a), []rune(b)
+	prev := make([]int, len(br)+1)
+	curr := make([]int, len(br)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ar); i++ {
+		curr[0] = i
+		for j := 1; j <= len(br); j++ {
+			cost := 1
+			if ar[i-1] == br[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(br)]
+}
+
+type ToolSearchArgs struct {
+	Query   string `json:"query" jsonschema:"Text to look for in tool names and descriptions (e.g. annotation, retry build)"`
+	Limit   int    `json:"limit,omitempty" jsonschema:"Maximum number of tools to return (default 10)"`
+	Fuzzy   bool   `json:"fuzzy,omitempty" jsonschema:"When nothing contains the query, fall back to typo-tolerant matching (e.g. piplines finds pipeline tools)"`
+	Toolset string `json:"toolset,omitempty" jsonschema:"Only search tools in this toolset (e.g. builds, logs). See list_toolsets for names"`
+}
+
+// ToolSearch returns the search_tools tool, which searches the tools in registry.
+func ToolSearch(registry *ToolsetRegistry) (mcp.Tool, mcp.ToolHandlerFor[ToolSearchArgs, any], []string) {
+	return mcp.Tool{
+			Name:        "search_tools",
+			Description: "Search the server's tools by name and description. Returns matching tools most relevant first, each with its name, toolset, description, whether it is read-only, and a relevance score. Use fuzzy: true to tolerate typos, and toolset to search within one toolset",
+			Annotations: &mcp.ToolAnnotations{
+				Title:        "Search Tools",
+				ReadOnlyHint: true,
+			},
+		},
+		func(ctx context.Context, request *mcp.CallToolRequest, args ToolSearchArgs) (*mcp.CallToolResult, any, error) {
+			_, span := trace.Start(ctx, "toolsets.ToolSearch")
+			defer span.End()
+
+			if args.Limit <= 0 {
+				args.Limit = defaultSearchToolsLimit
+			}
+
+			span.SetAttributes(
+				attribute.String("query", args.Query),
+				attribute.Int("limit", args.Limit),
+				attribute.Bool("fuzzy", args.Fuzzy),
+				attribute.String("toolset", args.Toolset),
+			)
+
+			if strings.TrimSpace(args.Query) == "" {
+				return utils.NewToolResultError("query is required"), nil, nil
+			}
+			if args.Toolset != "" && !IsValidToolset(args.Toolset) {
+				return utils.NewToolResultError(fmt.Sprintf("invalid toolset %q; valid toolsets are: %s", args.Toolset, strings.Join(ValidToolsets, ", "))), nil, nil
+			}
+
+			results := registry.SearchToolsWithMetadata(args.Query, SearchOptions{
+				Limit:   args.Limit,
+				Fuzzy:   args.Fuzzy,
+				Toolset: args.Toolset,
+			})
+
+			span.SetAttributes(attribute.Int("item_count", len(results)))
+
+			return jsonToolResult(ctx, map[string]any{"tools": results})
+		}, []string{}
+}
+
+type DescribeToolArgs struct {
+	Name string `json:"name" jsonschema:"Exact tool name, e.g. from search_tools"`
+}
+
+// ToolDescription is the full definition of a tool, as returned by describe_tool.
+type ToolDescription struct {
+	Name           string               `json:"name"`
+	ToolsetName    string               `json:"toolset"`
+	Description    string               `json:"description"`
+	InputSchema    any                  `json:"input_schema,omitempty"`
+	Annotations    *mcp.ToolAnnotations `json:"annotations,omitempty"`
+	ReadOnly       bool                 `json:"read_only"`
+	RequiredScopes []string             `json:"required_scopes"`
+}
+
+// DescribeTool returns the describe_tool tool, which reports the full
+// definition of one tool in registry.
+func DescribeTool(registry *ToolsetRegistry) (mcp.Tool, mcp.ToolHandlerFor[DescribeToolArgs, any], []string) {
+	return mcp.Tool{
+			Name:        "describe_tool",
+			Description: "Get the full definition of a tool by name: its input schema (parameters, types, and which are required), annotations, toolset, and the API token scopes it needs",
+			Annotations: &mcp.ToolAnnotations{
+				Title:        "Describe Tool",
+				ReadOnlyHint: true,
+			},
+		},
+		func(ctx context.Context, request *mcp.CallToolRequest, args DescribeToolArgs) (*mcp.CallToolResult, any, error) {
+			_, span := trace.Start(ctx, "toolsets.DescribeTool")
+			defer span.End()
+
+			span.SetAttributes(attribute.String("name", args.Name))
+
+			toolsetName, tool, ok := registry.lookupTool(args.Name)
+			if !ok {
+				return utils.NewToolResultError(fmt.Sprintf("tool %q not found; use search_tools to find tool names", args.Name)), nil, nil
+			}
+
+			return jsonToolResult(ctx, ToolDescription{
+				Name:           tool.Tool.Name,
+				ToolsetName:    toolsetName,
+				Description:    tool.Tool.Description,
+				InputSchema:    tool.Tool.InputSchema,
+				Annotations:    tool.Tool.Annotations,
+				ReadOnly:       tool.IsReadOnly(),
+				RequiredScopes: tool.RequiredScopes,
+			})
+		}, []string{}
+}
+
+// ListToolsets returns the list_toolsets tool, which describes the toolsets in registry.
+func ListToolsets(registry *ToolsetRegistry) (mcp.Tool, mcp.ToolHandlerFor[struct{}, any], []string) {
+	return mcp.Tool{
+			Name:        "list_toolsets",
+			Description: "List the server's toolsets with their descriptions and tool counts",
+			Annotations: &mcp.ToolAnnotations{
+				Title:        "List Toolsets",
+				ReadOnlyHint: true,
+			},
+		},
+		func(ctx context.Context, request *mcp.CallToolRequest, args struct{}) (*mcp.CallToolResult, any, error) {
+			_, span := trace.Start(ctx, "toolsets.ListToolsets")
+			defer span.End()
+
+			return jsonToolResult(ctx, map[string]any{"toolsets": registry.GetMetadata()})
+		}, []string{}
+}
+
+type GetRequiredScopesArgs struct {
+	Toolsets []string `json:"toolsets" jsonschema:"Toolset names to check (e.g. builds, logs), or all. See list_toolsets for names"`
+	ReadOnly bool     `json:"read_only,omitempty" jsonschema:"Only count the read-only tools, as the server does in read-only mode"`
+}
+
+// GetRequiredScopes returns the get_required_scopes tool, which reports the
+// API token scopes needed by a selection of toolsets in registry.
+func GetRequiredScopes(registry *ToolsetRegistry) (mcp.Tool, mcp.ToolHandlerFor[GetRequiredScopesArgs, any], []string) {
+	return mcp.Tool{
+			Name:        "get_required_scopes",
+			Description: "List the Buildkite API token scopes needed to use every tool in the given toolsets, deduplicated and sorted. Set read_only to match a server running in read-only mode",
+			Annotations: &mcp.ToolAnnotations{
+				Title:        "Get Required Scopes",
+				ReadOnlyHint: true,
+			},
+		},
+		func(ctx context.Context, request *mcp.CallToolRequest, args GetRequiredScopesArgs) (*mcp.CallToolResult, any, error) {
+			_, span := trace.Start(ctx, "toolsets.GetRequiredScopes")
+			defer span.End()
+
+			span.SetAttributes(
+				attribute.StringSlice("toolsets", args.Toolsets),
+				attribute.Bool("read_only", args.ReadOnly),
+			)
+
+			if len(args.Toolsets) == 0 {
+				return utils.NewToolResultError("toolsets is required"), nil, nil
+			}
+			if err := ValidateToolsets(args.Toolsets); err != nil {
+				return utils.NewToolResultError(fmt.Sprintf("%v; valid toolsets are: %s", err, strings.Join(ValidToolsets, ", "))), nil, nil
+			}
+
+			scopes := registry.GetRequiredScopes(args.Toolsets, args.ReadOnly)
+
+			span.SetAttributes(attribute.Int("item_count", len(scopes)))
+
+			return jsonToolResult(ctx, map[string]any{"scopes": scopes})
+		}, []string{}
+}
+
+func jsonToolResult(ctx context.Context, result any) (*mcp.CallToolResult, any, error) {
+	b, err := buildkite.MarshalResult(ctx, result)
+	if err != nil {
+		return utils.NewToolResultError(err.Error()), nil, nil
+	}
+	return utils.NewToolResultText(string(b)), nil, nil
+}
+
+// NewDiscoveryToolset builds the toolset of tools that describe registry
+// itself. It is separate from CreateBuiltinToolsets because its tools need
+// the registry they search.
+func NewDiscoveryToolset(registry *ToolsetRegistry) Toolset {
+	return Toolset{
+		Name:        "Tool Discovery",
+		Description: "Tools for finding other tools and toolsets on this server",
+		Tools: []ToolDefinition{
+			newRegistryToolDef(registry, ListToolsets),
+			newRegistryToolDef(registry, ToolSearch),
+			newRegistryToolDef(registry, DescribeTool),
+			newRegistryToolDef(registry, GetRequiredScopes),
+		},
+	}
+}
+
+// newRegistryToolDef is newToolDef for tools constructed from a registry.
+func newRegistryToolDef[In, Out any](registry *ToolsetRegistry, toolFunc func(*ToolsetRegistry) (mcp.Tool, mcp.ToolHandlerFor[In, Out], []string)) ToolDefinition {
+	return newToolDef(func() (mcp.Tool, mcp.ToolHandlerFor[In, Out], []string) {
+		return toolFunc(registry)
+	})
+}
+
+// NewDefaultRegistry returns a registry holding the builtin toolsets and the
+// discovery toolset that searches them.
+func NewDefaultRegistry() *ToolsetRegistry {
+	registry := NewToolsetRegistry()
+	registry.RegisterToolsets(CreateBuiltinToolsets())
+	registry.Register(ToolsetDiscovery, NewDiscoveryToolset(registry))
+	return registry
+}
@@ -0,0 +1,295 @@
+package toolsets
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/require"
+)
+
+func newSearchTestRegistry() *ToolsetRegistry {
+	tool := func(name, description string, readOnly bool) ToolDefinition {
+		return ToolDefinition{Tool: mcp.Tool{
+			Name:        name,
+			Description: description,
+			Annotations: &mcp.ToolAnnotations{ReadOnlyHint: readOnly},
+		}}
+	}
+
+	registry := NewToolsetRegistry()
+	registry.Register("annotations", Toolset{Tools: []ToolDefinition{
+		tool("list_annotations", "List annotations on a build", true),
+		tool("create_annotation", "Create an annotation on a build", false),
+	}})
+	registry.Register("builds", Toolset{Tools: []ToolDefinition{
+		tool("get_build", "Get a build", true),
+	}})
+	registry.Register("pipelines", Toolset{Tools: []ToolDefinition{
+		tool("list_pipelines", "List pipelines in an organization", true),
+		tool("get_pipeline", "Get a pipeline", true),
+	}})
+	return registry
+}
+
+func searchResultNames(results []SearchResult) []string {
+	names := make([]string, len(results))
+	for i, r := range results {
+		names[i] = r.Name
+	}
+	return names
+}
+
+func TestSearchToolsWithMetadata_Substring(t *testing.T) {
+	assert := require.New(t)
+	registry := newSearchTestRegistry()
+
+	results := registry.SearchToolsWithMetadata("Annotation", SearchOptions{})
+	assert.Equal([]string{"create_annotation", "list_annotations"}, searchResultNames(results))
+	assert.Equal("annotations", results[0].ToolsetName)
+	assert.False(results[0].ReadOnly)
+	assert.True(results[1].ReadOnly)
+
+	// Description matches count too.
+	assert.Equal([]string{"list_pipelines"}, searchResultNames(registry.SearchToolsWithMetadata("organization", SearchOptions{})))
+
+	assert.Len(registry.SearchToolsWithMetadata("list", SearchOptions{Limit: 1}), 1)
+}
+
+func TestSearchToolsWithMetadata_Relevance(t *testing.T) {
+	assert := require.New(t)
+	registry := newSearchTestRegistry()
+
+	// Name matches rank above description matches, which tie alphabetically.
+	results := registry.SearchToolsWithMetadata("build", SearchOptions{})
+	assert.Equal([]string{"get_build", "create_annotation", "list_annotations"}, searchResultNames(results))
+	assert.Greater(results[0].Score, results[1].Score)
+	assert.Equal(results[1].Score, results[2].Score)
+
+	// An exact name beats a prefix match, which beats a match mid-name.
+	registry.Register("extra", Toolset{Tools: []ToolDefinition{
+		{Tool: mcp.Tool{Name: "get_pipeline_schedule"}},
+	}})
+	results = registry.SearchToolsWithMetadata("get_pipeline", SearchOptions{})
+	assert.Equal([]string{"get_pipeline", "get_pipeline_schedule"}, searchResultNames(results))
+	assert.Equal(searchScoreExactName, results[0].Score)
+	assert.Equal(searchScoreNamePrefix, results[1].Score)
+
+	results = registry.SearchToolsWithMetadata("pipeline", SearchOptions{})
+	assert.Equal([]string{"get_pipeline", "get_pipeline_schedule", "list_pipelines"}, searchResultNames(results))
+}
+
+// TestSearchToolsWithMetadata_LimitAfterSort guards against applying the
+// limit while collecting matches toolset by toolset, which would return the
+// first N matches found rather than the top N.
+func TestSearchToolsWithMetadata_LimitAfterSort(t *testing.T) {
+	assert := require.New(t)
+
+	registry := NewToolsetRegistry()
+	registry.Register("a_first", Toolset{Tools: []ToolDefinition{
+		{Tool: mcp.Tool{Name: "zeta_deploy"}},
+		{Tool: mcp.Tool{Name: "yankee_deploy"}},
+	}})
+	registry.Register("b_second", Toolset{Tools: []ToolDefinition{
+		{Tool: mcp.Tool{Name: "alpha_deploy"}},
+	}})
+	registry.Register("c_third", Toolset{Tools: []ToolDefinition{
+		{Tool: mcp.Tool{Name: "deploy"}},
+	}})
+
+	// The exact match lives in the last toolset searched.
+	results := registry.SearchToolsWithMetadata("deploy", SearchOptions{Limit: 1})
+	assert.Equal([]string{"deploy"}, searchResultNames(results))
+
+	// Equal scores are cut alphabetically across toolsets, not by toolset order.
+	results = registry.SearchToolsWithMetadata("_deploy", SearchOptions{Limit: 2})
+	assert.Equal([]string{"alpha_deploy", "yankee_deploy"}, searchResultNames(results))
+}
+
+func TestSearchToolsWithMetadata_Fuzzy(t *testing.T) {
+	assert := require.New(t)
+	registry := newSearchTestRegistry()
+
+	// Without fuzzy, a typo finds nothing.
+	assert.Empty(registry.SearchToolsWithMetadata("anotate", SearchOptions{}))
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{query: "anotate", want: []string{"create_annotation", "list_annotations"}},
+		{query: "piplines", want: []string{"list_pipelines", "get_pipeline"}},
+		{query: "pipelnie", want: []string{"get_pipeline", "list_pipelines"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			results := registry.SearchToolsWithMetadata(tt.query, SearchOptions{Fuzzy: true})
+			require.Equal(t, tt.want, searchResultNames(results))
+		})
+	}
+
+	// Unrelated queries stay empty rather than matching everything.
+	assert.Empty(registry.SearchToolsWithMetadata("kubernetes", SearchOptions{Fuzzy: true}))
+
+	// Exact matches never fall through to fuzzy ranking.
+	assert.Equal([]string{"get_pipeline"}, searchResultNames(registry.SearchToolsWithMetadata("get_pipeline", SearchOptions{Fuzzy: true})))
+}
+
+func TestSearchToolsWithMetadata_Toolset(t *testing.T) {
+	assert := require.New(t)
+	registry := newSearchTestRegistry()
+
+	// "build" appears in the annotations toolset's descriptions too.
+	results := registry.SearchToolsWithMetadata("build", SearchOptions{Toolset: "builds"})
+	assert.Equal([]string{"get_build"}, searchResultNames(results))
+
+	// The filter also applies to fuzzy matches.
+	results = registry.SearchToolsWithMetadata("piplines", SearchOptions{Fuzzy: true, Toolset: "annotations"})
+	assert.Empty(results)
+
+	// "all" searches everything.
+	assert.Len(registry.SearchToolsWithMetadata("build", SearchOptions{Toolset: ToolsetAll}), 3)
+}
+
+func TestLevenshtein(t *testing.T) {
+	assert := require.New(t)
+	assert.Equal(0, levenshtein("build", "build"))
+	assert.Equal(1, levenshtein("piplines", "pipelines"))
+	assert.Equal(3, levenshtein("kitten", "sitting"))
+	assert.Equal(5, levenshtein("", "agent"))
+}
+
+func TestToolSearch(t *testing.T) {
+	assert := require.New(t)
+	_, handler, _ := ToolSearch(newSearchTestRegistry())
+
+	result, _, err := handler(context.Background(), &mcp.CallToolRequest{}, ToolSearchArgs{Query: "piplines", Fuzzy: true})
+	assert.NoError(err)
+	assert.False(result.IsError)
+
+	var got struct {
+		Tools []SearchResult `json:"tools"`
+	}
+	assert.NoError(json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &got))
+	assert.Equal([]string{"list_pipelines", "get_pipeline"}, searchResultNames(got.Tools))
+
+	result, _, err = handler(context.Background(), &mcp.CallToolRequest{}, ToolSearchArgs{Query: " "})
+	assert.NoError(err)
+	assert.True(result.IsError)
+
+	result, _, err = handler(context.Background(), &mcp.CallToolRequest{}, ToolSearchArgs{Query: "build", Toolset: "bulds"})
+	assert.NoError(err)
+	assert.True(result.IsError)
+	assert.Contains(result.Content[0].(*mcp.TextContent).Text, `invalid toolset "bulds"; valid toolsets are: all, clusters`)
+}
+
+func TestToolSearch_SharedToolName(t *testing.T) {
+	assert := require.New(t)
+
+	registry := NewToolsetRegistry()
+	for _, toolsetName := range []string{"pipelines", "builds"} {
+		registry.Register(toolsetName, Toolset{Tools: []ToolDefinition{
+			{Tool: mcp.Tool{Name: "get_status", Description: "Status from " + toolsetName}},
+		}})
+	}
+	_, handler, _ := ToolSearch(registry)
+
+	result, _, err := handler(context.Background(), &mcp.CallToolRequest{}, ToolSearchArgs{Query: "get_status"})
+	assert.NoError(err)
+
+	var got struct {
+		Tools []SearchResult `json:"tools"`
+	}
+	assert.NoError(json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &got))
+
+	// Each result reports the toolset it was found in.
+	assert.Len(got.Tools, 2)
+	assert.Equal("builds", got.Tools[0].ToolsetName)
+	assert.Equal("Status from builds", got.Tools[0].Description)
+	assert.Equal("pipelines", got.Tools[1].ToolsetName)
+	assert.Equal("Status from pipelines", got.Tools[1].Description)
+}
+
+func TestDescribeTool(t *testing.T) {
+	assert := require.New(t)
+	registry := NewDefaultRegistry()
+	_, handler, _ := DescribeTool(registry)
+
+	result, _, err := handler(context.Background(), &mcp.CallToolRequest{}, DescribeToolArgs{Name: "search_logs"})
+	assert.NoError(err)
+	assert.False(result.IsError)
+
+	var got struct {
+		ToolDescription
+		InputSchema struct {
+			Type       string         `json:"type"`
+			Required   []string       `json:"required"`
+			Properties map[string]any `json:"properties"`
+		} `json:"input_schema"`
+	}
+	assert.NoError(json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &got))
+	assert.Equal("search_logs", got.Name)
+	assert.Equal(ToolsetLogs, got.ToolsetName)
+	assert.True(got.ReadOnly)
+	assert.Equal([]string{"read_build_logs"}, got.RequiredScopes)
+	assert.Equal("object", got.InputSchema.Type)
+	assert.Contains(got.InputSchema.Required, "pattern")
+	assert.Contains(got.InputSchema.Properties, "limit")
+
+	result, _, err = handler(context.Background(), &mcp.CallToolRequest{}, DescribeToolArgs{Name: "no_such_tool"})
+	assert.NoError(err)
+	assert.True(result.IsError)
+	assert.Contains(result.Content[0].(*mcp.TextContent).Text, "not found")
+}
+
+func TestGetRequiredScopesTool(t *testing.T) {
+	assert := require.New(t)
+	registry := NewToolsetRegistry()
+	registry.Register("builds", Toolset{Tools: []ToolDefinition{
+		{Tool: mcp.Tool{Name: "get_build", Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true}}, RequiredScopes: []string{"read_builds"}},
+		{Tool: mcp.Tool{Name: "create_build"}, RequiredScopes: []string{"write_builds", "read_builds"}},
+	}})
+	registry.Register("logs", Toolset{Tools: []ToolDefinition{
+		{Tool: mcp.Tool{Name: "read_logs", Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true}}, RequiredScopes: []string{"read_build_logs"}},
+	}})
+	_, handler, _ := GetRequiredScopes(registry)
+
+	scopes := func(args GetRequiredScopesArgs) []string {
+		result, _, err := handler(context.Background(), &mcp.CallToolRequest{}, args)
+		assert.NoError(err)
+		assert.False(result.IsError)
+
+		var got struct {
+			Scopes []string `json:"scopes"`
+		}
+		assert.NoError(json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &got))
+		return got.Scopes
+	}
+
+	assert.Equal([]string{"read_build_logs", "read_builds", "write_builds"}, scopes(GetRequiredScopesArgs{Toolsets: []string{"builds", "logs"}}))
+	assert.Equal([]string{"read_builds"}, scopes(GetRequiredScopesArgs{Toolsets: []string{"builds"}, ReadOnly: true}))
+	assert.Equal([]string{"read_build_logs", "read_builds", "write_builds"}, scopes(GetRequiredScopesArgs{Toolsets: []string{ToolsetAll}}))
+
+	result, _, err := handler(context.Background(), &mcp.CallToolRequest{}, GetRequiredScopesArgs{Toolsets: []string{"bulds"}})
+	assert.NoError(err)
+	assert.True(result.IsError)
+	assert.Contains(result.Content[0].(*mcp.TextContent).Text, "invalid toolset names: [bulds]")
+
+	result, _, err = handler(context.Background(), &mcp.CallToolRequest{}, GetRequiredScopesArgs{})
+	assert.NoError(err)
+	assert.True(result.IsError)
+}
+
+func TestNewDefaultRegistry_IncludesDiscovery(t *testing.T) {
+	assert := require.New(t)
+	registry := NewDefaultRegistry()
+
+	discovery, ok := registry.Get(ToolsetDiscovery)
+	assert.True(ok)
+	assert.Len(discovery.Tools, 4)
+
+	// The discovery tools can find builtin tools through the shared registry.
+	results := registry.SearchToolsWithMetadata("search_logs", SearchOptions{})
+	assert.Equal("logs", results[0].ToolsetName)
+}
@@ -3,8 +3,10 @@ package toolsets
 import (
 	"fmt"
 	"slices"
+	"sync"
 
 	"github.com/buildkite/buildkite-mcp-server/pkg/buildkite"
+	"github.com/google/jsonschema-go/jsonschema"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
@@ -64,39 +66,97 @@ func (ts Toolset) GetRequiredScopes() []string {
 }
 
 // ToolsetRegistry manages the registration and discovery of toolsets.
-// It is safe for concurrent reads after initialization, but concurrent
-// writes are not supported. In typical usage, the registry is populated
-// once at server startup via RegisterToolsets and then only read.
+// It is safe for concurrent use, so toolsets can be registered while the
+// registry is being searched, e.g. by tools that enable toolsets at runtime.
 type ToolsetRegistry struct {
+	mu       sync.RWMutex
 	toolsets map[string]Toolset
+	tools    map[string]toolRef // tool name index, rebuilt on registration
+}
+
+// toolRef locates a tool in the registry.
+type toolRef struct {
+	toolsetName string
+	tool        ToolDefinition
 }
 
 // NewToolsetRegistry creates a new toolset registry
 func NewToolsetRegistry() *ToolsetRegistry {
 	return &ToolsetRegistry{
 		toolsets: make(map[string]Toolset),
+		tools:    make(map[string]toolRef),
 	}
 }
 
 // Register adds a toolset to the registry
 func (tr *ToolsetRegistry) Register(name string, toolset Toolset) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+
 	tr.toolsets[name] = toolset
+	tr.reindexTools()
 }
 
 func (tr *ToolsetRegistry) RegisterToolsets(toolsets map[string]Toolset) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+
 	for name, toolset := range toolsets {
-		tr.Register(name, toolset)
+		tr.toolsets[name] = toolset
 	}
+	tr.reindexTools()
+}
+
+// reindexTools rebuilds the tool name index. Toolsets are indexed in name
+// order, so a tool name shared by two toolsets resolves to the first. The
+// caller must hold tr.mu for writing.
+func (tr *ToolsetRegistry) reindexTools() {
+	clear(tr.tools)
+	for _, toolsetName := range tr.names() {
+		for _, tool := range tr.toolsets[toolsetName].Tools {
+			if _, exists := tr.tools[tool.Tool.Name]; !exists {
+				tr.tools[tool.Tool.Name] = toolRef{toolsetName: toolsetName, tool: tool}
+			}
+		}
+	}
+}
+
+// GetToolByName retrieves a tool from any registered toolset by its name
+func (tr *ToolsetRegistry) GetToolByName(name string) (ToolDefinition, bool) {
+	_, tool, exists := tr.lookupTool(name)
+	return tool, exists
+}
+
+// lookupTool is GetToolByName that also returns the name of the toolset
+// holding the tool.
+func (tr *ToolsetRegistry) lookupTool(name string) (string, ToolDefinition, bool) {
+	tr.mu.RLock()
+	defer tr.mu.RUnlock()
+
+	ref, exists := tr.tools[name]
+	return ref.toolsetName, ref.tool, exists
+}
+
+// GetToolsetName returns the name of the toolset a tool is registered in.
+func (tr *ToolsetRegistry) GetToolsetName(toolName string) (string, bool) {
+	name, _, ok := tr.lookupTool(toolName)
+	return name, ok
 }
 
 // Get retrieves a toolset by name
 func (tr *ToolsetRegistry) Get(name string) (Toolset, bool) {
+	tr.mu.RLock()
+	defer tr.mu.RUnlock()
+
 	toolset, exists := tr.toolsets[name]
 	return toolset, exists
 }
 
 // GetToolsForToolsets returns tools from specified toolset names, optionally filtering for read-only
 func (tr *ToolsetRegistry) GetToolsForToolsets(toolsetNames []string, readOnlyMode bool) []ToolDefinition {
+	tr.mu.RLock()
+	defer tr.mu.RUnlock()
+
 	var tools []ToolDefinition
 
 	for _, name := range toolsetNames {
@@ -114,6 +174,14 @@ func (tr *ToolsetRegistry) GetToolsForToolsets(toolsetNames []string, readOnlyMo
 
 // List returns all registered toolset names
 func (tr *ToolsetRegistry) List() []string {
+	tr.mu.RLock()
+	defer tr.mu.RUnlock()
+
+	return tr.names()
+}
+
+// names is List for callers already holding tr.mu.
+func (tr *ToolsetRegistry) names() []string {
 	names := make([]string, 0, len(tr.toolsets))
 	for name := range tr.toolsets {
 		names = append(names, name)
@@ -122,17 +190,23 @@ func (tr *ToolsetRegistry) List() []string {
 	return names
 }
 
-// expandAllToolsets replaces the "all" sentinel with the full list of
-// registered toolset names; otherwise returns enabled unchanged.
+// expandAllToolsets replaces the "all" sentinel with the registered toolset
+// names, except opt-in toolsets that aren't also named; otherwise returns
+// enabled with aliases expanded.
 func (tr *ToolsetRegistry) expandAllToolsets(enabled []string) []string {
 	if slices.Contains(enabled, ToolsetAll) {
-		return tr.List()
+		return slices.DeleteFunc(tr.names(), func(name string) bool {
+			return IsOptInToolset(name) && !slices.Contains(enabled, name)
+		})
 	}
-	return enabled
+	return ExpandToolsetAliases(enabled)
 }
 
 // GetEnabledTools returns tools from enabled toolsets, optionally filtering for read-only
 func (tr *ToolsetRegistry) GetEnabledTools(enabledToolsets []string, readOnlyMode bool) []ToolDefinition {
+	tr.mu.RLock()
+	defer tr.mu.RUnlock()
+
 	var tools []ToolDefinition
 
 	enabledToolsets = tr.expandAllToolsets(enabledToolsets)
@@ -152,6 +226,9 @@ func (tr *ToolsetRegistry) GetEnabledTools(enabledToolsets []string, readOnlyMod
 
 // GetAllTools returns all tools across all toolsets
 func (tr *ToolsetRegistry) GetAllTools() []ToolDefinition {
+	tr.mu.RLock()
+	defer tr.mu.RUnlock()
+
 	var tools []ToolDefinition
 	for _, toolset := range tr.toolsets {
 		tools = append(tools, toolset.Tools...)
@@ -169,6 +246,9 @@ type ToolsetMetadata struct {
 
 // GetMetadata returns metadata for all registered toolsets
 func (tr *ToolsetRegistry) GetMetadata() []ToolsetMetadata {
+	tr.mu.RLock()
+	defer tr.mu.RUnlock()
+
 	metadata := make([]ToolsetMetadata, 0, len(tr.toolsets))
 
 	for name, toolset := range tr.toolsets {
@@ -196,6 +276,9 @@ func (tr *ToolsetRegistry) GetMetadata() []ToolsetMetadata {
 
 // GetRequiredScopes returns all unique scopes required by enabled toolsets
 func (tr *ToolsetRegistry) GetRequiredScopes(enabledToolsets []string, readOnlyMode bool) []string {
+	tr.mu.RLock()
+	defer tr.mu.RUnlock()
+
 	scopeMap := make(map[string]bool)
 
 	enabledToolsets = tr.expandAllToolsets(enabledToolsets)
@@ -247,6 +330,9 @@ const (
 	ToolsetInvestigations = "investigations"
 	ToolsetUser           = "user"
 	ToolsetSkills         = "skills"
+	ToolsetNotifications  = "notifications"
+	ToolsetDiscovery      = "discovery"
+	ToolsetGraphQL        = "graphql"
 )
 
 var ValidToolsets = []string{
@@ -262,6 +348,67 @@ var ValidToolsets = []string{
 	ToolsetInvestigations,
 	ToolsetUser,
 	ToolsetSkills,
+	ToolsetNotifications,
+	ToolsetDiscovery,
+	ToolsetGraphQL,
+}
+
+// OptInToolsets are left out of ToolsetAll and aliases, and only enabled when
+// named, because their tools reach beyond what the other toolsets allow.
+var OptInToolsets = []string{
+	ToolsetGraphQL,
+}
+
+// IsOptInToolset reports whether name is only enabled when named.
+func IsOptInToolset(name string) bool {
+	return slices.Contains(OptInToolsets, name)
+}
+
+// ToolsetAliases maps meta-names that can be used wherever toolsets are
+// enabled to the toolsets they stand for.
+var ToolsetAliases = map[string][]string{
+	"ci":         {ToolsetBuilds, ToolsetLogs, ToolsetAnnotations},
+	"monitoring": {ToolsetAgents, ToolsetClusters, ToolsetBuilds},
+}
+
+func init() {
+	if err := validateToolsetAliases(); err != nil {
+		panic(err)
+	}
+}
+
+// validateToolsetAliases checks that no alias shadows a toolset and that
+// every alias expands to real toolsets.
+func validateToolsetAliases() error {
+	for alias, targets := range ToolsetAliases {
+		if IsValidToolset(alias) {
+			return fmt.Errorf("toolset alias %q shadows a toolset", alias)
+		}
+		for _, target := range targets {
+			if target == ToolsetAll || !IsValidToolset(target) {
+				return fmt.Errorf("toolset alias %q targets invalid toolset %q", alias, target)
+			}
+		}
+	}
+	return nil
+}
+
+// ExpandToolsetAliases replaces any aliases in names with the toolsets they
+// stand for, dropping duplicates but otherwise keeping the order.
+func ExpandToolsetAliases(names []string) []string {
+	expanded := make([]string, 0, len(names))
+	for _, name := range names {
+		targets, ok := ToolsetAliases[name]
+		if !ok {
+			targets = []string{name}
+		}
+		for _, target := range targets {
+			if !slices.Contains(expanded, target) {
+				expanded = append(expanded, target)
+			}
+		}
+	}
+	return expanded
 }
 
 // IsValidToolset checks if a toolset name is valid
@@ -270,17 +417,19 @@ func IsValidToolset(name string) bool {
 }
 
 // IsToolsetEnabled reports whether name is enabled, treating ToolsetAll as a
-// wildcard that enables every toolset.
+// wildcard that enables every toolset but the opt-in ones and expanding
+// aliases.
 func IsToolsetEnabled(enabled []string, name string) bool {
-	return slices.Contains(enabled, ToolsetAll) || slices.Contains(enabled, name)
+	enabled = ExpandToolsetAliases(enabled)
+	return slices.Contains(enabled, name) || (slices.Contains(enabled, ToolsetAll) && !IsOptInToolset(name))
 }
 
-// ValidateToolsets checks if all toolset names are valid
+// ValidateToolsets checks if all toolset names are valid toolsets or aliases
 func ValidateToolsets(names []string) error {
 	invalidToolsets := []string{}
 
 	for _, name := range names {
-		if !IsValidToolset(name) {
+		if _, isAlias := ToolsetAliases[name]; !isAlias && !IsValidToolset(name) {
 			invalidToolsets = append(invalidToolsets, name)
 		}
 	}
@@ -294,6 +443,13 @@ func ValidateToolsets(names []string) error {
 // The generic parameters In and Out match the typed handler signature.
 func newToolDef[In, Out any](toolFunc func() (mcp.Tool, mcp.ToolHandlerFor[In, Out], []string)) ToolDefinition {
 	tool, handler, scopes := toolFunc()
+	if tool.InputSchema == nil {
+		// Infer the schema here, as mcp.AddTool would, so it is available
+		// for introspection (describe_tool) without registering the tool.
+		if schema, err := jsonschema.For[In](&jsonschema.ForOptions{}); err == nil {
+			tool.InputSchema = schema
+		}
+	}
 	return ToolDefinition{
 		Tool: tool,
 		Register: func(s *mcp.Server) {
@@ -337,6 +493,7 @@ func CreateBuiltinToolsets() map[string]Toolset {
 			Tools: []ToolDefinition{
 				newToolDef(buildkite.GetPipeline),
 				newToolDef(buildkite.ListPipelines),
+				newToolDef(buildkite.GetPipelineMetrics),
 				newToolDef(buildkite.CreatePipeline),
 				newToolDef(buildkite.UpdatePipeline),
 				newToolDef(buildkite.ListPipelineSchedules),
@@ -351,13 +508,21 @@ func CreateBuiltinToolsets() map[string]Toolset {
 			Tools: []ToolDefinition{
 				newToolDef(buildkite.ListBuilds),
 				newToolDef(buildkite.GetBuild),
+				newToolDef(buildkite.WaitForBuild),
+				newToolDef(buildkite.GetBuildByCommit),
+				newToolDef(buildkite.GetLatestBuild),
+				newToolDef(buildkite.CompareBuilds),
+				newToolDef(buildkite.ListBlockedBuilds),
+				newToolDef(buildkite.ListRunningBuilds),
 				newToolDef(buildkite.GetBuildTestEngineRuns),
 				newToolDef(buildkite.CreateBuild),
 				newToolDef(buildkite.CancelBuild),
+				newToolDef(buildkite.CancelRunningBuilds),
 				newToolDef(buildkite.RebuildBuild),
 				newToolDef(buildkite.ListJobs),
 				newToolDef(buildkite.GetJob),
 				newToolDef(buildkite.UnblockJob),
+				newToolDef(buildkite.UnblockAllJobs),
 				newToolDef(buildkite.RetryJob),
 				newToolDef(buildkite.GetJobEnvironmentVariables),
 			},
@@ -388,6 +553,15 @@ func CreateBuiltinToolsets() map[string]Toolset {
 				newToolDef(buildkite.SearchLogs),
 				newToolDef(buildkite.TailLogs),
 				newToolDef(buildkite.ReadLogs),
+				newToolDef(buildkite.GetJobLogs),
+				newToolDef(buildkite.GetBuildFailedLogs),
+			},
+		},
+		ToolsetNotifications: {
+			Name:        "Notification Services",
+			Description: "Tools for auditing where pipelines send notifications",
+			Tools: []ToolDefinition{
+				newToolDef(buildkite.ListPipelineNotificationServices),
 			},
 		},
 		ToolsetAnnotations: {
@@ -422,5 +596,12 @@ func CreateBuiltinToolsets() map[string]Toolset {
 				newToolDef(buildkite.LoadSkill),
 			},
 		},
+		ToolsetGraphQL: {
+			Name:        "GraphQL",
+			Description: "Run queries against the Buildkite GraphQL API. Only enabled when named, not by 'all'",
+			Tools: []ToolDefinition{
+				newToolDef(buildkite.GraphQLQuery),
+			},
+		},
 	}
 }
@@ -1,8 +1,12 @@
 package toolsets
 
 import (
+	"fmt"
+	"strings"
+	"sync"
 	"testing"
 
+	"github.com/google/jsonschema-go/jsonschema"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -211,6 +215,67 @@ func TestToolsetRegistry_List(t *testing.T) {
 	})
 }
 
+func TestToolsetRegistry_GetToolByName(t *testing.T) {
+	assert := require.New(t)
+
+	registry := NewToolsetRegistry()
+	registry.Register("b", Toolset{Tools: []ToolDefinition{
+		{Tool: mcp.Tool{Name: "shared", Description: "from b"}},
+		{Tool: mcp.Tool{Name: "only_b"}},
+	}})
+	registry.Register("a", Toolset{Tools: []ToolDefinition{
+		{Tool: mcp.Tool{Name: "shared", Description: "from a"}},
+	}})
+
+	tool, ok := registry.GetToolByName("only_b")
+	assert.True(ok)
+	assert.Equal("only_b", tool.Tool.Name)
+
+	// A name in several toolsets resolves to the first toolset by name,
+	// regardless of registration order.
+	toolsetName, tool, ok := registry.lookupTool("shared")
+	assert.True(ok)
+	assert.Equal("a", toolsetName)
+	assert.Equal("from a", tool.Tool.Description)
+
+	_, ok = registry.GetToolByName("missing")
+	assert.False(ok)
+
+	// Re-registering a toolset drops its old tools from the index.
+	registry.Register("b", Toolset{Tools: []ToolDefinition{{Tool: mcp.Tool{Name: "new_b"}}}})
+	_, ok = registry.GetToolByName("only_b")
+	assert.False(ok)
+	_, ok = registry.GetToolByName("new_b")
+	assert.True(ok)
+}
+
+func TestToolsetRegistry_ConcurrentRegisterAndRead(t *testing.T) {
+	registry := NewToolsetRegistry()
+	registry.RegisterToolsets(CreateBuiltinToolsets())
+
+	var wg sync.WaitGroup
+	for i := range 10 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			registry.Register(fmt.Sprintf("extra-%d", i), Toolset{Tools: []ToolDefinition{
+				{Tool: mcp.Tool{Name: fmt.Sprintf("extra_tool_%d", i)}},
+			}})
+		}()
+		go func() {
+			defer wg.Done()
+			_, _ = registry.Get(ToolsetBuilds)
+			_ = registry.List()
+			_ = registry.GetEnabledTools([]string{ToolsetAll}, true)
+			_ = registry.GetMetadata()
+			_ = registry.SearchToolsWithMetadata("extra", SearchOptions{Fuzzy: true})
+		}()
+	}
+	wg.Wait()
+
+	require.Len(t, registry.SearchToolsWithMetadata("extra_tool", SearchOptions{}), 10)
+}
+
 func TestToolsetRegistry_GetToolsForToolsets(t *testing.T) {
 	registry := NewToolsetRegistry()
 
@@ -612,6 +677,10 @@ func TestIsToolsetEnabled(t *testing.T) {
 		{"not in list", []string{"builds"}, "logs", false},
 		{"present among several", []string{"builds", "logs", "annotations"}, "logs", true},
 		{"empty enabled list", []string{}, "builds", false},
+		{"alias enables its targets", []string{"ci"}, "logs", true},
+		{"alias does not enable other toolsets", []string{"ci"}, "clusters", false},
+		{"all does not enable an opt-in toolset", []string{"all"}, "graphql", false},
+		{"opt-in toolset enabled by name", []string{"all", "graphql"}, "graphql", true},
 	}
 
 	for _, tt := range tests {
@@ -645,6 +714,52 @@ func TestValidateToolsets(t *testing.T) {
 		err := ValidateToolsets([]string{})
 		assert.NoError(err)
 	})
+
+	t.Run("aliases are valid", func(t *testing.T) {
+		assert := require.New(t)
+		assert.NoError(ValidateToolsets([]string{"ci", "monitoring", "user"}))
+	})
+}
+
+func TestExpandToolsetAliases(t *testing.T) {
+	assert := require.New(t)
+
+	assert.Equal([]string{"user", "builds", "logs", "annotations"}, ExpandToolsetAliases([]string{"user", "ci"}))
+	// Toolsets shared between aliases are listed once.
+	assert.Equal([]string{"builds", "logs", "annotations", "agents", "clusters"}, ExpandToolsetAliases([]string{"ci", "monitoring", "builds"}))
+	assert.Equal([]string{"all"}, ExpandToolsetAliases([]string{"all"}))
+	assert.Empty(ExpandToolsetAliases(nil))
+}
+
+func TestValidateToolsetAliases(t *testing.T) {
+	assert := require.New(t)
+	assert.NoError(validateToolsetAliases())
+
+	original := ToolsetAliases
+	t.Cleanup(func() { ToolsetAliases = original })
+
+	ToolsetAliases = map[string][]string{"broken": {"builds", "bulds"}}
+	assert.EqualError(validateToolsetAliases(), `toolset alias "broken" targets invalid toolset "bulds"`)
+
+	ToolsetAliases = map[string][]string{"logs": {"builds"}}
+	assert.EqualError(validateToolsetAliases(), `toolset alias "logs" shadows a toolset`)
+}
+
+func TestToolsetRegistry_GetEnabledTools_Alias(t *testing.T) {
+	assert := require.New(t)
+
+	registry := NewToolsetRegistry()
+	registry.RegisterToolsets(CreateBuiltinToolsets())
+
+	var want []ToolDefinition
+	for _, name := range ToolsetAliases["ci"] {
+		toolset, _ := registry.Get(name)
+		want = append(want, toolset.Tools...)
+	}
+
+	tools := registry.GetEnabledTools([]string{"ci"}, false)
+	assert.Len(tools, len(want))
+	assert.Equal(registry.GetRequiredScopes([]string{"builds", "logs", "annotations"}, false), registry.GetRequiredScopes([]string{"ci"}, false))
 }
 
 func TestCreateBuiltinToolsets(t *testing.T) {
@@ -667,3 +782,59 @@ func TestCreateBuiltinToolsets(t *testing.T) {
 	assert.Equal("get_build_failure_summary", investigations.Tools[0].Tool.Name)
 	assert.Equal([]string{"read_build_logs", "read_builds", "read_suites"}, investigations.GetRequiredScopes())
 }
+
+// TestBuiltinToolsetsResourceParamNames keeps the parameters that identify an
+// organization, pipeline or build named the same way in every tool, so the
+// model doesn't have to guess between org and org_slug.
+func TestBuiltinToolsetsResourceParamNames(t *testing.T) {
+	canonical := map[string]string{
+		"org":      "org_slug",
+		"pipeline": "pipeline_slug",
+		"build":    "build_number",
+	}
+
+	for _, toolset := range CreateBuiltinToolsets() {
+		for _, tool := range toolset.Tools {
+			schema, ok := tool.Tool.InputSchema.(*jsonschema.Schema)
+			require.True(t, ok, "%s has no input schema", tool.Tool.Name)
+
+			for name := range schema.Properties {
+				for prefix, want := range canonical {
+					if name == prefix || strings.HasPrefix(name, prefix+"_") {
+						require.Equal(t, want, name, "%s should name its %s parameter %s", tool.Tool.Name, prefix, want)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestBuiltinToolsetsWriteToolsSupportDryRun(t *testing.T) {
+	for _, toolset := range CreateBuiltinToolsets() {
+		for _, tool := range toolset.Tools {
+			if tool.IsReadOnly() {
+				continue
+			}
+			schema, ok := tool.Tool.InputSchema.(*jsonschema.Schema)
+			require.True(t, ok, "%s has no input schema", tool.Tool.Name)
+			require.Contains(t, schema.Properties, "dry_run", "%s is a write tool without dry_run", tool.Tool.Name)
+		}
+	}
+}
+
+func TestToolsetRegistry_GetEnabledTools_OptIn(t *testing.T) {
+	registry := NewToolsetRegistry()
+	registry.RegisterToolsets(CreateBuiltinToolsets())
+
+	toolNames := func(enabled ...string) []string {
+		var names []string
+		for _, tool := range registry.GetEnabledTools(enabled, false) {
+			names = append(names, tool.Tool.Name)
+		}
+		return names
+	}
+
+	require.NotContains(t, toolNames("all"), "graphql_query")
+	require.Contains(t, toolNames("all", "graphql"), "graphql_query")
+	require.Equal(t, []string{"graphql_query"}, toolNames("graphql"))
+}
@@ -2,7 +2,10 @@ package trace
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"slices"
+	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/rs/zerolog/log"
@@ -10,7 +13,61 @@ import (
 	"go.opentelemetry.io/otel/codes"
 )
 
-func NewMiddleware() mcp.Middleware {
+// SafeArgumentKeys are tool arguments whose values identify what a tool was
+// called on without being sensitive, so they can be recorded on spans.
+var SafeArgumentKeys = []string{"org_slug", "pipeline_slug", "build_number", "job_id", "cluster_id", "test_suite_slug"}
+
+// MiddlewareOption configures NewMiddleware.
+type MiddlewareOption func(*middlewareConfig)
+
+type middlewareConfig struct {
+	argumentValueKeys map[string]bool
+	slowToolLatency   time.Duration
+	largeResultBytes  int
+	toolObserver      ToolCallObserver
+}
+
+// ToolCallObserver is told how long each tool call took and how many bytes
+// of JSON its result was, such as to aggregate them as metrics.
+type ToolCallObserver interface {
+	ObserveToolCall(toolName string, duration time.Duration, resultBytes int)
+}
+
+// WithArgumentValues records the values of the named tool arguments on spans.
+// Only the keys of other arguments are recorded, as their values may contain
+// secrets such as environment variables or build messages.
+func WithArgumentValues(keys ...string) MiddlewareOption {
+	return func(cfg *middlewareConfig) {
+		for _, key := range keys {
+			cfg.argumentValueKeys[key] = true
+		}
+	}
+}
+
+// WithToolThresholds logs a warning for tool calls that take longer than
+// latency or whose result is larger than resultBytes. Zero disables either
+// check.
+func WithToolThresholds(latency time.Duration, resultBytes int) MiddlewareOption {
+	return func(cfg *middlewareConfig) {
+		cfg.slowToolLatency = latency
+		cfg.largeResultBytes = resultBytes
+	}
+}
+
+// WithToolCallObserver passes the duration and result size of every tool
+// call that returns a result to observer.
+func WithToolCallObserver(observer ToolCallObserver) MiddlewareOption {
+	return func(cfg *middlewareConfig) {
+		cfg.toolObserver = observer
+	}
+}
+
+func NewMiddleware(opts ...MiddlewareOption) mcp.Middleware {
+	cfg := &middlewareConfig{argumentValueKeys: make(map[string]bool)}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
 	return func(next mcp.MethodHandler) mcp.MethodHandler {
 		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
 			ctx, span := Start(ctx, fmt.Sprintf("mcp.%s", method))
@@ -22,8 +79,12 @@ func NewMiddleware() mcp.Middleware {
 				attribute.String("mcp.session_id", sessionID),
 			}
 
-			if params, ok := req.GetParams().(*mcp.CallToolParamsRaw); ok && params != nil {
+			params, isToolCall := req.GetParams().(*mcp.CallToolParamsRaw)
+			if isToolCall && params != nil {
 				attrs = append(attrs, attribute.String("mcp.tool_name", params.Name))
+				if span.IsRecording() {
+					attrs = append(attrs, cfg.argumentAttributes(params.Arguments)...)
+				}
 			}
 
 			var clientName, clientVersion string
@@ -46,7 +107,9 @@ func NewMiddleware() mcp.Middleware {
 			}
 			baseLog.Msg("Handling MCP request")
 
+			start := time.Now()
 			res, err := next(ctx, method, req)
+			duration := time.Since(start)
 			if err != nil {
 				span.RecordError(err)
 				span.SetStatus(codes.Error, err.Error())
@@ -56,6 +119,12 @@ func NewMiddleware() mcp.Middleware {
 				}
 				errLog.Msg("Error in MCP request")
 			} else {
+				if isToolCall && params != nil && (span.IsRecording() || cfg.measuresToolCalls()) {
+					if out, err := json.Marshal(res); err == nil {
+						span.SetAttributes(attribute.Int("mcp.tool.result_bytes", len(out)))
+						cfg.recordToolCall(ctx, params.Name, duration, len(out))
+					}
+				}
 				span.SetStatus(codes.Ok, "OK")
 				completedLog := log.Debug().Str("mcp.method", method).Str("mcp.session_id", sessionID)
 				if clientName != "" {
@@ -68,3 +137,64 @@ func NewMiddleware() mcp.Middleware {
 		}
 	}
 }
+
+// measuresToolCalls reports whether tool call durations and result sizes are
+// needed beyond the span.
+func (cfg *middlewareConfig) measuresToolCalls() bool {
+	return cfg.slowToolLatency > 0 || cfg.largeResultBytes > 0 || cfg.toolObserver != nil
+}
+
+// recordToolCall passes a tool call to the observer and warns when it passed
+// a threshold. The warning uses the context's logger, so over HTTP it names
+// the request.
+func (cfg *middlewareConfig) recordToolCall(ctx context.Context, toolName string, duration time.Duration, resultBytes int) {
+	if cfg.toolObserver != nil {
+		cfg.toolObserver.ObserveToolCall(toolName, duration, resultBytes)
+	}
+
+	slow := cfg.slowToolLatency > 0 && duration > cfg.slowToolLatency
+	large := cfg.largeResultBytes > 0 && resultBytes > cfg.largeResultBytes
+	if !slow && !large {
+		return
+	}
+	log.Ctx(ctx).Warn().
+		Str("mcp.tool_name", toolName).
+		Dur("duration", duration).
+		Int("result_bytes", resultBytes).
+		Bool("slow", slow).
+		Bool("large_result", large).
+		Msg("Tool call exceeded a threshold")
+}
+
+// argumentAttributes describes which arguments a tool was called with: the
+// sorted argument keys, plus the values of allowlisted keys.
+func (cfg *middlewareConfig) argumentAttributes(raw json.RawMessage) []attribute.KeyValue {
+	var args map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &args); err != nil {
+		return nil
+	}
+
+	keys := make([]string, 0, len(args))
+	for key := range args {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	attrs := []attribute.KeyValue{
+		attribute.StringSlice("mcp.tool.argument_keys", keys),
+		attribute.Int("mcp.tool.argument_count", len(keys)),
+	}
+	for _, key := range keys {
+		if !cfg.argumentValueKeys[key] {
+			continue
+		}
+		// Strings are recorded unquoted; other JSON values as written.
+		value := string(args[key])
+		var s string
+		if json.Unmarshal(args[key], &s) == nil {
+			value = s
+		}
+		attrs = append(attrs, attribute.String("mcp.tool.argument."+key, value))
+	}
+	return attrs
+}
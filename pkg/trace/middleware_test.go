@@ -1,12 +1,16 @@
 package trace
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/otel"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
@@ -15,7 +19,7 @@ import (
 
 // setupMiddlewareServer creates a server with the trace middleware and a no-op ping tool,
 // wiring an in-memory span recorder as the global tracer provider.
-func setupMiddlewareServer(t *testing.T) (*mcp.Server, *tracetest.SpanRecorder) {
+func setupMiddlewareServer(t *testing.T, opts ...MiddlewareOption) (*mcp.Server, *tracetest.SpanRecorder) {
 	t.Helper()
 	ctx := context.Background()
 
@@ -25,9 +29,9 @@ func setupMiddlewareServer(t *testing.T) (*mcp.Server, *tracetest.SpanRecorder)
 	t.Cleanup(func() { _ = tp.Shutdown(ctx) })
 
 	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "v0.0.1"}, nil)
-	server.AddReceivingMiddleware(NewMiddleware())
+	server.AddReceivingMiddleware(NewMiddleware(opts...))
 	mcp.AddTool(server, &mcp.Tool{Name: "ping"}, func(_ context.Context, _ *mcp.CallToolRequest, _ any) (*mcp.CallToolResult, any, error) {
-		return &mcp.CallToolResult{}, nil, nil
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "pong"}}}, nil, nil
 	})
 
 	return server, sr
@@ -112,3 +116,111 @@ func TestNewMiddlewareHTTP(t *testing.T) {
 	assert.Equal("v0.0.1", attrs["mcp.client.version"], "mcp.client.version should be captured from initialize handshake")
 	assert.Equal("ping", attrs["mcp.tool_name"], "mcp.tool_name should be set for tools/call requests")
 }
+
+func TestNewMiddlewareToolArguments(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+
+	server, sr := setupMiddlewareServer(t, WithArgumentValues("org_slug", "build_number"))
+
+	t1, t2 := mcp.NewInMemoryTransports()
+	_, err := server.Connect(ctx, t1, nil)
+	assert.NoError(err)
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "v0.0.1"}, nil)
+	session, err := client.Connect(ctx, t2, nil)
+	assert.NoError(err)
+	defer session.Close()
+
+	_, err = session.CallTool(ctx, &mcp.CallToolParams{Name: "ping", Arguments: map[string]any{
+		"org_slug":     "acme",
+		"build_number": 42,
+		"env":          map[string]string{"SECRET": "hunter2"},
+	}})
+	assert.NoError(err)
+
+	tp := otel.GetTracerProvider().(*sdktrace.TracerProvider)
+	assert.NoError(tp.ForceFlush(ctx))
+
+	attrs := map[string]any{}
+	for _, s := range sr.Ended() {
+		if s.Name() == "mcp.tools/call" {
+			for _, a := range s.Attributes() {
+				attrs[string(a.Key)] = a.Value.AsInterface()
+			}
+		}
+	}
+	assert.Equal([]string{"build_number", "env", "org_slug"}, attrs["mcp.tool.argument_keys"])
+	assert.Equal(int64(3), attrs["mcp.tool.argument_count"])
+	assert.Equal("acme", attrs["mcp.tool.argument.org_slug"])
+	assert.Equal("42", attrs["mcp.tool.argument.build_number"])
+	assert.NotContains(attrs, "mcp.tool.argument.env")
+	assert.Greater(attrs["mcp.tool.result_bytes"], int64(len("pong")))
+}
+
+type toolCall struct {
+	name        string
+	duration    time.Duration
+	resultBytes int
+}
+
+type recordingObserver struct {
+	calls []toolCall
+}
+
+func (o *recordingObserver) ObserveToolCall(toolName string, duration time.Duration, resultBytes int) {
+	o.calls = append(o.calls, toolCall{name: toolName, duration: duration, resultBytes: resultBytes})
+}
+
+func TestNewMiddlewareToolThresholds(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+
+	var logs bytes.Buffer
+	logger := zerolog.New(&logs)
+	observer := &recordingObserver{}
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "v0.0.1"}, nil)
+	// The server adds a context logger before the trace middleware.
+	server.AddReceivingMiddleware(
+		func(next mcp.MethodHandler) mcp.MethodHandler {
+			return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+				return next(logger.WithContext(ctx), method, req)
+			}
+		},
+		NewMiddleware(WithToolThresholds(time.Hour, 100), WithToolCallObserver(observer)),
+	)
+	for name, text := range map[string]string{"small": "ok", "large": string(make([]byte, 200))} {
+		mcp.AddTool(server, &mcp.Tool{Name: name}, func(_ context.Context, _ *mcp.CallToolRequest, _ any) (*mcp.CallToolResult, any, error) {
+			return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: text}}}, nil, nil
+		})
+	}
+
+	t1, t2 := mcp.NewInMemoryTransports()
+	_, err := server.Connect(ctx, t1, nil)
+	assert.NoError(err)
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "v0.0.1"}, nil)
+	session, err := client.Connect(ctx, t2, nil)
+	assert.NoError(err)
+	defer session.Close()
+
+	for _, name := range []string{"small", "large"} {
+		_, err = session.CallTool(ctx, &mcp.CallToolParams{Name: name})
+		assert.NoError(err)
+	}
+
+	assert.Len(observer.calls, 2)
+	assert.Equal("small", observer.calls[0].name)
+	assert.Less(observer.calls[0].resultBytes, 100)
+	assert.Equal("large", observer.calls[1].name)
+	assert.Greater(observer.calls[1].resultBytes, 200)
+
+	// Only the large result passed a threshold.
+	var warning map[string]any
+	assert.NoError(json.Unmarshal(logs.Bytes(), &warning))
+	assert.Equal("warn", warning["level"])
+	assert.Equal("large", warning["mcp.tool_name"])
+	assert.Equal(true, warning["large_result"])
+	assert.Equal(false, warning["slow"])
+	assert.Equal(float64(observer.calls[1].resultBytes), warning["result_bytes"])
+}
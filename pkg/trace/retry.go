@@ -0,0 +1,216 @@
+package trace
+
+import (
+	"context"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// retryBaseDelay is the backoff before the first retry when the API
+	// doesn't say how long to wait; it doubles with each attempt.
+	retryBaseDelay = 500 * time.Millisecond
+	// maxRetryDelay caps the wait between attempts, including waits asked
+	// for by the API.
+	maxRetryDelay = time.Minute
+)
+
+// retryTransport retries Buildkite API requests that are rate limited or fail
+// with a server error, waiting as long as the API asks or backing off
+// exponentially, and records each attempt as an event on the caller's span so
+// slow tool calls caused by upstream throttling show up in traces.
+//
+// The server disables go-buildkite's own retries, so with maxAttempts above 1
+// every retry happens here. The limited map only matters for callers that
+// keep the default maxAttempts of 1 and retry on their own by sending the
+// same *http.Request again, which is how their attempts are counted as one
+// request.
+type retryTransport struct {
+	next        http.RoundTripper
+	maxAttempts int
+	now         func() time.Time
+	sleep       func(context.Context, time.Duration) error
+
+	mu      sync.Mutex
+	limited map[*http.Request]rateLimited
+}
+
+// rateLimited is the last rate limited attempt of a request.
+type rateLimited struct {
+	attempt int
+	at      time.Time
+}
+
+func newRetryTransport(next http.RoundTripper, maxAttempts int) *retryTransport {
+	return &retryTransport{
+		next:        next,
+		maxAttempts: max(maxAttempts, 1),
+		now:         time.Now,
+		sleep:       sleepContext,
+		limited:     make(map[*http.Request]rateLimited),
+	}
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	span := trace.SpanFromContext(req.Context())
+
+	attempt := 1
+	t.mu.Lock()
+	previous, retried := t.limited[req]
+	delete(t.limited, req)
+	t.mu.Unlock()
+	if retried {
+		attempt = previous.attempt + 1
+		t.addRetryEvent(span, req, attempt, previous.at)
+	}
+
+	attemptReq := req
+	for tries := 1; ; tries++ {
+		res, err := t.next.RoundTrip(attemptReq)
+		if err != nil || !isRetryable(req, res) {
+			return res, err
+		}
+
+		wait, hasWait := retryAfterSeconds(res.Header)
+		if res.StatusCode == http.StatusTooManyRequests && span.IsRecording() {
+			attrs := []attribute.KeyValue{
+				attribute.String("http.request.method", req.Method),
+				attribute.String("url.path", req.URL.Path),
+				attribute.Int("http.response.status_code", res.StatusCode),
+				attribute.Int("attempt", attempt),
+			}
+			if hasWait {
+				attrs = append(attrs, attribute.Int("retry_after_seconds", wait))
+			}
+			span.AddEvent("buildkite.api.rate_limited", trace.WithAttributes(attrs...))
+		}
+
+		delay := retryDelay(res.Header, tries)
+		deadline, hasDeadline := req.Context().Deadline()
+		if tries >= t.maxAttempts || (hasDeadline && t.now().Add(delay).After(deadline)) {
+			t.remember(req, res, attempt)
+			return res, nil
+		}
+
+		nextReq, ok := rewind(req)
+		if !ok {
+			// The body can't be sent again, so leave retrying to the caller.
+			t.remember(req, res, attempt)
+			return res, nil
+		}
+
+		_, _ = io.Copy(io.Discard, res.Body)
+		_ = res.Body.Close()
+
+		at := t.now()
+		if err := t.sleep(req.Context(), delay); err != nil {
+			return nil, err
+		}
+		attempt++
+		t.addRetryEvent(span, req, attempt, at)
+		attemptReq = nextReq
+	}
+}
+
+// remember records a rate limited response handed back to the caller, so an
+// attempt the caller retries continues the count. The final attempt isn't
+// retried, so the request is forgotten once its caller is done with it.
+func (t *retryTransport) remember(req *http.Request, res *http.Response, attempt int) {
+	if res.StatusCode != http.StatusTooManyRequests || !trace.SpanFromContext(req.Context()).IsRecording() {
+		return
+	}
+
+	t.mu.Lock()
+	t.limited[req] = rateLimited{attempt: attempt, at: t.now()}
+	t.mu.Unlock()
+	context.AfterFunc(req.Context(), func() {
+		t.mu.Lock()
+		delete(t.limited, req)
+		t.mu.Unlock()
+	})
+}
+
+func (t *retryTransport) addRetryEvent(span trace.Span, req *http.Request, attempt int, since time.Time) {
+	if !span.IsRecording() {
+		return
+	}
+	span.AddEvent("buildkite.api.retry", trace.WithAttributes(
+		attribute.String("http.request.method", req.Method),
+		attribute.String("url.path", req.URL.Path),
+		attribute.Int("attempt", attempt),
+		attribute.Int64("waited_ms", t.now().Sub(since).Milliseconds()),
+	))
+}
+
+// isRetryable reports whether a response may succeed if the request is sent
+// again. Rate limited requests were never processed, so any method can be
+// retried; server errors are only retried for methods without side effects.
+func isRetryable(req *http.Request, res *http.Response) bool {
+	switch {
+	case res.StatusCode == http.StatusTooManyRequests:
+		return true
+	case res.StatusCode >= http.StatusInternalServerError && res.StatusCode != http.StatusNotImplemented:
+		return req.Method == http.MethodGet || req.Method == http.MethodHead || req.Method == http.MethodOptions
+	default:
+		return false
+	}
+}
+
+// rewind returns a copy of req that can be sent again, with a fresh body, or
+// false if the body can't be replayed.
+func rewind(req *http.Request) (*http.Request, bool) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return req, true
+	}
+	if req.GetBody == nil {
+		return nil, false
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, false
+	}
+	clone := req.Clone(req.Context())
+	clone.Body = body
+	return clone, true
+}
+
+// retryDelay returns how long to wait before sending a request again: as long
+// as the API asked, or an exponential backoff with jitter, capped at
+// maxRetryDelay.
+func retryDelay(header http.Header, tries int) time.Duration {
+	if seconds, ok := retryAfterSeconds(header); ok {
+		return min(time.Duration(seconds)*time.Second, maxRetryDelay)
+	}
+	backoff := retryBaseDelay << min(tries-1, 10)
+	//nolint:gosec // G404: jitter for retry backoff, not cryptographic
+	return min(backoff+rand.N(retryBaseDelay), maxRetryDelay)
+}
+
+// retryAfterSeconds returns how long the server asked the client to wait,
+// from Buildkite's RateLimit-Reset header or a standard Retry-After.
+func retryAfterSeconds(header http.Header) (int, bool) {
+	for _, name := range []string{"RateLimit-Reset", "Retry-After"} {
+		if seconds, err := strconv.Atoi(header.Get(name)); err == nil && seconds >= 0 {
+			return seconds, true
+		}
+	}
+	return 0, false
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
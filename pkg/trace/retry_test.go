@@ -0,0 +1,197 @@
+package trace
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	gobuildkite "github.com/buildkite/go-buildkite/v5"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestRetryTransport_Events(t *testing.T) {
+	assert := require.New(t)
+
+	requests := 0
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.Header().Set("RateLimit-Reset", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":"123","name":"Buildkite"}`))
+	}))
+	defer api.Close()
+
+	client, err := gobuildkite.NewOpts(
+		gobuildkite.WithBaseURL(api.URL),
+		gobuildkite.WithHTTPClient(NewHTTPClientWithHeaders(nil)),
+	)
+	assert.NoError(err)
+
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+	defer func() { _ = tp.Shutdown(context.Background()) }()
+
+	ctx, span := tp.Tracer("test").Start(context.Background(), "tool")
+	_, _, err = client.User.CurrentUser(ctx)
+	assert.NoError(err)
+	span.End()
+
+	// Without an active span the events are skipped.
+	requests = 0
+	_, _, err = client.User.CurrentUser(context.Background())
+	assert.NoError(err)
+
+	var events []sdktrace.Event
+	for _, s := range sr.Ended() {
+		if s.Name() == "tool" {
+			events = s.Events()
+		}
+	}
+	assert.Len(events, 2)
+
+	assert.Equal("buildkite.api.rate_limited", events[0].Name)
+	attrs := map[string]any{}
+	for _, kv := range events[0].Attributes {
+		attrs[string(kv.Key)] = kv.Value.AsInterface()
+	}
+	assert.Equal(int64(http.StatusTooManyRequests), attrs["http.response.status_code"])
+	assert.Equal(int64(1), attrs["attempt"])
+	assert.Equal(int64(0), attrs["retry_after_seconds"])
+	assert.Equal("/v2/user", attrs["url.path"])
+
+	assert.Equal("buildkite.api.retry", events[1].Name)
+	attrs = map[string]any{}
+	for _, kv := range events[1].Attributes {
+		attrs[string(kv.Key)] = kv.Value.AsInterface()
+	}
+	assert.Equal(int64(2), attrs["attempt"])
+	assert.Contains(attrs, "waited_ms")
+}
+
+func TestRetryAfterSeconds(t *testing.T) {
+	assert := require.New(t)
+
+	seconds, ok := retryAfterSeconds(http.Header{"Ratelimit-Reset": {"12"}, "Retry-After": {"30"}})
+	assert.True(ok)
+	assert.Equal(12, seconds)
+
+	seconds, ok = retryAfterSeconds(http.Header{"Retry-After": {"30"}})
+	assert.True(ok)
+	assert.Equal(30, seconds)
+
+	_, ok = retryAfterSeconds(http.Header{"Retry-After": {"Wed, 21 Oct 2015 07:28:00 GMT"}})
+	assert.False(ok)
+}
+
+// fakeTransport replies to each request with the next of its status codes.
+type fakeTransport struct {
+	statuses []int
+	header   http.Header
+	requests []*http.Request
+	bodies   []string
+}
+
+func (f *fakeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	f.requests = append(f.requests, req)
+	if req.Body != nil {
+		body, _ := io.ReadAll(req.Body)
+		f.bodies = append(f.bodies, string(body))
+	}
+	status := f.statuses[min(len(f.requests), len(f.statuses))-1]
+	return &http.Response{
+		StatusCode: status,
+		Header:     f.header.Clone(),
+		Body:       io.NopCloser(strings.NewReader("")),
+		Request:    req,
+	}, nil
+}
+
+func TestRetryTransport(t *testing.T) {
+	newTransport := func(next http.RoundTripper, maxAttempts int) (*retryTransport, *[]time.Duration) {
+		var slept []time.Duration
+		rt := newRetryTransport(next, maxAttempts)
+		rt.sleep = func(_ context.Context, d time.Duration) error {
+			slept = append(slept, d)
+			return nil
+		}
+		return rt, &slept
+	}
+
+	t.Run("retries a rate limited request after the reset", func(t *testing.T) {
+		assert := require.New(t)
+
+		next := &fakeTransport{statuses: []int{429, 200}, header: http.Header{"Ratelimit-Reset": {"3"}}}
+		rt, slept := newTransport(next, 4)
+
+		req := httptest.NewRequest(http.MethodPost, "https://api.buildkite.com/v2/builds", strings.NewReader(`{"commit":"HEAD"}`))
+		req.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(strings.NewReader(`{"commit":"HEAD"}`)), nil }
+		res, err := rt.RoundTrip(req)
+		assert.NoError(err)
+		assert.Equal(http.StatusOK, res.StatusCode)
+		assert.Equal([]time.Duration{3 * time.Second}, *slept)
+		assert.Equal([]string{`{"commit":"HEAD"}`, `{"commit":"HEAD"}`}, next.bodies)
+	})
+
+	t.Run("backs off on server errors", func(t *testing.T) {
+		assert := require.New(t)
+
+		next := &fakeTransport{statuses: []int{503, 502, 200}}
+		rt, slept := newTransport(next, 4)
+
+		res, err := rt.RoundTrip(httptest.NewRequest(http.MethodGet, "https://api.buildkite.com/v2/user", nil))
+		assert.NoError(err)
+		assert.Equal(http.StatusOK, res.StatusCode)
+		assert.Len(*slept, 2)
+		assert.GreaterOrEqual((*slept)[0], retryBaseDelay)
+		assert.GreaterOrEqual((*slept)[1], 2*retryBaseDelay)
+	})
+
+	t.Run("gives up after max attempts", func(t *testing.T) {
+		assert := require.New(t)
+
+		next := &fakeTransport{statuses: []int{429}, header: http.Header{"Retry-After": {"600"}}}
+		rt, slept := newTransport(next, 3)
+
+		res, err := rt.RoundTrip(httptest.NewRequest(http.MethodGet, "https://api.buildkite.com/v2/user", nil))
+		assert.NoError(err)
+		assert.Equal(http.StatusTooManyRequests, res.StatusCode)
+		assert.Len(next.requests, 3)
+		assert.Equal([]time.Duration{maxRetryDelay, maxRetryDelay}, *slept)
+	})
+
+	t.Run("doesn't retry server errors with side effects", func(t *testing.T) {
+		assert := require.New(t)
+
+		next := &fakeTransport{statuses: []int{500, 200}}
+		rt, _ := newTransport(next, 4)
+
+		res, err := rt.RoundTrip(httptest.NewRequest(http.MethodPost, "https://api.buildkite.com/v2/builds", nil))
+		assert.NoError(err)
+		assert.Equal(http.StatusInternalServerError, res.StatusCode)
+		assert.Len(next.requests, 1)
+	})
+
+	t.Run("doesn't wait past the context deadline", func(t *testing.T) {
+		assert := require.New(t)
+
+		next := &fakeTransport{statuses: []int{429, 200}, header: http.Header{"Ratelimit-Reset": {"30"}}}
+		rt, slept := newTransport(next, 4)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		req := httptest.NewRequest(http.MethodGet, "https://api.buildkite.com/v2/user", nil).WithContext(ctx)
+		res, err := rt.RoundTrip(req)
+		assert.NoError(err)
+		assert.Equal(http.StatusTooManyRequests, res.StatusCode)
+		assert.Empty(*slept)
+	})
+}
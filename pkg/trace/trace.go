@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"os"
 
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 	"go.opentelemetry.io/otel"
@@ -21,10 +22,65 @@ import (
 // tracerName is the instrumentation library name, fixed for this package.
 const tracerName = "buildkite-mcp-server"
 
-func NewProvider(ctx context.Context, exporter, name, version string) (*sdktrace.TracerProvider, error) {
-	exp, err := newExporter(ctx, exporter)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create exporter: %w", err)
+// ProviderOption configures the OTLP exporter and sampling of NewProvider.
+// Options take precedence over the equivalent standard OTEL_* environment
+// variables.
+type ProviderOption func(*providerConfig)
+
+type providerConfig struct {
+	endpoint    string
+	headers     map[string]string
+	sampleRatio *float64
+}
+
+// WithEndpoint sets the OTLP collector URL, e.g. https://collector:4318.
+func WithEndpoint(endpoint string) ProviderOption {
+	return func(cfg *providerConfig) {
+		cfg.endpoint = endpoint
+	}
+}
+
+// WithHeaders sets headers sent with every export, such as an API key.
+func WithHeaders(headers map[string]string) ProviderOption {
+	return func(cfg *providerConfig) {
+		cfg.headers = headers
+	}
+}
+
+// WithSampleRatio samples this fraction of new traces, from 0 to 1. Spans
+// with a sampled parent are always sampled, so traces aren't broken up.
+func WithSampleRatio(ratio float64) ProviderOption {
+	return func(cfg *providerConfig) {
+		cfg.sampleRatio = &ratio
+	}
+}
+
+// ResolveExporter returns the exporter NewProvider will use. Setting an
+// endpoint implies http/protobuf if no exporter was chosen, and with no
+// endpoint from either the option or OTEL_EXPORTER_OTLP_ENDPOINT or
+// OTEL_EXPORTER_OTLP_TRACES_ENDPOINT, tracing is a no-op.
+func ResolveExporter(exporter, endpoint string) string {
+	if endpoint == "" {
+		endpoint = os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT")
+	}
+	if endpoint == "" {
+		endpoint = os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
+	}
+
+	switch {
+	case endpoint == "":
+		return "noop"
+	case exporter == "" || exporter == "noop":
+		return "http/protobuf"
+	default:
+		return exporter
+	}
+}
+
+func NewProvider(ctx context.Context, exporter, name, version string, opts ...ProviderOption) (*sdktrace.TracerProvider, error) {
+	cfg := &providerConfig{}
+	for _, opt := range opts {
+		opt(cfg)
 	}
 
 	res, err := newResource(ctx, name, version)
@@ -32,10 +88,25 @@ func NewProvider(ctx context.Context, exporter, name, version string) (*sdktrace
 		return nil, fmt.Errorf("failed to create resource: %w", err)
 	}
 
-	tp := sdktrace.NewTracerProvider(
-		sdktrace.WithBatcher(exp),
-		sdktrace.WithResource(res),
-	)
+	providerOpts := []sdktrace.TracerProviderOption{sdktrace.WithResource(res)}
+	exporter = ResolveExporter(exporter, cfg.endpoint)
+	if exporter == "noop" {
+		// Nothing is exported, so don't record spans at all.
+		providerOpts = append(providerOpts, sdktrace.WithSampler(sdktrace.NeverSample()))
+	} else {
+		exp, err := newExporter(ctx, exporter, cfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create exporter: %w", err)
+		}
+		providerOpts = append(providerOpts, sdktrace.WithBatcher(exp))
+		if cfg.sampleRatio != nil {
+			providerOpts = append(providerOpts, sdktrace.WithSampler(
+				sdktrace.ParentBased(sdktrace.TraceIDRatioBased(*cfg.sampleRatio)),
+			))
+		}
+	}
+
+	tp := sdktrace.NewTracerProvider(providerOpts...)
 	otel.SetTracerProvider(tp)
 
 	otel.SetTextMapPropagator(
@@ -63,36 +134,100 @@ func NewError(span trace.Span, msg string, args ...any) error {
 	return fmt.Errorf(msg, args...)
 }
 
-func NewHTTPClient() *http.Client {
+// HTTPClientOption configures the http.Client returned by NewHTTPClient and
+// its variants.
+type HTTPClientOption func(*httpClientConfig)
+
+type httpClientConfig struct {
+	maxAttempts int
+}
+
+// WithMaxAttempts sends a request up to n times while it is rate limited or,
+// for GET, HEAD and OPTIONS requests, fails with a server error. Defaults to
+// 1, which leaves retrying to the caller. When enabling this for a
+// go-buildkite client, disable its own retries with WithMaxRetries(0) so
+// attempts aren't multiplied.
+func WithMaxAttempts(n int) HTTPClientOption {
+	return func(cfg *httpClientConfig) {
+		cfg.maxAttempts = n
+	}
+}
+
+func NewHTTPClient(opts ...HTTPClientOption) *http.Client {
+	return NewHTTPClientWithTransport(http.DefaultTransport, opts...)
+}
+
+// NewHTTPClientWithTransport is like NewHTTPClient but sends requests with
+// base, wrapped in the same tracing and retries, instead of
+// http.DefaultTransport. A nil base uses http.DefaultTransport. Use this to
+// test code that makes requests against a fake transport.
+func NewHTTPClientWithTransport(base http.RoundTripper, opts ...HTTPClientOption) *http.Client {
 	return &http.Client{
-		Transport: otelhttp.NewTransport(http.DefaultTransport),
+		Transport: newClientTransport(base, opts),
 	}
 }
 
 // NewHTTPClientWithHeaders returns an http.Client that injects the provided headers into every request.
-func NewHTTPClientWithHeaders(headers map[string]string) *http.Client {
-	return NewHTTPClientWithHeadersAndTransport(headers, http.DefaultTransport)
+func NewHTTPClientWithHeaders(headers map[string]string, opts ...HTTPClientOption) *http.Client {
+	return NewHTTPClientWithHeaderValuesAndTransport(headerValues(headers), http.DefaultTransport, opts...)
 }
 
 // NewHTTPClientWithHeadersAndTransport is like NewHTTPClientWithHeaders but uses inner as the
 // innermost RoundTripper instead of http.DefaultTransport. Use this to inject a recording or replay transport.
-func NewHTTPClientWithHeadersAndTransport(headers map[string]string, inner http.RoundTripper) *http.Client {
+func NewHTTPClientWithHeadersAndTransport(headers map[string]string, inner http.RoundTripper, opts ...HTTPClientOption) *http.Client {
+	return NewHTTPClientWithHeaderValuesAndTransport(headerValues(headers), inner, opts...)
+}
+
+// NewHTTPClientWithHeaderValues is like NewHTTPClientWithHeaders but takes an
+// http.Header, so a header can be sent with more than one value.
+func NewHTTPClientWithHeaderValues(headers http.Header, opts ...HTTPClientOption) *http.Client {
+	return NewHTTPClientWithHeaderValuesAndTransport(headers, http.DefaultTransport, opts...)
+}
+
+// NewHTTPClientWithHeaderValuesAndTransport is like
+// NewHTTPClientWithHeaderValues but uses inner as the innermost RoundTripper
+// instead of http.DefaultTransport.
+func NewHTTPClientWithHeaderValuesAndTransport(headers http.Header, inner http.RoundTripper, opts ...HTTPClientOption) *http.Client {
 	return &http.Client{
 		Transport: &headerInjector{
 			headers: headers,
-			wrapped: otelhttp.NewTransport(inner),
+			wrapped: newClientTransport(inner, opts),
 		},
 	}
 }
 
+func headerValues(headers map[string]string) http.Header {
+	values := make(http.Header, len(headers))
+	for k, v := range headers {
+		values.Set(k, v)
+	}
+	return values
+}
+
+func newClientTransport(inner http.RoundTripper, opts []HTTPClientOption) http.RoundTripper {
+	cfg := &httpClientConfig{maxAttempts: 1}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if inner == nil {
+		inner = http.DefaultTransport
+	}
+	return newRetryTransport(otelhttp.NewTransport(inner), cfg.maxAttempts)
+}
+
 type headerInjector struct {
-	headers map[string]string
+	headers http.Header
 	wrapped http.RoundTripper
 }
 
+// RoundTrip replaces any values the request has for each of the injected
+// headers, sending every value of a header with more than one.
 func (h *headerInjector) RoundTrip(req *http.Request) (*http.Response, error) {
-	for k, v := range h.headers {
-		req.Header.Set(k, v)
+	for k, values := range h.headers {
+		req.Header.Del(k)
+		for _, v := range values {
+			req.Header.Add(k, v)
+		}
 	}
 	return h.wrapped.RoundTrip(req)
 }
@@ -116,12 +251,26 @@ func newResource(cxt context.Context, name, version string) (*resource.Resource,
 	)
 }
 
-func newExporter(ctx context.Context, exporter string) (sdktrace.SpanExporter, error) {
+func newExporter(ctx context.Context, exporter string, cfg *providerConfig) (sdktrace.SpanExporter, error) {
 	switch exporter {
 	case "http/protobuf":
-		return otlptracehttp.New(ctx)
+		var opts []otlptracehttp.Option
+		if cfg.endpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpointURL(cfg.endpoint))
+		}
+		if len(cfg.headers) > 0 {
+			opts = append(opts, otlptracehttp.WithHeaders(cfg.headers))
+		}
+		return otlptracehttp.New(ctx, opts...)
 	case "grpc":
-		return otlptracegrpc.New(ctx)
+		var opts []otlptracegrpc.Option
+		if cfg.endpoint != "" {
+			opts = append(opts, otlptracegrpc.WithEndpointURL(cfg.endpoint))
+		}
+		if len(cfg.headers) > 0 {
+			opts = append(opts, otlptracegrpc.WithHeaders(cfg.headers))
+		}
+		return otlptracegrpc.New(ctx, opts...)
 	default:
 		return tracetest.NewNoopExporter(), nil
 	}
@@ -2,6 +2,7 @@ package trace
 
 import (
 	"context"
+	"net/http"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -23,3 +24,102 @@ func TestNewProvider(t *testing.T) {
 	_, err = NewProvider(context.Background(), "", "test", "1.2.3")
 	assert.NoError(err)
 }
+
+func TestResolveExporter(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "")
+
+	assert := require.New(t)
+	assert.Equal("noop", ResolveExporter("http/protobuf", ""))
+	assert.Equal("http/protobuf", ResolveExporter("noop", "https://collector:4318"))
+	assert.Equal("grpc", ResolveExporter("grpc", "https://collector:4317"))
+
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "https://collector:4318")
+	assert.Equal("http/protobuf", ResolveExporter("http/protobuf", ""))
+}
+
+func TestNewProvider_Sampling(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "")
+
+	assert := require.New(t)
+	ctx := context.Background()
+
+	// Without an endpoint spans aren't recorded.
+	provider, err := NewProvider(ctx, "http/protobuf", "test", "1.2.3")
+	assert.NoError(err)
+	_, span := provider.Tracer("test").Start(ctx, "noop")
+	assert.False(span.IsRecording())
+	assert.NoError(provider.Shutdown(ctx))
+
+	provider, err = NewProvider(ctx, "", "test", "1.2.3",
+		WithEndpoint("http://127.0.0.1:4318"),
+		WithHeaders(map[string]string{"x-api-key": "secret"}),
+		WithSampleRatio(0),
+	)
+	assert.NoError(err)
+	_, span = provider.Tracer("test").Start(ctx, "unsampled")
+	assert.False(span.IsRecording())
+
+	provider, err = NewProvider(ctx, "", "test", "1.2.3",
+		WithEndpoint("http://127.0.0.1:4318"),
+		WithSampleRatio(1),
+	)
+	assert.NoError(err)
+	_, span = provider.Tracer("test").Start(ctx, "sampled")
+	assert.True(span.IsRecording())
+	span.End()
+}
+
+func TestNewHTTPClientWithTransport(t *testing.T) {
+	assert := require.New(t)
+
+	base := &fakeTransport{statuses: []int{http.StatusServiceUnavailable, http.StatusOK}}
+	client := NewHTTPClientWithTransport(base, WithMaxAttempts(2))
+
+	res, err := client.Get("https://api.buildkite.com/v2/user")
+	assert.NoError(err)
+	defer res.Body.Close()
+
+	// The retried request goes through the base transport again.
+	assert.Equal(http.StatusOK, res.StatusCode)
+	assert.Len(base.requests, 2)
+	assert.Equal("api.buildkite.com", base.requests[0].URL.Host)
+}
+
+func TestNewHTTPClientWithHeaders(t *testing.T) {
+	assert := require.New(t)
+
+	base := &fakeTransport{statuses: []int{http.StatusOK}}
+	client := NewHTTPClientWithHeadersAndTransport(map[string]string{"x-team": "builds"}, base)
+
+	req, err := http.NewRequest(http.MethodGet, "https://api.buildkite.com/v2/user", nil)
+	assert.NoError(err)
+	req.Header.Set("X-Team", "replaced")
+
+	res, err := client.Do(req)
+	assert.NoError(err)
+	defer res.Body.Close()
+
+	assert.Equal([]string{"builds"}, base.requests[0].Header.Values("X-Team"))
+}
+
+func TestNewHTTPClientWithHeaderValues_MultipleValues(t *testing.T) {
+	assert := require.New(t)
+
+	base := &fakeTransport{statuses: []int{http.StatusOK}}
+	headers := http.Header{"X-Proxy": {"one", "two"}, "X-Team": {"builds"}}
+	client := NewHTTPClientWithHeaderValuesAndTransport(headers, base)
+
+	req, err := http.NewRequest(http.MethodGet, "https://api.buildkite.com/v2/user", nil)
+	assert.NoError(err)
+	req.Header.Set("X-Team", "replaced")
+
+	res, err := client.Do(req)
+	assert.NoError(err)
+	defer res.Body.Close()
+
+	assert.Len(base.requests, 1)
+	assert.Equal([]string{"one", "two"}, base.requests[0].Header.Values("X-Proxy"))
+	assert.Equal([]string{"builds"}, base.requests[0].Header.Values("X-Team"))
+}